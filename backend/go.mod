@@ -3,16 +3,23 @@ module github.com/Mattouff/Lending-Borrowing
 go 1.24.2
 
 require (
+	github.com/gofiber/contrib/websocket v1.3.4
 	github.com/gofiber/swagger v1.1.1
 	github.com/golang-jwt/jwt/v4 v4.5.2
+	github.com/graph-gophers/graphql-go v1.3.0
+	github.com/vektah/gqlparser/v2 v2.5.31
 )
 
 require (
+	github.com/agnivade/levenshtein v1.2.1 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.7 // indirect
+	github.com/fasthttp/websocket v1.5.8
 	github.com/gabriel-vasile/mimetype v1.4.8 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
+	github.com/opentracing/opentracing-go v1.1.0 // indirect
+	github.com/savsgio/gotils v0.0.0-20240303185622-093b76447511 // indirect
 	github.com/swaggo/files/v2 v2.0.2 // indirect
 	github.com/urfave/cli/v2 v2.27.6 // indirect
 	github.com/valkey-io/valkey-go v1.0.59
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// LinkChallengeRequest represents the address a user wants to link to its account
+type LinkChallengeRequest struct {
+	Address string `json:"address" validate:"required,checksum_addr"`
+}
+
+// LinkChallengeResponse represents the message to sign with both the primary address and the address to link
+type LinkChallengeResponse struct {
+	Nonce     string    `json:"nonce"`
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// LinkAddressRequest represents a link challenge signed by both addresses
+type LinkAddressRequest struct {
+	Address          string `json:"address" validate:"required,checksum_addr"`
+	Nonce            string `json:"nonce" validate:"required"`
+	PrimarySignature string `json:"primarySignature" validate:"required"` // Signature of the challenge by the primary address
+	AddressSignature string `json:"addressSignature" validate:"required"` // Signature of the challenge by the address to link
+}
+
+// UserAddressResponse represents a linked address in API responses
+type UserAddressResponse struct {
+	Address   string    `json:"address"`
+	Primary   bool      `json:"primary"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// UserAddressListResponse represents the addresses linked to a user for API responses
+type UserAddressListResponse struct {
+	Addresses []UserAddressResponse `json:"addresses"`
+}
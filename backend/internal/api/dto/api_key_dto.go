@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest represents the data needed to issue an API key
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" validate:"required,max=100"`
+	Scopes     []string   `json:"scopes" validate:"required,min=1"`                       // e.g. "read:market", "read:positions", "write:lending", "liquidate"
+	AllowedIPs []string   `json:"allowedIps,omitempty" validate:"omitempty,dive,ip|cidr"` // IPs or CIDRs, empty allows any
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`                                    // Optional
+}
+
+// APIKeyResponse represents an API key in API responses; its secret is never included
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowedIps"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreateAPIKeyResponse represents a newly issued API key along with its plaintext value, shown only once
+type CreateAPIKeyResponse struct {
+	APIKey APIKeyResponse `json:"apiKey"`
+	Key    string         `json:"key"`
+}
+
+// APIKeyListResponse represents the API keys of a user for API responses
+type APIKeyListResponse struct {
+	APIKeys []APIKeyResponse `json:"apiKeys"`
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLogFilterRequest represents the query parameters selecting audit log entries. The dates bound the
+// recording date; entries have no type or status to filter by
+type AuditLogFilterRequest struct {
+	FilterRequest
+	ActorID    uint   `query:"actorId" validate:"omitempty,min=1"`
+	Action     string `query:"action" validate:"omitempty,max=50"` // e.g. user.verify
+	TargetType string `query:"targetType" validate:"omitempty,max=50"`
+	TargetID   string `query:"targetId" validate:"omitempty,max=100"`
+}
+
+// AuditLogResponse represents an audit log entry in API responses
+type AuditLogResponse struct {
+	ID           uint            `json:"id"`
+	ActorID      *uint           `json:"actorId,omitempty"`
+	ActorAddress string          `json:"actorAddress,omitempty"`
+	ActorRole    string          `json:"actorRole,omitempty"`
+	APIKeyID     *uint           `json:"apiKeyId,omitempty"`
+	Action       string          `json:"action"`
+	TargetType   string          `json:"targetType,omitempty"`
+	TargetID     string          `json:"targetId,omitempty"`
+	RequestID    string          `json:"requestId,omitempty"`
+	IP           string          `json:"ip,omitempty"`
+	Status       int             `json:"status,omitempty"`
+	Changes      json.RawMessage `json:"changes,omitempty"`
+	PrevHash     string          `json:"prevHash"`
+	Hash         string          `json:"hash"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// AuditLogListResponse represents a page of audit log entries for API responses
+type AuditLogListResponse struct {
+	Entries   []AuditLogResponse `json:"entries"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	PageSize  int                `json:"pageSize"`
+	TotalPage int                `json:"totalPage"`
+}
+
+// AuditVerificationResponse represents the result of checking the audit log hash chain
+type AuditVerificationResponse struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	HeadHash string `json:"headHash"`
+	BrokenAt *uint  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+import "time"
+
+// BundleActionRequest represents one action of a bundle
+type BundleActionRequest struct {
+	Action  string `json:"action" validate:"required,oneof=approve depositCollateral borrow repay withdraw"`
+	Amount  string `json:"amount" validate:"required,uint256"`                                // May be 0 for approvals only
+	Spender string `json:"spender,omitempty" validate:"omitempty,oneof=collateral borrowing"` // For approvals; the next step pulling tokens when empty
+}
+
+// CreateBundleRequest represents the data needed to create a bundle of actions
+type CreateBundleRequest struct {
+	Mode    string                `json:"mode" validate:"required,oneof=signer unsigned"`
+	Actions []BundleActionRequest `json:"actions" validate:"required,min=1,max=10,dive"`
+}
+
+// SubmitBundleStepRequest represents the hash of the transaction a wallet sent for a bundle step
+type SubmitBundleStepRequest struct {
+	Hash string `json:"hash" validate:"required"`
+}
+
+// UnsignedTransaction represents a transaction for the user's wallet to sign and send
+type UnsignedTransaction struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Data    string `json:"data"`
+	Value   string `json:"value"`
+	Nonce   uint64 `json:"nonce"`
+	ChainID int    `json:"chainId"`
+}
+
+// BundleStepResponse represents a bundle step in API responses
+type BundleStepResponse struct {
+	Position     int                  `json:"position"`
+	Action       string               `json:"action"`
+	Amount       string               `json:"amount"`
+	Spender      string               `json:"spender,omitempty"`
+	Status       string               `json:"status"`                // pending, submitted, completed, failed or skipped
+	Transaction  *UnsignedTransaction `json:"transaction,omitempty"` // Of unsigned bundles
+	Hash         string               `json:"hash,omitempty"`
+	BlockNumber  uint64               `json:"blockNumber,omitempty"`
+	GasUsed      uint64               `json:"gasUsed,omitempty"`
+	ErrorMessage string               `json:"errorMessage,omitempty"`
+}
+
+// BundleResponse represents a bundle in API responses
+type BundleResponse struct {
+	ID        uint                 `json:"id"`
+	MarketID  uint                 `json:"marketId"`
+	Address   string               `json:"address"`
+	Mode      string               `json:"mode"`
+	Status    string               `json:"status"` // prepared, running, completed or failed
+	Error     string               `json:"error,omitempty"`
+	Steps     []BundleStepResponse `json:"steps"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+// BundleListResponse represents a page of bundles for API responses
+type BundleListResponse struct {
+	Bundles   []BundleResponse `json:"bundles"`
+	Total     int64            `json:"total"`
+	Page      int              `json:"page"`
+	PageSize  int              `json:"pageSize"`
+	TotalPage int              `json:"totalPage"`
+}
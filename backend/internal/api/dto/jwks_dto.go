@@ -0,0 +1,18 @@
+package dto
+
+// JSONWebKey represents a public key as a JSON Web Key (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`           // RSA or OKP
+	Use string `json:"use"`           // Always "sig"
+	Alg string `json:"alg"`           // RS256 or EdDSA
+	Kid string `json:"kid"`           // Key ID, as sent in the "kid" header of tokens
+	N   string `json:"n,omitempty"`   // RSA modulus, base64url encoded
+	E   string `json:"e,omitempty"`   // RSA public exponent, base64url encoded
+	Crv string `json:"crv,omitempty"` // Curve of OKP keys, Ed25519
+	X   string `json:"x,omitempty"`   // Ed25519 public key, base64url encoded
+}
+
+// JSONWebKeySet represents the public keys access tokens may be signed with
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
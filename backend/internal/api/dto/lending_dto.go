@@ -3,26 +3,40 @@ package dto
 // LendingInfoResponse represents lending information for a user
 type LendingInfoResponse struct {
 	TotalDeposited      string `json:"totalDeposited"`
+	TotalDepositedUSD   string `json:"totalDepositedUSD,omitempty"`
 	InterestEarned      string `json:"interestEarned"`
+	InterestEarnedUSD   string `json:"interestEarnedUSD,omitempty"`
 	CurrentInterestRate string `json:"currentInterestRate"`
 }
 
 // BorrowingInfoResponse represents borrowing information for a user
 type BorrowingInfoResponse struct {
 	TotalBorrowed       string `json:"totalBorrowed"`
+	TotalBorrowedUSD    string `json:"totalBorrowedUSD,omitempty"`
 	InterestAccrued     string `json:"interestAccrued"`
+	InterestAccruedUSD  string `json:"interestAccruedUSD,omitempty"`
 	CurrentInterestRate string `json:"currentInterestRate"`
 }
 
 // CollateralInfoResponse represents collateral information for a user
 type CollateralInfoResponse struct {
 	TotalCollateral    string `json:"totalCollateral"`
+	TotalCollateralUSD string `json:"totalCollateralUSD,omitempty"`
 	CollateralRatio    string `json:"collateralRatio"`
 	MinCollateralRatio string `json:"minCollateralRatio"`
 	MaxBorrowable      string `json:"maxBorrowable"`
+	MaxBorrowableUSD   string `json:"maxBorrowableUSD,omitempty"`
 	IsAtRisk           bool   `json:"isAtRisk"`
 }
 
+// CollateralReconciliationResponse represents the result of a protocol-wide collateral cross-check
+type CollateralReconciliationResponse struct {
+	OnChainCollateral string `json:"onChainCollateral"`
+	IndexedCollateral string `json:"indexedCollateral"`
+	Difference        string `json:"difference"`
+	Diverged          bool   `json:"diverged"`
+}
+
 // LiquidatablePositionResponse represents a position that can be liquidated
 type LiquidatablePositionResponse struct {
 	PositionID       uint   `json:"positionId"`
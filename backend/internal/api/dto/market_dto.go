@@ -1,13 +1,24 @@
 package dto
 
+// TVLBreakdown splits the protocol value into its components
+type TVLBreakdown struct {
+	Lending    string `json:"lending"`
+	Collateral string `json:"collateral"`
+	Borrowed   string `json:"borrowed"`
+}
+
 // MarketOverviewResponse represents overall market data
 type MarketOverviewResponse struct {
-	TotalValueLocked    string `json:"totalValueLocked"`
-	TotalBorrowed       string `json:"totalBorrowed"`
-	ActiveUsers         int64  `json:"activeUsers"`
-	ActivePositions     int64  `json:"activePositions"`
-	AverageLendingAPY   string `json:"averageLendingAPY"`
-	AverageBorrowingAPY string `json:"averageBorrowingAPY"`
+	Market              string       `json:"market"`
+	TotalValueLocked    string       `json:"totalValueLocked"`
+	TotalValueLockedUSD string       `json:"totalValueLockedUSD,omitempty"`
+	TVLBreakdown        TVLBreakdown `json:"tvlBreakdown"`
+	TotalBorrowed       string       `json:"totalBorrowed"`
+	TotalBorrowedUSD    string       `json:"totalBorrowedUSD,omitempty"`
+	ActiveUsers         int64        `json:"activeUsers"`
+	ActivePositions     int64        `json:"activePositions"`
+	AverageLendingAPY   string       `json:"averageLendingAPY"`
+	AverageBorrowingAPY string       `json:"averageBorrowingAPY"`
 }
 
 // TokenMetadata represents information about a token
@@ -16,23 +27,44 @@ type TokenMetadata struct {
 	Symbol   string `json:"symbol"`
 	Name     string `json:"name"`
 	Decimals int    `json:"decimals"`
-	PriceUSD string `json:"priceUSD"`
+	PriceUSD string `json:"priceUSD,omitempty"`
 	LogoURI  string `json:"logoURI,omitempty"`
 }
 
 // TokenMarketData represents market data for a specific token
 type TokenMarketData struct {
-	Token              TokenMetadata `json:"token"`
-	TotalSupply        string        `json:"totalSupply"`
-	TotalDeposited     string        `json:"totalDeposited"`
-	TotalBorrowed      string        `json:"totalBorrowed"`
-	AvailableLiquidity string        `json:"availableLiquidity"`
-	LendingAPY         string        `json:"lendingAPY"`
-	BorrowingAPY       string        `json:"borrowingAPY"`
-	CollateralFactor   string        `json:"collateralFactor"`
+	Market                string        `json:"market"`
+	Token                 TokenMetadata `json:"token"`
+	TotalSupply           string        `json:"totalSupply"`
+	TotalDeposited        string        `json:"totalDeposited"`
+	TotalDepositedUSD     string        `json:"totalDepositedUSD,omitempty"`
+	TotalBorrowed         string        `json:"totalBorrowed"`
+	TotalBorrowedUSD      string        `json:"totalBorrowedUSD,omitempty"`
+	AvailableLiquidity    string        `json:"availableLiquidity"`
+	AvailableLiquidityUSD string        `json:"availableLiquidityUSD,omitempty"`
+	LendingAPY            string        `json:"lendingAPY"`
+	BorrowingAPY          string        `json:"borrowingAPY"`
+	CollateralFactor      string        `json:"collateralFactor"`
+	BlockNumber           uint64        `json:"blockNumber"`
 }
 
 // TokensMarketResponse represents market data for multiple tokens
 type TokensMarketResponse struct {
 	Tokens []TokenMarketData `json:"tokens"`
 }
+
+// MarketResponse represents a market and its contracts
+type MarketResponse struct {
+	ID                 uint   `json:"id"`
+	Identifier         string `json:"identifier"`
+	Name               string `json:"name"`
+	TokenAddress       string `json:"tokenAddress"`
+	LendingPoolAddress string `json:"lendingPoolAddress"`
+	BorrowingAddress   string `json:"borrowingAddress"`
+	CollateralAddress  string `json:"collateralAddress"`
+}
+
+// MarketsResponse represents the list of active markets
+type MarketsResponse struct {
+	Markets []MarketResponse `json:"markets"`
+}
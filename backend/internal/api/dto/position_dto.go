@@ -15,6 +15,7 @@ const (
 type PositionResponse struct {
 	ID               uint           `json:"id"`
 	UserID           uint           `json:"userId"`
+	MarketID         uint           `json:"marketId"`
 	CollateralAmount string         `json:"collateralAmount"`
 	CollateralToken  string         `json:"collateralToken"`
 	BorrowedAmount   string         `json:"borrowedAmount"`
@@ -33,4 +34,24 @@ type PositionListResponse struct {
 	Page      int                `json:"page"`
 	PageSize  int                `json:"pageSize"`
 	TotalPage int                `json:"totalPage"`
+	Next      string             `json:"next,omitempty"` // Link to the following page, by cursor
+	Prev      string             `json:"prev,omitempty"` // Link to the preceding page, by cursor
+}
+
+// PositionListRequest represents the query parameters of position lists. The status takes comma-separated
+// statuses, e.g. "active,liquidated", and the dates bound the opening date
+type PositionListRequest struct {
+	PaginationRequest
+	FilterRequest
+	MinHealthFactor string `query:"minHealthFactor"`                            // Inclusive, in the unit of healthFactor
+	MaxHealthFactor string `query:"maxHealthFactor"`                            // Inclusive, in the unit of healthFactor
+	Address         string `query:"address" validate:"omitempty,checksum_addr"` // Owner of the positions, admin list only
+	SortBy          string `query:"sortBy" validate:"omitempty,oneof=createdAt healthFactor size"`
+	Order           string `query:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+// PositionDetailResponse represents a position along with the transactions that changed it
+type PositionDetailResponse struct {
+	Position     PositionResponse      `json:"position"`
+	Transactions []TransactionResponse `json:"transactions"`
 }
@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// CreateDataExportRequest represents the data needed to request a personal data export
+type CreateDataExportRequest struct {
+	Format string `json:"format" validate:"omitempty,oneof=json zip"` // json (default) or zip
+}
+
+// DataExportResponse represents a personal data export in API responses
+type DataExportResponse struct {
+	ID          uint       `json:"id"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"` // pending, processing, ready or failed
+	Size        int64      `json:"size,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// DataExportListResponse represents the data exports of a user for API responses
+type DataExportListResponse struct {
+	Exports []DataExportResponse `json:"exports"`
+}
+
+// CreateDeletionRequest represents a request from a user to have their account purged
+type CreateDeletionRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=1000"`
+}
+
+// ReviewDeletionRequest represents the decision of an admin on a deletion request
+type ReviewDeletionRequest struct {
+	Note string `json:"note,omitempty" validate:"max=1000"`
+}
+
+// DeletionRequestResponse represents an account deletion request in API responses
+type DeletionRequestResponse struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"userId"`
+	Reason     string     `json:"reason,omitempty"`
+	Status     string     `json:"status"` // pending, approved or rejected
+	ReviewedBy *uint      `json:"reviewedBy,omitempty"`
+	ReviewNote string     `json:"reviewNote,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// DeletionRequestListResponse represents a page of deletion requests for API responses
+type DeletionRequestListResponse struct {
+	Requests  []DeletionRequestResponse `json:"requests"`
+	Total     int64                     `json:"total"`
+	Page      int                       `json:"page"`
+	PageSize  int                       `json:"pageSize"`
+	TotalPage int                       `json:"totalPage"`
+}
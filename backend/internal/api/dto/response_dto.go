@@ -8,10 +8,12 @@ type APIResponse struct {
 	Error   string `json:"error,omitempty"`
 }
 
-// PaginationRequest represents pagination parameters for list requests
+// PaginationRequest represents pagination parameters for list requests. A cursor, taken from the next or prev
+// link of a list response, replaces the page number
 type PaginationRequest struct {
-	Page     int `query:"page" validate:"omitempty,min=1"`
-	PageSize int `query:"pageSize" validate:"omitempty,min=1,max=100"`
+	Page     int    `query:"page" validate:"omitempty,page"`
+	PageSize int    `query:"pageSize" validate:"omitempty,page_size"`
+	Cursor   string `query:"cursor"`
 }
 
 // FilterRequest represents common filter parameters
@@ -22,9 +24,13 @@ type FilterRequest struct {
 	Type      string `query:"type" validate:"omitempty"`
 }
 
-// ErrorResponse represents an error response
+// ErrorResponse represents an error response. Clients should act on the code, which never changes meaning;
+// error repeats the message for clients written before codes were added
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"` // e.g. the maximum amount, or the message of each invalid field
+	Error   string         `json:"error"`
 }
 
 // SuccessResponse represents a simple success response
@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+// SolvencyAlertResponse represents a solvency ratio below one of its thresholds
+type SolvencyAlertResponse struct {
+	Level     string `json:"level"`
+	Metric    string `json:"metric"`
+	Ratio     string `json:"ratio"`
+	Threshold string `json:"threshold"`
+}
+
+// SolvencyReportResponse represents the solvency of a market
+type SolvencyReportResponse struct {
+	Market              string                  `json:"market"`
+	BlockNumber         uint64                  `json:"blockNumber"`
+	LendingLiability    string                  `json:"lendingLiability"`
+	LenderInterest      string                  `json:"lenderInterest"`
+	CollateralLiability string                  `json:"collateralLiability"`
+	LendingPoolBalance  string                  `json:"lendingPoolBalance"`
+	BorrowingBalance    string                  `json:"borrowingBalance"`
+	CollateralBalance   string                  `json:"collateralBalance"`
+	TotalBorrowed       string                  `json:"totalBorrowed"`
+	BorrowInterest      string                  `json:"borrowInterest"`
+	TotalAssets         string                  `json:"totalAssets"`
+	TotalLiabilities    string                  `json:"totalLiabilities"`
+	NetReserves         string                  `json:"netReserves"`
+	NetReservesUSD      string                  `json:"netReservesUSD,omitempty"`
+	CoverageRatio       string                  `json:"coverageRatio"`
+	LiquidityRatio      string                  `json:"liquidityRatio"`
+	Status              string                  `json:"status"`
+	Alerts              []SolvencyAlertResponse `json:"alerts"`
+	GeneratedAt         time.Time               `json:"generatedAt"`
+}
+
+// SolvencySnapshotResponse represents a point of the solvency time series
+type SolvencySnapshotResponse struct {
+	LendingLiability    string    `json:"lendingLiability"`
+	LenderInterest      string    `json:"lenderInterest"`
+	CollateralLiability string    `json:"collateralLiability"`
+	LendingPoolBalance  string    `json:"lendingPoolBalance"`
+	BorrowingBalance    string    `json:"borrowingBalance"`
+	CollateralBalance   string    `json:"collateralBalance"`
+	TotalBorrowed       string    `json:"totalBorrowed"`
+	BorrowInterest      string    `json:"borrowInterest"`
+	NetReserves         string    `json:"netReserves"`
+	CoverageRatio       string    `json:"coverageRatio"`
+	LiquidityRatio      string    `json:"liquidityRatio"`
+	Status              string    `json:"status"`
+	BlockNumber         uint64    `json:"blockNumber"`
+	Timestamp           time.Time `json:"timestamp"`
+}
+
+// SolvencyHistoryResponse represents the solvency time series of a market
+type SolvencyHistoryResponse struct {
+	From      time.Time                  `json:"from"`
+	To        time.Time                  `json:"to"`
+	Snapshots []SolvencySnapshotResponse `json:"snapshots"`
+}
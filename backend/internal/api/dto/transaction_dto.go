@@ -11,6 +11,9 @@ const (
 	TransactionTypeBorrow    TransactionType = "borrow"
 	TransactionTypeRepay     TransactionType = "repay"
 	TransactionTypeLiquidate TransactionType = "liquidate"
+
+	TransactionTypeCollateralDeposit  TransactionType = "collateral_deposit"
+	TransactionTypeCollateralWithdraw TransactionType = "collateral_withdraw"
 )
 
 // TransactionStatus for DTO
@@ -24,20 +27,23 @@ const (
 
 // TransactionRequest represents data for a new transaction
 type TransactionRequest struct {
-	Amount string `json:"amount" validate:"required"`
+	Amount string `json:"amount" validate:"required,positive_amount"` // In token units
 }
 
 // TransactionLiquidationRequest represents data for a liquidation transaction
 type TransactionLiquidationRequest struct {
-	BorrowerAddress string `json:"borrowerAddress" validate:"required,eth_addr"`
-	Amount          string `json:"amount" validate:"required"`
+	BorrowerAddress string `json:"borrowerAddress" validate:"required,checksum_addr"`
+	Amount          string `json:"amount" validate:"required,positive_amount"`
 }
 
 // TransactionResponse represents a transaction in API responses
 type TransactionResponse struct {
 	ID           uint              `json:"id"`
 	UserID       uint              `json:"userId"`
+	MarketID     uint              `json:"marketId"`
 	Type         TransactionType   `json:"type"`
+	Contract     string            `json:"contract"`
+	Role         string            `json:"role"`
 	Status       TransactionStatus `json:"status"`
 	Hash         string            `json:"hash"`
 	Amount       string            `json:"amount"`
@@ -49,6 +55,15 @@ type TransactionResponse struct {
 	UpdatedAt    time.Time         `json:"updatedAt"`
 }
 
+// TransactionListRequest represents the filters of a transaction list. Type, status, contract and role
+// take comma-separated values; the date window covers startDate through endDate
+type TransactionListRequest struct {
+	PaginationRequest
+	FilterRequest
+	Contract string `query:"contract"`
+	Role     string `query:"role"`
+}
+
 // TransactionListResponse represents a list of transactions for API responses
 type TransactionListResponse struct {
 	Transactions []TransactionResponse `json:"transactions"`
@@ -56,4 +71,6 @@ type TransactionListResponse struct {
 	Page         int                   `json:"page"`
 	PageSize     int                   `json:"pageSize"`
 	TotalPage    int                   `json:"totalPage"`
+	Next         string                `json:"next,omitempty"` // Link to the following page, by cursor
+	Prev         string                `json:"prev,omitempty"` // Link to the preceding page, by cursor
 }
@@ -6,21 +6,35 @@ import "time"
 type UserRole string
 
 const (
-	UserRoleUser  UserRole = "user"
-	UserRoleAdmin UserRole = "admin"
+	UserRoleUser       UserRole = "user"
+	UserRoleAdmin      UserRole = "admin"
+	UserRoleOperator   UserRole = "operator"
+	UserRoleAuditor    UserRole = "auditor"
+	UserRoleLiquidator UserRole = "liquidator"
 )
 
 // UserRegistrationRequest represents the data needed to register a new user
 type UserRegistrationRequest struct {
-	Address  string `json:"address" validate:"required,eth_addr"`
+	Address  string `json:"address" validate:"required,checksum_addr"`
 	Username string `json:"username" validate:"required,min=3,max=50"`
 }
 
 // UserAuthRequest represents data needed for user authentication
 type UserAuthRequest struct {
-	Address   string `json:"address" validate:"required,eth_addr"`
-	Signature string `json:"signature" validate:"required"`
-	Message   string `json:"message" validate:"required"`
+	Message   string `json:"message" validate:"required"`   // Sign-In with Ethereum (EIP-4361) message
+	Signature string `json:"signature" validate:"required"` // Personal signature of the message
+}
+
+// AddressParams represents the Ethereum address in the path of a request
+type AddressParams struct {
+	Address string `params:"address" validate:"required,checksum_addr"`
+}
+
+// NonceResponse represents a sign-in nonce and the message to sign with it
+type NonceResponse struct {
+	Nonce     string    `json:"nonce"`
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 // UserUpdateRequest represents data that can be updated for a user
@@ -28,6 +42,17 @@ type UserUpdateRequest struct {
 	Username string `json:"username" validate:"omitempty,min=3,max=50"`
 }
 
+// AssignRoleRequest represents the role to give a user
+type AssignRoleRequest struct {
+	Role UserRole `json:"role" validate:"required,oneof=user admin operator auditor liquidator"`
+}
+
+// RoleResponse represents a built-in role and the permissions it grants
+type RoleResponse struct {
+	Role        UserRole `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
 // UserResponse represents a user in API responses
 type UserResponse struct {
 	ID        uint      `json:"id"`
@@ -47,11 +72,46 @@ type UserListResponse struct {
 	Page      int            `json:"page"`
 	PageSize  int            `json:"pageSize"`
 	TotalPage int            `json:"totalPage"`
+	Next      string         `json:"next,omitempty"` // Link to the following page, by cursor
+	Prev      string         `json:"prev,omitempty"` // Link to the preceding page, by cursor
 }
 
 // AuthResponse represents the response after successful authentication
 type AuthResponse struct {
-	User      UserResponse `json:"user"`
-	Token     string       `json:"token"`
-	ExpiresAt time.Time    `json:"expiresAt"`
+	User             UserResponse `json:"user"`
+	SessionID        string       `json:"sessionId"`
+	Token            string       `json:"token"`
+	ExpiresAt        time.Time    `json:"expiresAt"`
+	RefreshToken     string       `json:"refreshToken"`
+	RefreshExpiresAt time.Time    `json:"refreshExpiresAt"`
+}
+
+// RefreshRequest represents data needed to renew an access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// TokenResponse represents a renewed token pair; the previous refresh token can no longer be used
+type TokenResponse struct {
+	SessionID        string    `json:"sessionId"`
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+// SessionResponse represents a login session in API responses
+type SessionResponse struct {
+	ID        string    `json:"id"`
+	Device    string    `json:"device"`
+	IP        string    `json:"ip"`
+	Current   bool      `json:"current"` // Whether the request was made from this session
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SessionListResponse represents the sessions of a user for API responses
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
 }
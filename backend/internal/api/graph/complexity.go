@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"encoding/json"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const (
+	// defaultListSize is the number of items of a list without a limit argument, or when it is omitted
+	defaultListSize = 10
+	// maxListSize is the largest limit a list accepts
+	maxListSize = 100
+)
+
+// complexity validates a query and returns the number of fields it may resolve: every field counts
+// for one, and the fields selected under a list count once per item the list may return
+func (s *Server) complexity(query, operationName string, variables map[string]any) (int, []*errors.QueryError) {
+	document, gqlErrors := gqlparser.LoadQuery(s.analysis, query)
+	if len(gqlErrors) > 0 {
+		queryErrors := make([]*errors.QueryError, len(gqlErrors))
+		for i, gqlErr := range gqlErrors {
+			queryErrors[i] = &errors.QueryError{Message: gqlErr.Message}
+			for _, location := range gqlErr.Locations {
+				queryErrors[i].Locations = append(queryErrors[i].Locations, errors.Location{Line: location.Line, Column: location.Column})
+			}
+		}
+		return 0, queryErrors
+	}
+
+	var operation *ast.OperationDefinition
+	if operationName == "" && len(document.Operations) == 1 {
+		operation = document.Operations[0]
+	} else {
+		operation = document.Operations.ForName(operationName)
+	}
+
+	// The executor reports a missing or ambiguous operation
+	if operation == nil {
+		return 0, nil
+	}
+
+	return selectionComplexity(operation.SelectionSet, variables), nil
+}
+
+// selectionComplexity returns the number of fields a selection may resolve
+func selectionComplexity(selections ast.SelectionSet, variables map[string]any) int {
+	total := 0
+
+	for _, selection := range selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			children := selectionComplexity(selection.SelectionSet, variables)
+			if selection.Definition != nil && selection.Definition.Type.Elem != nil {
+				children *= listSize(selection, variables)
+			}
+			total += 1 + children
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				total += selectionComplexity(selection.Definition.SelectionSet, variables)
+			}
+		case *ast.InlineFragment:
+			total += selectionComplexity(selection.SelectionSet, variables)
+		}
+	}
+
+	return total
+}
+
+// listSize returns the number of items a list field may return, from its limit argument
+func listSize(field *ast.Field, variables map[string]any) int {
+	var limit int
+	switch value := field.ArgumentMap(variables)["limit"].(type) {
+	case int64:
+		limit = int(value)
+	case float64:
+		limit = int(value)
+	case json.Number:
+		parsed, _ := value.Int64()
+		limit = int(parsed)
+	}
+
+	// Out of range limits are rejected by the resolvers
+	if limit < 1 || limit > maxListSize {
+		return defaultListSize
+	}
+	return limit
+}
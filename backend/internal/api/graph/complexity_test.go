@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+)
+
+// newTestServer creates a server over the given services, with the default query limits
+func newTestServer(services Services) *Server {
+	return NewServer(services, &config.Config{GraphQL: config.GraphQLConfig{MaxDepth: 8, MaxComplexity: 1000}})
+}
+
+func TestComplexity(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      int
+	}{
+		{name: "scalar fields", query: `{ me { id username } }`, want: 3},
+		{name: "list without a limit", query: `{ users { id } }`, want: 1 + defaultListSize},
+		{name: "nested lists", query: `{ users(limit: 5) { id positions(limit: 20) { id } } }`, want: 1 + 5*(1+1+20)},
+		{name: "limit out of range", query: `{ users(limit: 500) { id } }`, want: 1 + defaultListSize},
+		{
+			name:  "fragment spread",
+			query: `query { users(limit: 3) { ...account } } fragment account on User { id role positions(limit: 4) { id } }`,
+			want:  1 + 3*(1+1+1+4),
+		},
+		{name: "inline fragment", query: `{ me { ... on User { id role } } }`, want: 3},
+		{
+			name:      "limit as a variable",
+			query:     `query Users($limit: Int) { users(limit: $limit) { id } }`,
+			variables: map[string]any{"limit": float64(50)},
+			want:      1 + 50,
+		},
+		{
+			name:      "limit as a JSON number variable",
+			query:     `query Users($limit: Int) { users(limit: $limit) { id } }`,
+			variables: map[string]any{"limit": json.Number("50")},
+			want:      1 + 50,
+		},
+		{name: "limit variable omitted", query: `query Users($limit: Int) { users(limit: $limit) { id } }`, want: 1 + defaultListSize},
+	}
+
+	s := newTestServer(Services{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			complexity, queryErrors := s.complexity(tt.query, "", tt.variables)
+			if len(queryErrors) > 0 {
+				t.Fatalf("unexpected errors: %v", queryErrors)
+			}
+			if complexity != tt.want {
+				t.Errorf("got complexity %d, want %d", complexity, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecRejectsComplexQueries(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+	}{
+		{name: "nested lists", query: `{ users(limit: 100) { positions(limit: 100) { id } } }`},
+		{
+			name:  "nested lists in a fragment",
+			query: `query { users(limit: 100) { ...positions } } fragment positions on User { positions(limit: 100) { id } }`,
+		},
+		{
+			name:      "limits as variables",
+			query:     `query Users($users: Int, $positions: Int) { users(limit: $users) { positions(limit: $positions) { id } } }`,
+			variables: map[string]any{"users": float64(100), "positions": float64(100)},
+		},
+	}
+
+	// The services are never called: the query is rejected before it runs
+	s := newTestServer(Services{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := s.Exec(context.Background(), Viewer{UserID: 1}, tt.query, "", tt.variables)
+			if len(response.Errors) != 1 || !strings.Contains(response.Errors[0].Message, "exceeds the limit of 1000") {
+				t.Fatalf("got errors %v, want the complexity limit exceeded", response.Errors)
+			}
+			if response.Data != nil {
+				t.Errorf("got data %s, want none", response.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// loader batches the lookups of a query by key. Keys primed or requested before a lookup runs are
+// fetched together, and every result is kept until the end of the query
+type loader[K comparable, V any] struct {
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error)
+	mu      sync.Mutex
+	pending map[K]struct{}
+	loaded  map[K]V
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]struct{}),
+		loaded:  make(map[K]V),
+	}
+}
+
+// prime queues keys to be fetched along with the next lookup, typically those of every item of a list
+func (l *loader[K, V]) prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if _, found := l.loaded[key]; !found {
+			l.pending[key] = struct{}{}
+		}
+	}
+}
+
+// load returns the value of a key, fetching it along with every queued key unless it was already loaded.
+// Keys the fetch does not return are loaded as the zero value
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if value, found := l.loaded[key]; found {
+		return value, nil
+	}
+
+	l.pending[key] = struct{}{}
+	keys := make([]K, 0, len(l.pending))
+	for pending := range l.pending {
+		keys = append(keys, pending)
+	}
+
+	values, err := l.fetch(ctx, keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	// Fetches may return more than asked, such as every market
+	for fetched, value := range values {
+		l.loaded[fetched] = value
+	}
+	for _, fetched := range keys {
+		l.loaded[fetched] = values[fetched]
+	}
+	clear(l.pending)
+
+	return l.loaded[key], nil
+}
+
+// loaders holds the lookups shared by the resolvers of a query
+type loaders struct {
+	users      *loader[uint, *models.User]               // By ID, from the database
+	markets    *loader[uint, *models.Market]             // By ID, from the market registry
+	marketData *loader[string, *service.TokenMarketData] // By market identifier, from the contracts
+}
+
+type loadersKey struct{}
+
+// loadersFrom returns the lookups of the query a resolver runs in
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func newLoaders(services Services) *loaders {
+	return &loaders{
+		users: newLoader(func(ctx context.Context, ids []uint) (map[uint]*models.User, error) {
+			users, err := services.UserRepository.FindByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[uint]*models.User, len(users))
+			for _, user := range users {
+				byID[user.ID] = user
+			}
+			return byID, nil
+		}),
+
+		// There are few markets, so the first lookup loads them all
+		markets: newLoader(func(ctx context.Context, _ []uint) (map[uint]*models.Market, error) {
+			markets, err := services.MarketRegistry.ListMarkets(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[uint]*models.Market, len(markets))
+			for _, market := range markets {
+				byID[market.ID] = market
+			}
+			return byID, nil
+		}),
+
+		// The market service reads the contracts of every market at once, at the same block
+		marketData: newLoader(func(ctx context.Context, _ []string) (map[string]*service.TokenMarketData, error) {
+			tokens, err := services.MarketService.GetTokensMarketData(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			byMarket := make(map[string]*service.TokenMarketData, len(tokens))
+			for _, token := range tokens {
+				byMarket[token.Market] = token
+			}
+			return byMarket, nil
+		}),
+	}
+}
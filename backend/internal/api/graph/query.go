@@ -0,0 +1,322 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// queryResolver resolves the fields of the Query type
+type queryResolver struct {
+	s *Server
+}
+
+// Me resolves the authenticated user
+func (r *queryResolver) Me(ctx context.Context) (*userResolver, error) {
+	user, err := loadersFrom(ctx).users.load(ctx, viewerFrom(ctx).UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return &userResolver{s: r.s, user: user}, nil
+}
+
+// User resolves a user by ID
+func (r *queryResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewerFrom(ctx).canReadAccount(id) {
+		return nil, errAccessDenied
+	}
+
+	return r.s.loadUser(ctx, id)
+}
+
+// Users resolves the users matching the filters
+func (r *queryResolver) Users(ctx context.Context, args struct {
+	Roles    *[]string
+	Verified *bool
+	Limit    *int32
+	Offset   *int32
+}) ([]*userResolver, error) {
+	if !viewerFrom(ctx).can(models.PermUsersRead) {
+		return nil, errAccessDenied
+	}
+
+	limit, offset, err := pageBounds(args.Limit, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.UserFilter{Verified: args.Verified}
+	for _, value := range optionalList(args.Roles) {
+		role := models.UserRole(value)
+		if !role.IsValid() {
+			return nil, fmt.Errorf("invalid role %s", value)
+		}
+		filter.Roles = append(filter.Roles, role)
+	}
+
+	users, err := r.s.services.UserService.ListUsers(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*userResolver, len(users))
+	for i, user := range users {
+		resolvers[i] = &userResolver{s: r.s, user: user}
+	}
+	return resolvers, nil
+}
+
+// Positions resolves the positions of the viewer, or of every user
+func (r *queryResolver) Positions(ctx context.Context, args struct {
+	All      *bool
+	Statuses *[]string
+	Market   *string
+	Limit    *int32
+	Offset   *int32
+}) ([]*positionResolver, error) {
+	viewer := viewerFrom(ctx)
+
+	var userID *uint
+	if args.All == nil || !*args.All {
+		userID = &viewer.UserID
+	} else if !viewer.can(models.PermPositionsReadAll) {
+		return nil, errAccessDenied
+	}
+
+	return r.s.listPositions(ctx, userID, args.Statuses, args.Market, args.Limit, args.Offset)
+}
+
+// Position resolves a position by ID, or null when the viewer has no access to it
+func (r *queryResolver) Position(ctx context.Context, args struct{ ID graphql.ID }) (*positionResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := viewerFrom(ctx)
+	var userID *uint
+	if !viewer.can(models.PermPositionsReadAll) {
+		userID = &viewer.UserID
+	}
+
+	position, _, err := r.s.services.PositionService.GetPosition(ctx, id, userID)
+	if errors.Is(err, service.ErrPositionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.s.positionResolvers(ctx, []*models.Position{position})[0], nil
+}
+
+// Transactions resolves the ledger entries of the viewer, or of every user
+func (r *queryResolver) Transactions(ctx context.Context, args struct {
+	All      *bool
+	Types    *[]string
+	Statuses *[]string
+	Market   *string
+	Limit    *int32
+	Offset   *int32
+}) ([]*transactionResolver, error) {
+	viewer := viewerFrom(ctx)
+
+	var userID *uint
+	if args.All == nil || !*args.All {
+		userID = &viewer.UserID
+	} else if !viewer.can(models.PermPositionsReadAll) {
+		return nil, errAccessDenied
+	}
+
+	return r.s.listTransactions(ctx, userID, args.Types, args.Statuses, args.Market, args.Limit, args.Offset)
+}
+
+// Transaction resolves the ledger entries of an on-chain transaction visible to the viewer
+func (r *queryResolver) Transaction(ctx context.Context, args struct{ Hash string }) ([]*transactionResolver, error) {
+	if decoded, err := hexutil.Decode(args.Hash); err != nil || len(decoded) != 32 {
+		return nil, errors.New("invalid transaction hash")
+	}
+
+	viewer := viewerFrom(ctx)
+	var userID *uint
+	if !viewer.can(models.PermPositionsReadAll) {
+		userID = &viewer.UserID
+	}
+
+	transactions, err := r.s.services.TransactionService.GetTransaction(ctx, args.Hash, userID)
+	if errors.Is(err, service.ErrTransactionNotFound) {
+		return []*transactionResolver{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.s.transactionResolvers(ctx, transactions), nil
+}
+
+// Markets resolves every active market
+func (r *queryResolver) Markets(ctx context.Context) ([]*marketResolver, error) {
+	markets, err := r.s.services.MarketRegistry.ListMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*marketResolver, len(markets))
+	for i, market := range markets {
+		resolvers[i] = &marketResolver{market: market}
+	}
+	return resolvers, nil
+}
+
+// Market resolves an active market by identifier, or null when there is none
+func (r *queryResolver) Market(ctx context.Context, args struct{ Identifier *string }) (*marketResolver, error) {
+	market, err := r.s.services.MarketRegistry.GetMarket(ctx, optionalString(args.Identifier))
+	if errors.Is(err, service.ErrMarketNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &marketResolver{market: market}, nil
+}
+
+// LiquidationCandidates resolves the positions of a market that can be liquidated
+func (r *queryResolver) LiquidationCandidates(ctx context.Context, args struct{ Market *string }) ([]*liquidationCandidateResolver, error) {
+	positions, err := r.s.services.LiquidationService.GetLiquidatablePositions(ctx, optionalString(args.Market))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*liquidationCandidateResolver, len(positions))
+	for i, position := range r.s.positionResolvers(ctx, positions) {
+		candidates[i] = &liquidationCandidateResolver{position: position}
+	}
+	return candidates, nil
+}
+
+// loadUser resolves a user by ID, or null when there is none
+func (s *Server) loadUser(ctx context.Context, id uint) (*userResolver, error) {
+	user, err := loadersFrom(ctx).users.load(ctx, id)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return &userResolver{s: s, user: user}, nil
+}
+
+// listPositions resolves a page of positions, of a user when userID is set
+func (s *Server) listPositions(ctx context.Context, userID *uint, statuses *[]string, market *string, limit, offset *int32) ([]*positionResolver, error) {
+	pageSize, skip, err := pageBounds(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.PositionFilter{UserID: userID, SortBy: models.SortByCreatedAt}
+	for _, value := range optionalList(statuses) {
+		status := models.PositionStatus(value)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status %s, expected active, closed or liquidated", value)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if filter.MarketID, err = s.marketID(ctx, market); err != nil {
+		return nil, err
+	}
+
+	positions, err := s.services.PositionService.ListPositions(ctx, filter, skip, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.positionResolvers(ctx, positions), nil
+}
+
+// listTransactions resolves a page of ledger entries, of a user when userID is set
+func (s *Server) listTransactions(ctx context.Context, userID *uint, types, statuses *[]string, market *string, limit, offset *int32) ([]*transactionResolver, error) {
+	pageSize, skip, err := pageBounds(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.TransactionFilter{UserID: userID}
+	for _, value := range optionalList(types) {
+		transactionType := models.TransactionType(value)
+		if !transactionType.IsValid() {
+			return nil, fmt.Errorf("invalid type %s", value)
+		}
+		filter.Types = append(filter.Types, transactionType)
+	}
+
+	for _, value := range optionalList(statuses) {
+		status := models.TransactionStatus(value)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status %s, expected pending, completed or failed", value)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if filter.MarketID, err = s.marketID(ctx, market); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.services.TransactionService.ListTransactions(ctx, filter, skip, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transactionResolvers(ctx, transactions), nil
+}
+
+// marketID returns the ID of the market named by an optional identifier argument
+func (s *Server) marketID(ctx context.Context, identifier *string) (*uint, error) {
+	if identifier == nil {
+		return nil, nil
+	}
+
+	market, err := s.services.MarketRegistry.GetMarket(ctx, *identifier)
+	if err != nil {
+		return nil, err
+	}
+	return &market.ID, nil
+}
+
+// parseID converts a GraphQL ID into a database ID
+func parseID(id graphql.ID) (uint, error) {
+	value, err := strconv.ParseUint(string(id), 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid ID")
+	}
+	return uint(value), nil
+}
+
+// optionalList returns the values of an optional list argument, none when it is omitted
+func optionalList(values *[]string) []string {
+	if values == nil {
+		return nil
+	}
+	return *values
+}
+
+// optionalString returns the value of an optional string argument, empty when it is omitted
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,96 @@
+// Package graph serves the GraphQL API, a single endpoint over the users, positions, transactions and
+// markets also exposed by the REST routes, so that clients can assemble a view in one request
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// Services holds the domain services and repositories resolvers read from
+type Services struct {
+	UserService        service.UserService
+	PositionService    service.PositionService
+	TransactionService service.TransactionService
+	LiquidationService service.LiquidationService
+	MarketService      service.MarketService
+	MarketRegistry     service.MarketRegistry
+	UserRepository     repository.UserRepository
+}
+
+// Server executes GraphQL queries on behalf of authenticated users
+type Server struct {
+	services      Services
+	schema        *graphql.Schema
+	analysis      *ast.Schema // The same schema, to measure the complexity of queries before running them
+	maxComplexity int
+}
+
+// NewServer creates a GraphQL server over the domain services, with the configured query limits
+func NewServer(services Services, cfg *config.Config) *Server {
+	s := &Server{
+		services:      services,
+		analysis:      gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: schemaSource}),
+		maxComplexity: cfg.GraphQL.MaxComplexity,
+	}
+
+	s.schema = graphql.MustParseSchema(schemaSource, &queryResolver{s: s},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(cfg.GraphQL.MaxDepth),
+	)
+
+	return s
+}
+
+// Exec runs a query for a viewer. Lookups are batched and cached for the duration of the query
+func (s *Server) Exec(ctx context.Context, viewer Viewer, query, operationName string, variables map[string]any) *graphql.Response {
+	complexity, queryErrors := s.complexity(query, operationName, variables)
+	if len(queryErrors) > 0 {
+		return &graphql.Response{Errors: queryErrors}
+	}
+
+	if s.maxComplexity > 0 && complexity > s.maxComplexity {
+		return &graphql.Response{Errors: []*errors.QueryError{
+			errors.Errorf("query complexity %d exceeds the limit of %d", complexity, s.maxComplexity),
+		}}
+	}
+
+	ctx = context.WithValue(ctx, viewerKey{}, &viewer)
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders(s.services))
+
+	return s.schema.Exec(ctx, query, operationName, variables)
+}
+
+// pageBounds applies the defaults and limits of the limit and offset arguments of a list
+func pageBounds(limit, offset *int32) (int, int, error) {
+	pageSize, skip := defaultListSize, 0
+
+	if limit != nil {
+		if *limit < 1 || *limit > maxListSize {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxListSize)
+		}
+		pageSize = int(*limit)
+	}
+
+	if offset != nil {
+		if *offset < 0 {
+			return 0, 0, fmt.Errorf("offset cannot be negative")
+		}
+		skip = int(*offset)
+	}
+
+	return pageSize, skip, nil
+}
@@ -0,0 +1,398 @@
+package graph
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// userResolver resolves the fields of the User type
+type userResolver struct {
+	s    *Server
+	user *models.User
+}
+
+func (r *userResolver) ID() graphql.ID {
+	return formatID(r.user.ID)
+}
+
+func (r *userResolver) Address() string {
+	return r.user.Address
+}
+
+func (r *userResolver) Username() string {
+	return r.user.Username
+}
+
+func (r *userResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.user.CreatedAt}
+}
+
+func (r *userResolver) Role(ctx context.Context) (*string, error) {
+	if !viewerFrom(ctx).canReadAccount(r.user.ID) {
+		return nil, errAccessDenied
+	}
+	role := string(r.user.Role)
+	return &role, nil
+}
+
+func (r *userResolver) Verified(ctx context.Context) (*bool, error) {
+	if !viewerFrom(ctx).canReadAccount(r.user.ID) {
+		return nil, errAccessDenied
+	}
+	return &r.user.Verified, nil
+}
+
+func (r *userResolver) LastLogin(ctx context.Context) (*graphql.Time, error) {
+	if !viewerFrom(ctx).canReadAccount(r.user.ID) {
+		return nil, errAccessDenied
+	}
+	if r.user.LastLogin == nil {
+		return nil, nil
+	}
+	return &graphql.Time{Time: *r.user.LastLogin}, nil
+}
+
+func (r *userResolver) Positions(ctx context.Context, args struct {
+	Statuses *[]string
+	Market   *string
+	Limit    *int32
+	Offset   *int32
+}) (*[]*positionResolver, error) {
+	if !viewerFrom(ctx).canReadPositions(r.user.ID) {
+		return nil, errAccessDenied
+	}
+
+	positions, err := r.s.listPositions(ctx, &r.user.ID, args.Statuses, args.Market, args.Limit, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &positions, nil
+}
+
+func (r *userResolver) Transactions(ctx context.Context, args struct {
+	Types    *[]string
+	Statuses *[]string
+	Market   *string
+	Limit    *int32
+	Offset   *int32
+}) (*[]*transactionResolver, error) {
+	if !viewerFrom(ctx).canReadPositions(r.user.ID) {
+		return nil, errAccessDenied
+	}
+
+	transactions, err := r.s.listTransactions(ctx, &r.user.ID, args.Types, args.Statuses, args.Market, args.Limit, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &transactions, nil
+}
+
+// positionResolver resolves the fields of the Position type
+type positionResolver struct {
+	s        *Server
+	position *models.Position
+}
+
+// positionResolvers wraps positions, queuing the lookup of their users and markets
+func (s *Server) positionResolvers(ctx context.Context, positions []*models.Position) []*positionResolver {
+	lookups := loadersFrom(ctx)
+
+	resolvers := make([]*positionResolver, len(positions))
+	for i, position := range positions {
+		lookups.users.prime(position.UserID)
+		lookups.markets.prime(position.MarketID)
+		resolvers[i] = &positionResolver{s: s, position: position}
+	}
+	return resolvers
+}
+
+func (r *positionResolver) ID() graphql.ID {
+	return formatID(r.position.ID)
+}
+
+func (r *positionResolver) User(ctx context.Context) (*userResolver, error) {
+	return r.s.loadUser(ctx, r.position.UserID)
+}
+
+func (r *positionResolver) Market(ctx context.Context) (*marketResolver, error) {
+	return loadMarket(ctx, r.position.MarketID)
+}
+
+func (r *positionResolver) CollateralAmount() string {
+	return r.position.CollateralAmount
+}
+
+func (r *positionResolver) CollateralToken() string {
+	return r.position.CollateralToken
+}
+
+func (r *positionResolver) BorrowedAmount() string {
+	return r.position.BorrowedAmount
+}
+
+func (r *positionResolver) BorrowedToken() string {
+	return r.position.BorrowedToken
+}
+
+func (r *positionResolver) InterestRate() string {
+	return r.position.InterestRate
+}
+
+func (r *positionResolver) Status() string {
+	return string(r.position.Status)
+}
+
+func (r *positionResolver) HealthFactor() string {
+	return r.position.HealthFactor
+}
+
+func (r *positionResolver) LiquidationPrice() string {
+	return r.position.LiquidationPrice
+}
+
+func (r *positionResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.position.CreatedAt}
+}
+
+func (r *positionResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.position.UpdatedAt}
+}
+
+func (r *positionResolver) Transactions(ctx context.Context) (*[]*transactionResolver, error) {
+	if !viewerFrom(ctx).canReadPositions(r.position.UserID) {
+		return nil, errAccessDenied
+	}
+
+	_, transactions, err := r.s.services.PositionService.GetPosition(ctx, r.position.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := r.s.transactionResolvers(ctx, transactions)
+	return &resolvers, nil
+}
+
+// transactionResolver resolves the fields of the Transaction type
+type transactionResolver struct {
+	s           *Server
+	transaction *models.Transaction
+}
+
+// transactionResolvers wraps ledger entries, queuing the lookup of their users and markets
+func (s *Server) transactionResolvers(ctx context.Context, transactions []*models.Transaction) []*transactionResolver {
+	lookups := loadersFrom(ctx)
+
+	resolvers := make([]*transactionResolver, len(transactions))
+	for i, transaction := range transactions {
+		lookups.users.prime(transaction.UserID)
+		lookups.markets.prime(transaction.MarketID)
+		resolvers[i] = &transactionResolver{s: s, transaction: transaction}
+	}
+	return resolvers
+}
+
+func (r *transactionResolver) ID() graphql.ID {
+	return formatID(r.transaction.ID)
+}
+
+func (r *transactionResolver) Hash() string {
+	return r.transaction.Hash
+}
+
+func (r *transactionResolver) Type() string {
+	return string(r.transaction.Type)
+}
+
+func (r *transactionResolver) Contract() string {
+	return string(r.transaction.Type.Contract())
+}
+
+func (r *transactionResolver) Role() string {
+	return string(r.transaction.Role)
+}
+
+func (r *transactionResolver) Status() string {
+	return string(r.transaction.Status)
+}
+
+func (r *transactionResolver) Amount() string {
+	return r.transaction.Amount
+}
+
+func (r *transactionResolver) TokenAddress() string {
+	return r.transaction.TokenAddress
+}
+
+func (r *transactionResolver) BlockNumber() string {
+	return strconv.FormatUint(r.transaction.BlockNumber, 10)
+}
+
+func (r *transactionResolver) GasUsed() string {
+	return strconv.FormatUint(r.transaction.GasUsed, 10)
+}
+
+func (r *transactionResolver) ErrorMessage() *string {
+	if r.transaction.ErrorMessage == "" {
+		return nil
+	}
+	return &r.transaction.ErrorMessage
+}
+
+func (r *transactionResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.transaction.CreatedAt}
+}
+
+func (r *transactionResolver) User(ctx context.Context) (*userResolver, error) {
+	return r.s.loadUser(ctx, r.transaction.UserID)
+}
+
+func (r *transactionResolver) Market(ctx context.Context) (*marketResolver, error) {
+	return loadMarket(ctx, r.transaction.MarketID)
+}
+
+// marketResolver resolves the fields of the Market type
+type marketResolver struct {
+	market *models.Market
+}
+
+// loadMarket resolves a market by ID, or null when it is no longer active
+func loadMarket(ctx context.Context, id uint) (*marketResolver, error) {
+	market, err := loadersFrom(ctx).markets.load(ctx, id)
+	if err != nil || market == nil {
+		return nil, err
+	}
+	return &marketResolver{market: market}, nil
+}
+
+func (r *marketResolver) ID() graphql.ID {
+	return formatID(r.market.ID)
+}
+
+func (r *marketResolver) Identifier() string {
+	return r.market.Identifier
+}
+
+func (r *marketResolver) Name() string {
+	return r.market.Name
+}
+
+func (r *marketResolver) TokenAddress() string {
+	return r.market.TokenAddress
+}
+
+func (r *marketResolver) LendingPoolAddress() string {
+	return r.market.LendingPoolAddress
+}
+
+func (r *marketResolver) BorrowingAddress() string {
+	return r.market.BorrowingAddress
+}
+
+func (r *marketResolver) CollateralAddress() string {
+	return r.market.CollateralAddress
+}
+
+func (r *marketResolver) Data(ctx context.Context) (*marketDataResolver, error) {
+	data, err := loadersFrom(ctx).marketData.load(ctx, r.market.Identifier)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return &marketDataResolver{data: data}, nil
+}
+
+// marketDataResolver resolves the fields of the MarketData type
+type marketDataResolver struct {
+	data *service.TokenMarketData
+}
+
+func (r *marketDataResolver) Name() string {
+	return r.data.Name
+}
+
+func (r *marketDataResolver) Symbol() string {
+	return r.data.Symbol
+}
+
+func (r *marketDataResolver) Decimals() int32 {
+	return int32(r.data.Decimals)
+}
+
+func (r *marketDataResolver) TotalSupply() string {
+	return formatBig(r.data.TotalSupply)
+}
+
+func (r *marketDataResolver) TotalDeposited() string {
+	return formatBig(r.data.TotalDeposited)
+}
+
+func (r *marketDataResolver) TotalBorrowed() string {
+	return formatBig(r.data.TotalBorrowed)
+}
+
+func (r *marketDataResolver) AvailableLiquidity() string {
+	return formatBig(r.data.AvailableLiquidity)
+}
+
+func (r *marketDataResolver) LendingRate() string {
+	return formatBig(r.data.LendingRate)
+}
+
+func (r *marketDataResolver) BorrowingRate() string {
+	return formatBig(r.data.BorrowingRate)
+}
+
+func (r *marketDataResolver) CollateralFactor() string {
+	return formatBig(r.data.CollateralFactor)
+}
+
+func (r *marketDataResolver) BlockNumber() string {
+	return strconv.FormatUint(r.data.BlockNumber, 10)
+}
+
+// liquidationCandidateResolver resolves the fields of the LiquidationCandidate type
+type liquidationCandidateResolver struct {
+	position *positionResolver
+}
+
+func (r *liquidationCandidateResolver) Position() *positionResolver {
+	return r.position
+}
+
+func (r *liquidationCandidateResolver) User(ctx context.Context) (*userResolver, error) {
+	return r.position.User(ctx)
+}
+
+func (r *liquidationCandidateResolver) Market(ctx context.Context) (*marketResolver, error) {
+	return r.position.Market(ctx)
+}
+
+func (r *liquidationCandidateResolver) HealthFactor() string {
+	return r.position.position.HealthFactor
+}
+
+func (r *liquidationCandidateResolver) BorrowedAmount() string {
+	return r.position.position.BorrowedAmount
+}
+
+func (r *liquidationCandidateResolver) CollateralAmount() string {
+	return r.position.position.CollateralAmount
+}
+
+// formatID converts a database ID into a GraphQL ID
+func formatID(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+// formatBig formats an on-chain figure, which may be missing
+func formatBig(value *big.Int) string {
+	if value == nil {
+		return "0"
+	}
+	return value.String()
+}
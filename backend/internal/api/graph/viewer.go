@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// errAccessDenied is returned by the fields the viewer may not read
+var errAccessDenied = errors.New("access denied")
+
+// Viewer is the authenticated user a query runs for
+type Viewer struct {
+	UserID uint
+	Role   models.UserRole
+	APIKey bool                 // Whether the user authenticated with an API key rather than a JWT
+	Scopes []models.APIKeyScope // Scopes of the API key, if any
+}
+
+type viewerKey struct{}
+
+// viewerFrom returns the viewer a query runs for
+func viewerFrom(ctx context.Context) *Viewer {
+	return ctx.Value(viewerKey{}).(*Viewer)
+}
+
+// can reports whether the role of the viewer grants a permission.
+// Requests made with an API key also need the scope covering the permission
+func (v *Viewer) can(permission models.Permission) bool {
+	if !v.Role.HasPermission(permission) {
+		return false
+	}
+	return !v.APIKey || slices.Contains(v.Scopes, models.PermissionScope(permission))
+}
+
+// canReadAccount reports whether the viewer may read the account details of a user
+func (v *Viewer) canReadAccount(userID uint) bool {
+	return v.UserID == userID || v.can(models.PermUsersRead)
+}
+
+// canReadPositions reports whether the viewer may read the positions and transactions of a user
+func (v *Viewer) canReadPositions(userID uint) bool {
+	return v.UserID == userID || v.can(models.PermPositionsReadAll)
+}
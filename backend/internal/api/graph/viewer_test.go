@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// userTable holds users in memory, for the user lookups of a query
+type userTable struct {
+	repository.UserRepository
+	users []*models.User
+}
+
+func (r *userTable) FindByIDs(ctx context.Context, ids []uint) ([]*models.User, error) {
+	var users []*models.User
+	for _, user := range r.users {
+		if slices.Contains(ids, user.ID) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// positionTable holds positions in memory, every one of them liquidatable
+type positionTable struct {
+	service.PositionService
+	service.LiquidationService
+	positions []*models.Position
+}
+
+func (r *positionTable) ListPositions(ctx context.Context, filter models.PositionFilter, offset, limit int) ([]*models.Position, error) {
+	var positions []*models.Position
+	for _, position := range r.positions {
+		if filter.UserID == nil || position.UserID == *filter.UserID {
+			positions = append(positions, position)
+		}
+	}
+	return positions, nil
+}
+
+func (r *positionTable) GetLiquidatablePositions(ctx context.Context, market string) ([]*models.Position, error) {
+	return r.positions, nil
+}
+
+func TestOtherUserFields(t *testing.T) {
+	lastLogin := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	users := &userTable{users: []*models.User{
+		{ID: 1, Address: "0x1", Username: "viewer", Role: models.RoleUser},
+		{ID: 2, Address: "0x2", Username: "borrower", Role: models.RoleUser, LastLogin: &lastLogin},
+	}}
+	positions := &positionTable{positions: []*models.Position{{ID: 7, UserID: 2}}}
+
+	s := newTestServer(Services{UserRepository: users, PositionService: positions, LiquidationService: positions})
+
+	// Liquidation candidates are public, and name the user of each position
+	query := `{ liquidationCandidates { user { username role lastLogin positions { id } } } }`
+
+	tests := []struct {
+		name       string
+		viewer     Viewer
+		wantDenied bool
+	}{
+		{name: "user", viewer: Viewer{UserID: 1, Role: models.RoleUser}, wantDenied: true},
+		{name: "admin", viewer: Viewer{UserID: 1, Role: models.RoleAdmin}},
+		{
+			name:       "admin with an API key without the admin scope",
+			viewer:     Viewer{UserID: 1, Role: models.RoleAdmin, APIKey: true, Scopes: []models.APIKeyScope{models.ScopeReadPositions}},
+			wantDenied: true,
+		},
+		{
+			name:   "admin with an API key with the admin scope",
+			viewer: Viewer{UserID: 1, Role: models.RoleAdmin, APIKey: true, Scopes: []models.APIKeyScope{models.ScopeAdmin}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := s.Exec(context.Background(), tt.viewer, query, "", nil)
+
+			var data struct {
+				LiquidationCandidates []struct {
+					User struct {
+						Username  string
+						Role      *string
+						LastLogin *string
+						Positions *[]struct{ ID string }
+					}
+				}
+			}
+			if err := json.Unmarshal(response.Data, &data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(data.LiquidationCandidates) != 1 {
+				t.Fatalf("got %d candidates, want 1", len(data.LiquidationCandidates))
+			}
+			user := data.LiquidationCandidates[0].User
+
+			// Public fields are always visible
+			if user.Username != "borrower" {
+				t.Errorf("got username %q, want borrower", user.Username)
+			}
+
+			if tt.wantDenied {
+				if user.Role != nil || user.LastLogin != nil || user.Positions != nil {
+					t.Errorf("got role %v, last login %v and positions %v, want null", user.Role, user.LastLogin, user.Positions)
+				}
+				if len(response.Errors) != 3 {
+					t.Fatalf("got errors %v, want one per restricted field", response.Errors)
+				}
+				for _, queryErr := range response.Errors {
+					if queryErr.Message != errAccessDenied.Error() {
+						t.Errorf("got error %q at %v, want %q", queryErr.Message, queryErr.Path, errAccessDenied)
+					}
+				}
+				return
+			}
+
+			if len(response.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", response.Errors)
+			}
+			if user.Role == nil || *user.Role != string(models.RoleUser) {
+				t.Errorf("got role %v, want user", user.Role)
+			}
+			if user.LastLogin == nil || *user.LastLogin != lastLogin.Format(time.RFC3339) {
+				t.Errorf("got last login %v, want %s", user.LastLogin, lastLogin.Format(time.RFC3339))
+			}
+			if user.Positions == nil || len(*user.Positions) != 1 || (*user.Positions)[0].ID != "7" {
+				t.Errorf("got positions %v, want position 7", user.Positions)
+			}
+		})
+	}
+}
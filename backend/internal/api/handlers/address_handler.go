@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// AddressHandler manages the wallet addresses linked to user accounts
+type AddressHandler struct {
+	userService service.UserService
+	authService service.AuthService
+}
+
+// NewAddressHandler creates a new address handler
+func NewAddressHandler(userService service.UserService, authService service.AuthService) *AddressHandler {
+	return &AddressHandler{
+		userService: userService,
+		authService: authService,
+	}
+}
+
+// ListAddresses godoc
+// @Summary List linked addresses
+// @Description Get the wallet addresses linked to the account, primary first
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.UserAddressListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/addresses [get]
+func (h *AddressHandler) ListAddresses(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	addresses, err := h.userService.ListAddresses(c.Context(), userID)
+	if err != nil {
+		return serviceError("list addresses", err)
+	}
+
+	addressResponses := make([]dto.UserAddressResponse, len(addresses))
+	for i, address := range addresses {
+		addressResponses[i] = toUserAddressResponse(address)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.UserAddressListResponse{
+		Addresses: addressResponses,
+	})
+}
+
+// CreateLinkChallenge godoc
+// @Summary Start linking an address
+// @Description Get a single-use message that must be signed by both the primary address and the address to link
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.LinkChallengeRequest true "Address to link"
+// @Success 200 {object} dto.LinkChallengeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/addresses/challenge [post]
+func (h *AddressHandler) CreateLinkChallenge(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.LinkChallengeRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	// Fail early rather than after both wallets have signed
+	owner, err := h.userService.GetByAddress(c.Context(), req.Address)
+	if err != nil {
+		return serviceError("check address", err)
+	}
+
+	if owner != nil {
+		return fiber.NewError(fiber.StatusConflict, service.ErrAddressInUse.Error())
+	}
+
+	challenge, err := h.authService.CreateLinkChallenge(c.Context(), user, req.Address)
+	if err != nil {
+		return serviceError("create link challenge", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.LinkChallengeResponse{
+		Nonce:     challenge.Nonce,
+		Message:   challenge.Message,
+		ExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+// LinkAddress godoc
+// @Summary Link an address
+// @Description Link a secondary wallet address to the account with a challenge signed by both the primary address and the new address
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.LinkAddressRequest true "Signed link challenge"
+// @Success 201 {object} dto.APIResponse{data=dto.UserAddressResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/addresses [post]
+func (h *AddressHandler) LinkAddress(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.LinkAddressRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	err = h.authService.VerifyLinkChallenge(c.Context(), user, req.Address, req.Nonce, req.PrimarySignature, req.AddressSignature)
+	if err != nil {
+		return serviceError("verify link challenge", err)
+	}
+
+	address, err := h.userService.LinkAddress(c.Context(), user.ID, req.Address)
+	if err != nil {
+		return serviceError("link address", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Address linked successfully",
+		Data:    toUserAddressResponse(address),
+	})
+}
+
+// UnlinkAddress godoc
+// @Summary Unlink an address
+// @Description Unlink a secondary wallet address from the account; the primary address cannot be unlinked
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param address path string true "Ethereum address"
+// @Success 200 {object} dto.APIResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/addresses/{address} [delete]
+func (h *AddressHandler) UnlinkAddress(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	err := h.userService.UnlinkAddress(c.Context(), userID, c.Params("address"))
+	if err != nil {
+		return serviceError("unlink address", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Address unlinked successfully",
+	})
+}
+
+// SetPrimaryAddress godoc
+// @Summary Set the primary address
+// @Description Make a linked wallet address the primary address of the account
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param address path string true "Ethereum address"
+// @Success 200 {object} dto.APIResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/addresses/{address}/primary [put]
+func (h *AddressHandler) SetPrimaryAddress(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	user, err := h.userService.SetPrimaryAddress(c.Context(), userID, c.Params("address"))
+	if err != nil {
+		return serviceError("set primary address", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Primary address updated successfully",
+		Data: fiber.Map{
+			"address": user.Address,
+		},
+	})
+}
+
+// currentUser loads the authenticated user
+func (h *AddressHandler) currentUser(c *fiber.Ctx) (*models.User, error) {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	user, err := h.userService.GetByID(c.Context(), userID)
+	if err != nil {
+		return nil, serviceError("get user", err)
+	}
+
+	if user == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	return user, nil
+}
+
+// toUserAddressResponse converts a linked address to its response DTO
+func toUserAddressResponse(address *models.UserAddress) dto.UserAddressResponse {
+	return dto.UserAddressResponse{
+		Address:   address.Address,
+		Primary:   address.IsPrimary,
+		CreatedAt: address.CreatedAt,
+	}
+}
+
+// requestAddresses returns the addresses a request covers, as resolved by the LinkedAddresses middleware
+func requestAddresses(c *fiber.Ctx) ([]common.Address, error) {
+	addresses, ok := c.Locals("addresses").([]common.Address)
+	if !ok || len(addresses) == 0 {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+	return addresses, nil
+}
+
+// sumOverAddresses adds up an amount read for each of a set of addresses
+func sumOverAddresses(addresses []common.Address, read func(address common.Address) (*big.Int, error)) (*big.Int, error) {
+	total := new(big.Int)
+	for _, address := range addresses {
+		amount, err := read(address)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, amount)
+	}
+	return total, nil
+}
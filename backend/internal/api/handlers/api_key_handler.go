@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// APIKeyHandler manages API key endpoints
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+	userService   service.UserService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService service.APIKeyService, userService service.UserService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		userService:   userService,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Issue a scoped API key for machine clients. The key is only returned once and must be sent in the X-API-Key header
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateAPIKeyRequest true "API key settings"
+// @Success 201 {object} dto.APIResponse{data=dto.CreateAPIKeyResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.userService.GetByID(c.Context(), userID)
+	if err != nil {
+		return serviceError("get user", err)
+	}
+
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	scopes := make([]models.APIKeyScope, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = models.APIKeyScope(scope)
+	}
+
+	apiKey, rawKey, err := h.apiKeyService.CreateKey(c.Context(), user, &service.APIKeyRequest{
+		Name:       req.Name,
+		Scopes:     scopes,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		return serviceError("create API key", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.APIResponse{
+		Success: true,
+		Message: "API key created successfully, store it now as it will not be shown again",
+		Data: dto.CreateAPIKeyResponse{
+			APIKey: toAPIKeyResponse(apiKey),
+			Key:    rawKey,
+		},
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the API keys of the authenticated user, including revoked ones
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=dto.APIKeyListResponse}
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	apiKeys, err := h.apiKeyService.ListKeys(c.Context(), userID)
+	if err != nil {
+		return serviceError("list API keys", err)
+	}
+
+	apiKeyResponses := make([]dto.APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		apiKeyResponses[i] = toAPIKeyResponse(apiKey)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data:    dto.APIKeyListResponse{APIKeys: apiKeyResponses},
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke an API key of the authenticated user; it stops working immediately
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	// Parse API key ID from URL
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid API key ID")
+	}
+
+	err = h.apiKeyService.RevokeKey(c.Context(), userID, uint(id))
+	if err != nil {
+		return serviceError("revoke API key", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "API key revoked successfully",
+	})
+}
+
+func toAPIKeyResponse(apiKey *models.APIKey) dto.APIKeyResponse {
+	scopes := make([]string, 0)
+	for _, scope := range apiKey.GetScopes() {
+		scopes = append(scopes, string(scope))
+	}
+
+	allowedIPs := apiKey.GetAllowedIPs()
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+
+	return dto.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		LastUsedIP: apiKey.LastUsedIP,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
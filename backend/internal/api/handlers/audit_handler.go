@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// AuditHandler manages audit log endpoints
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List audit log entries
+// @Description Get a page of audit log entries, newest first (requires audit.read)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param actorId query int false "Filter by actor user ID"
+// @Param action query string false "Filter by action, e.g. user.verify"
+// @Param targetType query string false "Filter by target type, e.g. user"
+// @Param targetId query string false "Filter by target ID"
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} dto.AuditLogListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /audit [get]
+func (h *AuditHandler) ListAuditLogs(c *fiber.Ctx) error {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		return err
+	}
+
+	// Get pagination parameters; the audit log shows longer pages by default
+	var pagination dto.PaginationRequest
+	if err := bindQuery(c, &pagination); err != nil {
+		return err
+	}
+
+	if pagination.PageSize == 0 {
+		pagination.PageSize = 20
+	}
+	page, pageSize, offset := paginationBounds(pagination)
+
+	entries, err := h.auditService.List(c.Context(), filter, offset, pageSize)
+	if err != nil {
+		return serviceError("list audit log", err)
+	}
+
+	total, err := h.auditService.Count(c.Context(), filter)
+	if err != nil {
+		return serviceError("count audit log entries", err)
+	}
+
+	entryResponses := make([]dto.AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		entryResponses[i] = toAuditLogResponse(entry)
+	}
+
+	// Calculate total pages
+	totalPages := (int(total) + pageSize - 1) / pageSize
+
+	return c.Status(fiber.StatusOK).JSON(dto.AuditLogListResponse{
+		Entries:   entryResponses,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: totalPages,
+	})
+}
+
+// ExportAuditLogs godoc
+// @Summary Export audit log
+// @Description Download every audit log entry matching the filters, oldest first, as CSV or JSON lines. Entries include their hashes so the chain can be checked offline (requires audit.read)
+// @Tags admin
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param format query string false "Export format" Enums(csv, json) default(csv)
+// @Param actorId query int false "Filter by actor user ID"
+// @Param action query string false "Filter by action, e.g. user.verify"
+// @Param targetType query string false "Filter by target type, e.g. user"
+// @Param targetId query string false "Filter by target ID"
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
+// @Success 200 {string} string "Audit log entries"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /audit/export [get]
+func (h *AuditHandler) ExportAuditLogs(c *fiber.Ctx) error {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		return err
+	}
+
+	format := c.Query("format", "csv")
+	filename := "audit-log-" + time.Now().UTC().Format("20060102T150405Z")
+	body := c.Response().BodyWriter()
+
+	switch format {
+	case "csv":
+		c.Set(fiber.HeaderContentType, "text/csv")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`.csv"`)
+
+		writer := csv.NewWriter(body)
+		if err := writer.Write([]string{
+			"id", "createdAt", "actorId", "actorAddress", "actorRole", "apiKeyId", "action",
+			"targetType", "targetId", "requestId", "ip", "status", "changes", "prevHash", "hash",
+		}); err != nil {
+			return serviceError("export audit log", err)
+		}
+
+		err = h.auditService.Export(c.Context(), filter, func(entry *models.AuditLog) error {
+			return writer.Write([]string{
+				strconv.FormatUint(uint64(entry.ID), 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+				optionalID(entry.ActorID),
+				entry.ActorAddress,
+				string(entry.ActorRole),
+				optionalID(entry.APIKeyID),
+				string(entry.Action),
+				entry.TargetType,
+				entry.TargetID,
+				entry.RequestID,
+				entry.IP,
+				strconv.Itoa(entry.Status),
+				entry.Changes,
+				entry.PrevHash,
+				entry.Hash,
+			})
+		})
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+	case "json":
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`.jsonl"`)
+
+		encoder := json.NewEncoder(body)
+		err = h.auditService.Export(c.Context(), filter, func(entry *models.AuditLog) error {
+			return encoder.Encode(toAuditLogResponse(entry))
+		})
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid format, expected csv or json")
+	}
+
+	if err != nil {
+		c.Response().ResetBody()
+		return serviceError("export audit log", err)
+	}
+
+	return nil
+}
+
+// VerifyAuditLog godoc
+// @Summary Verify audit log
+// @Description Recompute the hash chain of the audit log to detect altered or removed entries (requires audit.read)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Success 200 {object} dto.AuditVerificationResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /audit/verify [get]
+func (h *AuditHandler) VerifyAuditLog(c *fiber.Ctx) error {
+	result, err := h.auditService.Verify(c.Context())
+	if err != nil {
+		return serviceError("verify audit log", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.AuditVerificationResponse{
+		Valid:    result.Valid,
+		Entries:  result.Entries,
+		HeadHash: result.HeadHash,
+		BrokenAt: result.BrokenAt,
+		Reason:   result.Reason,
+	})
+}
+
+// parseAuditLogFilter reads and validates the audit log filters of the query string
+func parseAuditLogFilter(c *fiber.Ctx) (models.AuditLogFilter, error) {
+	var req dto.AuditLogFilterRequest
+	if err := bindQuery(c, &req); err != nil {
+		return models.AuditLogFilter{}, err
+	}
+	return auditLogFilter(req)
+}
+
+// toAuditLogResponse converts an audit log entry to its response DTO
+func toAuditLogResponse(entry *models.AuditLog) dto.AuditLogResponse {
+	response := dto.AuditLogResponse{
+		ID:           entry.ID,
+		ActorID:      entry.ActorID,
+		ActorAddress: entry.ActorAddress,
+		ActorRole:    string(entry.ActorRole),
+		APIKeyID:     entry.APIKeyID,
+		Action:       string(entry.Action),
+		TargetType:   entry.TargetType,
+		TargetID:     entry.TargetID,
+		RequestID:    entry.RequestID,
+		IP:           entry.IP,
+		Status:       entry.Status,
+		PrevHash:     entry.PrevHash,
+		Hash:         entry.Hash,
+		CreatedAt:    entry.CreatedAt,
+	}
+
+	if entry.Changes != "" {
+		response.Changes = json.RawMessage(entry.Changes)
+	}
+
+	return response
+}
+
+// optionalID formats an optional ID, empty when unset
+func optionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
@@ -2,25 +2,25 @@ package handlers
 
 import (
 	"math/big"
-	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
-	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
 // BorrowingHandler manages borrowing-related API endpoints
 type BorrowingHandler struct {
 	borrowingService service.BorrowingService
+	priceService     service.PriceService
 }
 
 // NewBorrowingHandler creates a new borrowing handler
-func NewBorrowingHandler(borrowingService service.BorrowingService) *BorrowingHandler {
+func NewBorrowingHandler(borrowingService service.BorrowingService, priceService service.PriceService) *BorrowingHandler {
 	return &BorrowingHandler{
 		borrowingService: borrowingService,
+		priceService:     priceService,
 	}
 }
 
@@ -31,10 +31,12 @@ func NewBorrowingHandler(borrowingService service.BorrowingService) *BorrowingHa
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Param request body dto.TransactionRequest true "Borrow amount"
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /borrowing/borrow [post]
 func (h *BorrowingHandler) Borrow(c *fiber.Ctx) error {
@@ -44,22 +46,17 @@ func (h *BorrowingHandler) Borrow(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Parse the request body
+	// Parse and validate the request body
 	var req dto.TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Convert the amount from string to *big.Int
-	amount, success := new(big.Int).SetString(req.Amount, 10)
-	if !success {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid amount format")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
+	amount := mustAmount(req.Amount)
 
 	// Call the borrowing service to process the borrow request
-	txHash, err := h.borrowingService.Borrow(c.Context(), common.HexToAddress(address), amount)
+	txHash, err := h.borrowingService.Borrow(c.Context(), marketIdentifier(c), common.HexToAddress(address), amount)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to process borrowing: "+err.Error())
+		return serviceError("process borrowing", err)
 	}
 
 	// Return the transaction hash
@@ -79,10 +76,12 @@ func (h *BorrowingHandler) Borrow(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Param request body dto.TransactionRequest true "Repay amount"
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /borrowing/repay [post]
 func (h *BorrowingHandler) Repay(c *fiber.Ctx) error {
@@ -92,22 +91,17 @@ func (h *BorrowingHandler) Repay(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Parse the request body
+	// Parse and validate the request body
 	var req dto.TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Convert the amount from string to *big.Int
-	amount, success := new(big.Int).SetString(req.Amount, 10)
-	if !success {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid amount format")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
+	amount := mustAmount(req.Amount)
 
 	// Call the borrowing service to process the repay request
-	txHash, err := h.borrowingService.Repay(c.Context(), common.HexToAddress(address), amount)
+	txHash, err := h.borrowingService.Repay(c.Context(), marketIdentifier(c), common.HexToAddress(address), amount)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to process repayment: "+err.Error())
+		return serviceError("process repayment", err)
 	}
 
 	// Return the transaction hash
@@ -127,21 +121,25 @@ func (h *BorrowingHandler) Repay(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
+// @Param aggregate query bool false "Sum over every address linked to the account"
 // @Success 200 {object} dto.APIResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /borrowing/balance [get]
 func (h *BorrowingHandler) GetBorrowedAmount(c *fiber.Ctx) error {
-	// Extract the user address from the authentication middleware
-	address, ok := c.Locals("address").(string)
-	if !ok {
-		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	// Get the addresses the request covers (set by the linked addresses middleware)
+	addresses, err := requestAddresses(c)
+	if err != nil {
+		return err
 	}
 
-	// Call the borrowing service to get the borrowed amount
-	borrowed, err := h.borrowingService.GetBorrowedAmount(c.Context(), common.HexToAddress(address))
+	// Call the borrowing service to get the amount borrowed by each address the request covers
+	borrowed, err := sumOverAddresses(addresses, func(address common.Address) (*big.Int, error) {
+		return h.borrowingService.GetBorrowedAmount(c.Context(), marketIdentifier(c), address)
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get borrowed amount: "+err.Error())
+		return serviceError("get borrowed amount", err)
 	}
 
 	// Return the borrowed amount
@@ -160,39 +158,53 @@ func (h *BorrowingHandler) GetBorrowedAmount(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
+// @Param aggregate query bool false "Sum over every address linked to the account"
 // @Success 200 {object} dto.BorrowingInfoResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /borrowing/info [get]
 func (h *BorrowingHandler) GetBorrowingInfo(c *fiber.Ctx) error {
-	// Extract the user address from the authentication middleware
-	address, ok := c.Locals("address").(string)
-	if !ok {
-		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	// Get the addresses the request covers (set by the linked addresses middleware)
+	addresses, err := requestAddresses(c)
+	if err != nil {
+		return err
 	}
 
 	// Get the borrowed amount
-	borrowed, err := h.borrowingService.GetBorrowedAmount(c.Context(), common.HexToAddress(address))
+	borrowed, err := sumOverAddresses(addresses, func(address common.Address) (*big.Int, error) {
+		return h.borrowingService.GetBorrowedAmount(c.Context(), marketIdentifier(c), address)
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get borrowed amount: "+err.Error())
+		return serviceError("get borrowed amount", err)
 	}
 
 	// Get the current interest rate
-	interestRate, err := h.borrowingService.GetCurrentInterestRate(c.Context())
+	interestRate, err := h.borrowingService.GetCurrentInterestRate(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get interest rate: "+err.Error())
+		return serviceError("get interest rate", err)
 	}
 
 	// Get the interest accrued by the user
-	interestAccrued, err := h.borrowingService.GetUserInterestAccrued(c.Context(), common.HexToAddress(address))
+	interestAccrued, err := sumOverAddresses(addresses, func(address common.Address) (*big.Int, error) {
+		return h.borrowingService.GetUserInterestAccrued(c.Context(), marketIdentifier(c), address)
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get interest accrued: "+err.Error())
+		return serviceError("get interest accrued", err)
+	}
+
+	// Get the borrowed token to value the debt in USD
+	token, err := h.borrowingService.GetUnderlyingToken(c.Context(), marketIdentifier(c))
+	if err != nil {
+		return serviceError("get underlying token", err)
 	}
 
 	// Return the borrowing information
 	return c.Status(fiber.StatusOK).JSON(dto.BorrowingInfoResponse{
 		TotalBorrowed:       borrowed.String(),
+		TotalBorrowedUSD:    usdValue(c.Context(), h.priceService, token, borrowed),
 		InterestAccrued:     interestAccrued.String(),
+		InterestAccruedUSD:  usdValue(c.Context(), h.priceService, token, interestAccrued),
 		CurrentInterestRate: interestRate.String(),
 	})
 }
@@ -204,9 +216,15 @@ func (h *BorrowingHandler) GetBorrowingInfo(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
+// @Param type query string false "Comma-separated transaction types"
+// @Param status query string false "Comma-separated statuses: pending, completed, failed"
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
 // @Param page query int false "Page number (default: 1)"
 // @Param pageSize query int false "Page size (default: 10, max: 100)"
 // @Success 200 {object} dto.TransactionListResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /borrowing/transactions [get]
@@ -218,60 +236,45 @@ func (h *BorrowingHandler) GetTransactionHistory(c *fiber.Ctx) error {
 	}
 
 	// Get pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("pageSize", "10"))
-
-	if page < 1 {
-		page = 1
+	page, pageSize, offset, err := parsePagination(c)
+	if err != nil {
+		return err
 	}
 
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	// Get filter parameters
+	filterReq, err := parseFilterRequest(c)
+	if err != nil {
+		return err
 	}
 
-	// Calculate offset
-	offset := (page - 1) * pageSize
+	filter, err := transactionFilter(filterReq)
+	if err != nil {
+		return err
+	}
 
 	// Get transaction history
 	transactions, err := h.borrowingService.GetUserTransactionHistory(
 		c.Context(),
+		marketIdentifier(c),
 		common.HexToAddress(address),
+		filter,
 		offset,
 		pageSize,
 	)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction history: "+err.Error())
+		return serviceError("get transaction history", err)
 	}
 
 	// Convert transactions to DTOs
 	txResponses := make([]dto.TransactionResponse, len(transactions))
 	for i, tx := range transactions {
-		txResponses[i] = dto.TransactionResponse{
-			ID:           tx.ID,
-			UserID:       tx.UserID,
-			Type:         dto.TransactionType(tx.Type),
-			Status:       dto.TransactionStatus(tx.Status),
-			Hash:         tx.Hash,
-			Amount:       tx.Amount,
-			TokenAddress: tx.TokenAddress,
-			BlockNumber:  tx.BlockNumber,
-			GasUsed:      tx.GasUsed,
-			GasPrice:     tx.GasPrice,
-			CreatedAt:    tx.CreatedAt,
-			UpdatedAt:    tx.UpdatedAt,
-		}
+		txResponses[i] = toTransactionResponse(tx)
 	}
 
 	// Get total count for pagination
-	ethAddress := common.HexToAddress(address)
-	filter := map[string]any{
-		"type": []models.TransactionType{models.TransactionBorrow, models.TransactionRepay},
-	}
-
-	// Get total count from service
-	total, err := h.borrowingService.CountUserTransactions(c.Context(), ethAddress, filter)
+	total, err := h.borrowingService.CountUserTransactions(c.Context(), marketIdentifier(c), common.HexToAddress(address), filter)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to count transactions: "+err.Error())
+		return serviceError("count transactions", err)
 	}
 
 	// Calculate total pages
@@ -297,15 +300,15 @@ func (h *BorrowingHandler) GetTransactionHistory(c *fiber.Ctx) error {
 // @Router /borrowing/stats [get]
 func (h *BorrowingHandler) GetBorrowingStats(c *fiber.Ctx) error {
 	// Get the total borrowed amount
-	totalBorrowed, err := h.borrowingService.GetTotalBorrowed(c.Context())
+	totalBorrowed, err := h.borrowingService.GetTotalBorrowed(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get total borrowed: "+err.Error())
+		return serviceError("get total borrowed", err)
 	}
 
 	// Get the current interest rate
-	interestRate, err := h.borrowingService.GetCurrentInterestRate(c.Context())
+	interestRate, err := h.borrowingService.GetCurrentInterestRate(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get interest rate: "+err.Error())
+		return serviceError("get interest rate", err)
 	}
 
 	// Return the borrowing statistics
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// BundleHandler manages multi-step action bundle endpoints
+type BundleHandler struct {
+	bundleService service.BundleService
+	chainID       int
+}
+
+// NewBundleHandler creates a new bundle handler
+func NewBundleHandler(bundleService service.BundleService, cfg *config.Config) *BundleHandler {
+	return &BundleHandler{
+		bundleService: bundleService,
+		chainID:       cfg.Blockchain.ChainID,
+	}
+}
+
+// CreateBundle godoc
+// @Summary Create an action bundle
+// @Description Validate an ordered list of actions (approve, depositCollateral, borrow, repay, withdraw) against the
+// @Description simulated state of the wallet and position after each step. In signer mode the steps are then sent one
+// @Description after the other, each once the previous one is mined, and the bundle is returned while it runs. In unsigned
+// @Description mode the steps are returned as transactions with consecutive nonces for the wallet to sign and send in order
+// @Tags bundles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param request body dto.CreateBundleRequest true "Bundle mode and actions"
+// @Success 201 {object} dto.APIResponse{data=dto.BundleResponse}
+// @Success 202 {object} dto.APIResponse{data=dto.BundleResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bundles [post]
+func (h *BundleHandler) CreateBundle(c *fiber.Ctx) error {
+	// Get user ID and address from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+	address, ok := c.Locals("address").(string)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req dto.CreateBundleRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	mode := models.BundleMode(req.Mode)
+
+	// API keys may only run the actions their scopes allow on their own
+	keyScopes, restricted := c.Locals("scopes").([]models.APIKeyScope)
+
+	actions := make([]service.BundleActionRequest, len(req.Actions))
+	for i, action := range req.Actions {
+		bundleAction := models.BundleAction(action.Action)
+		if scope, scoped := bundleActionScope(bundleAction); restricted && scoped && !slices.Contains(keyScopes, scope) {
+			return fiber.NewError(fiber.StatusForbidden, "API key is missing the "+string(scope)+" scope")
+		}
+
+		actions[i] = service.BundleActionRequest{
+			Action:  bundleAction,
+			Amount:  mustAmount(action.Amount),
+			Spender: models.TransactionContract(action.Spender),
+		}
+	}
+
+	bundle, err := h.bundleService.CreateBundle(c.Context(), marketIdentifier(c), userID, common.HexToAddress(address), mode, actions)
+	if err != nil {
+		return serviceError("create bundle", err)
+	}
+
+	if mode == models.BundleModeSigner {
+		return c.Status(fiber.StatusAccepted).JSON(dto.APIResponse{
+			Success: true,
+			Message: "Bundle validated, steps are being submitted",
+			Data:    h.toBundleResponse(bundle),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Bundle validated, sign and send the transactions in order",
+		Data:    h.toBundleResponse(bundle),
+	})
+}
+
+// ListBundles godoc
+// @Summary List action bundles
+// @Description Get a page of the authenticated user's bundles, newest first
+// @Tags bundles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} dto.BundleListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bundles [get]
+func (h *BundleHandler) ListBundles(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	// Get pagination parameters
+	page, pageSize, offset, err := parsePagination(c)
+	if err != nil {
+		return err
+	}
+
+	bundles, err := h.bundleService.ListBundles(c.Context(), userID, offset, pageSize)
+	if err != nil {
+		return serviceError("list bundles", err)
+	}
+
+	total, err := h.bundleService.CountBundles(c.Context(), userID)
+	if err != nil {
+		return serviceError("count bundles", err)
+	}
+
+	bundleResponses := make([]dto.BundleResponse, len(bundles))
+	for i, bundle := range bundles {
+		bundleResponses[i] = h.toBundleResponse(bundle)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.BundleListResponse{
+		Bundles:   bundleResponses,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: (int(total) + pageSize - 1) / pageSize,
+	})
+}
+
+// GetBundle godoc
+// @Summary Get an action bundle
+// @Description Get a bundle of the authenticated user with the status of each step
+// @Tags bundles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param id path int true "Bundle ID"
+// @Success 200 {object} dto.APIResponse{data=dto.BundleResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bundles/{id} [get]
+func (h *BundleHandler) GetBundle(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid bundle ID")
+	}
+
+	bundle, err := h.bundleService.GetBundle(c.Context(), userID, uint(id))
+	if err != nil {
+		return serviceError("get bundle", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Bundle retrieved successfully",
+		Data:    h.toBundleResponse(bundle),
+	})
+}
+
+// SubmitBundleStep godoc
+// @Summary Submit an unsigned bundle step
+// @Description Record the hash of the transaction the wallet sent for the next pending step of an unsigned bundle.
+// @Description The transaction must be known to the node, sent from the bundle address and match the step
+// @Tags bundles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param id path int true "Bundle ID"
+// @Param position path int true "Step position, from 0"
+// @Param request body dto.SubmitBundleStepRequest true "Transaction hash"
+// @Success 200 {object} dto.APIResponse{data=dto.BundleResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /bundles/{id}/steps/{position}/submit [post]
+func (h *BundleHandler) SubmitBundleStep(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid bundle ID")
+	}
+
+	position, err := strconv.Atoi(c.Params("position"))
+	if err != nil || position < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid step position")
+	}
+
+	var req dto.SubmitBundleStepRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	if decoded, err := hexutil.Decode(req.Hash); err != nil || len(decoded) != 32 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid transaction hash")
+	}
+
+	bundle, err := h.bundleService.SubmitStep(c.Context(), userID, uint(id), position, req.Hash)
+	if err != nil {
+		return serviceError("submit bundle step", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Bundle step submitted",
+		Data:    h.toBundleResponse(bundle),
+	})
+}
+
+// bundleActionScope returns the API key scope needed to run an action, false for approvals which only move an allowance
+func bundleActionScope(action models.BundleAction) (models.APIKeyScope, bool) {
+	switch action {
+	case models.ActionDepositCollateral, models.ActionWithdraw:
+		return models.ScopeWriteCollateral, true
+	case models.ActionBorrow, models.ActionRepay:
+		return models.ScopeWriteBorrowing, true
+	}
+	return "", false
+}
+
+func (h *BundleHandler) toBundleResponse(bundle *models.Bundle) dto.BundleResponse {
+	steps := make([]dto.BundleStepResponse, len(bundle.Steps))
+	for i, step := range bundle.Steps {
+		steps[i] = dto.BundleStepResponse{
+			Position:     step.Position,
+			Action:       string(step.Action),
+			Amount:       step.Amount,
+			Spender:      step.Spender,
+			Status:       string(step.Status),
+			Hash:         step.Hash,
+			BlockNumber:  step.BlockNumber,
+			GasUsed:      step.GasUsed,
+			ErrorMessage: step.ErrorMessage,
+		}
+
+		if bundle.Mode == models.BundleModeUnsigned {
+			steps[i].Transaction = &dto.UnsignedTransaction{
+				From:    bundle.Address,
+				To:      step.To,
+				Data:    step.Data,
+				Value:   "0",
+				Nonce:   step.Nonce,
+				ChainID: h.chainID,
+			}
+		}
+	}
+
+	return dto.BundleResponse{
+		ID:        bundle.ID,
+		MarketID:  bundle.MarketID,
+		Address:   bundle.Address,
+		Mode:      string(bundle.Mode),
+		Status:    string(bundle.Status),
+		Error:     bundle.Error,
+		Steps:     steps,
+		CreatedAt: bundle.CreatedAt,
+		UpdatedAt: bundle.UpdatedAt,
+	}
+}
@@ -13,12 +13,14 @@ import (
 // CollateralHandler manages collateral-related API endpoints
 type CollateralHandler struct {
 	collateralService service.CollateralService
+	priceService      service.PriceService
 }
 
 // NewCollateralHandler creates a new collateral handler
-func NewCollateralHandler(collateralService service.CollateralService) *CollateralHandler {
+func NewCollateralHandler(collateralService service.CollateralService, priceService service.PriceService) *CollateralHandler {
 	return &CollateralHandler{
 		collateralService: collateralService,
+		priceService:      priceService,
 	}
 }
 
@@ -29,10 +31,12 @@ func NewCollateralHandler(collateralService service.CollateralService) *Collater
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Param request body dto.TransactionRequest true "Collateral amount to deposit"
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /collateral/deposit [post]
 func (h *CollateralHandler) DepositCollateral(c *fiber.Ctx) error {
@@ -42,22 +46,17 @@ func (h *CollateralHandler) DepositCollateral(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Parse the request body
+	// Parse and validate the request body
 	var req dto.TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Convert the amount from string to *big.Int
-	amount, success := new(big.Int).SetString(req.Amount, 10)
-	if !success {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid amount format")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
+	amount := mustAmount(req.Amount)
 
 	// Call the collateral service to deposit collateral
-	txHash, err := h.collateralService.DepositCollateral(c.Context(), common.HexToAddress(address), amount)
+	txHash, err := h.collateralService.DepositCollateral(c.Context(), marketIdentifier(c), common.HexToAddress(address), amount)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to deposit collateral: "+err.Error())
+		return serviceError("deposit collateral", err)
 	}
 
 	// Return the transaction hash
@@ -77,10 +76,12 @@ func (h *CollateralHandler) DepositCollateral(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Param request body dto.TransactionRequest true "Collateral amount to withdraw"
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /collateral/withdraw [post]
 func (h *CollateralHandler) WithdrawCollateral(c *fiber.Ctx) error {
@@ -90,22 +91,17 @@ func (h *CollateralHandler) WithdrawCollateral(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Parse the request body
+	// Parse and validate the request body
 	var req dto.TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Convert the amount from string to *big.Int
-	amount, success := new(big.Int).SetString(req.Amount, 10)
-	if !success {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid amount format")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
+	amount := mustAmount(req.Amount)
 
 	// Call the collateral service to withdraw collateral
-	txHash, err := h.collateralService.WithdrawCollateral(c.Context(), common.HexToAddress(address), amount)
+	txHash, err := h.collateralService.WithdrawCollateral(c.Context(), marketIdentifier(c), common.HexToAddress(address), amount)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to withdraw collateral: "+err.Error())
+		return serviceError("withdraw collateral", err)
 	}
 
 	// Return the transaction hash
@@ -125,21 +121,25 @@ func (h *CollateralHandler) WithdrawCollateral(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
+// @Param aggregate query bool false "Sum over every address linked to the account"
 // @Success 200 {object} dto.APIResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /collateral/balance [get]
 func (h *CollateralHandler) GetCollateralBalance(c *fiber.Ctx) error {
-	// Extract the user address from the authentication middleware
-	address, ok := c.Locals("address").(string)
-	if !ok {
-		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	// Get the addresses the request covers (set by the linked addresses middleware)
+	addresses, err := requestAddresses(c)
+	if err != nil {
+		return err
 	}
 
-	// Call the collateral service to get the collateral balance
-	balance, err := h.collateralService.GetCollateralBalance(c.Context(), common.HexToAddress(address))
+	// Call the collateral service to get the collateral of each address the request covers
+	balance, err := sumOverAddresses(addresses, func(address common.Address) (*big.Int, error) {
+		return h.collateralService.GetCollateralBalance(c.Context(), marketIdentifier(c), address)
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get collateral balance: "+err.Error())
+		return serviceError("get collateral balance", err)
 	}
 
 	// Return the collateral balance
@@ -158,6 +158,7 @@ func (h *CollateralHandler) GetCollateralBalance(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Success 200 {object} dto.CollateralInfoResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -170,41 +171,157 @@ func (h *CollateralHandler) GetCollateralInfo(c *fiber.Ctx) error {
 	}
 
 	// Get the user's collateral balance
-	balance, err := h.collateralService.GetCollateralBalance(c.Context(), common.HexToAddress(address))
+	balance, err := h.collateralService.GetCollateralBalance(c.Context(), marketIdentifier(c), common.HexToAddress(address))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get collateral balance: "+err.Error())
+		return serviceError("get collateral balance", err)
 	}
 
 	// Get the collateral ratio
-	ratio, err := h.collateralService.GetCollateralRatio(c.Context(), common.HexToAddress(address))
+	ratio, err := h.collateralService.GetCollateralRatio(c.Context(), marketIdentifier(c), common.HexToAddress(address))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get collateral ratio: "+err.Error())
+		return serviceError("get collateral ratio", err)
 	}
 
 	// Get the minimum collateral ratio
-	minRatio, err := h.collateralService.GetMinCollateralRatio(c.Context())
+	minRatio, err := h.collateralService.GetMinCollateralRatio(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get minimum collateral ratio: "+err.Error())
+		return serviceError("get minimum collateral ratio", err)
 	}
 
 	// Get the maximum borrowable amount
-	maxBorrowable, err := h.collateralService.GetMaxBorrowableAmount(c.Context(), common.HexToAddress(address))
+	maxBorrowable, err := h.collateralService.GetMaxBorrowableAmount(c.Context(), marketIdentifier(c), common.HexToAddress(address))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get max borrowable amount: "+err.Error())
+		return serviceError("get max borrowable amount", err)
 	}
 
 	// Check if the position is at risk
-	isAtRisk, err := h.collateralService.IsAtRisk(c.Context(), common.HexToAddress(address))
+	isAtRisk, err := h.collateralService.IsAtRisk(c.Context(), marketIdentifier(c), common.HexToAddress(address))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to check risk status: "+err.Error())
+		return serviceError("check risk status", err)
+	}
+
+	// Get the collateral token to value the position in USD
+	token, err := h.collateralService.GetUnderlyingToken(c.Context(), marketIdentifier(c))
+	if err != nil {
+		return serviceError("get underlying token", err)
 	}
 
 	// Return the collateral information
 	return c.Status(fiber.StatusOK).JSON(dto.CollateralInfoResponse{
 		TotalCollateral:    balance.String(),
+		TotalCollateralUSD: usdValue(c.Context(), h.priceService, token, balance),
 		CollateralRatio:    ratio.String(),
 		MinCollateralRatio: minRatio.String(),
 		MaxBorrowable:      maxBorrowable.String(),
+		MaxBorrowableUSD:   usdValue(c.Context(), h.priceService, token, maxBorrowable),
 		IsAtRisk:           isAtRisk,
 	})
 }
+
+// GetTransactionHistory godoc
+// @Summary Get collateral transaction history
+// @Description Get paginated history of user's collateral deposits, withdrawals and liquidations
+// @Tags collateral
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param type query string false "Comma-separated transaction types"
+// @Param status query string false "Comma-separated statuses: pending, completed, failed"
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
+// @Param page query int false "Page number (default: 1)"
+// @Param pageSize query int false "Page size (default: 10, max: 100)"
+// @Success 200 {object} dto.TransactionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /collateral/transactions [get]
+func (h *CollateralHandler) GetTransactionHistory(c *fiber.Ctx) error {
+	// Extract the user address from the authentication middleware
+	address, ok := c.Locals("address").(string)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	// Get pagination parameters
+	page, pageSize, offset, err := parsePagination(c)
+	if err != nil {
+		return err
+	}
+
+	// Get filter parameters
+	filterReq, err := parseFilterRequest(c)
+	if err != nil {
+		return err
+	}
+
+	filter, err := transactionFilter(filterReq)
+	if err != nil {
+		return err
+	}
+
+	// Get transaction history
+	transactions, err := h.collateralService.GetUserTransactionHistory(
+		c.Context(),
+		marketIdentifier(c),
+		common.HexToAddress(address),
+		filter,
+		offset,
+		pageSize,
+	)
+	if err != nil {
+		return serviceError("get transaction history", err)
+	}
+
+	// Convert transactions to DTOs
+	txResponses := make([]dto.TransactionResponse, len(transactions))
+	for i, tx := range transactions {
+		txResponses[i] = toTransactionResponse(tx)
+	}
+
+	// Get total count for pagination
+	total, err := h.collateralService.CountUserTransactions(c.Context(), marketIdentifier(c), common.HexToAddress(address), filter)
+	if err != nil {
+		return serviceError("count transactions", err)
+	}
+
+	// Calculate total pages
+	totalPages := (int(total) + pageSize - 1) / pageSize
+
+	return c.Status(fiber.StatusOK).JSON(dto.TransactionListResponse{
+		Transactions: txResponses,
+		Total:        total,
+		Page:         page,
+		PageSize:     pageSize,
+		TotalPage:    totalPages,
+	})
+}
+
+// GetCollateralReconciliation godoc
+// @Summary Reconcile protocol collateral
+// @Description Compare the collateral held by the Collateral contract with the indexed per-user balances (requires positions.read_all)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Success 200 {object} dto.CollateralReconciliationResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /collateral/admin/reconciliation [get]
+func (h *CollateralHandler) GetCollateralReconciliation(c *fiber.Ctx) error {
+	// Cross-check on-chain collateral against indexed balances
+	reconciliation, err := h.collateralService.ReconcileTotalCollateral(c.Context(), marketIdentifier(c))
+	if err != nil {
+		return serviceError("reconcile collateral", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.CollateralReconciliationResponse{
+		OnChainCollateral: reconciliation.OnChain.String(),
+		IndexedCollateral: reconciliation.Indexed.String(),
+		Difference:        reconciliation.Difference.String(),
+		Diverged:          reconciliation.Diverged,
+	})
+}
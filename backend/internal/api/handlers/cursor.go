@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// cursorCodec turns cursors into opaque tokens, signed so that clients cannot forge positions
+type cursorCodec struct {
+	key []byte
+}
+
+// cursorToken is the signed content of a cursor token
+type cursorToken struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"i"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// newCursorCodec derives the cursor signing key from a secret, which may also sign access tokens
+func newCursorCodec(secret string) *cursorCodec {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("pagination cursor"))
+	return &cursorCodec{key: mac.Sum(nil)}
+}
+
+// encode returns the token of a cursor
+func (c *cursorCodec) encode(cursor models.Cursor) string {
+	payload, _ := json.Marshal(cursorToken{
+		CreatedAt: cursor.CreatedAt,
+		ID:        cursor.ID,
+		Backward:  cursor.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// decode returns the cursor of a token, or nil for an empty token
+func (c *cursorCodec) decode(token string) (*models.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	invalid := fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, invalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, invalid
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return nil, invalid
+	}
+
+	var decoded cursorToken
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, invalid
+	}
+
+	return &models.Cursor{CreatedAt: decoded.CreatedAt, ID: decoded.ID, Backward: decoded.Backward}, nil
+}
+
+func (c *cursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// pageLinks returns the links to the pages before and after a page, from the cursors of its first and last rows.
+// The links repeat the query of the request, with the cursor replacing the page number
+func (c *cursorCodec) pageLinks(ctx *fiber.Ctx, first, last models.Cursor, hasPrev, hasNext bool) (prev, next string) {
+	query, _ := url.ParseQuery(string(ctx.Request().URI().QueryString()))
+	query.Del("page")
+
+	link := func(cursor models.Cursor) string {
+		query.Set("cursor", c.encode(cursor))
+		return ctx.Path() + "?" + query.Encode()
+	}
+
+	if hasPrev {
+		first.Backward = true
+		prev = link(first)
+	}
+
+	if hasNext {
+		last.Backward = false
+		next = link(last)
+	}
+
+	return prev, next
+}
+
+// trimCursorPage drops the extra row read past a cursor page to find out whether the list goes on,
+// and reports whether pages exist before and after the page
+func trimCursorPage[T any](rows []T, cursor *models.Cursor, pageSize int) (page []T, hasPrev, hasNext bool) {
+	more := len(rows) > pageSize
+
+	// Reading backward, the extra row comes first and the page was reached from the one after it
+	if cursor != nil && cursor.Backward {
+		if more {
+			rows = rows[1:]
+		}
+		return rows, more, true
+	}
+
+	if more {
+		rows = rows[:pageSize]
+	}
+	return rows, cursor != nil, more
+}
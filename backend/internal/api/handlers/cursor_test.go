@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+func TestCursorCodecRoundTrip(t *testing.T) {
+	codec := newCursorCodec("secret")
+	cursor := models.Cursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC), ID: 42, Backward: true}
+
+	decoded, err := codec.decode(codec.encode(cursor))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID || decoded.Backward != cursor.Backward {
+		t.Errorf("got cursor %+v, want %+v", *decoded, cursor)
+	}
+
+	if decoded, err := codec.decode(""); decoded != nil || err != nil {
+		t.Errorf("got cursor %v and error %v for an empty token, want neither", decoded, err)
+	}
+}
+
+func TestCursorCodecRejectsTamperedTokens(t *testing.T) {
+	codec := newCursorCodec("secret")
+	token := codec.encode(models.Cursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42})
+	payload, signature, _ := strings.Cut(token, ".")
+
+	// A payload pointing at another row, kept with the signature of the original one
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2025-01-02T03:04:05Z","i":1}`))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "forged payload", token: forged + "." + signature},
+		{name: "payload flipped to backward", token: base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2025-01-02T03:04:05Z","i":42,"b":true}`)) + "." + signature},
+		{name: "truncated signature", token: payload + "." + signature[:len(signature)-2]},
+		{name: "missing signature", token: payload},
+		{name: "empty signature", token: payload + "."},
+		{name: "signature of another secret", token: newCursorCodec("other").encode(models.Cursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42})},
+		{name: "payload not base64", token: "!!." + signature},
+		{name: "signature not base64", token: payload + ".!!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := codec.decode(tt.token)
+			var fiberErr *fiber.Error
+			if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+				t.Fatalf("got cursor %v and error %v, want a bad request", cursor, err)
+			}
+		})
+	}
+}
+
+func TestTrimCursorPage(t *testing.T) {
+	forward := &models.Cursor{ID: 10}
+	backward := &models.Cursor{ID: 10, Backward: true}
+
+	tests := []struct {
+		name     string
+		rows     []int
+		cursor   *models.Cursor
+		want     []int
+		wantPrev bool
+		wantNext bool
+	}{
+		{name: "first page with more rows", rows: []int{1, 2, 3}, want: []int{1, 2}, wantNext: true},
+		{name: "only page", rows: []int{1, 2}, want: []int{1, 2}},
+		{name: "empty list", rows: nil, want: nil},
+		{name: "forward with more rows", rows: []int{11, 12, 13}, cursor: forward, want: []int{11, 12}, wantPrev: true, wantNext: true},
+		{name: "forward on the last page", rows: []int{11}, cursor: forward, want: []int{11}, wantPrev: true},
+		// Backward rows are already in list order, the extra row being the one furthest from the cursor
+		{name: "backward with more rows", rows: []int{7, 8, 9}, cursor: backward, want: []int{8, 9}, wantPrev: true, wantNext: true},
+		{name: "backward to the first page", rows: []int{8, 9}, cursor: backward, want: []int{8, 9}, wantNext: true},
+		{name: "backward past the first row", rows: nil, cursor: backward, want: nil, wantNext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, hasPrev, hasNext := trimCursorPage(tt.rows, tt.cursor, 2)
+			if !slices.Equal(page, tt.want) || hasPrev != tt.wantPrev || hasNext != tt.wantNext {
+				t.Errorf("got %v (prev %v, next %v), want %v (prev %v, next %v)",
+					page, hasPrev, hasNext, tt.want, tt.wantPrev, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestCursorCodecPageLinks(t *testing.T) {
+	codec := newCursorCodec("secret")
+	first := models.Cursor{CreatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), ID: 5}
+	last := models.Cursor{CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), ID: 3, Backward: true}
+
+	app := fiber.New()
+	app.Get("/transactions", func(c *fiber.Ctx) error {
+		prev, next := codec.pageLinks(c, first, last, true, true)
+		return c.SendString(prev + "\n" + next)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/transactions?page=3&pageSize=2&type=deposit", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, next, _ := strings.Cut(string(body), "\n")
+
+	links := []struct {
+		name         string
+		link         string
+		wantID       uint
+		wantBackward bool
+	}{
+		{name: "previous", link: prev, wantID: first.ID, wantBackward: true},
+		{name: "next", link: next, wantID: last.ID, wantBackward: false},
+	}
+	for _, l := range links {
+		parsed, err := url.Parse(l.link)
+		if err != nil {
+			t.Fatalf("%s link %q: %v", l.name, l.link, err)
+		}
+		query := parsed.Query()
+		if parsed.Path != "/transactions" || query.Has("page") || query.Get("pageSize") != "2" || query.Get("type") != "deposit" {
+			t.Errorf("%s link %q does not repeat the query without the page", l.name, l.link)
+		}
+
+		cursor, err := codec.decode(query.Get("cursor"))
+		if err != nil {
+			t.Fatalf("%s link: %v", l.name, err)
+		}
+		if cursor.ID != l.wantID || cursor.Backward != l.wantBackward {
+			t.Errorf("%s link points at %+v, want row %d with backward %v", l.name, *cursor, l.wantID, l.wantBackward)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// serviceError returns the error of a failed service call for the error handler. Domain errors carry a code and a
+// message clients may see and are returned as is; any other error is wrapped with the action for the server log
+// and answered with a generic 500
+func serviceError(action string, err error) error {
+	var domainErr *service.Error
+	if errors.As(err, &domainErr) {
+		return err
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
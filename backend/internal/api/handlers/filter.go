@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// parseFilterRequest reads and validates the common filter query parameters
+func parseFilterRequest(c *fiber.Ctx) (dto.FilterRequest, error) {
+	var req dto.FilterRequest
+	err := bindQuery(c, &req)
+	return req, err
+}
+
+// parsePagination reads and validates the page and pageSize query parameters, returning the offset of the page
+func parsePagination(c *fiber.Ctx) (page, pageSize, offset int, err error) {
+	var req dto.PaginationRequest
+	if err := bindQuery(c, &req); err != nil {
+		return 0, 0, 0, err
+	}
+
+	page, pageSize, offset = paginationBounds(req)
+	return page, pageSize, offset, nil
+}
+
+// dateWindow converts the startDate and endDate of a filter into an inclusive start and an exclusive end,
+// so that the end date is covered in full
+func dateWindow(req dto.FilterRequest) (from, to *time.Time, err error) {
+	if req.StartDate != "" {
+		start, err := time.Parse(time.DateOnly, req.StartDate)
+		if err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid startDate, expected YYYY-MM-DD")
+		}
+		from = &start
+	}
+
+	if req.EndDate != "" {
+		end, err := time.Parse(time.DateOnly, req.EndDate)
+		if err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid endDate, expected YYYY-MM-DD")
+		}
+		end = end.AddDate(0, 0, 1)
+		to = &end
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "startDate must not be after endDate")
+	}
+
+	return from, to, nil
+}
+
+// transactionFilter converts the common filter parameters into a transaction filter
+func transactionFilter(req dto.FilterRequest) (models.TransactionFilter, error) {
+	var filter models.TransactionFilter
+
+	for _, value := range queryList(req.Type) {
+		txType := models.TransactionType(value)
+		if !txType.IsValid() {
+			return filter, fiber.NewError(fiber.StatusBadRequest, "Invalid type "+value)
+		}
+		filter.Types = append(filter.Types, txType)
+	}
+
+	for _, value := range queryList(req.Status) {
+		status := models.TransactionStatus(value)
+		if !status.IsValid() {
+			return filter, fiber.NewError(fiber.StatusBadRequest, "Invalid status "+value+", expected pending, completed or failed")
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	var err error
+	filter.From, filter.To, err = dateWindow(req)
+	return filter, err
+}
+
+// userFilter converts the common filter parameters into a user filter: the type is a role, the status is
+// verified or unverified and the dates bound the registration date
+func userFilter(req dto.FilterRequest) (models.UserFilter, error) {
+	var filter models.UserFilter
+
+	for _, value := range queryList(req.Type) {
+		role := models.UserRole(value)
+		if !role.IsValid() {
+			return filter, fiber.NewError(fiber.StatusBadRequest, "Invalid type "+value+", expected a role")
+		}
+		filter.Roles = append(filter.Roles, role)
+	}
+
+	switch req.Status {
+	case "":
+	case "verified", "unverified":
+		verified := req.Status == "verified"
+		filter.Verified = &verified
+	default:
+		return filter, fiber.NewError(fiber.StatusBadRequest, "Invalid status, expected verified or unverified")
+	}
+
+	var err error
+	filter.From, filter.To, err = dateWindow(req)
+	return filter, err
+}
+
+// deletionRequestFilter converts the common filter parameters into a deletion request filter
+func deletionRequestFilter(req dto.FilterRequest) (models.DeletionRequestFilter, error) {
+	var filter models.DeletionRequestFilter
+
+	if req.Type != "" {
+		return filter, fiber.NewError(fiber.StatusBadRequest, "Deletion requests cannot be filtered by type")
+	}
+
+	for _, value := range queryList(req.Status) {
+		status := models.DeletionRequestStatus(value)
+		if !status.IsValid() {
+			return filter, fiber.NewError(fiber.StatusBadRequest, "Invalid status "+value+", expected pending, approved or rejected")
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	var err error
+	filter.From, filter.To, err = dateWindow(req)
+	return filter, err
+}
+
+// auditLogFilter converts the audit log filter parameters into an audit log filter
+func auditLogFilter(req dto.AuditLogFilterRequest) (models.AuditLogFilter, error) {
+	filter := models.AuditLogFilter{
+		Action:     models.AuditAction(req.Action),
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	}
+
+	if req.Type != "" || req.Status != "" {
+		return filter, fiber.NewError(fiber.StatusBadRequest, "Audit log entries cannot be filtered by type or status")
+	}
+
+	if req.ActorID != 0 {
+		filter.ActorID = &req.ActorID
+	}
+
+	var err error
+	filter.From, filter.To, err = dateWindow(req.FilterRequest)
+	return filter, err
+}
+
+// queryList splits a comma-separated query parameter into its trimmed values
+func queryList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var values []string
+	for item := range strings.SplitSeq(value, ",") {
+		values = append(values, strings.TrimSpace(item))
+	}
+	return values
+}
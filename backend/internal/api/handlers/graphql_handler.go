@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/graph"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// GraphQLHandler manages the GraphQL endpoint
+type GraphQLHandler struct {
+	server *graph.Server
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(server *graph.Server) *GraphQLHandler {
+	return &GraphQLHandler{
+		server: server,
+	}
+}
+
+// Query godoc
+// @Summary Run a GraphQL query
+// @Description Query users, positions, transactions, markets and liquidation candidates in one request.
+// @Description Queries are limited in depth and complexity; fields the user may not read resolve to null with an error
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param request body dto.GraphQLRequest true "GraphQL query"
+// @Success 200 {object} dto.GraphQLResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req dto.GraphQLRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	role, _ := c.Locals("role").(models.UserRole)
+	scopes, isAPIKey := c.Locals("scopes").([]models.APIKeyScope)
+
+	viewer := graph.Viewer{
+		UserID: userID,
+		Role:   role,
+		APIKey: isAPIKey,
+		Scopes: scopes,
+	}
+
+	// Errors are part of the GraphQL response, which is sent whatever the outcome of the query
+	return c.Status(fiber.StatusOK).JSON(h.server.Exec(c.Context(), viewer, req.Query, req.OperationName, req.Variables))
+}
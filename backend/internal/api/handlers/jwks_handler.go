@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with
+type JWKSHandler struct {
+	authService service.AuthService
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(authService service.AuthService) *JWKSHandler {
+	return &JWKSHandler{
+		authService: authService,
+	}
+}
+
+// GetJWKS godoc
+// @Summary Get the JSON Web Key Set
+// @Description Get the public keys of the active signing key and of retired keys still in their grace period
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dto.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	keySet := dto.JSONWebKeySet{Keys: []dto.JSONWebKey{}}
+	for _, key := range h.authService.VerificationKeys() {
+		if jwk, ok := toJSONWebKey(key); ok {
+			keySet.Keys = append(keySet.Keys, jwk)
+		}
+	}
+
+	// Let verifiers cache the keys, a retired key stays published for its whole grace period
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(keySet)
+}
+
+// toJSONWebKey converts a verification key to its JWK representation
+func toJSONWebKey(key service.VerificationKey) (dto.JSONWebKey, bool) {
+	jwk := dto.JSONWebKey{
+		Use: "sig",
+		Alg: key.Algorithm,
+		Kid: key.ID,
+	}
+
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	default:
+		return dto.JSONWebKey{}, false
+	}
+
+	return jwk, true
+}
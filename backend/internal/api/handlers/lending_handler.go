@@ -2,25 +2,25 @@ package handlers
 
 import (
 	"math/big"
-	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
-	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
 // LendingHandler manages lending-related API endpoints
 type LendingHandler struct {
 	lendingService service.LendingService
+	priceService   service.PriceService
 }
 
 // NewLendingHandler creates a new lending handler
-func NewLendingHandler(lendingService service.LendingService) *LendingHandler {
+func NewLendingHandler(lendingService service.LendingService, priceService service.PriceService) *LendingHandler {
 	return &LendingHandler{
 		lendingService: lendingService,
+		priceService:   priceService,
 	}
 }
 
@@ -31,10 +31,12 @@ func NewLendingHandler(lendingService service.LendingService) *LendingHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Param request body dto.TransactionRequest true "Deposit amount"
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /lending/deposit [post]
 func (h *LendingHandler) Deposit(c *fiber.Ctx) error {
@@ -44,22 +46,17 @@ func (h *LendingHandler) Deposit(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Parse the request body
+	// Parse and validate the request body
 	var req dto.TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Convert the amount from string to *big.Int
-	amount, success := new(big.Int).SetString(req.Amount, 10)
-	if !success {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid amount format")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
+	amount := mustAmount(req.Amount)
 
 	// Call the lending service to make the deposit
-	txHash, err := h.lendingService.Deposit(c.Context(), common.HexToAddress(address), amount)
+	txHash, err := h.lendingService.Deposit(c.Context(), marketIdentifier(c), common.HexToAddress(address), amount)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to process deposit: "+err.Error())
+		return serviceError("process deposit", err)
 	}
 
 	// Return the transaction hash
@@ -79,10 +76,12 @@ func (h *LendingHandler) Deposit(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Param request body dto.TransactionRequest true "Withdraw amount"
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /lending/withdraw [post]
 func (h *LendingHandler) Withdraw(c *fiber.Ctx) error {
@@ -92,22 +91,17 @@ func (h *LendingHandler) Withdraw(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Parse the request body
+	// Parse and validate the request body
 	var req dto.TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Convert the amount from string to *big.Int
-	amount, success := new(big.Int).SetString(req.Amount, 10)
-	if !success {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid amount format")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
+	amount := mustAmount(req.Amount)
 
 	// Call the lending service to make the withdrawal
-	txHash, err := h.lendingService.Withdraw(c.Context(), common.HexToAddress(address), amount)
+	txHash, err := h.lendingService.Withdraw(c.Context(), marketIdentifier(c), common.HexToAddress(address), amount)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to process withdrawal: "+err.Error())
+		return serviceError("process withdrawal", err)
 	}
 
 	// Return the transaction hash
@@ -127,21 +121,25 @@ func (h *LendingHandler) Withdraw(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
+// @Param aggregate query bool false "Sum over every address linked to the account"
 // @Success 200 {object} dto.APIResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /lending/balance [get]
 func (h *LendingHandler) GetLendingBalance(c *fiber.Ctx) error {
-	// Extract the user address from the authentication middleware
-	address, ok := c.Locals("address").(string)
-	if !ok {
-		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	// Get the addresses the request covers (set by the linked addresses middleware)
+	addresses, err := requestAddresses(c)
+	if err != nil {
+		return err
 	}
 
-	// Call the lending service to get the balance
-	balance, err := h.lendingService.GetUserBalance(c.Context(), common.HexToAddress(address))
+	// Call the lending service to get the balance of each address the request covers
+	balance, err := sumOverAddresses(addresses, func(address common.Address) (*big.Int, error) {
+		return h.lendingService.GetUserBalance(c.Context(), marketIdentifier(c), address)
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get lending balance: "+err.Error())
+		return serviceError("get lending balance", err)
 	}
 
 	// Return the balance
@@ -160,39 +158,53 @@ func (h *LendingHandler) GetLendingBalance(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
+// @Param aggregate query bool false "Sum over every address linked to the account"
 // @Success 200 {object} dto.LendingInfoResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /lending/info [get]
 func (h *LendingHandler) GetLendingInfo(c *fiber.Ctx) error {
-	// Extract the user address from the authentication middleware
-	address, ok := c.Locals("address").(string)
-	if !ok {
-		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	// Get the addresses the request covers (set by the linked addresses middleware)
+	addresses, err := requestAddresses(c)
+	if err != nil {
+		return err
 	}
 
 	// Get the user's balance
-	balance, err := h.lendingService.GetUserBalance(c.Context(), common.HexToAddress(address))
+	balance, err := sumOverAddresses(addresses, func(address common.Address) (*big.Int, error) {
+		return h.lendingService.GetUserBalance(c.Context(), marketIdentifier(c), address)
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get lending balance: "+err.Error())
+		return serviceError("get lending balance", err)
 	}
 
 	// Get the current interest rate
-	interestRate, err := h.lendingService.GetCurrentInterestRate(c.Context())
+	interestRate, err := h.lendingService.GetCurrentInterestRate(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get interest rate: "+err.Error())
+		return serviceError("get interest rate", err)
 	}
 
 	// Get the interest earned by the user
-	interestEarned, err := h.lendingService.GetUserInterestEarned(c.Context(), common.HexToAddress(address))
+	interestEarned, err := sumOverAddresses(addresses, func(address common.Address) (*big.Int, error) {
+		return h.lendingService.GetUserInterestEarned(c.Context(), marketIdentifier(c), address)
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get interest earned: "+err.Error())
+		return serviceError("get interest earned", err)
+	}
+
+	// Get the deposited token to value the position in USD
+	token, err := h.lendingService.GetUnderlyingToken(c.Context(), marketIdentifier(c))
+	if err != nil {
+		return serviceError("get underlying token", err)
 	}
 
 	// Return the lending information
 	return c.Status(fiber.StatusOK).JSON(dto.LendingInfoResponse{
 		TotalDeposited:      balance.String(),
+		TotalDepositedUSD:   usdValue(c.Context(), h.priceService, token, balance),
 		InterestEarned:      interestEarned.String(),
+		InterestEarnedUSD:   usdValue(c.Context(), h.priceService, token, interestEarned),
 		CurrentInterestRate: interestRate.String(),
 	})
 }
@@ -204,9 +216,15 @@ func (h *LendingHandler) GetLendingInfo(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
+// @Param type query string false "Comma-separated transaction types"
+// @Param status query string false "Comma-separated statuses: pending, completed, failed"
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
 // @Param page query int false "Page number (default: 1)"
 // @Param pageSize query int false "Page size (default: 10, max: 100)"
 // @Success 200 {object} dto.TransactionListResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /lending/transactions [get]
@@ -218,60 +236,45 @@ func (h *LendingHandler) GetTransactionHistory(c *fiber.Ctx) error {
 	}
 
 	// Get pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("pageSize", "10"))
-
-	if page < 1 {
-		page = 1
+	page, pageSize, offset, err := parsePagination(c)
+	if err != nil {
+		return err
 	}
 
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	// Get filter parameters
+	filterReq, err := parseFilterRequest(c)
+	if err != nil {
+		return err
 	}
 
-	// Calculate offset
-	offset := (page - 1) * pageSize
+	filter, err := transactionFilter(filterReq)
+	if err != nil {
+		return err
+	}
 
 	// Get transaction history
 	transactions, err := h.lendingService.GetUserTransactionHistory(
 		c.Context(),
+		marketIdentifier(c),
 		common.HexToAddress(address),
+		filter,
 		offset,
 		pageSize,
 	)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction history: "+err.Error())
+		return serviceError("get transaction history", err)
 	}
 
 	// Convert transactions to DTOs
 	txResponses := make([]dto.TransactionResponse, len(transactions))
 	for i, tx := range transactions {
-		txResponses[i] = dto.TransactionResponse{
-			ID:           tx.ID,
-			UserID:       tx.UserID,
-			Type:         dto.TransactionType(tx.Type),
-			Status:       dto.TransactionStatus(tx.Status),
-			Hash:         tx.Hash,
-			Amount:       tx.Amount,
-			TokenAddress: tx.TokenAddress,
-			BlockNumber:  tx.BlockNumber,
-			GasUsed:      tx.GasUsed,
-			GasPrice:     tx.GasPrice,
-			CreatedAt:    tx.CreatedAt,
-			UpdatedAt:    tx.UpdatedAt,
-		}
+		txResponses[i] = toTransactionResponse(tx)
 	}
 
 	// Get total count for pagination
-	ethAddress := common.HexToAddress(address)
-	filter := map[string]any{
-		"type": []models.TransactionType{models.TransactionDeposit, models.TransactionWithdraw},
-	}
-
-	// Get total count from service
-	total, err := h.lendingService.CountUserTransactions(c.Context(), ethAddress, filter)
+	total, err := h.lendingService.CountUserTransactions(c.Context(), marketIdentifier(c), common.HexToAddress(address), filter)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to count transactions: "+err.Error())
+		return serviceError("count transactions", err)
 	}
 
 	// Calculate total pages
@@ -297,15 +300,15 @@ func (h *LendingHandler) GetTransactionHistory(c *fiber.Ctx) error {
 // @Router /lending/pool-info [get]
 func (h *LendingHandler) GetPoolInfo(c *fiber.Ctx) error {
 	// Get the total deposited amount
-	totalDeposited, err := h.lendingService.GetTotalDeposited(c.Context())
+	totalDeposited, err := h.lendingService.GetTotalDeposited(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get total deposited: "+err.Error())
+		return serviceError("get total deposited", err)
 	}
 
 	// Get the current interest rate
-	interestRate, err := h.lendingService.GetCurrentInterestRate(c.Context())
+	interestRate, err := h.lendingService.GetCurrentInterestRate(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get interest rate: "+err.Error())
+		return serviceError("get interest rate", err)
 	}
 
 	// Return the pool information
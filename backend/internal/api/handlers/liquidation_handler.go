@@ -1,9 +1,6 @@
 package handlers
 
 import (
-	"math/big"
-	"strconv"
-
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofiber/fiber/v2"
 
@@ -30,10 +27,12 @@ func NewLiquidationHandler(liquidationService service.LiquidationService) *Liqui
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Param request body dto.TransactionLiquidationRequest true "Liquidation parameters"
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /liquidation/liquidate [post]
 func (h *LiquidationHandler) Liquidate(c *fiber.Ctx) error {
@@ -43,27 +42,23 @@ func (h *LiquidationHandler) Liquidate(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Parse the request body
+	// Parse and validate the request body
 	var req dto.TransactionLiquidationRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Convert the amount from string to *big.Int
-	amount, success := new(big.Int).SetString(req.Amount, 10)
-	if !success {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid amount format")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
+	amount := mustAmount(req.Amount)
 
 	// Call the liquidation service to liquidate the position
 	txHash, err := h.liquidationService.Liquidate(
 		c.Context(),
+		marketIdentifier(c),
 		common.HexToAddress(liquidatorAddress),
 		common.HexToAddress(req.BorrowerAddress),
 		amount,
 	)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to process liquidation: "+err.Error())
+		return serviceError("process liquidation", err)
 	}
 
 	// Return the transaction hash
@@ -87,15 +82,15 @@ func (h *LiquidationHandler) Liquidate(c *fiber.Ctx) error {
 // @Router /liquidation/positions [get]
 func (h *LiquidationHandler) GetLiquidatablePositions(c *fiber.Ctx) error {
 	// Call the liquidation service to get liquidatable positions
-	positions, err := h.liquidationService.GetLiquidatablePositions(c.Context())
+	positions, err := h.liquidationService.GetLiquidatablePositions(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get liquidatable positions: "+err.Error())
+		return serviceError("get liquidatable positions", err)
 	}
 
 	// Get liquidation bonus
-	bonus, err := h.liquidationService.GetLiquidationBonus(c.Context())
+	bonus, err := h.liquidationService.GetLiquidationBonus(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get liquidation bonus: "+err.Error())
+		return serviceError("get liquidation bonus", err)
 	}
 
 	// Convert positions to DTOs
@@ -125,56 +120,49 @@ func (h *LiquidationHandler) GetLiquidatablePositions(c *fiber.Ctx) error {
 // @Tags liquidation
 // @Accept json
 // @Produce json
+// @Param status query string false "Comma-separated statuses: pending, completed, failed"
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
 // @Param page query int false "Page number (default: 1)"
 // @Param pageSize query int false "Page size (default: 10, max: 100)"
 // @Success 200 {object} dto.TransactionListResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /liquidation/history [get]
 func (h *LiquidationHandler) GetLiquidationHistory(c *fiber.Ctx) error {
 	// Get pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("pageSize", "10"))
-
-	if page < 1 {
-		page = 1
+	page, pageSize, offset, err := parsePagination(c)
+	if err != nil {
+		return err
 	}
 
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	// Get filter parameters
+	filterReq, err := parseFilterRequest(c)
+	if err != nil {
+		return err
 	}
 
-	// Calculate offset
-	offset := (page - 1) * pageSize
+	filter, err := transactionFilter(filterReq)
+	if err != nil {
+		return err
+	}
 
 	// Get liquidation history
-	transactions, err := h.liquidationService.GetLiquidationHistory(c.Context(), offset, pageSize)
+	transactions, err := h.liquidationService.GetLiquidationHistory(c.Context(), marketIdentifier(c), filter, offset, pageSize)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get liquidation history: "+err.Error())
+		return serviceError("get liquidation history", err)
 	}
 
 	// Convert transactions to DTOs
 	txResponses := make([]dto.TransactionResponse, len(transactions))
 	for i, tx := range transactions {
-		txResponses[i] = dto.TransactionResponse{
-			ID:           tx.ID,
-			UserID:       tx.UserID,
-			Type:         dto.TransactionType(tx.Type),
-			Status:       dto.TransactionStatus(tx.Status),
-			Hash:         tx.Hash,
-			Amount:       tx.Amount,
-			TokenAddress: tx.TokenAddress,
-			BlockNumber:  tx.BlockNumber,
-			GasUsed:      tx.GasUsed,
-			GasPrice:     tx.GasPrice,
-			CreatedAt:    tx.CreatedAt,
-			UpdatedAt:    tx.UpdatedAt,
-		}
+		txResponses[i] = toTransactionResponse(tx)
 	}
 
 	// Get total count from service
-	total, err := h.liquidationService.CountLiquidations(c.Context())
+	total, err := h.liquidationService.CountLiquidations(c.Context(), marketIdentifier(c), filter)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to count liquidations: "+err.Error())
+		return serviceError("count liquidations", err)
 	}
 
 	// Calculate total pages
@@ -200,9 +188,9 @@ func (h *LiquidationHandler) GetLiquidationHistory(c *fiber.Ctx) error {
 // @Router /liquidation/bonus [get]
 func (h *LiquidationHandler) GetLiquidationBonus(c *fiber.Ctx) error {
 	// Call the liquidation service to get the liquidation bonus
-	bonus, err := h.liquidationService.GetLiquidationBonus(c.Context())
+	bonus, err := h.liquidationService.GetLiquidationBonus(c.Context(), marketIdentifier(c))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get liquidation bonus: "+err.Error())
+		return serviceError("get liquidation bonus", err)
 	}
 
 	// Return the liquidation bonus
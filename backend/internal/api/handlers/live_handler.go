@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// LiveHandler manages the live event feed, over WebSocket or Server-Sent Events
+type LiveHandler struct {
+	liveService service.LiveService
+	heartbeat   time.Duration
+}
+
+// NewLiveHandler creates a new live feed handler
+func NewLiveHandler(liveService service.LiveService, cfg *config.Config) *LiveHandler {
+	return &LiveHandler{
+		liveService: liveService,
+		heartbeat:   time.Duration(cfg.Live.Heartbeat) * time.Second,
+	}
+}
+
+// Upgrade godoc
+// @Summary Open a live feed WebSocket
+// @Description Stream health factor changes of the user's positions, status changes of their transactions,
+// @Description new blocks and market rate changes as JSON messages. Browsers may pass the access token as ?access_token=.
+// @Description The server pings every LIVE_HEARTBEAT seconds and closes with code 1013 clients that fall behind
+// @Tags live
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param topics query string false "Comma-separated topics: health, transactions, blocks, rates (all by default)"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 426 {object} dto.ErrorResponse
+// @Router /live/ws [get]
+func (h *LiveHandler) Upgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.NewError(fiber.StatusUpgradeRequired, "WebSocket upgrade required, use /live/events for Server-Sent Events")
+	}
+
+	topics, err := parseLiveTopics(c.Query("topics"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	c.Locals("topics", topics)
+	return c.Next()
+}
+
+// WebSocket streams the events of a subscription over an upgraded connection
+func (h *LiveHandler) WebSocket(conn *websocket.Conn) {
+	userID, _ := conn.Locals("userID").(uint)
+	topics, _ := conn.Locals("topics").([]service.LiveTopic)
+
+	subscription := h.liveService.Subscribe(userID, topics)
+	defer subscription.Close()
+
+	// Clients are not expected to send messages, but reading handles their pongs and close frames.
+	// A client missing two heartbeats is disconnected
+	conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	})
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-subscription.Done():
+			message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, subscription.Err().Error())
+			conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(h.heartbeat))
+			return
+		case event := <-subscription.Events():
+			conn.SetWriteDeadline(time.Now().Add(h.heartbeat))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.heartbeat)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Events godoc
+// @Summary Stream live events
+// @Description Server-Sent Events fallback of the live feed WebSocket. Each event is named after its topic and
+// @Description carries the same JSON as WebSocket messages; a comment is sent every LIVE_HEARTBEAT seconds.
+// @Description Clients that fall behind receive a close event and are disconnected
+// @Tags live
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param topics query string false "Comma-separated topics: health, transactions, blocks, rates (all by default)"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /live/events [get]
+func (h *LiveHandler) Events(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	topics, err := parseLiveTopics(c.Query("topics"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Keep reverse proxies from buffering the stream
+
+	subscription := h.liveService.Subscribe(userID, topics)
+	heartbeat := h.heartbeat
+
+	// The stream outlives the handler, so it must not use the request context
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		// Flushing right away lets the client know the stream is open
+		fmt.Fprint(w, ": connected\n\n")
+
+		for {
+			if err := w.Flush(); err != nil {
+				// The client went away
+				return
+			}
+
+			select {
+			case <-subscription.Done():
+				fmt.Fprintf(w, "event: close\ndata: %s\n\n", subscription.Err())
+				w.Flush()
+				return
+			case event := <-subscription.Events():
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Topic, data)
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+		}
+	})
+
+	return nil
+}
+
+// parseLiveTopics parses a comma-separated list of live topics, every topic when empty
+func parseLiveTopics(value string) ([]service.LiveTopic, error) {
+	if value == "" {
+		return service.LiveTopics, nil
+	}
+
+	var topics []service.LiveTopic
+	for name := range strings.SplitSeq(value, ",") {
+		topic := service.LiveTopic(strings.TrimSpace(name))
+		if !topic.IsValid() {
+			return nil, fmt.Errorf("invalid topic %s, expected health, transactions, blocks or rates", topic)
+		}
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
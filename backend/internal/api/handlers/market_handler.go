@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"math/big"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,6 +18,9 @@ type MarketHandler struct {
 	lendingService     service.LendingService
 	borrowingService   service.BorrowingService
 	collateralService  service.CollateralService
+	marketService      service.MarketService
+	marketRegistry     service.MarketRegistry
+	priceService       service.PriceService
 	userRepository     repository.UserRepository
 	positionRepository repository.PositionRepository
 }
@@ -26,6 +30,9 @@ func NewMarketHandler(
 	lendingService service.LendingService,
 	borrowingService service.BorrowingService,
 	collateralService service.CollateralService,
+	marketService service.MarketService,
+	marketRegistry service.MarketRegistry,
+	priceService service.PriceService,
 	userRepository repository.UserRepository,
 	positionRepository repository.PositionRepository,
 ) *MarketHandler {
@@ -33,6 +40,9 @@ func NewMarketHandler(
 		lendingService:     lendingService,
 		borrowingService:   borrowingService,
 		collateralService:  collateralService,
+		marketService:      marketService,
+		marketRegistry:     marketRegistry,
+		priceService:       priceService,
 		userRepository:     userRepository,
 		positionRepository: positionRepository,
 	}
@@ -40,54 +50,83 @@ func NewMarketHandler(
 
 // GetMarketOverview godoc
 // @Summary Get market overview
-// @Description Get overview of the market including TVL, borrowed amounts, and rates
+// @Description Get overview of the market including TVL (lending, collateral and borrowed breakdown) and rates
 // @Tags market
 // @Accept json
 // @Produce json
+// @Param market query string false "Market identifier, defaults to the default market"
 // @Success 200 {object} dto.MarketOverviewResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /market/overview [get]
 func (h *MarketHandler) GetMarketOverview(c *fiber.Ctx) error {
+	// Resolve the requested market
+	market, err := h.marketRegistry.GetMarket(c.Context(), c.Query("market"))
+	if err != nil {
+		return serviceError("resolve market", err)
+	}
+
 	// Get total deposited amount
-	totalDeposited, err := h.lendingService.GetTotalDeposited(c.Context())
+	totalDeposited, err := h.lendingService.GetTotalDeposited(c.Context(), market.Identifier)
+	if err != nil {
+		return serviceError("get total deposited", err)
+	}
+
+	// Get total collateral amount
+	totalCollateral, err := h.collateralService.GetTotalCollateral(c.Context(), market.Identifier)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get total deposited: "+err.Error())
+		return serviceError("get total collateral", err)
 	}
 
 	// Get total borrowed amount
-	totalBorrowed, err := h.borrowingService.GetTotalBorrowed(c.Context())
+	totalBorrowed, err := h.borrowingService.GetTotalBorrowed(c.Context(), market.Identifier)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get total borrowed: "+err.Error())
+		return serviceError("get total borrowed", err)
 	}
 
 	// Get lending interest rate
-	lendingRate, err := h.lendingService.GetCurrentInterestRate(c.Context())
+	lendingRate, err := h.lendingService.GetCurrentInterestRate(c.Context(), market.Identifier)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get lending interest rate: "+err.Error())
+		return serviceError("get lending interest rate", err)
 	}
 
 	// Get borrowing interest rate
-	borrowingRate, err := h.borrowingService.GetCurrentInterestRate(c.Context())
+	borrowingRate, err := h.borrowingService.GetCurrentInterestRate(c.Context(), market.Identifier)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get borrowing interest rate: "+err.Error())
+		return serviceError("get borrowing interest rate", err)
 	}
 
 	// Get active users count (users who have logged in within the last 30 days)
 	activeUsersCount, err := h.getActiveUsersCount(c.Context())
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get active users count: "+err.Error())
+		return serviceError("get active users count", err)
 	}
 
 	// Get active positions count
-	activePositionsCount, err := h.getActivePositionsCount(c.Context())
+	activePositionsCount, err := h.getActivePositionsCount(c.Context(), market.ID)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get active positions count: "+err.Error())
+		return serviceError("get active positions count", err)
 	}
 
+	// Value locked is what sits in the lending pool and the collateral contract;
+	// borrowed funds have left the protocol and are reported separately
+	totalValueLocked := new(big.Int).Add(totalDeposited, totalCollateral)
+
+	// Every contract of a market works with the same token, so a single price values all totals
+	token := market.GetTokenAddress()
+
 	// Return the market overview
 	return c.Status(fiber.StatusOK).JSON(dto.MarketOverviewResponse{
-		TotalValueLocked:    totalDeposited.String(),
+		Market:              market.Identifier,
+		TotalValueLocked:    totalValueLocked.String(),
+		TotalValueLockedUSD: usdValue(c.Context(), h.priceService, token, totalValueLocked),
+		TVLBreakdown: dto.TVLBreakdown{
+			Lending:    totalDeposited.String(),
+			Collateral: totalCollateral.String(),
+			Borrowed:   totalBorrowed.String(),
+		},
 		TotalBorrowed:       totalBorrowed.String(),
+		TotalBorrowedUSD:    usdValue(c.Context(), h.priceService, token, totalBorrowed),
 		ActiveUsers:         activeUsersCount,
 		ActivePositions:     activePositionsCount,
 		AverageLendingAPY:   lendingRate.String(),
@@ -101,18 +140,19 @@ func (h *MarketHandler) getActiveUsersCount(ctx context.Context) (int64, error)
 	thirtyDaysAgo := time.Now().AddDate(0, 0, -30)
 
 	// Using a filter to count users who have logged in recently
-	filter := map[string]any{
-		"last_login_after": thirtyDaysAgo,
+	filter := models.UserFilter{
+		LastLoginAfter: &thirtyDaysAgo,
 	}
 
 	return h.userRepository.CountWithFilter(ctx, filter)
 }
 
-// getActivePositionsCount returns the count of active positions
-func (h *MarketHandler) getActivePositionsCount(ctx context.Context) (int64, error) {
+// getActivePositionsCount returns the count of active positions in a market
+func (h *MarketHandler) getActivePositionsCount(ctx context.Context, marketID uint) (int64, error) {
 	// Using a filter to count positions with status "active"
-	filter := map[string]any{
-		"status": models.StatusActive,
+	filter := models.PositionFilter{
+		MarketID: &marketID,
+		Statuses: []models.PositionStatus{models.StatusActive},
 	}
 
 	// Count positions that match the filter
@@ -129,29 +169,78 @@ func (h *MarketHandler) getActivePositionsCount(ctx context.Context) (int64, err
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /market/tokens [get]
 func (h *MarketHandler) GetTokensMarketData(c *fiber.Ctx) error {
-	// In a real implementation, you'd get token data from your services or a dedicated token service
-	// This is just a placeholder implementation
+	// Get market data for all supported tokens
+	tokens, err := h.marketService.GetTokensMarketData(c.Context())
+	if err != nil {
+		return serviceError("get tokens market data", err)
+	}
 
-	// Return a placeholder response
-	return c.Status(fiber.StatusOK).JSON(dto.TokensMarketResponse{
-		Tokens: []dto.TokenMarketData{
-			{
-				Token: dto.TokenMetadata{
-					Address:  "0x...", // Replace with actual token address
-					Symbol:   "ETH",
-					Name:     "Ethereum",
-					Decimals: 18,
-					PriceUSD: "1000.00",
-					LogoURI:  "https://example.com/eth-logo.png",
-				},
-				TotalSupply:        "1000000000000000000000",
-				TotalDeposited:     "500000000000000000000",
-				TotalBorrowed:      "200000000000000000000",
-				AvailableLiquidity: "300000000000000000000",
-				LendingAPY:         "500000000000000000",  // 0.5%
-				BorrowingAPY:       "1000000000000000000", // 1%
-				CollateralFactor:   "750000000000000000",  // 75%
+	// Convert market data to DTOs
+	tokenResponses := make([]dto.TokenMarketData, len(tokens))
+	for i, token := range tokens {
+		tokenResponses[i] = dto.TokenMarketData{
+			Market: token.Market,
+			Token: dto.TokenMetadata{
+				Address:  token.Address.Hex(),
+				Symbol:   token.Symbol,
+				Name:     token.Name,
+				Decimals: int(token.Decimals),
+				PriceUSD: usdPrice(c.Context(), h.priceService, token.Address),
 			},
-		},
+			TotalSupply:           token.TotalSupply.String(),
+			TotalDeposited:        token.TotalDeposited.String(),
+			TotalDepositedUSD:     usdValue(c.Context(), h.priceService, token.Address, token.TotalDeposited),
+			TotalBorrowed:         token.TotalBorrowed.String(),
+			TotalBorrowedUSD:      usdValue(c.Context(), h.priceService, token.Address, token.TotalBorrowed),
+			AvailableLiquidity:    token.AvailableLiquidity.String(),
+			AvailableLiquidityUSD: usdValue(c.Context(), h.priceService, token.Address, token.AvailableLiquidity),
+			LendingAPY:            token.LendingRate.String(),
+			BorrowingAPY:          token.BorrowingRate.String(),
+			CollateralFactor:      token.CollateralFactor.String(),
+			BlockNumber:           token.BlockNumber,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.TokensMarketResponse{
+		Tokens: tokenResponses,
 	})
 }
+
+// ListMarkets godoc
+// @Summary List markets
+// @Description Get every active market and its contracts
+// @Tags market
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.MarketsResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /markets [get]
+func (h *MarketHandler) ListMarkets(c *fiber.Ctx) error {
+	markets, err := h.marketRegistry.ListMarkets(c.Context())
+	if err != nil {
+		return serviceError("list markets", err)
+	}
+
+	marketResponses := make([]dto.MarketResponse, len(markets))
+	for i, market := range markets {
+		marketResponses[i] = dto.MarketResponse{
+			ID:                 market.ID,
+			Identifier:         market.Identifier,
+			Name:               market.Name,
+			TokenAddress:       market.TokenAddress,
+			LendingPoolAddress: market.LendingPoolAddress,
+			BorrowingAddress:   market.BorrowingAddress,
+			CollateralAddress:  market.CollateralAddress,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.MarketsResponse{
+		Markets: marketResponses,
+	})
+}
+
+// marketIdentifier returns the market selected by the route, or an empty string for the default market
+func marketIdentifier(c *fiber.Ctx) string {
+	market, _ := c.Locals("market").(string)
+	return market
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// PositionHandler manages position endpoints
+type PositionHandler struct {
+	positionService service.PositionService
+	userService     service.UserService
+	cursors         *cursorCodec
+}
+
+// NewPositionHandler creates a new position handler
+func NewPositionHandler(positionService service.PositionService, userService service.UserService, cfg *config.Config) *PositionHandler {
+	return &PositionHandler{
+		positionService: positionService,
+		userService:     userService,
+		cursors:         newCursorCodec(cfg.Pagination.CursorSecret),
+	}
+}
+
+// ListMyPositions godoc
+// @Summary List my positions
+// @Description Get a page of the positions of the authenticated user, whether active, closed or liquidated
+// @Tags positions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param status query string false "Comma-separated statuses: active, closed, liquidated"
+// @Param minHealthFactor query string false "Minimum health factor (inclusive)"
+// @Param maxHealthFactor query string false "Maximum health factor (inclusive)"
+// @Param startDate query string false "First opening day, YYYY-MM-DD"
+// @Param endDate query string false "Last opening day, YYYY-MM-DD"
+// @Param sortBy query string false "Sort by createdAt, healthFactor or size" default(createdAt)
+// @Param order query string false "asc or desc" default(desc)
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param cursor query string false "Cursor from a next or prev link, replacing the page number (createdAt sort only)"
+// @Success 200 {object} dto.PositionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /positions [get]
+func (h *PositionHandler) ListMyPositions(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	req, filter, err := parsePositionListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if req.Address != "" {
+		return fiber.NewError(fiber.StatusBadRequest, "The address filter is only available to admins")
+	}
+
+	cursor, err := h.cursors.decode(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	filter.UserID = &userID
+	return h.listPositions(c, req, filter, cursor)
+}
+
+// ListAllPositions godoc
+// @Summary List positions of every user
+// @Description Get a page of positions across users (requires positions.read_all)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param address query string false "Filter by owner address"
+// @Param status query string false "Comma-separated statuses: active, closed, liquidated"
+// @Param minHealthFactor query string false "Minimum health factor (inclusive)"
+// @Param maxHealthFactor query string false "Maximum health factor (inclusive)"
+// @Param startDate query string false "First opening day, YYYY-MM-DD"
+// @Param endDate query string false "Last opening day, YYYY-MM-DD"
+// @Param sortBy query string false "Sort by createdAt, healthFactor or size" default(createdAt)
+// @Param order query string false "asc or desc" default(desc)
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param cursor query string false "Cursor from a next or prev link, replacing the page number (createdAt sort only)"
+// @Success 200 {object} dto.PositionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /positions/admin [get]
+func (h *PositionHandler) ListAllPositions(c *fiber.Ctx) error {
+	req, filter, err := parsePositionListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := h.cursors.decode(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	if req.Address != "" {
+		user, err := h.userService.GetByAddress(c.Context(), req.Address)
+		if err != nil {
+			return serviceError("get user", err)
+		}
+
+		// An unknown address has no positions
+		if user == nil {
+			page, pageSize, _ := paginationBounds(req.PaginationRequest)
+			response := dto.PositionListResponse{
+				Positions: []dto.PositionResponse{},
+				PageSize:  pageSize,
+			}
+			if cursor == nil {
+				response.Page = page
+			}
+			return c.Status(fiber.StatusOK).JSON(response)
+		}
+		filter.UserID = &user.ID
+	}
+
+	return h.listPositions(c, req, filter, cursor)
+}
+
+// listPositions responds with the page of positions matching a filter, from a cursor when one is set.
+// Cursors follow the creation order, so only that sort gets next and prev links
+func (h *PositionHandler) listPositions(c *fiber.Ctx, req *dto.PositionListRequest, filter models.PositionFilter, cursor *models.Cursor) error {
+	if cursor != nil && filter.SortBy != models.SortByCreatedAt {
+		return fiber.NewError(fiber.StatusBadRequest, "Cursors are only available when sorting by createdAt")
+	}
+
+	page, pageSize, offset := paginationBounds(req.PaginationRequest)
+	response := dto.PositionListResponse{PageSize: pageSize}
+
+	var positions []*models.Position
+	var hasPrev, hasNext bool
+	var err error
+	if cursor != nil {
+		// Cursor pages read one extra row to find out whether the list goes on, and are not counted
+		positions, err = h.positionService.ListPositionsByCursor(c.Context(), filter, cursor, pageSize+1)
+		if err != nil {
+			return serviceError("list positions", err)
+		}
+		positions, hasPrev, hasNext = trimCursorPage(positions, cursor, pageSize)
+	} else {
+		positions, err = h.positionService.ListPositions(c.Context(), filter, offset, pageSize)
+		if err != nil {
+			return serviceError("list positions", err)
+		}
+
+		total, err := h.positionService.CountPositions(c.Context(), filter)
+		if err != nil {
+			return serviceError("count positions", err)
+		}
+
+		response.Total = total
+		response.Page = page
+		response.TotalPage = (int(total) + pageSize - 1) / pageSize
+		hasPrev, hasNext = page > 1, offset+len(positions) < int(total)
+	}
+
+	response.Positions = make([]dto.PositionResponse, len(positions))
+	for i, position := range positions {
+		response.Positions[i] = toPositionResponse(position)
+	}
+
+	if len(positions) > 0 && filter.SortBy == models.SortByCreatedAt {
+		first, last := positions[0], positions[len(positions)-1]
+		response.Prev, response.Next = h.cursors.pageLinks(c,
+			models.Cursor{CreatedAt: first.CreatedAt, ID: first.ID},
+			models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+			hasPrev, hasNext)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetMyPosition godoc
+// @Summary Get my position
+// @Description Get a position of the authenticated user along with the transactions that opened, changed or closed it
+// @Tags positions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param id path int true "Position ID"
+// @Success 200 {object} dto.APIResponse{data=dto.PositionDetailResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /positions/{id} [get]
+func (h *PositionHandler) GetMyPosition(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	return h.getPosition(c, &userID)
+}
+
+// GetAnyPosition godoc
+// @Summary Get a position of any user
+// @Description Get a position along with the transactions that opened, changed or closed it (requires positions.read_all)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param id path int true "Position ID"
+// @Success 200 {object} dto.APIResponse{data=dto.PositionDetailResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /positions/admin/{id} [get]
+func (h *PositionHandler) GetAnyPosition(c *fiber.Ctx) error {
+	return h.getPosition(c, nil)
+}
+
+// getPosition responds with the position named by the id parameter, restricted to a user when userID is set
+func (h *PositionHandler) getPosition(c *fiber.Ctx, userID *uint) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid position ID")
+	}
+
+	position, transactions, err := h.positionService.GetPosition(c.Context(), uint(id), userID)
+	if err != nil {
+		return serviceError("get position", err)
+	}
+
+	txResponses := make([]dto.TransactionResponse, len(transactions))
+	for i, tx := range transactions {
+		txResponses[i] = toTransactionResponse(tx)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data: dto.PositionDetailResponse{
+			Position:     toPositionResponse(position),
+			Transactions: txResponses,
+		},
+	})
+}
+
+// parsePositionListRequest reads the query parameters of a position list into a filter
+func parsePositionListRequest(c *fiber.Ctx) (*dto.PositionListRequest, models.PositionFilter, error) {
+	var req dto.PositionListRequest
+	if err := bindQuery(c, &req); err != nil {
+		return nil, models.PositionFilter{}, err
+	}
+
+	filter := models.PositionFilter{
+		SortBy:    models.SortByCreatedAt,
+		Ascending: req.Order == "asc",
+	}
+
+	if req.SortBy != "" {
+		filter.SortBy = models.PositionSort(req.SortBy)
+	}
+
+	if req.Type != "" {
+		return nil, filter, fiber.NewError(fiber.StatusBadRequest, "Positions cannot be filtered by type")
+	}
+
+	for _, value := range queryList(req.Status) {
+		status := models.PositionStatus(value)
+		if !status.IsValid() {
+			return nil, filter, fiber.NewError(fiber.StatusBadRequest, "Invalid status "+value+", expected active, closed or liquidated")
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	var err error
+	if filter.From, filter.To, err = dateWindow(req.FilterRequest); err != nil {
+		return nil, filter, err
+	}
+
+	if !isDecimal(req.MinHealthFactor) || !isDecimal(req.MaxHealthFactor) {
+		return nil, filter, fiber.NewError(fiber.StatusBadRequest, "Invalid health factor bound, expected a number")
+	}
+	filter.MinHealthFactor = req.MinHealthFactor
+	filter.MaxHealthFactor = req.MaxHealthFactor
+
+	return &req, filter, nil
+}
+
+// isDecimal reports whether an optional value is empty or a decimal number
+func isDecimal(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, ok := new(big.Float).SetString(value)
+	return ok
+}
+
+// paginationBounds applies the defaults of a validated pagination request
+func paginationBounds(req dto.PaginationRequest) (page, pageSize, offset int) {
+	page, pageSize = req.Page, req.PageSize
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	return page, pageSize, (page - 1) * pageSize
+}
+
+func toPositionResponse(position *models.Position) dto.PositionResponse {
+	return dto.PositionResponse{
+		ID:               position.ID,
+		UserID:           position.UserID,
+		MarketID:         position.MarketID,
+		CollateralAmount: position.CollateralAmount,
+		CollateralToken:  position.CollateralToken,
+		BorrowedAmount:   position.BorrowedAmount,
+		BorrowedToken:    position.BorrowedToken,
+		InterestRate:     position.InterestRate,
+		Status:           dto.PositionStatus(position.Status),
+		HealthFactor:     position.HealthFactor,
+		CreatedAt:        position.CreatedAt,
+		UpdatedAt:        position.UpdatedAt,
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/oracle"
+)
+
+// usdValue formats the USD value of a token amount with cents precision.
+// Pricing is best effort: an empty string is returned when no price is available
+// so that responses still carry the token amounts
+func usdValue(ctx context.Context, priceService service.PriceService, token common.Address, amount *big.Int) string {
+	value, err := priceService.ValueInUSD(ctx, token, amount)
+	if err != nil {
+		return ""
+	}
+	return oracle.FormatDecimal(value, 2)
+}
+
+// usdPrice formats the USD price of a token, or returns an empty string when no price is available
+func usdPrice(ctx context.Context, priceService service.PriceService, token common.Address) string {
+	price, err := priceService.GetTokenPrice(ctx, token)
+	if err != nil {
+		return ""
+	}
+	return oracle.FormatDecimal(price.Price, 8)
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// PrivacyHandler manages personal data export and account deletion endpoints
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(privacyService service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+	}
+}
+
+// CreateDataExport godoc
+// @Summary Request data export
+// @Description Queue an export of the profile, sessions, API keys, transactions, positions and audit entries of the authenticated user. The archive is assembled in the background
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateDataExportRequest false "Archive format"
+// @Success 202 {object} dto.APIResponse{data=dto.DataExportResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/exports [post]
+func (h *PrivacyHandler) CreateDataExport(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req dto.CreateDataExportRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	format := models.ExportFormatJSON
+	if req.Format != "" {
+		format = models.DataExportFormat(req.Format)
+	}
+
+	export, err := h.privacyService.RequestExport(c.Context(), userID, format)
+	if err != nil {
+		return serviceError("request data export", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Data export requested, download it once its status is ready",
+		Data:    toDataExportResponse(export),
+	})
+}
+
+// ListDataExports godoc
+// @Summary List data exports
+// @Description List the personal data exports of the authenticated user, newest first
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=dto.DataExportListResponse}
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/exports [get]
+func (h *PrivacyHandler) ListDataExports(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	exports, err := h.privacyService.ListExports(c.Context(), userID)
+	if err != nil {
+		return serviceError("list data exports", err)
+	}
+
+	exportResponses := make([]dto.DataExportResponse, len(exports))
+	for i, export := range exports {
+		exportResponses[i] = toDataExportResponse(export)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data:    dto.DataExportListResponse{Exports: exportResponses},
+	})
+}
+
+// GetDataExport godoc
+// @Summary Get data export
+// @Description Get the status of a personal data export of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Export ID"
+// @Success 200 {object} dto.APIResponse{data=dto.DataExportResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/exports/{id} [get]
+func (h *PrivacyHandler) GetDataExport(c *fiber.Ctx) error {
+	export, err := h.findDataExport(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data:    toDataExportResponse(export),
+	})
+}
+
+// DownloadDataExport godoc
+// @Summary Download data export
+// @Description Download the archive of a ready personal data export of the authenticated user
+// @Tags users
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path int true "Export ID"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/exports/{id}/download [get]
+func (h *PrivacyHandler) DownloadDataExport(c *fiber.Ctx) error {
+	export, err := h.findDataExport(c)
+	if err != nil {
+		return err
+	}
+
+	if export.Status != models.ExportReady {
+		return fiber.NewError(fiber.StatusConflict, service.ErrExportNotReady.Error())
+	}
+
+	contentType := fiber.MIMEApplicationJSON
+	if export.Format == models.ExportFormatZIP {
+		contentType = "application/zip"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="personal-data-%d.%s"`, export.ID, export.Format))
+	return c.Send(export.Archive)
+}
+
+// findDataExport returns the data export of the authenticated user named by the id parameter
+func (h *PrivacyHandler) findDataExport(c *fiber.Ctx) (*models.DataExport, error) {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid export ID")
+	}
+
+	export, err := h.privacyService.GetExport(c.Context(), userID, uint(id))
+	if err != nil {
+		return nil, serviceError("get data export", err)
+	}
+
+	return export, nil
+}
+
+// CreateDeletionRequest godoc
+// @Summary Request account purge
+// @Description Ask for the account of the authenticated user to be purged. Once an admin approves, every token is revoked and off-chain records are anonymised
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateDeletionRequest false "Reason"
+// @Success 201 {object} dto.APIResponse{data=dto.DeletionRequestResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/deletion-request [post]
+func (h *PrivacyHandler) CreateDeletionRequest(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req dto.CreateDeletionRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	request, err := h.privacyService.RequestDeletion(c.Context(), userID, req.Reason)
+	if err != nil {
+		return serviceError("request account deletion", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Deletion requested, the account will be purged once an admin approves",
+		Data:    toDeletionRequestResponse(request),
+	})
+}
+
+// GetDeletionRequest godoc
+// @Summary Get account purge request
+// @Description Get the most recent deletion request of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=dto.DeletionRequestResponse}
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/deletion-request [get]
+func (h *PrivacyHandler) GetDeletionRequest(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	request, err := h.privacyService.GetDeletionRequest(c.Context(), userID)
+	if err != nil {
+		return serviceError("get deletion request", err)
+	}
+
+	if request == nil {
+		return fiber.NewError(fiber.StatusNotFound, "Deletion request not found")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data:    toDeletionRequestResponse(request),
+	})
+}
+
+// ListDeletionRequests godoc
+// @Summary List account purge requests
+// @Description Get a page of deletion requests, oldest first (requires users.purge)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param status query string false "Comma-separated statuses: pending, approved, rejected"
+// @Param startDate query string false "First submission day, YYYY-MM-DD"
+// @Param endDate query string false "Last submission day, YYYY-MM-DD"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} dto.DeletionRequestListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/admin/deletion-requests [get]
+func (h *PrivacyHandler) ListDeletionRequests(c *fiber.Ctx) error {
+	// Get pagination parameters
+	page, pageSize, offset, err := parsePagination(c)
+	if err != nil {
+		return err
+	}
+
+	// Get filter parameters
+	filterReq, err := parseFilterRequest(c)
+	if err != nil {
+		return err
+	}
+
+	filter, err := deletionRequestFilter(filterReq)
+	if err != nil {
+		return err
+	}
+
+	requests, err := h.privacyService.ListDeletionRequests(c.Context(), filter, offset, pageSize)
+	if err != nil {
+		return serviceError("list deletion requests", err)
+	}
+
+	total, err := h.privacyService.CountDeletionRequests(c.Context(), filter)
+	if err != nil {
+		return serviceError("count deletion requests", err)
+	}
+
+	requestResponses := make([]dto.DeletionRequestResponse, len(requests))
+	for i, request := range requests {
+		requestResponses[i] = toDeletionRequestResponse(request)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.DeletionRequestListResponse{
+		Requests:  requestResponses,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: (int(total) + pageSize - 1) / pageSize,
+	})
+}
+
+// ApproveDeletionRequest godoc
+// @Summary Approve account purge
+// @Description Approve a pending deletion request: every token of the user is revoked and its off-chain records are anonymised. Transaction hashes and amounts are kept for accounting (requires users.purge)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param id path int true "Deletion request ID"
+// @Param request body dto.ReviewDeletionRequest false "Review note"
+// @Success 200 {object} dto.APIResponse{data=dto.DeletionRequestResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/admin/deletion-requests/{id}/approve [post]
+func (h *PrivacyHandler) ApproveDeletionRequest(c *fiber.Ctx) error {
+	return h.reviewDeletionRequest(c, h.privacyService.ApproveDeletion, "Account purged successfully")
+}
+
+// RejectDeletionRequest godoc
+// @Summary Reject account purge
+// @Description Reject a pending deletion request (requires users.purge)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param id path int true "Deletion request ID"
+// @Param request body dto.ReviewDeletionRequest false "Review note"
+// @Success 200 {object} dto.APIResponse{data=dto.DeletionRequestResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/admin/deletion-requests/{id}/reject [post]
+func (h *PrivacyHandler) RejectDeletionRequest(c *fiber.Ctx) error {
+	return h.reviewDeletionRequest(c, h.privacyService.RejectDeletion, "Deletion request rejected")
+}
+
+// reviewDeletionRequest applies the decision of the authenticated admin to the deletion request named by the id parameter
+func (h *PrivacyHandler) reviewDeletionRequest(c *fiber.Ctx, review func(ctx context.Context, reviewerID, requestID uint, note string) (*models.DeletionRequest, error), message string) error {
+	// Get user ID from context (set by authentication middleware)
+	reviewerID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid deletion request ID")
+	}
+
+	var req dto.ReviewDeletionRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	request, err := review(c.Context(), reviewerID, uint(id), req.Note)
+	if err != nil {
+		return serviceError("review deletion request", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    toDeletionRequestResponse(request),
+	})
+}
+
+func toDataExportResponse(export *models.DataExport) dto.DataExportResponse {
+	return dto.DataExportResponse{
+		ID:          export.ID,
+		Format:      string(export.Format),
+		Status:      string(export.Status),
+		Size:        export.Size,
+		Error:       export.Error,
+		ExpiresAt:   export.ExpiresAt,
+		CompletedAt: export.CompletedAt,
+		CreatedAt:   export.CreatedAt,
+	}
+}
+
+func toDeletionRequestResponse(request *models.DeletionRequest) dto.DeletionRequestResponse {
+	return dto.DeletionRequestResponse{
+		ID:         request.ID,
+		UserID:     request.UserID,
+		Reason:     request.Reason,
+		Status:     string(request.Status),
+		ReviewedBy: request.ReviewedBy,
+		ReviewNote: request.ReviewNote,
+		ReviewedAt: request.ReviewedAt,
+		CreatedAt:  request.CreatedAt,
+	}
+}
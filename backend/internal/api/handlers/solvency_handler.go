@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SolvencyHandler manages protocol solvency API endpoints
+type SolvencyHandler struct {
+	solvencyService service.SolvencyService
+	priceService    service.PriceService
+}
+
+// NewSolvencyHandler creates a new solvency handler
+func NewSolvencyHandler(solvencyService service.SolvencyService, priceService service.PriceService) *SolvencyHandler {
+	return &SolvencyHandler{
+		solvencyService: solvencyService,
+		priceService:    priceService,
+	}
+}
+
+// GetSolvencyReport godoc
+// @Summary Get solvency report
+// @Description Compare what the market owes lenders and borrowers with the tokens its contracts hold and are owed, with threshold alerts (requires system.read)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Success 200 {object} dto.SolvencyReportResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /solvency/report [get]
+func (h *SolvencyHandler) GetSolvencyReport(c *fiber.Ctx) error {
+	report, err := h.solvencyService.GetReport(c.Context(), marketIdentifier(c))
+	if err != nil {
+		return serviceError("get solvency report", err)
+	}
+
+	alerts := make([]dto.SolvencyAlertResponse, len(report.Alerts))
+	for i, alert := range report.Alerts {
+		alerts[i] = dto.SolvencyAlertResponse{
+			Level:     string(alert.Level),
+			Metric:    alert.Metric,
+			Ratio:     alert.Ratio.String(),
+			Threshold: alert.Threshold.String(),
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.SolvencyReportResponse{
+		Market:              report.Market,
+		BlockNumber:         report.BlockNumber,
+		LendingLiability:    report.LendingLiability.String(),
+		LenderInterest:      report.LenderInterest.String(),
+		CollateralLiability: report.CollateralLiability.String(),
+		LendingPoolBalance:  report.LendingPoolBalance.String(),
+		BorrowingBalance:    report.BorrowingBalance.String(),
+		CollateralBalance:   report.CollateralBalance.String(),
+		TotalBorrowed:       report.TotalBorrowed.String(),
+		BorrowInterest:      report.BorrowInterest.String(),
+		TotalAssets:         report.TotalAssets.String(),
+		TotalLiabilities:    report.TotalLiabilities.String(),
+		NetReserves:         report.NetReserves.String(),
+		NetReservesUSD:      usdValue(c.Context(), h.priceService, report.Token, report.NetReserves),
+		CoverageRatio:       report.CoverageRatio.String(),
+		LiquidityRatio:      report.LiquidityRatio.String(),
+		Status:              string(report.Status),
+		Alerts:              alerts,
+		GeneratedAt:         report.GeneratedAt,
+	})
+}
+
+// GetSolvencyHistory godoc
+// @Summary Get solvency history
+// @Description Get the solvency snapshots recorded for the market over a time range, defaulting to the last 24 hours (requires system.read)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
+// @Success 200 {object} dto.SolvencyHistoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /solvency/history [get]
+func (h *SolvencyHandler) GetSolvencyHistory(c *fiber.Ctx) error {
+	filterReq, err := parseFilterRequest(c)
+	if err != nil {
+		return err
+	}
+	if filterReq.Type != "" || filterReq.Status != "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Solvency snapshots cannot be filtered by type or status")
+	}
+
+	start, end, err := dateWindow(filterReq)
+	if err != nil {
+		return err
+	}
+
+	// Without dates, the window is the last 24 hours up to now or up to the end date
+	to := time.Now()
+	if end != nil {
+		to = *end
+	}
+	from := to.Add(-24 * time.Hour)
+	if start != nil {
+		from = *start
+	}
+	if !from.Before(to) {
+		return fiber.NewError(fiber.StatusBadRequest, "startDate must not be after endDate")
+	}
+
+	snapshots, err := h.solvencyService.GetHistory(c.Context(), marketIdentifier(c), from, to)
+	if err != nil {
+		return serviceError("get solvency history", err)
+	}
+
+	snapshotResponses := make([]dto.SolvencySnapshotResponse, len(snapshots))
+	for i, snapshot := range snapshots {
+		snapshotResponses[i] = dto.SolvencySnapshotResponse{
+			LendingLiability:    snapshot.LendingLiability,
+			LenderInterest:      snapshot.LenderInterest,
+			CollateralLiability: snapshot.CollateralLiability,
+			LendingPoolBalance:  snapshot.LendingPoolBalance,
+			BorrowingBalance:    snapshot.BorrowingBalance,
+			CollateralBalance:   snapshot.CollateralBalance,
+			TotalBorrowed:       snapshot.TotalBorrowed,
+			BorrowInterest:      snapshot.BorrowInterest,
+			NetReserves:         snapshot.NetReserves,
+			CoverageRatio:       snapshot.CoverageRatio,
+			LiquidityRatio:      snapshot.LiquidityRatio,
+			Status:              string(snapshot.Status),
+			BlockNumber:         snapshot.BlockNumber,
+			Timestamp:           snapshot.CreatedAt,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.SolvencyHistoryResponse{
+		From:      from,
+		To:        to,
+		Snapshots: snapshotResponses,
+	})
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// TransactionHandler manages the transaction ledger endpoints
+type TransactionHandler struct {
+	transactionService service.TransactionService
+	cursors            *cursorCodec
+}
+
+// NewTransactionHandler creates a new transaction handler
+func NewTransactionHandler(transactionService service.TransactionService, cfg *config.Config) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		cursors:            newCursorCodec(cfg.Pagination.CursorSecret),
+	}
+}
+
+// ListTransactions godoc
+// @Summary List my transactions
+// @Description Get a page of the transactions of the authenticated user across the lending pool, borrowing and collateral contracts, most recent first
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param type query string false "Comma-separated types: deposit, withdraw, borrow, repay, collateral_deposit, collateral_withdraw, liquidate"
+// @Param status query string false "Comma-separated statuses: pending, completed, failed"
+// @Param contract query string false "Comma-separated contracts: lending_pool, borrowing, collateral"
+// @Param role query string false "Comma-separated roles: owner, liquidator, borrower"
+// @Param startDate query string false "First day, YYYY-MM-DD"
+// @Param endDate query string false "Last day, YYYY-MM-DD"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param cursor query string false "Cursor from a next or prev link, replacing the page number"
+// @Success 200 {object} dto.TransactionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /transactions [get]
+func (h *TransactionHandler) ListTransactions(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	req, filter, err := parseTransactionListRequest(c)
+	if err != nil {
+		return err
+	}
+	filter.UserID = &userID
+
+	cursor, err := h.cursors.decode(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	page, pageSize, offset := paginationBounds(req.PaginationRequest)
+	response := dto.TransactionListResponse{PageSize: pageSize}
+
+	var transactions []*models.Transaction
+	var hasPrev, hasNext bool
+	if cursor != nil {
+		// Cursor pages read one extra row to find out whether the list goes on, and are not counted
+		transactions, err = h.transactionService.ListTransactionsByCursor(c.Context(), filter, cursor, pageSize+1)
+		if err != nil {
+			return serviceError("list transactions", err)
+		}
+		transactions, hasPrev, hasNext = trimCursorPage(transactions, cursor, pageSize)
+	} else {
+		transactions, err = h.transactionService.ListTransactions(c.Context(), filter, offset, pageSize)
+		if err != nil {
+			return serviceError("list transactions", err)
+		}
+
+		total, err := h.transactionService.CountTransactions(c.Context(), filter)
+		if err != nil {
+			return serviceError("count transactions", err)
+		}
+
+		response.Total = total
+		response.Page = page
+		response.TotalPage = (int(total) + pageSize - 1) / pageSize
+		hasPrev, hasNext = page > 1, offset+len(transactions) < int(total)
+	}
+
+	response.Transactions = make([]dto.TransactionResponse, len(transactions))
+	for i, tx := range transactions {
+		response.Transactions[i] = toTransactionResponse(tx)
+	}
+
+	if len(transactions) > 0 {
+		first, last := transactions[0], transactions[len(transactions)-1]
+		response.Prev, response.Next = h.cursors.pageLinks(c,
+			models.Cursor{CreatedAt: first.CreatedAt, ID: first.ID},
+			models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+			hasPrev, hasNext)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetTransaction godoc
+// @Summary Get a transaction by hash
+// @Description Get the ledger entries the authenticated user has for an on-chain transaction, one per role
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param hash path string true "Transaction hash"
+// @Success 200 {object} dto.APIResponse{data=[]dto.TransactionResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /transactions/{hash} [get]
+func (h *TransactionHandler) GetTransaction(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	hash := c.Params("hash")
+	if decoded, err := hexutil.Decode(hash); err != nil || len(decoded) != 32 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid transaction hash")
+	}
+
+	transactions, err := h.transactionService.GetTransaction(c.Context(), hash, &userID)
+	if err != nil {
+		return serviceError("get transaction", err)
+	}
+
+	txResponses := make([]dto.TransactionResponse, len(transactions))
+	for i, tx := range transactions {
+		txResponses[i] = toTransactionResponse(tx)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data:    txResponses,
+	})
+}
+
+// parseTransactionListRequest reads the query parameters of a transaction list into a filter
+func parseTransactionListRequest(c *fiber.Ctx) (*dto.TransactionListRequest, models.TransactionFilter, error) {
+	var req dto.TransactionListRequest
+	if err := bindQuery(c, &req); err != nil {
+		return nil, models.TransactionFilter{}, err
+	}
+
+	filter, err := transactionFilter(req.FilterRequest)
+	if err != nil {
+		return nil, filter, err
+	}
+
+	// A contract filter narrows the types to those sent to the contract
+	if req.Contract != "" {
+		var contractTypes []models.TransactionType
+		for _, value := range queryList(req.Contract) {
+			contract := models.TransactionContract(value)
+			if !contract.IsValid() {
+				return nil, filter, fiber.NewError(fiber.StatusBadRequest, "Invalid contract "+value+", expected lending_pool, borrowing or collateral")
+			}
+			contractTypes = append(contractTypes, contract.Types()...)
+		}
+
+		if !filter.RestrictTypes(contractTypes) {
+			return nil, filter, fiber.NewError(fiber.StatusBadRequest, "None of the requested types are sent to the requested contracts")
+		}
+	}
+
+	for _, value := range queryList(req.Role) {
+		role := models.TransactionRole(value)
+		if !role.IsValid() {
+			return nil, filter, fiber.NewError(fiber.StatusBadRequest, "Invalid role "+value+", expected owner, liquidator or borrower")
+		}
+		filter.Roles = append(filter.Roles, role)
+	}
+
+	return &req, filter, nil
+}
+
+func toTransactionResponse(tx *models.Transaction) dto.TransactionResponse {
+	return dto.TransactionResponse{
+		ID:           tx.ID,
+		UserID:       tx.UserID,
+		MarketID:     tx.MarketID,
+		Type:         dto.TransactionType(tx.Type),
+		Contract:     string(tx.Type.Contract()),
+		Role:         string(tx.Role),
+		Status:       dto.TransactionStatus(tx.Status),
+		Hash:         tx.Hash,
+		Amount:       tx.Amount,
+		TokenAddress: tx.TokenAddress,
+		BlockNumber:  tx.BlockNumber,
+		GasUsed:      tx.GasUsed,
+		GasPrice:     tx.GasPrice,
+		CreatedAt:    tx.CreatedAt,
+		UpdatedAt:    tx.UpdatedAt,
+	}
+}
@@ -6,10 +6,14 @@
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 
 package handlers
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
@@ -17,6 +21,7 @@ import (
 
 	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
 	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
@@ -25,6 +30,7 @@ type UserHandler struct {
 	userService service.UserService
 	authService service.AuthService
 	config      *config.Config
+	cursors     *cursorCodec
 }
 
 // NewUserHandler creates a new user handler
@@ -33,6 +39,7 @@ func NewUserHandler(userService service.UserService, authService service.AuthSer
 		userService: userService,
 		authService: authService,
 		config:      cfg,
+		cursors:     newCursorCodec(cfg.Pagination.CursorSecret),
 	}
 }
 
@@ -49,19 +56,14 @@ func NewUserHandler(userService service.UserService, authService service.AuthSer
 // @Router /users/register [post]
 func (h *UserHandler) Register(c *fiber.Ctx) error {
 	var req dto.UserRegistrationRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
-	}
-
-	// Validate the request
-	if req.Address == "" || req.Username == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "Address and username are required")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
 
 	// Create the user
 	user, err := h.userService.Register(c.Context(), req.Address, req.Username)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to register user: "+err.Error())
+		return serviceError("register user", err)
 	}
 
 	// Convert to response DTO
@@ -87,8 +89,8 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 }
 
 // Authenticate godoc
-// @Summary Authenticate with signature
-// @Description Authenticate user with Ethereum signature
+// @Summary Authenticate with Sign-In with Ethereum
+// @Description Authenticate user with a signed EIP-4361 message; its nonce must come from /users/nonce/{address} and can only be used once
 // @Tags users
 // @Accept json
 // @Produce json
@@ -96,43 +98,35 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 // @Success 200 {object} dto.AuthResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /users/auth [post]
 func (h *UserHandler) Authenticate(c *fiber.Ctx) error {
 	var req dto.UserAuthRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
 
-	// Validate the request
-	if req.Address == "" || req.Signature == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "Address and signature are required")
-	}
-
-	// Verify the signature
-	isValid, err := h.userService.VerifySignature(c.Context(), req.Address, req.Message, req.Signature)
+	// Verify the message, its signature and its nonce
+	address, err := h.authService.VerifySignIn(c.Context(), req.Message, req.Signature)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to verify signature: "+err.Error())
-	}
-
-	if !isValid {
-		return fiber.NewError(fiber.StatusUnauthorized, "Invalid signature")
+		return serviceError("verify sign-in", err)
 	}
 
 	// Get the user
-	user, err := h.userService.GetByAddress(c.Context(), req.Address)
+	user, err := h.userService.GetByAddress(c.Context(), address)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("get user", err)
 	}
 
 	if user == nil {
 		return fiber.NewError(fiber.StatusNotFound, "User not found")
 	}
 
-	// Generate token using auth service
-	token, expiresAt, err := h.userService.GenerateAuthToken(c.Context(), user)
+	// Start a session for this device
+	tokens, err := h.authService.CreateSession(c.Context(), user, c.Get(fiber.HeaderUserAgent), c.IP())
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to generate token: "+err.Error())
+		return serviceError("generate token", err)
 	}
 
 	// Convert to response DTO
@@ -151,9 +145,178 @@ func (h *UserHandler) Authenticate(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dto.AuthResponse{
-		User:      userResponse,
-		Token:     token,
-		ExpiresAt: expiresAt,
+		User:             userResponse,
+		SessionID:        tokens.SessionID,
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	})
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new token pair. Refresh tokens are single-use: presenting one that was already exchanged revokes its session
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Refresh token"
+// @Success 200 {object} dto.TokenResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/refresh [post]
+func (h *UserHandler) Refresh(c *fiber.Ctx) error {
+	var req dto.RefreshRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	tokens, err := h.authService.RefreshSession(c.Context(), req.RefreshToken, c.IP())
+	if err != nil {
+		return serviceError("refresh token", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.TokenResponse{
+		SessionID:        tokens.SessionID,
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	})
+}
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description List the active sessions of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=dto.SessionListResponse}
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/sessions [get]
+func (h *UserHandler) ListSessions(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	sessions, err := h.authService.ListSessions(c.Context(), userID)
+	if err != nil {
+		return serviceError("list sessions", err)
+	}
+
+	currentSessionID, _ := c.Locals("sessionID").(string)
+
+	sessionResponses := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		sessionResponses[i] = dto.SessionResponse{
+			ID:        session.ID,
+			Device:    session.Device,
+			IP:        session.IP,
+			Current:   session.ID == currentSessionID,
+			CreatedAt: session.CreatedAt,
+			LastSeen:  session.LastSeen,
+			ExpiresAt: session.ExpiresAt,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data:    dto.SessionListResponse{Sessions: sessionResponses},
+	})
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Revoke one session of the authenticated user; its tokens stop working immediately
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/sessions/{id} [delete]
+func (h *UserHandler) RevokeSession(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	err := h.authService.RevokeSession(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return serviceError("revoke session", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Session revoked successfully",
+	})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the session the request was made from
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/logout [post]
+func (h *UserHandler) Logout(c *fiber.Ctx) error {
+	// Get user and session IDs from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+	sessionID, _ := c.Locals("sessionID").(string)
+
+	// The session may have expired since the token was validated, which is fine
+	err := h.authService.RevokeSession(c.Context(), userID, sessionID)
+	if err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+		return serviceError("log out", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Logged out successfully",
+	})
+}
+
+// LogoutAll godoc
+// @Summary Log out everywhere
+// @Description Revoke every session of the authenticated user, including the current one
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/logout-all [post]
+func (h *UserHandler) LogoutAll(c *fiber.Ctx) error {
+	// Get user ID from context (set by authentication middleware)
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	if err := h.authService.RevokeAllSessions(c.Context(), userID); err != nil {
+		return serviceError("log out", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "Logged out of every session successfully",
 	})
 }
 
@@ -179,7 +342,7 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 	// Get the user
 	user, err := h.userService.GetByID(c.Context(), userID)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("get user", err)
 	}
 
 	if user == nil {
@@ -229,14 +392,14 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	}
 
 	var req dto.UserUpdateRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	if err := bindBody(c, &req); err != nil {
+		return err
 	}
 
 	// Get the current user
 	user, err := h.userService.GetByID(c.Context(), userID)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("get user", err)
 	}
 
 	if user == nil {
@@ -252,7 +415,7 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 
 	// Save the updated user
 	if err := h.userService.Update(c.Context(), user); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update profile: "+err.Error())
+		return serviceError("update profile", err)
 	}
 
 	// Convert to response DTO
@@ -279,7 +442,7 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 
 // GetUserByID godoc
 // @Summary Get user by ID
-// @Description Retrieves a user by ID (admin only)
+// @Description Retrieves a user by ID (requires users.read)
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -303,7 +466,7 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
 	// Get the user
 	user, err := h.userService.GetByID(c.Context(), uint(id))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("get user", err)
 	}
 
 	if user == nil {
@@ -333,7 +496,7 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
 
 // GetUserByAddress godoc
 // @Summary Get user by address
-// @Description Retrieves a user by Ethereum address (admin only)
+// @Description Retrieves a user by Ethereum address (requires users.read)
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -348,15 +511,15 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
 // @Router /users/admin/address/{address} [get]
 func (h *UserHandler) GetUserByAddress(c *fiber.Ctx) error {
 	// Get address from URL
-	address := c.Params("address")
-	if address == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "Address is required")
+	var params dto.AddressParams
+	if err := bindParams(c, &params); err != nil {
+		return err
 	}
 
 	// Get the user
-	user, err := h.userService.GetByAddress(c.Context(), address)
+	user, err := h.userService.GetByAddress(c.Context(), params.Address)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("get user", err)
 	}
 
 	if user == nil {
@@ -386,38 +549,74 @@ func (h *UserHandler) GetUserByAddress(c *fiber.Ctx) error {
 
 // ListUsers godoc
 // @Summary List all users
-// @Description Lists all users with pagination (admin only)
+// @Description Lists all users with filtering and pagination (requires users.read)
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param type query string false "Comma-separated roles"
+// @Param status query string false "verified or unverified"
+// @Param startDate query string false "First registration day, YYYY-MM-DD"
+// @Param endDate query string false "Last registration day, YYYY-MM-DD"
 // @Param page query int false "Page number (default: 1)"
 // @Param pageSize query int false "Page size (default: 10, max: 100)"
+// @Param cursor query string false "Cursor from a next or prev link, replacing the page number"
 // @Success 200 {object} dto.UserListResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /users/admin [get]
 func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
 	// Get pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("pageSize", "10"))
-
-	if page < 1 {
-		page = 1
+	page, pageSize, offset, err := parsePagination(c)
+	if err != nil {
+		return err
 	}
 
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	// Get filter parameters
+	filterReq, err := parseFilterRequest(c)
+	if err != nil {
+		return err
 	}
 
-	// Calculate offset
-	offset := (page - 1) * pageSize
+	filter, err := userFilter(filterReq)
+	if err != nil {
+		return err
+	}
 
-	// Get users
-	users, err := h.userService.ListUsers(c.Context(), offset, pageSize)
+	cursor, err := h.cursors.decode(c.Query("cursor"))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list users: "+err.Error())
+		return err
+	}
+
+	response := dto.UserListResponse{PageSize: pageSize}
+
+	// Get users, reading one extra row past a cursor to find out whether the list goes on
+	var users []*models.User
+	var hasPrev, hasNext bool
+	if cursor != nil {
+		users, err = h.userService.ListUsersByCursor(c.Context(), filter, cursor, pageSize+1)
+		if err != nil {
+			return serviceError("list users", err)
+		}
+		users, hasPrev, hasNext = trimCursorPage(users, cursor, pageSize)
+	} else {
+		users, err = h.userService.ListUsers(c.Context(), filter, offset, pageSize)
+		if err != nil {
+			return serviceError("list users", err)
+		}
+
+		// Get total count, which cursor pages skip
+		total, err := h.userService.CountWithFilter(c.Context(), filter)
+		if err != nil {
+			return serviceError("count users", err)
+		}
+
+		response.Total = total
+		response.Page = page
+		response.TotalPage = (int(total) + pageSize - 1) / pageSize
+		hasPrev, hasNext = page > 1, offset+len(users) < int(total)
 	}
 
 	// Convert to response DTOs
@@ -439,28 +638,22 @@ func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
 
 		userResponses[i] = userResponse
 	}
+	response.Users = userResponses
 
-	// Get total count
-	total, err := h.userService.Count(c.Context())
-	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to count users: "+err.Error())
+	if len(users) > 0 {
+		first, last := users[0], users[len(users)-1]
+		response.Prev, response.Next = h.cursors.pageLinks(c,
+			models.Cursor{CreatedAt: first.CreatedAt, ID: first.ID},
+			models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+			hasPrev, hasNext)
 	}
 
-	// Calculate total pages
-	totalPages := (int(total) + pageSize - 1) / pageSize
-
-	return c.Status(fiber.StatusOK).JSON(dto.UserListResponse{
-		Users:     userResponses,
-		Total:     total,
-		Page:      page,
-		PageSize:  pageSize,
-		TotalPage: totalPages,
-	})
+	return c.Status(fiber.StatusOK).JSON(response)
 }
 
 // VerifyUser godoc
 // @Summary Verify user
-// @Description Marks a user as verified (admin only)
+// @Description Marks a user as verified (requires users.verify)
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -481,76 +674,147 @@ func (h *UserHandler) VerifyUser(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
 	}
 
-	// Get the user
-	user, err := h.userService.GetByID(c.Context(), uint(id))
+	// Update verification status
+	user, err := h.userService.Verify(c.Context(), uint(id))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("verify user", err)
 	}
 
 	if user == nil {
 		return fiber.NewError(fiber.StatusNotFound, "User not found")
 	}
 
-	// Update verification status
-	user.Verified = true
-	user.UpdatedAt = time.Now()
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Message: "User verified successfully",
+	})
+}
 
-	if err := h.userService.Update(c.Context(), user); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to verify user: "+err.Error())
+// AssignRole godoc
+// @Summary Assign role
+// @Description Change the role of a user, which takes effect on its next request (requires users.assign_roles)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body dto.AssignRoleRequest true "Role to assign"
+// @Success 200 {object} dto.APIResponse{data=dto.UserResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/admin/{id}/role [put]
+func (h *UserHandler) AssignRole(c *fiber.Ctx) error {
+	// Get ID from URL
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req dto.AssignRoleRequest
+	if err := bindBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.userService.AssignRole(c.Context(), uint(id), models.UserRole(req.Role))
+	if err != nil {
+		return serviceError("assign role", err)
+	}
+
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	response := dto.UserResponse{
+		ID:        user.ID,
+		Address:   user.Address,
+		Username:  user.Username,
+		Role:      dto.UserRole(user.Role),
+		Verified:  user.Verified,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+
+	if user.LastLogin != nil {
+		response.LastLogin = *user.LastLogin
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
 		Success: true,
-		Message: "User verified successfully",
+		Message: "Role assigned successfully",
+		Data:    response,
+	})
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the built-in roles and the permissions they grant (requires users.read)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.RoleResponse}
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /users/admin/roles [get]
+func (h *UserHandler) ListRoles(c *fiber.Ctx) error {
+	roles := []models.UserRole{
+		models.RoleUser,
+		models.RoleAdmin,
+		models.RoleOperator,
+		models.RoleAuditor,
+		models.RoleLiquidator,
+	}
+
+	roleResponses := make([]dto.RoleResponse, len(roles))
+	for i, role := range roles {
+		permissions := make([]string, len(role.Permissions()))
+		for j, permission := range role.Permissions() {
+			permissions[j] = string(permission)
+		}
+		roleResponses[i] = dto.RoleResponse{
+			Role:        dto.UserRole(role),
+			Permissions: permissions,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
+		Success: true,
+		Data:    roleResponses,
 	})
 }
 
 // NonceMessage godoc
-// @Summary Get nonce message
-// @Description Gets a nonce message for the user to sign for authentication
+// @Summary Get sign-in nonce
+// @Description Issues a single-use nonce and the Sign-In with Ethereum (EIP-4361) message to sign with it
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param address path string true "Ethereum address"
-// @Success 200 {object} object{message=string,nonce=string}
+// @Success 200 {object} dto.NonceResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /users/nonce/{address} [get]
 func (h *UserHandler) NonceMessage(c *fiber.Ctx) error {
 	// Get address from URL
-	address := c.Params("address")
-	if address == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "Address is required")
+	var params dto.AddressParams
+	if err := bindParams(c, &params); err != nil {
+		return err
 	}
 
-	// Get or create the user
-	user, err := h.userService.GetByAddress(c.Context(), address)
+	// Nonces are issued whether or not the address is registered, so the response does not reveal which addresses have accounts
+	challenge, err := h.authService.CreateSignInChallenge(c.Context(), params.Address)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("create nonce", err)
 	}
 
-	var nonce string
-	if user == nil {
-		// User doesn't exist yet, create a temporary nonce
-		nonce = h.userService.GenerateNonce(address)
-	} else {
-		nonce = user.Nonce
-		if nonce == "" {
-			// Generate a new nonce if none exists
-			nonce = h.userService.GenerateNonce(address)
-			user.Nonce = nonce
-			if err := h.userService.Update(c.Context(), user); err != nil {
-				return fiber.NewError(fiber.StatusInternalServerError, "Failed to update user nonce: "+err.Error())
-			}
-		}
-	}
-
-	// Create the message to be signed
-	message := "Sign this message to authenticate with our platform. Nonce: " + nonce
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": message,
-		"nonce":   nonce,
+	return c.Status(fiber.StatusOK).JSON(dto.NonceResponse{
+		Nonce:     challenge.Nonce,
+		Message:   challenge.Message,
+		ExpiresAt: challenge.ExpiresAt,
 	})
 }
 
@@ -577,7 +841,7 @@ func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
 	// Get the user
 	user, err := h.userService.GetByID(c.Context(), userID)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("get user", err)
 	}
 
 	if user == nil {
@@ -586,7 +850,7 @@ func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
 
 	// Delete the user (soft delete)
 	if err := h.userService.Delete(c.Context(), userID); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete account: "+err.Error())
+		return serviceError("delete account", err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
@@ -597,7 +861,7 @@ func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
 
 // DeleteUser godoc
 // @Summary Delete any user
-// @Description Mark any user account as deleted (soft delete) - (Admin only)
+// @Description Mark any user account as deleted (soft delete) (requires users.delete)
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -619,7 +883,7 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	// Check if user exists
 	user, err := h.userService.GetByID(c.Context(), uint(id))
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user: "+err.Error())
+		return serviceError("get user", err)
 	}
 
 	if user == nil {
@@ -628,7 +892,7 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 
 	// Delete the user (soft delete)
 	if err := h.userService.Delete(c.Context(), uint(id)); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete user: "+err.Error())
+		return serviceError("delete user", err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{
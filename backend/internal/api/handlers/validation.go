@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultPageSize is the page size of lists when none is requested
+	defaultPageSize = 10
+	// maxPageSize is the largest page size a list accepts
+	maxPageSize = 100
+)
+
+// maxUint256 is the largest amount a contract can hold
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// validate checks requests against their validate tags. Besides the built-in tags it knows:
+//   - uint256: a decimal amount that fits in a uint256
+//   - positive_amount: a uint256 amount greater than 0
+//   - checksum_addr: a hex address whose checksum, when it is mixed-case, is valid (EIP-55)
+//   - page: a page number, from 1
+//   - page_size: a page size, from 1 to maxPageSize
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by the name clients send them under
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "params"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return ""
+	})
+
+	v.RegisterValidation("uint256", func(fl validator.FieldLevel) bool {
+		_, ok := parseUint256(fl.Field().String())
+		return ok
+	})
+	v.RegisterValidation("positive_amount", func(fl validator.FieldLevel) bool {
+		amount, ok := parseUint256(fl.Field().String())
+		return ok && amount.Sign() > 0
+	})
+	v.RegisterValidation("checksum_addr", func(fl validator.FieldLevel) bool {
+		return isChecksumAddress(fl.Field().String())
+	})
+	v.RegisterValidation("page", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int() >= 1
+	})
+	v.RegisterValidation("page_size", func(fl validator.FieldLevel) bool {
+		size := fl.Field().Int()
+		return size >= 1 && size <= maxPageSize
+	})
+
+	return v
+}
+
+// bindBody parses the JSON body of a request into req and validates it. An empty body is left to the
+// validate tags, so that requests with only optional fields may omit it
+func bindBody(c *fiber.Ctx, req any) error {
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	return validate.Struct(req)
+}
+
+// bindQuery parses the query parameters of a request into req and validates them
+func bindQuery(c *fiber.Ctx, req any) error {
+	if err := c.QueryParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	return validate.Struct(req)
+}
+
+// bindParams parses the path parameters of a request into req and validates them
+func bindParams(c *fiber.Ctx, req any) error {
+	if err := c.ParamsParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid path parameters")
+	}
+	return validate.Struct(req)
+}
+
+// parseUint256 parses a decimal amount that fits in a uint256
+func parseUint256(value string) (*big.Int, bool) {
+	// SetString would also accept signs, underscores and base prefixes
+	if value == "" || strings.TrimLeft(value, "0123456789") != "" {
+		return nil, false
+	}
+
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Cmp(maxUint256) > 0 {
+		return nil, false
+	}
+	return amount, true
+}
+
+// mustAmount returns an amount checked by the uint256 or positive_amount tag
+func mustAmount(value string) *big.Int {
+	amount, _ := parseUint256(value)
+	return amount
+}
+
+// isChecksumAddress reports whether a value is a hex address, with a valid checksum if it is mixed-case.
+// All lower or upper case addresses carry no checksum and are accepted as is
+func isChecksumAddress(value string) bool {
+	if !strings.HasPrefix(value, "0x") || !common.IsHexAddress(value) {
+		return false
+	}
+
+	hex := value[2:]
+	if hex == strings.ToLower(hex) || hex == strings.ToUpper(hex) {
+		return true
+	}
+	return common.HexToAddress(value).Hex() == value
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+)
+
+func TestBindParamsAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{name: "checksummed", address: "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"},
+		{name: "lower case", address: "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"},
+		{name: "bad checksum", address: "0xF39Fd6e51aad88F6F4ce6aB8827279cffFb92266", wantErr: true},
+		{name: "too short", address: "0x1234", wantErr: true},
+		{name: "not an address", address: "alice", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bindErr error
+			var params dto.AddressParams
+
+			app := fiber.New()
+			app.Get("/users/nonce/:address", func(c *fiber.Ctx) error {
+				bindErr = bindParams(c, &params)
+				return nil
+			})
+			if _, err := app.Test(httptest.NewRequest("GET", "/users/nonce/"+tt.address, nil)); err != nil {
+				t.Fatal(err)
+			}
+
+			var validationErrors validator.ValidationErrors
+			switch {
+			case tt.wantErr && !errors.As(bindErr, &validationErrors):
+				t.Errorf("got error %v, want a validation error", bindErr)
+			case tt.wantErr && validationErrors[0].Field() != "address":
+				t.Errorf("got error on %s, want it reported on address", validationErrors[0].Field())
+			case !tt.wantErr && (bindErr != nil || params.Address != tt.address):
+				t.Errorf("got %q and error %v, want %q", params.Address, bindErr, tt.address)
+			}
+		})
+	}
+}
+
+func TestAuditLogFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     dto.AuditLogFilterRequest
+		wantErr bool
+	}{
+		{name: "no filter", req: dto.AuditLogFilterRequest{}},
+		{name: "every filter", req: dto.AuditLogFilterRequest{
+			FilterRequest: dto.FilterRequest{StartDate: "2025-01-01", EndDate: "2025-01-31"},
+			ActorID:       3,
+			Action:        "user.verify",
+			TargetType:    "user",
+			TargetID:      "7",
+		}},
+		{name: "type", req: dto.AuditLogFilterRequest{FilterRequest: dto.FilterRequest{Type: "user"}}, wantErr: true},
+		{name: "dates out of order", req: dto.AuditLogFilterRequest{FilterRequest: dto.FilterRequest{StartDate: "2025-02-01", EndDate: "2025-01-01"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := auditLogFilter(tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("got no error, want a bad request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if (filter.ActorID != nil) != (tt.req.ActorID != 0) || (filter.ActorID != nil && *filter.ActorID != tt.req.ActorID) {
+				t.Errorf("got actor %v, want %d", filter.ActorID, tt.req.ActorID)
+			}
+			if string(filter.Action) != tt.req.Action || filter.TargetType != tt.req.TargetType || filter.TargetID != tt.req.TargetID {
+				t.Errorf("got filter %+v, want the fields of %+v", filter, tt.req)
+			}
+			if tt.req.EndDate != "" && filter.To.Format("2006-01-02") != "2025-02-01" {
+				t.Errorf("got end %s, want the day after the end date", filter.To)
+			}
+		})
+	}
+}
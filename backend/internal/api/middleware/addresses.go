@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// LinkedAddresses middleware to resolve the wallet addresses a request covers: the primary address
+// of the user, or with ?aggregate=true every address linked to its account
+func LinkedAddresses(userService service.UserService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		address, ok := c.Locals("address").(string)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+		}
+
+		addresses := []common.Address{common.HexToAddress(address)}
+
+		if c.QueryBool("aggregate") {
+			userID, _ := c.Locals("userID").(uint)
+			linked, err := userService.ListAddresses(c.Context(), userID)
+			if err != nil {
+				return fmt.Errorf("failed to list linked addresses: %w", err)
+			}
+
+			for _, userAddress := range linked {
+				if !userAddress.IsPrimary {
+					addresses = append(addresses, common.HexToAddress(userAddress.Address))
+				}
+			}
+		}
+
+		// Store the addresses in the context
+		c.Locals("addresses", addresses)
+
+		return c.Next()
+	}
+}
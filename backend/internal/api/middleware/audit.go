@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// maxRequestIDLength bounds the request IDs accepted from clients and proxies
+const maxRequestIDLength = 64
+
+// RequestID middleware tags each request with an ID, reusing the X-Request-ID sent by the client when it is short enough
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(fiber.HeaderXRequestID, requestID)
+		c.Locals("requestID", requestID)
+
+		return c.Next()
+	}
+}
+
+// AuditContext middleware stores the actor of the request for the audit log; Authentication fills in the user
+func AuditContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID, _ := c.Locals("requestID").(string)
+		c.Locals(service.AuditActorKey, &models.AuditActor{
+			RequestID: requestID,
+			IP:        c.IP(),
+		})
+
+		return c.Next()
+	}
+}
+
+// Audit middleware to record a request in the audit log once it has been served, whatever its outcome.
+// The target is read from the route parameter targetParam, if any
+func Audit(auditService service.AuditService, action models.AuditAction, targetType, targetParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = ErrorStatus(err)
+		}
+
+		target := service.AuditTarget{Type: targetType}
+		if targetParam != "" {
+			target.ID = c.Params(targetParam)
+		}
+
+		if recordErr := auditService.RecordRequest(c.Context(), action, target, status); recordErr != nil {
+			log.Printf("Failed to record %s audit entry: %v", action, recordErr)
+		}
+
+		return err
+	}
+}
+
+// setAuditActor attributes the audit entries of the request to an authenticated user
+func setAuditActor(c *fiber.Ctx, user *models.User, apiKey *models.APIKey) {
+	actor, ok := c.Locals(service.AuditActorKey).(*models.AuditActor)
+	if !ok {
+		return
+	}
+
+	actor.UserID = &user.ID
+	actor.Address = user.Address
+	actor.Role = user.Role
+	if apiKey != nil {
+		actor.APIKeyID = &apiKey.ID
+	}
+}
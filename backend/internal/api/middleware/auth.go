@@ -16,14 +16,36 @@ import (
 // AuthClaims extends JWT standard claims with user information
 type AuthClaims struct {
 	jwt.RegisteredClaims
-	UserID  uint            `json:"id"`
-	Address string          `json:"address"`
-	Role    models.UserRole `json:"role"`
+	SessionID string          `json:"sid"`
+	UserID    uint            `json:"id"`
+	Address   string          `json:"address"`
+	Role      models.UserRole `json:"role"`
 }
 
-// Authentication middleware to verify JWT tokens
+// APIKeyHeader is the header machine clients send their API key in, instead of a JWT
+const APIKeyHeader = "X-API-Key"
+
+// Authentication middleware to verify JWT tokens or API keys
 func Authentication(cfg *config.Config, authService service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// API keys are accepted as an alternative to JWTs
+		if rawKey := c.Get(APIKeyHeader); rawKey != "" {
+			user, apiKey, err := authService.ValidateAPIKey(c.Context(), rawKey, c.IP())
+			if err != nil {
+				return fiber.NewError(fiber.StatusUnauthorized, "Invalid, expired or revoked API key")
+			}
+
+			// Store user and key information in the context
+			c.Locals("userID", user.ID)
+			c.Locals("address", user.Address)
+			c.Locals("role", user.Role)
+			c.Locals("apiKeyID", apiKey.ID)
+			c.Locals("scopes", apiKey.GetScopes())
+			setAuditActor(c, user, apiKey)
+
+			return c.Next()
+		}
+
 		// Get authorization header
 		authHeader := c.Get("Authorization")
 
@@ -41,7 +63,7 @@ func Authentication(cfg *config.Config, authService service.AuthService) fiber.H
 		tokenString := parts[1]
 
         // Validate token using auth service
-        user, err := authService.ValidateToken(c.Context(), tokenString)
+        user, sessionID, err := authService.ValidateToken(c.Context(), tokenString)
         if err != nil {
             return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
         }
@@ -50,24 +72,75 @@ func Authentication(cfg *config.Config, authService service.AuthService) fiber.H
 		c.Locals("userID", user.ID)
 		c.Locals("address", user.Address)
 		c.Locals("role", user.Role)
+		c.Locals("sessionID", sessionID)
+		setAuditActor(c, user, nil)
 
 		return c.Next()
 	}
 }
 
-// RoleAuthorization middleware to check if user has required role
-func RoleAuthorization(roles ...models.UserRole) fiber.Handler {
+// PermissionAuthorization middleware to check that the role of the user grants a permission
+// Requests made with an API key also need the scope covering the permission
+func PermissionAuthorization(permission models.Permission) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userRole, ok := c.Locals("role").(models.UserRole)
 		if !ok {
 			return fiber.NewError(fiber.StatusUnauthorized, "Role information not found")
 		}
 
-		// Check if user role is in the allowed roles
-		if slices.Contains(roles, userRole) {
+		if !userRole.HasPermission(permission) {
+			return fiber.NewError(fiber.StatusForbidden, "Access denied")
+		}
+
+		if scopes, ok := c.Locals("scopes").([]models.APIKeyScope); ok {
+			scope := models.PermissionScope(permission)
+			if !slices.Contains(scopes, scope) {
+				return fiber.NewError(fiber.StatusForbidden, "API key is missing the "+string(scope)+" scope")
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// ScopeAuthorization middleware to check that requests made with an API key hold one of the scopes
+// Requests authenticated with a JWT act with every permission of their user and are not restricted
+func ScopeAuthorization(scopes ...models.APIKeyScope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		keyScopes, ok := c.Locals("scopes").([]models.APIKeyScope)
+		if !ok {
+			return c.Next()
+		}
+
+		for _, scope := range scopes {
+			if slices.Contains(keyScopes, scope) {
 				return c.Next()
 			}
+		}
+
+		return fiber.NewError(fiber.StatusForbidden, "API key is missing a required scope")
+	}
+}
+
+// SessionOnly middleware to reject requests made with an API key, for account management routes
+func SessionOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("apiKeyID").(uint); ok {
+			return fiber.NewError(fiber.StatusForbidden, "This endpoint cannot be used with an API key")
+		}
 
-		return fiber.NewError(fiber.StatusForbidden, "Access denied")
+		return c.Next()
+	}
+}
+
+// QueryToken middleware to accept the access token in the access_token query parameter, for clients
+// that cannot set headers such as browser WebSocket and EventSource connections
+func QueryToken() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token := c.Query("access_token"); token != "" && c.Get("Authorization") == "" {
+			c.Request().Header.Set("Authorization", "Bearer "+token)
+		}
+
+		return c.Next()
 	}
 }
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// Error codes of failures that do not come from a service
+const (
+	codeValidationFailed = "VALIDATION_FAILED"
+	codeNotFound         = "NOT_FOUND"
+	codeConflict         = "CONFLICT"
+	codeInternalError    = "INTERNAL_ERROR"
+
+	// Requests repeating an idempotency key
+	codeIdempotencyKeyInUse  = "IDEMPOTENCY_KEY_IN_USE"
+	codeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// errorStatuses maps the code of each service error to the HTTP status it is answered with
+var errorStatuses = map[service.ErrorCode]int{
+	// Malformed requests
+	service.CodeInvalidAmount:        fiber.StatusBadRequest,
+	service.CodeInvalidRole:          fiber.StatusBadRequest,
+	service.CodeInvalidLinkChallenge: fiber.StatusBadRequest,
+	service.CodeInvalidExportFormat:  fiber.StatusBadRequest,
+	service.CodeInvalidAPIKeyRequest: fiber.StatusBadRequest,
+	service.CodeBundleStepMismatch:   fiber.StatusBadRequest,
+
+	// Failed authentication
+	service.CodeInvalidSignIn:       fiber.StatusUnauthorized,
+	service.CodeInvalidSignature:    fiber.StatusUnauthorized,
+	service.CodeInvalidRefreshToken: fiber.StatusUnauthorized,
+	service.CodeRefreshTokenReused:  fiber.StatusUnauthorized,
+	service.CodeInvalidAPIKey:       fiber.StatusUnauthorized,
+
+	// Calls the contracts refuse from the platform
+	service.CodeContractUnauthorized: fiber.StatusForbidden,
+
+	// Missing resources
+	service.CodeMarketNotFound:            fiber.StatusNotFound,
+	service.CodePositionNotFound:          fiber.StatusNotFound,
+	service.CodeTransactionNotFound:       fiber.StatusNotFound,
+	service.CodeBundleNotFound:            fiber.StatusNotFound,
+	service.CodeBundleTransactionNotFound: fiber.StatusNotFound,
+	service.CodeSessionNotFound:           fiber.StatusNotFound,
+	service.CodeAPIKeyNotFound:            fiber.StatusNotFound,
+	service.CodeAddressNotLinked:          fiber.StatusNotFound,
+	service.CodeExportNotFound:            fiber.StatusNotFound,
+	service.CodeDeletionRequestNotFound:   fiber.StatusNotFound,
+
+	// Requests at odds with the current state of a resource
+	service.CodeLastAdmin:         fiber.StatusConflict,
+	service.CodeAddressInUse:      fiber.StatusConflict,
+	service.CodePrimaryAddress:    fiber.StatusConflict,
+	service.CodeExportNotReady:    fiber.StatusConflict,
+	service.CodeDeletionPending:   fiber.StatusConflict,
+	service.CodeDeletionReviewed:  fiber.StatusConflict,
+	service.CodeBundleStepNotNext: fiber.StatusConflict,
+
+	// Well-formed requests the position, the wallet or the contracts cannot honour
+	service.CodeInsufficientTokenBalance: fiber.StatusUnprocessableEntity,
+	service.CodeInsufficientAllowance:    fiber.StatusUnprocessableEntity,
+	service.CodeTokenTransferFailed:      fiber.StatusUnprocessableEntity,
+	service.CodeInsufficientDeposit:      fiber.StatusUnprocessableEntity,
+	service.CodeBorrowLimitExceeded:      fiber.StatusUnprocessableEntity,
+	service.CodeRepayExceedsDebt:         fiber.StatusUnprocessableEntity,
+	service.CodeNoDebt:                   fiber.StatusUnprocessableEntity,
+	service.CodeInsufficientCollateral:   fiber.StatusUnprocessableEntity,
+	service.CodeCollateralRatioTooLow:    fiber.StatusUnprocessableEntity,
+	service.CodeNotLiquidatable:          fiber.StatusUnprocessableEntity,
+	service.CodeContractReverted:         fiber.StatusUnprocessableEntity,
+	service.CodeBundleStepWouldFail:      fiber.StatusUnprocessableEntity,
+
+	// Features the platform cannot serve right now
+	service.CodePriceUnavailable:   fiber.StatusServiceUnavailable,
+	service.CodeSigningUnavailable: fiber.StatusNotImplemented,
+}
+
+// ErrorStatus returns the HTTP status an error returned by a handler is answered with
+func ErrorStatus(err error) int {
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		return fiberError.Code
+	}
+
+	var domainErr *service.Error
+	if errors.As(err, &domainErr) {
+		if status, ok := errorStatuses[domainErr.Code]; ok {
+			return status
+		}
+	}
+
+	return fiber.StatusInternalServerError
+}
+
+// statusErrorCode returns the error code of a failure that only has an HTTP status, e.g. TOO_MANY_REQUESTS
+func statusErrorCode(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(utils.StatusMessage(status), " ", "_"))
+}
@@ -3,54 +3,59 @@ package middleware
 import (
 	"errors"
 	"log"
+	"reflect"
+	"strings"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
-// ErrorHandler is a middleware that handles all errors in the application
+// ErrorHandler is a middleware that handles all errors in the application. Every error is answered with a
+// stable code: the code of service errors, VALIDATION_FAILED for invalid requests, or one derived from the status
 func ErrorHandler() fiber.ErrorHandler {
 	return func(c *fiber.Ctx, err error) error {
-		// Default status code
-		statusCode := fiber.StatusInternalServerError
-		errorMsg := "Internal Server Error"
-
-		// Check if the error is a Fiber error
-		var fiberError *fiber.Error
-		if errors.As(err, &fiberError) {
-			statusCode = fiberError.Code
-			errorMsg = fiberError.Message
-		} else {
-			// Handle specific error types
-			switch {
-			case errors.Is(err, gorm.ErrRecordNotFound):
-				statusCode = fiber.StatusNotFound
-				errorMsg = "Resource not found"
-			case errors.Is(err, gorm.ErrDuplicatedKey):
-				statusCode = fiber.StatusConflict
-				errorMsg = "Resource already exists"
-			}
+		// Default status code; the message of unexpected errors stays in the server log
+		statusCode := ErrorStatus(err)
+		response := dto.ErrorResponse{
+			Code:    codeInternalError,
+			Message: "Internal Server Error",
 		}
 
-		// Check for validation errors
+		var fiberError *fiber.Error
+		var domainErr *service.Error
 		var validateErr validator.ValidationErrors
-		if errors.As(err, &validateErr) {
+		switch {
+		case errors.As(err, &validateErr):
 			statusCode = fiber.StatusBadRequest
-			errorMsg = "Validation error"
+			response.Code = codeValidationFailed
+			response.Message = "Validation error"
 
-			// Create a more user-friendly error message
-			validationErrors := make(map[string]string)
+			// Create a more user-friendly error message for each field
+			response.Details = make(map[string]any, len(validateErr))
 			for _, e := range validateErr {
-				validationErrors[e.Field()] = getValidationErrorMessage(e)
+				response.Details[validationField(e)] = getValidationErrorMessage(e)
 			}
-
-			return c.Status(statusCode).JSON(fiber.Map{
-				"error":       errorMsg,
-				"validations": validationErrors,
-			})
+		case errors.As(err, &fiberError):
+			response.Code = statusErrorCode(fiberError.Code)
+			response.Message = fiberError.Message
+		case errors.As(err, &domainErr):
+			// Services may add to the message of a domain error, e.g. the market that was not found
+			response.Code = string(domainErr.Code)
+			response.Message = err.Error()
+			response.Details = domainErr.Details
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			statusCode = fiber.StatusNotFound
+			response.Code = codeNotFound
+			response.Message = "Resource not found"
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			statusCode = fiber.StatusConflict
+			response.Code = codeConflict
+			response.Message = "Resource already exists"
 		}
 
 		// Log the error if it's a server error
@@ -59,23 +64,59 @@ func ErrorHandler() fiber.ErrorHandler {
 		}
 
 		// Return a JSON response with the error
-		return c.Status(statusCode).JSON(dto.ErrorResponse{
-			Error: errorMsg,
-		})
+		response.Error = response.Message
+		return c.Status(statusCode).JSON(response)
 	}
 }
 
+// validationField returns the path of a field that failed validation as clients send it, e.g. actions[1].amount.
+// The request type and embedded structs, which are named after their Go type, are left out
+func validationField(e validator.FieldError) string {
+	var path []string
+	for _, part := range strings.Split(e.Namespace(), ".")[1:] {
+		if part != "" && unicode.IsUpper(rune(part[0])) {
+			continue
+		}
+		path = append(path, part)
+	}
+
+	if len(path) == 0 {
+		return e.Field()
+	}
+	return strings.Join(path, ".")
+}
+
 // getValidationErrorMessage returns a user-friendly error message for a validation error
 func getValidationErrorMessage(e validator.FieldError) string {
 	switch e.Tag() {
 	case "required":
 		return "This field is required"
 	case "min":
+		if e.Kind() == reflect.Slice {
+			return "Must hold at least " + e.Param() + " items"
+		}
 		return "Value does not satisfy minimum length"
 	case "max":
+		if e.Kind() == reflect.Slice {
+			return "Must hold at most " + e.Param() + " items"
+		}
 		return "Value exceeds maximum length"
+	case "oneof":
+		return "Value must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
+	case "datetime":
+		return "Invalid date, expected YYYY-MM-DD"
 	case "eth_addr":
 		return "Invalid Ethereum address"
+	case "checksum_addr":
+		return "Invalid Ethereum address or checksum"
+	case "uint256":
+		return "Invalid amount, expected a whole number of token units below 2^256"
+	case "positive_amount":
+		return "Amount must be a whole number of token units greater than 0 and below 2^256"
+	case "page":
+		return "Page must be at least 1"
+	case "page_size":
+		return "Page size must be between 1 and 100"
 	default:
 		return "Invalid value"
 	}
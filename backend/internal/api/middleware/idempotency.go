@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	valkey "github.com/Mattouff/Lending-Borrowing/pkg/cache"
+)
+
+const (
+	// IdempotencyKeyHeader is the header identifying the attempts of a request that changes state
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks the responses replayed for a repeated idempotency key
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength is the longest idempotency key accepted
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyRecord is what is stored under an idempotency key: the request it was first sent with and,
+// once served, its response
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"` // Zero while the first attempt is being served
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency middleware to serve the attempts of a request that changes state once. The response to the first
+// request of a user with an Idempotency-Key is stored in Valkey and replayed to the requests repeating the key.
+// Must run after authentication; requests without a key or a user are served as usual
+func Idempotency(valkeyClient *valkey.Client, cfg *config.Config) fiber.Handler {
+	retention := time.Duration(cfg.Idempotency.Retention) * time.Hour
+	if retention <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		userID, authenticated := c.Locals("userID").(uint)
+		if key == "" || !authenticated || c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key must not exceed 255 characters")
+		}
+
+		fingerprint := requestFingerprint(c)
+		pending, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+
+		claimed, err := valkeyClient.ClaimIdempotencyKey(c.Context(), userID, key, string(pending), retention)
+		if err != nil {
+			// Availability over strictness: serve the request if Valkey is unavailable
+			log.Printf("Failed to claim idempotency key: %v", err)
+			return c.Next()
+		}
+		if !claimed {
+			return replayResponse(c, valkeyClient, userID, key, fingerprint)
+		}
+
+		// Answer errors here, so that their response is stored like any other
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		// Throttled and failed requests may have nothing to replay, so their next attempt is served anew
+		status := c.Response().StatusCode()
+		if status == fiber.StatusTooManyRequests || status >= fiber.StatusInternalServerError {
+			if err := valkeyClient.ReleaseIdempotencyKey(c.Context(), userID, key); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+			return nil
+		}
+
+		record, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        c.Response().Body(),
+		})
+		if err := valkeyClient.StoreIdempotencyRecord(c.Context(), userID, key, string(record), retention); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+
+		return nil
+	}
+}
+
+// replayResponse answers a request repeating an idempotency key with the response to its first attempt
+func replayResponse(c *fiber.Ctx, valkeyClient *valkey.Client, userID uint, key, fingerprint string) error {
+	stored, err := valkeyClient.LoadIdempotencyRecord(c.Context(), userID, key)
+	if err != nil {
+		return err
+	}
+
+	// Released or expired since it was claimed: the first attempt failed, the next one is served anew
+	var record idempotencyRecord
+	if stored != "" {
+		if err := json.Unmarshal([]byte(stored), &record); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case stored != "" && record.Fingerprint != fingerprint:
+		return idempotencyError(c, fiber.StatusUnprocessableEntity, codeIdempotencyKeyReused, "Idempotency-Key was already used for another request")
+	case record.Status == 0:
+		c.Set(fiber.HeaderRetryAfter, "1")
+		return idempotencyError(c, fiber.StatusConflict, codeIdempotencyKeyInUse, "A request with this Idempotency-Key is being served, retry later")
+	}
+
+	c.Set(idempotentReplayedHeader, "true")
+	if record.ContentType != "" {
+		c.Set(fiber.HeaderContentType, record.ContentType)
+	}
+	return c.Status(record.Status).Send(record.Body)
+}
+
+// idempotencyError answers a request with an error of its idempotency key
+func idempotencyError(c *fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).JSON(dto.ErrorResponse{
+		Code:    code,
+		Message: message,
+		Error:   message,
+	})
+}
+
+// requestFingerprint identifies what a request asks for: its method, its URL and its body
+func requestFingerprint(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// Market middleware to resolve the market named in the route
+func Market(markets service.MarketRegistry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		market, err := markets.GetMarket(c.Context(), c.Params("market"))
+		if err != nil {
+			return err
+		}
+
+		// Store the market identifier in the context
+		c.Locals("market", market.Identifier)
+
+		return c.Next()
+	}
+}
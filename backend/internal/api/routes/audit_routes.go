@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SetupAuditRoutes configures the routes for reviewing the audit log
+func SetupAuditRoutes(router fiber.Router, auditService service.AuditService, authService service.AuthService, limiter *middleware.RateLimiter, cfg *config.Config) {
+	// Create handler
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	// Audit routes, themselves audited
+	auditRouter := router.Group("/audit")
+	auditRouter.Use(middleware.Authentication(cfg, authService))
+	auditRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	auditRouter.Use(middleware.PermissionAuthorization(models.PermAuditRead))
+	auditRouter.Get("/", middleware.Audit(auditService, models.AuditLogRead, "", ""), auditHandler.ListAuditLogs)
+	auditRouter.Get("/export", middleware.Audit(auditService, models.AuditLogExport, "", ""), auditHandler.ExportAuditLogs)
+	auditRouter.Get("/verify", middleware.Audit(auditService, models.AuditLogRead, "", ""), auditHandler.VerifyAuditLog)
+}
@@ -6,25 +6,28 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
 	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
 	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
 // SetupBorrowingRoutes configures the routes for borrowing operations
-func SetupBorrowingRoutes(router fiber.Router, borrowingService service.BorrowingService, authService service.AuthService, cfg *config.Config) {
+func SetupBorrowingRoutes(router fiber.Router, borrowingService service.BorrowingService, priceService service.PriceService, userService service.UserService, authService service.AuthService, limiter *middleware.RateLimiter, idempotency fiber.Handler, cfg *config.Config) {
 	// Create handler
-	borrowingHandler := handlers.NewBorrowingHandler(borrowingService)
+	borrowingHandler := handlers.NewBorrowingHandler(borrowingService, priceService)
 
 	// Borrowing routes
 	borrowingRouter := router.Group("/borrowing")
 
 	// Public routes
-	borrowingRouter.Get("/stats", borrowingHandler.GetBorrowingStats)
+	borrowingRouter.Get("/stats", limiter.Limit(config.RateLimitMarket), borrowingHandler.GetBorrowingStats)
 
 	// Protected routes (require authentication)
 	borrowingRouter.Use(middleware.Authentication(cfg, authService))
-	borrowingRouter.Post("/borrow", borrowingHandler.Borrow)
-	borrowingRouter.Post("/repay", borrowingHandler.Repay)
-	borrowingRouter.Get("/balance", borrowingHandler.GetBorrowedAmount)
-	borrowingRouter.Get("/info", borrowingHandler.GetBorrowingInfo)
-	borrowingRouter.Get("/transactions", borrowingHandler.GetTransactionHistory)
+	borrowingRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	borrowingRouter.Use(idempotency)
+	borrowingRouter.Post("/borrow", middleware.ScopeAuthorization(models.ScopeWriteBorrowing), borrowingHandler.Borrow)
+	borrowingRouter.Post("/repay", middleware.ScopeAuthorization(models.ScopeWriteBorrowing), borrowingHandler.Repay)
+	borrowingRouter.Get("/balance", middleware.ScopeAuthorization(models.ScopeReadPositions), middleware.LinkedAddresses(userService), borrowingHandler.GetBorrowedAmount)
+	borrowingRouter.Get("/info", middleware.ScopeAuthorization(models.ScopeReadPositions), middleware.LinkedAddresses(userService), borrowingHandler.GetBorrowingInfo)
+	borrowingRouter.Get("/transactions", middleware.ScopeAuthorization(models.ScopeReadPositions), borrowingHandler.GetTransactionHistory)
 }
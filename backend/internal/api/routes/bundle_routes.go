@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SetupBundleRoutes configures the routes for multi-step action bundles
+func SetupBundleRoutes(router fiber.Router, bundleService service.BundleService, authService service.AuthService, limiter *middleware.RateLimiter, idempotency fiber.Handler, cfg *config.Config) {
+	// Create handler
+	bundleHandler := handlers.NewBundleHandler(bundleService, cfg)
+
+	// Bundle routes
+	bundleRouter := router.Group("/bundles")
+
+	// Protected routes (require authentication); creating a bundle also checks the scope of each action
+	bundleRouter.Use(middleware.Authentication(cfg, authService))
+	bundleRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	bundleRouter.Use(idempotency)
+	bundleRouter.Post("/", middleware.ScopeAuthorization(models.ScopeWriteCollateral, models.ScopeWriteBorrowing), bundleHandler.CreateBundle)
+	bundleRouter.Get("/", middleware.ScopeAuthorization(models.ScopeReadPositions), bundleHandler.ListBundles)
+	bundleRouter.Get("/:id", middleware.ScopeAuthorization(models.ScopeReadPositions), bundleHandler.GetBundle)
+	bundleRouter.Post("/:id/steps/:position/submit", middleware.ScopeAuthorization(models.ScopeWriteCollateral, models.ScopeWriteBorrowing), bundleHandler.SubmitBundleStep)
+}
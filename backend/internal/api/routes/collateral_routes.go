@@ -6,21 +6,30 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
 	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
 	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
 // SetupCollateralRoutes configures the routes for collateral management
-func SetupCollateralRoutes(router fiber.Router, collateralService service.CollateralService, authService service.AuthService, cfg *config.Config) {
+func SetupCollateralRoutes(router fiber.Router, collateralService service.CollateralService, priceService service.PriceService, userService service.UserService, authService service.AuthService, limiter *middleware.RateLimiter, idempotency fiber.Handler, cfg *config.Config) {
 	// Create handler
-	collateralHandler := handlers.NewCollateralHandler(collateralService)
+	collateralHandler := handlers.NewCollateralHandler(collateralService, priceService)
 
 	// Collateral routes
 	collateralRouter := router.Group("/collateral")
 
 	// Protected routes (require authentication)
 	collateralRouter.Use(middleware.Authentication(cfg, authService))
-	collateralRouter.Post("/deposit", collateralHandler.DepositCollateral)
-	collateralRouter.Post("/withdraw", collateralHandler.WithdrawCollateral)
-	collateralRouter.Get("/balance", collateralHandler.GetCollateralBalance)
-	collateralRouter.Get("/info", collateralHandler.GetCollateralInfo)
+	collateralRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	collateralRouter.Use(idempotency)
+	collateralRouter.Post("/deposit", middleware.ScopeAuthorization(models.ScopeWriteCollateral), collateralHandler.DepositCollateral)
+	collateralRouter.Post("/withdraw", middleware.ScopeAuthorization(models.ScopeWriteCollateral), collateralHandler.WithdrawCollateral)
+	collateralRouter.Get("/balance", middleware.ScopeAuthorization(models.ScopeReadPositions), middleware.LinkedAddresses(userService), collateralHandler.GetCollateralBalance)
+	collateralRouter.Get("/info", middleware.ScopeAuthorization(models.ScopeReadPositions), collateralHandler.GetCollateralInfo)
+	collateralRouter.Get("/transactions", middleware.ScopeAuthorization(models.ScopeReadPositions), collateralHandler.GetTransactionHistory)
+
+	// Admin routes
+	adminRouter := collateralRouter.Group("/admin")
+	adminRouter.Use(middleware.PermissionAuthorization(models.PermPositionsReadAll))
+	adminRouter.Get("/reconciliation", collateralHandler.GetCollateralReconciliation)
 }
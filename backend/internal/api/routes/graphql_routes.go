@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/graph"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SetupGraphQLRoutes configures the GraphQL endpoint
+func SetupGraphQLRoutes(router fiber.Router, server *graph.Server, authService service.AuthService, limiter *middleware.RateLimiter, cfg *config.Config) {
+	// Create handler
+	graphQLHandler := handlers.NewGraphQLHandler(server)
+
+	// Protected route (requires authentication); fields check the permissions of the user themselves
+	router.Post("/graphql",
+		middleware.Authentication(cfg, authService),
+		limiter.Limit(config.RateLimitAuthenticated),
+		middleware.ScopeAuthorization(models.ScopeReadPositions),
+		graphQLHandler.Query,
+	)
+}
@@ -6,25 +6,28 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
 	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
 	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
 // SetupLendingRoutes configures the routes for lending operations
-func SetupLendingRoutes(router fiber.Router, lendingService service.LendingService, authService service.AuthService, cfg *config.Config) {
+func SetupLendingRoutes(router fiber.Router, lendingService service.LendingService, priceService service.PriceService, userService service.UserService, authService service.AuthService, limiter *middleware.RateLimiter, idempotency fiber.Handler, cfg *config.Config) {
 	// Create handler
-	lendingHandler := handlers.NewLendingHandler(lendingService)
+	lendingHandler := handlers.NewLendingHandler(lendingService, priceService)
 
 	// Lending routes
 	lendingRouter := router.Group("/lending")
 
 	// Public routes
-	lendingRouter.Get("/pool-info", lendingHandler.GetPoolInfo)
+	lendingRouter.Get("/pool-info", limiter.Limit(config.RateLimitMarket), lendingHandler.GetPoolInfo)
 
 	// Protected routes (require authentication)
 	lendingRouter.Use(middleware.Authentication(cfg, authService))
-	lendingRouter.Post("/deposit", lendingHandler.Deposit)
-	lendingRouter.Post("/withdraw", lendingHandler.Withdraw)
-	lendingRouter.Get("/balance", lendingHandler.GetLendingBalance)
-	lendingRouter.Get("/info", lendingHandler.GetLendingInfo)
-	lendingRouter.Get("/transactions", lendingHandler.GetTransactionHistory)
+	lendingRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	lendingRouter.Use(idempotency)
+	lendingRouter.Post("/deposit", middleware.ScopeAuthorization(models.ScopeWriteLending), lendingHandler.Deposit)
+	lendingRouter.Post("/withdraw", middleware.ScopeAuthorization(models.ScopeWriteLending), lendingHandler.Withdraw)
+	lendingRouter.Get("/balance", middleware.ScopeAuthorization(models.ScopeReadPositions), middleware.LinkedAddresses(userService), lendingHandler.GetLendingBalance)
+	lendingRouter.Get("/info", middleware.ScopeAuthorization(models.ScopeReadPositions), middleware.LinkedAddresses(userService), lendingHandler.GetLendingInfo)
+	lendingRouter.Get("/transactions", middleware.ScopeAuthorization(models.ScopeReadPositions), lendingHandler.GetTransactionHistory)
 }
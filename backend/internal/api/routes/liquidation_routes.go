@@ -6,11 +6,12 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
 	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
 	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
 
 // SetupLiquidationRoutes configures the routes for liquidation operations
-func SetupLiquidationRoutes(router fiber.Router, liquidationService service.LiquidationService, authService service.AuthService, cfg *config.Config) {
+func SetupLiquidationRoutes(router fiber.Router, liquidationService service.LiquidationService, authService service.AuthService, limiter *middleware.RateLimiter, idempotency fiber.Handler, cfg *config.Config) {
 	// Create handler
 	liquidationHandler := handlers.NewLiquidationHandler(liquidationService)
 
@@ -18,11 +19,14 @@ func SetupLiquidationRoutes(router fiber.Router, liquidationService service.Liqu
 	liquidationRouter := router.Group("/liquidation")
 
 	// Public routes
-	liquidationRouter.Get("/positions", liquidationHandler.GetLiquidatablePositions)
-	liquidationRouter.Get("/history", liquidationHandler.GetLiquidationHistory)
-	liquidationRouter.Get("/bonus", liquidationHandler.GetLiquidationBonus)
+	marketLimit := limiter.Limit(config.RateLimitMarket)
+	liquidationRouter.Get("/positions", marketLimit, liquidationHandler.GetLiquidatablePositions)
+	liquidationRouter.Get("/history", marketLimit, liquidationHandler.GetLiquidationHistory)
+	liquidationRouter.Get("/bonus", marketLimit, liquidationHandler.GetLiquidationBonus)
 
 	// Protected routes (require authentication)
 	liquidationRouter.Use(middleware.Authentication(cfg, authService))
-	liquidationRouter.Post("/liquidate", liquidationHandler.Liquidate)
+	liquidationRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	liquidationRouter.Use(idempotency)
+	liquidationRouter.Post("/liquidate", middleware.PermissionAuthorization(models.PermLiquidationExecute), liquidationHandler.Liquidate)
 }
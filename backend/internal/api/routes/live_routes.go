@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SetupLiveRoutes configures the live event feed routes
+func SetupLiveRoutes(router fiber.Router, liveService service.LiveService, authService service.AuthService, limiter *middleware.RateLimiter, cfg *config.Config) {
+	// Create handler
+	liveHandler := handlers.NewLiveHandler(liveService, cfg)
+
+	// Protected routes (requires authentication); browsers cannot set headers on these connections
+	liveRoutes := router.Group("/live",
+		middleware.QueryToken(),
+		middleware.Authentication(cfg, authService),
+		limiter.Limit(config.RateLimitAuthenticated),
+		middleware.ScopeAuthorization(models.ScopeReadPositions),
+	)
+	liveRoutes.Get("/ws", liveHandler.Upgrade, websocket.New(liveHandler.WebSocket))
+	liveRoutes.Get("/events", liveHandler.Events)
+}
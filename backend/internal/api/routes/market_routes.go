@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
 )
@@ -14,14 +16,21 @@ func SetupMarketRoutes(
 	lendingService service.LendingService,
 	borrowingService service.BorrowingService,
 	collateralService service.CollateralService,
+	marketService service.MarketService,
+	marketRegistry service.MarketRegistry,
+	priceService service.PriceService,
 	userRepository repository.UserRepository,
 	positionRepository repository.PositionRepository,
+	limiter *middleware.RateLimiter,
 ) {
 	// Create handler with all required dependencies
 	marketHandler := handlers.NewMarketHandler(
 		lendingService,
 		borrowingService,
 		collateralService,
+		marketService,
+		marketRegistry,
+		priceService,
 		userRepository,
 		positionRepository,
 	)
@@ -30,6 +39,10 @@ func SetupMarketRoutes(
 	marketRouter := router.Group("/market")
 
 	// Public routes
-	marketRouter.Get("/overview", marketHandler.GetMarketOverview)
-	marketRouter.Get("/tokens", marketHandler.GetTokensMarketData)
+	marketLimit := limiter.Limit(config.RateLimitMarket)
+	marketRouter.Get("/overview", marketLimit, marketHandler.GetMarketOverview)
+	marketRouter.Get("/tokens", marketLimit, marketHandler.GetTokensMarketData)
+
+	// Markets listing
+	router.Get("/markets", marketLimit, marketHandler.ListMarkets)
 }
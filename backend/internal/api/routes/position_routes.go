@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SetupPositionRoutes configures the routes for reading positions
+func SetupPositionRoutes(router fiber.Router, positionService service.PositionService, userService service.UserService, authService service.AuthService, limiter *middleware.RateLimiter, cfg *config.Config) {
+	// Create handler
+	positionHandler := handlers.NewPositionHandler(positionService, userService, cfg)
+
+	// Position routes
+	positionRouter := router.Group("/positions")
+
+	// Protected routes (require authentication)
+	positionRouter.Use(middleware.Authentication(cfg, authService))
+	positionRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+
+	// Admin routes, registered first so that "admin" is not taken for a position ID
+	adminRouter := positionRouter.Group("/admin")
+	adminRouter.Use(middleware.PermissionAuthorization(models.PermPositionsReadAll))
+	adminRouter.Get("/", positionHandler.ListAllPositions)
+	adminRouter.Get("/:id", positionHandler.GetAnyPosition)
+
+	positionRouter.Get("/", middleware.ScopeAuthorization(models.ScopeReadPositions), positionHandler.ListMyPositions)
+	positionRouter.Get("/:id", middleware.ScopeAuthorization(models.ScopeReadPositions), positionHandler.GetMyPosition)
+}
@@ -6,6 +6,9 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/Mattouff/Lending-Borrowing/internal/api/graph"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
 	"github.com/Mattouff/Lending-Borrowing/internal/config"
 )
 
@@ -42,14 +45,57 @@ func SetupRoutes(app *fiber.App, services *Services, repositories *Repositories,
 		})
 	})
 
+	// Public keys access tokens are signed with, for services verifying them (no auth needed)
+	jwksHandler := handlers.NewJWKSHandler(services.AuthService)
+	app.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	api := app.Group("/api/v1")
 
+	// Attribute audit entries to the request
+	api.Use(middleware.AuditContext())
+
+	// Throttle every client and reject those banned for failing to sign in
+	limiter := middleware.NewRateLimiter(services.ValkeyClient, cfg)
+	api.Use(limiter.RejectBanned(), limiter.Limit(config.RateLimitDefault))
+
+	// Replay the response to a write repeated with the same Idempotency-Key, registered after authentication
+	idempotency := middleware.Idempotency(services.ValkeyClient, cfg)
+
 	// Setup individual route groups
-	SetupUserRoutes(api, services.UserService, services.AuthService, cfg)
-	SetupLendingRoutes(api, services.LendingService, services.AuthService, cfg)
-	SetupBorrowingRoutes(api, services.BorrowingService, services.AuthService, cfg)
-	SetupCollateralRoutes(api, services.CollateralService, services.AuthService, cfg)
-	SetupLiquidationRoutes(api, services.LiquidationService, services.AuthService, cfg)
+	SetupUserRoutes(api, services.UserService, services.AuthService, services.APIKeyService, services.PrivacyService, services.AuditService, limiter, idempotency, cfg)
+	SetupAuditRoutes(api, services.AuditService, services.AuthService, limiter, cfg)
+	SetupLendingRoutes(api, services.LendingService, services.PriceService, services.UserService, services.AuthService, limiter, idempotency, cfg)
+	SetupBorrowingRoutes(api, services.BorrowingService, services.PriceService, services.UserService, services.AuthService, limiter, idempotency, cfg)
+	SetupCollateralRoutes(api, services.CollateralService, services.PriceService, services.UserService, services.AuthService, limiter, idempotency, cfg)
+	SetupLiquidationRoutes(api, services.LiquidationService, services.AuthService, limiter, idempotency, cfg)
+	SetupSolvencyRoutes(api, services.SolvencyService, services.PriceService, services.AuthService, limiter, cfg)
+	SetupPositionRoutes(api, services.PositionService, services.UserService, services.AuthService, limiter, cfg)
+	SetupTransactionRoutes(api, services.TransactionService, services.AuthService, limiter, cfg)
+	SetupBundleRoutes(api, services.BundleService, services.AuthService, limiter, idempotency, cfg)
+
+	// GraphQL over the same services, for clients assembling a view in one request
+	graphServer := graph.NewServer(graph.Services{
+		UserService:        services.UserService,
+		PositionService:    services.PositionService,
+		TransactionService: services.TransactionService,
+		LiquidationService: services.LiquidationService,
+		MarketService:      services.MarketService,
+		MarketRegistry:     services.MarketRegistry,
+		UserRepository:     repositories.UserRepository,
+	}, cfg)
+	SetupGraphQLRoutes(api, graphServer, services.AuthService, limiter, cfg)
+
+	// Live position, transaction and market updates pushed to clients
+	SetupLiveRoutes(api, services.LiveService, services.AuthService, limiter, cfg)
+
+	// The same routes scoped to a market; the unscoped ones above operate on the default market
+	marketAPI := api.Group("/markets/:market", middleware.Market(services.MarketRegistry))
+	SetupLendingRoutes(marketAPI, services.LendingService, services.PriceService, services.UserService, services.AuthService, limiter, idempotency, cfg)
+	SetupBorrowingRoutes(marketAPI, services.BorrowingService, services.PriceService, services.UserService, services.AuthService, limiter, idempotency, cfg)
+	SetupCollateralRoutes(marketAPI, services.CollateralService, services.PriceService, services.UserService, services.AuthService, limiter, idempotency, cfg)
+	SetupLiquidationRoutes(marketAPI, services.LiquidationService, services.AuthService, limiter, idempotency, cfg)
+	SetupSolvencyRoutes(marketAPI, services.SolvencyService, services.PriceService, services.AuthService, limiter, cfg)
+	SetupBundleRoutes(marketAPI, services.BundleService, services.AuthService, limiter, idempotency, cfg)
 
 	// Setup market routes (uses multiple services and repositories)
 	SetupMarketRoutes(
@@ -57,7 +103,11 @@ func SetupRoutes(app *fiber.App, services *Services, repositories *Repositories,
 		services.LendingService,
 		services.BorrowingService,
 		services.CollateralService,
+		services.MarketService,
+		services.MarketRegistry,
+		services.PriceService,
 		repositories.UserRepository,
 		repositories.PositionRepository,
+		limiter,
 	)
 }
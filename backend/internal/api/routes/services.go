@@ -13,7 +13,18 @@ type Services struct {
 	BorrowingService   service.BorrowingService
 	CollateralService  service.CollateralService
 	LiquidationService service.LiquidationService
+	MarketService      service.MarketService
+	MarketRegistry     service.MarketRegistry
+	PriceService       service.PriceService
+	SolvencyService    service.SolvencyService
 	AuthService        service.AuthService
+	APIKeyService      service.APIKeyService
+	AuditService       service.AuditService
+	PrivacyService     service.PrivacyService
+	PositionService    service.PositionService
+	TransactionService service.TransactionService
+	LiveService        service.LiveService
+	BundleService      service.BundleService
 	ValkeyClient       *valkey.Client
 }
 
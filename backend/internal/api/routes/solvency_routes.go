@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SetupSolvencyRoutes configures the routes for protocol solvency monitoring
+func SetupSolvencyRoutes(router fiber.Router, solvencyService service.SolvencyService, priceService service.PriceService, authService service.AuthService, limiter *middleware.RateLimiter, cfg *config.Config) {
+	// Create handler
+	solvencyHandler := handlers.NewSolvencyHandler(solvencyService, priceService)
+
+	// Solvency routes (admin only)
+	solvencyRouter := router.Group("/solvency")
+	solvencyRouter.Use(middleware.Authentication(cfg, authService))
+	solvencyRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	solvencyRouter.Use(middleware.PermissionAuthorization(models.PermSystemRead))
+	solvencyRouter.Get("/report", solvencyHandler.GetSolvencyReport)
+	solvencyRouter.Get("/history", solvencyHandler.GetSolvencyHistory)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/handlers"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// SetupTransactionRoutes configures the routes for reading the transaction ledger
+func SetupTransactionRoutes(router fiber.Router, transactionService service.TransactionService, authService service.AuthService, limiter *middleware.RateLimiter, cfg *config.Config) {
+	// Create handler
+	transactionHandler := handlers.NewTransactionHandler(transactionService, cfg)
+
+	// Transaction routes
+	transactionRouter := router.Group("/transactions")
+
+	// Protected routes (require authentication)
+	transactionRouter.Use(middleware.Authentication(cfg, authService))
+	transactionRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	transactionRouter.Get("/", middleware.ScopeAuthorization(models.ScopeReadPositions), transactionHandler.ListTransactions)
+	transactionRouter.Get("/:hash", middleware.ScopeAuthorization(models.ScopeReadPositions), transactionHandler.GetTransaction)
+}
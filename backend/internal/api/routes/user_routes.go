@@ -11,30 +11,63 @@ import (
 )
 
 // SetupUserRoutes configures the routes for user management
-func SetupUserRoutes(router fiber.Router, userService service.UserService, authService service.AuthService, cfg *config.Config) {
-	// Create handler
+func SetupUserRoutes(router fiber.Router, userService service.UserService, authService service.AuthService, apiKeyService service.APIKeyService, privacyService service.PrivacyService, auditService service.AuditService, limiter *middleware.RateLimiter, idempotency fiber.Handler, cfg *config.Config) {
+	// Create handlers
 	userHandler := handlers.NewUserHandler(userService, authService, cfg)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, userService)
+	addressHandler := handlers.NewAddressHandler(userService, authService)
+	privacyHandler := handlers.NewPrivacyHandler(privacyService)
 
 	// User routes
 	userRouter := router.Group("/users")
 
 	// Public routes
-	userRouter.Post("/register", userHandler.Register)
-	userRouter.Post("/auth", userHandler.Authenticate)
-	userRouter.Get("/nonce/:address", userHandler.NonceMessage)
+	authLimit := limiter.Limit(config.RateLimitAuth)
+	userRouter.Post("/register", authLimit, userHandler.Register)
+	userRouter.Post("/auth", authLimit, limiter.BanOnFailure(), userHandler.Authenticate)
+	userRouter.Post("/refresh", authLimit, userHandler.Refresh)
+	userRouter.Get("/nonce/:address", authLimit, userHandler.NonceMessage)
 
 	// Protected routes (require authentication)
 	userRouter.Use(middleware.Authentication(cfg, authService))
-	userRouter.Get("/profile", userHandler.GetProfile)
-	userRouter.Put("/profile", userHandler.UpdateProfile)
-	userRouter.Delete("/account", userHandler.DeleteAccount)
+	userRouter.Use(limiter.Limit(config.RateLimitAuthenticated))
+	userRouter.Use(idempotency)
 
-	// Admin only routes
+	// Account management is not available to API keys
+	sessionOnly := middleware.SessionOnly()
+	userRouter.Get("/profile", sessionOnly, userHandler.GetProfile)
+	userRouter.Put("/profile", sessionOnly, userHandler.UpdateProfile)
+	userRouter.Delete("/account", sessionOnly, userHandler.DeleteAccount)
+	userRouter.Post("/logout", sessionOnly, userHandler.Logout)
+	userRouter.Post("/logout-all", sessionOnly, userHandler.LogoutAll)
+	userRouter.Get("/sessions", sessionOnly, userHandler.ListSessions)
+	userRouter.Delete("/sessions/:id", sessionOnly, userHandler.RevokeSession)
+	userRouter.Get("/addresses", sessionOnly, addressHandler.ListAddresses)
+	userRouter.Post("/addresses/challenge", sessionOnly, addressHandler.CreateLinkChallenge)
+	userRouter.Post("/addresses", sessionOnly, addressHandler.LinkAddress)
+	userRouter.Put("/addresses/:address/primary", sessionOnly, addressHandler.SetPrimaryAddress)
+	userRouter.Delete("/addresses/:address", sessionOnly, addressHandler.UnlinkAddress)
+	userRouter.Get("/api-keys", sessionOnly, apiKeyHandler.ListAPIKeys)
+	userRouter.Post("/api-keys", sessionOnly, apiKeyHandler.CreateAPIKey)
+	userRouter.Delete("/api-keys/:id", sessionOnly, apiKeyHandler.RevokeAPIKey)
+	userRouter.Post("/exports", sessionOnly, privacyHandler.CreateDataExport)
+	userRouter.Get("/exports", sessionOnly, privacyHandler.ListDataExports)
+	userRouter.Get("/exports/:id", sessionOnly, privacyHandler.GetDataExport)
+	userRouter.Get("/exports/:id/download", sessionOnly, privacyHandler.DownloadDataExport)
+	userRouter.Post("/deletion-request", sessionOnly, privacyHandler.CreateDeletionRequest)
+	userRouter.Get("/deletion-request", sessionOnly, privacyHandler.GetDeletionRequest)
+
+	// Admin routes, each requiring the permission it exercises. Reads are audited here,
+	// changes by the user service along with their diff
 	adminRouter := userRouter.Group("/admin")
-	adminRouter.Use(middleware.RoleAuthorization(models.RoleAdmin))
-	adminRouter.Get("/", userHandler.ListUsers)
-	adminRouter.Get("/:id", userHandler.GetUserByID)
-	adminRouter.Get("/address/:address", userHandler.GetUserByAddress)
-	adminRouter.Put("/:id/verify", userHandler.VerifyUser)
-	adminRouter.Delete("/:id", userHandler.DeleteUser)
+	adminRouter.Get("/", middleware.PermissionAuthorization(models.PermUsersRead), middleware.Audit(auditService, models.AuditUserList, "", ""), userHandler.ListUsers)
+	adminRouter.Get("/roles", middleware.PermissionAuthorization(models.PermUsersRead), userHandler.ListRoles)
+	adminRouter.Get("/deletion-requests", middleware.PermissionAuthorization(models.PermUsersPurge), privacyHandler.ListDeletionRequests)
+	adminRouter.Post("/deletion-requests/:id/approve", middleware.PermissionAuthorization(models.PermUsersPurge), privacyHandler.ApproveDeletionRequest)
+	adminRouter.Post("/deletion-requests/:id/reject", middleware.PermissionAuthorization(models.PermUsersPurge), privacyHandler.RejectDeletionRequest)
+	adminRouter.Get("/:id", middleware.PermissionAuthorization(models.PermUsersRead), middleware.Audit(auditService, models.AuditUserRead, "user", "id"), userHandler.GetUserByID)
+	adminRouter.Get("/address/:address", middleware.PermissionAuthorization(models.PermUsersRead), middleware.Audit(auditService, models.AuditUserRead, "address", "address"), userHandler.GetUserByAddress)
+	adminRouter.Put("/:id/verify", middleware.PermissionAuthorization(models.PermUsersVerify), userHandler.VerifyUser)
+	adminRouter.Put("/:id/role", middleware.PermissionAuthorization(models.PermUsersAssignRoles), userHandler.AssignRole)
+	adminRouter.Delete("/:id", middleware.PermissionAuthorization(models.PermUsersDelete), userHandler.DeleteUser)
 }
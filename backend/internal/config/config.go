@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
 	"github.com/ethereum/go-ethereum/common"
@@ -10,12 +12,22 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	App        AppConfig
-	Database   DatabaseConfig
-	Valkey     ValkeyConfig
-	Blockchain BlockchainConfig
-	JWT        JWTConfig
-	Server     ServerConfig
+	App         AppConfig
+	Database    DatabaseConfig
+	Valkey      ValkeyConfig
+	Blockchain  BlockchainConfig
+	Oracle      OracleConfig
+	Solvency    SolvencyConfig
+	JWT         JWTConfig
+	SIWE        SIWEConfig
+	RateLimit   RateLimitConfig
+	Privacy     PrivacyConfig
+	Pagination  PaginationConfig
+	GraphQL     GraphQLConfig
+	Live        LiveConfig
+	Bundle      BundleConfig
+	Idempotency IdempotencyConfig
+	Server      ServerConfig
 }
 
 // AppConfig holds application-wide configuration
@@ -47,24 +59,130 @@ type ValkeyConfig struct {
 
 // BlockchainConfig holds blockchain connection information
 type BlockchainConfig struct {
-	RpcURL            string
-	NetworkName       blockchain.Network
-	ChainID           int
-	GasLimit          uint64
-	GasPrice          int64
-	ContractAddresses map[string]common.Address
+	RpcURL                  string
+	NetworkName             blockchain.Network
+	ChainID                 int
+	GasLimit                uint64
+	GasPrice                int64
+	ContractAddresses       map[string]common.Address
+	DefaultMarket           string                  // Market used by routes that do not name one
+	Markets                 map[string]MarketConfig // Markets declared in CONTRACT_ADDRESSES, by identifier
+	CollateralCheckInterval int                     // In Minutes, 0 disables the reconciliation check
+}
+
+// MarketConfig holds the contract addresses of a market
+type MarketConfig struct {
+	LendingPool common.Address
+	Borrowing   common.Address
+	Collateral  common.Address
+}
+
+// OracleConfig holds price oracle configuration
+type OracleConfig struct {
+	StaticPricesFile string                            // Optional JSON file of token => USD price
+	Aggregators      map[common.Address]common.Address // Token => AggregatorV3 feed
+	MaxPriceAge      int                               // In Seconds, prices older than this are ignored
+	TWAPWindow       int                               // In Minutes, 0 disables the TWAP feed
+	SampleInterval   int                               // In Minutes, 0 disables price sampling
+}
+
+// SolvencyConfig holds solvency monitoring configuration.
+// Ratios are in percent: a coverage of 100 means assets exactly match liabilities
+type SolvencyConfig struct {
+	CoverageWarning   int // Total assets over total liabilities
+	CoverageCritical  int
+	LiquidityWarning  int // LendingPool token balance over the lending liability
+	LiquidityCritical int
+	SnapshotInterval  int // In Minutes, 0 disables snapshots
+	Retention         int // In Days, 0 keeps every snapshot
 }
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret     string
-	ExpireTime int // In Minutes
+	Secret            string // HS256 secret, used to sign tokens when no signing key is active
+	ExpireTime        int    // In Minutes, lifetime of access tokens
+	RefreshExpireTime int    // In Minutes, lifetime of refresh tokens and their sessions
+	// Asymmetric signing keys (RS256 or EdDSA, depending on the key type), by key ID: path of a PEM private key
+	SigningKeys map[string]string
+	ActiveKey   string               // ID of the key new tokens are signed with, empty to sign with the HS256 secret
+	RetiredKeys map[string]time.Time // By key ID, when the key stopped signing tokens
+	KeyGrace    int                  // In Minutes, how long tokens signed by a retired key stay valid after it retires
+	// When the HS256 secret stopped signing tokens, required once a key is active if the secret is still set
+	SecretRetiredAt time.Time
+}
+
+// SIWEConfig holds Sign-In with Ethereum (EIP-4361) configuration
+type SIWEConfig struct {
+	Domain    string // Host sign-in messages must be issued for, e.g. "app.example.com"
+	URI       string // Origin sign-in messages must refer to, e.g. "https://app.example.com"
+	Statement string
+	NonceTTL  int // In Minutes
+}
+
+// Rate limit policies applied by the routes
+const (
+	RateLimitDefault       = "default"       // Every request, by IP
+	RateLimitAuth          = "auth"          // Registration and sign-in, by IP
+	RateLimitMarket        = "market"        // Public reads backed by RPC calls, by IP
+	RateLimitAuthenticated = "authenticated" // Authenticated requests, by API key or user
+)
+
+// RateLimitPolicy allows a number of requests per sliding window
+type RateLimitPolicy struct {
+	Requests int
+	Window   int // In Seconds
+}
+
+// RateLimitConfig holds rate limiting and abuse protection configuration
+type RateLimitConfig struct {
+	Enabled      bool
+	Policies     map[string]RateLimitPolicy // By policy name
+	BanThreshold int                        // Failed signature verifications before a client is banned, 0 disables bans
+	BanWindow    int                        // In Minutes, window failures are counted over
+	BanDuration  int                        // In Minutes
+}
+
+// PrivacyConfig holds personal data export configuration
+type PrivacyConfig struct {
+	ExportInterval  int // In Seconds, how often pending exports are assembled, 0 disables the worker
+	ExportRetention int // In Days, how long archives can be downloaded, 0 keeps them
+}
+
+// PaginationConfig holds list pagination configuration
+type PaginationConfig struct {
+	CursorSecret string // Signs page cursors, defaults to JWT_SECRET
+}
+
+// GraphQLConfig holds the limits of GraphQL queries
+type GraphQLConfig struct {
+	MaxDepth      int // Deepest field nesting a query may have
+	MaxComplexity int // Fields a query may resolve, counting every item a list may return
+}
+
+// LiveConfig holds live feed configuration
+type LiveConfig struct {
+	PollInterval   int // In Seconds, how often the chain is polled for new blocks, 0 disables block, transaction and rate events
+	HealthInterval int // In Seconds, how often health factors are refreshed, 0 disables health events
+	Heartbeat      int // In Seconds, how often idle connections are pinged
+	BufferSize     int // Events buffered per connection before a slow client is disconnected
+}
+
+// BundleConfig holds action bundle configuration
+type BundleConfig struct {
+	ResumeInterval int // In Seconds, how often signer bundles left by a stopped server are resumed, 0 only resumes them on startup
+}
+
+// IdempotencyConfig holds the configuration of requests repeated with an Idempotency-Key
+type IdempotencyConfig struct {
+	Retention int // In Hours, how long responses are kept for replay, 0 serves every request anew
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Host string
-	Port int
+	Host           string
+	Port           int
+	TrustedProxies []string // IPs and CIDR ranges of the reverse proxies whose ProxyHeader gives the client IP
+	ProxyHeader    string   // Header the trusted proxies set to the client IP, replacing any value sent by the client
 }
 
 // LoadConfig loads all configuration from environment variables
@@ -119,19 +237,174 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	// Group market contracts: unprefixed names belong to the default market,
+	// "<market>.LendingPool" style names declare additional markets
+	defaultMarket := GetEnv("DEFAULT_MARKET", "default")
+	markets, err := parseMarkets(contractAddresses, defaultMarket)
+	if err != nil {
+		return nil, err
+	}
+
 	blockchainConfig := BlockchainConfig{
-		RpcURL:            GetEnv("BLOCKCHAIN_RPC_URL", "http://localhost:8545"),
-		NetworkName:       networkName,
-		ChainID:           GetEnvInt("BLOCKCHAIN_CHAIN_ID", 1337),
-		GasLimit:          uint64(GetEnvInt("BLOCKCHAIN_GAS_LIMIT", 3000000)),
-		GasPrice:          int64(GetEnvInt("BLOCKCHAIN_GAS_PRICE", 20000000000)), // 20 Gwei
-		ContractAddresses: contractAddresses,
+		RpcURL:                  GetEnv("BLOCKCHAIN_RPC_URL", "http://localhost:8545"),
+		NetworkName:             networkName,
+		ChainID:                 GetEnvInt("BLOCKCHAIN_CHAIN_ID", 1337),
+		GasLimit:                uint64(GetEnvInt("BLOCKCHAIN_GAS_LIMIT", 3000000)),
+		GasPrice:                int64(GetEnvInt("BLOCKCHAIN_GAS_PRICE", 20000000000)), // 20 Gwei
+		ContractAddresses:       contractAddresses,
+		DefaultMarket:           defaultMarket,
+		Markets:                 markets,
+		CollateralCheckInterval: GetEnvInt("COLLATERAL_CHECK_INTERVAL", 5),
+	}
+
+	// Parse oracle aggregators (format: TOKEN=AGGREGATOR,TOKEN2=AGGREGATOR2)
+	aggregators := make(map[common.Address]common.Address)
+	for pair := range strings.SplitSeq(GetEnv("ORACLE_AGGREGATORS", ""), ",") {
+		token, aggregator, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		if !common.IsHexAddress(token) || !common.IsHexAddress(aggregator) {
+			return nil, fmt.Errorf("invalid oracle aggregator mapping: %s", pair)
+		}
+		aggregators[common.HexToAddress(token)] = common.HexToAddress(aggregator)
+	}
+
+	oracleConfig := OracleConfig{
+		StaticPricesFile: GetEnv("ORACLE_STATIC_PRICES_FILE", ""),
+		Aggregators:      aggregators,
+		MaxPriceAge:      GetEnvInt("ORACLE_MAX_PRICE_AGE", 3600),
+		TWAPWindow:       GetEnvInt("ORACLE_TWAP_WINDOW", 30),
+		SampleInterval:   GetEnvInt("ORACLE_SAMPLE_INTERVAL", 1),
+	}
+
+	solvencyConfig := SolvencyConfig{
+		CoverageWarning:   GetEnvInt("SOLVENCY_COVERAGE_WARNING", 110),
+		CoverageCritical:  GetEnvInt("SOLVENCY_COVERAGE_CRITICAL", 100),
+		LiquidityWarning:  GetEnvInt("SOLVENCY_LIQUIDITY_WARNING", 100),
+		LiquidityCritical: GetEnvInt("SOLVENCY_LIQUIDITY_CRITICAL", 90),
+		SnapshotInterval:  GetEnvInt("SOLVENCY_SNAPSHOT_INTERVAL", 15),
+		Retention:         GetEnvInt("SOLVENCY_RETENTION", 90),
+	}
+
+	// Parse JWT signing keys (format: KID=PATH,KID2=PATH2)
+	signingKeys := make(map[string]string)
+	for pair := range strings.SplitSeq(GetEnv("JWT_SIGNING_KEYS", ""), ",") {
+		kid, path, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		signingKeys[kid] = path
+	}
+
+	// Parse retired JWT signing keys (format: KID=RFC3339,KID2=RFC3339)
+	retiredKeys := make(map[string]time.Time)
+	for pair := range strings.SplitSeq(GetEnv("JWT_RETIRED_KEYS", ""), ",") {
+		kid, retiredAt, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		retiredTime, err := time.Parse(time.RFC3339, retiredAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retirement time for JWT key %s: %w", kid, err)
+		}
+		retiredKeys[kid] = retiredTime
+	}
+
+	// Parse when the HS256 secret stopped signing tokens (RFC3339)
+	var secretRetiredAt time.Time
+	if value := GetEnv("JWT_SECRET_RETIRED_AT", ""); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_SECRET_RETIRED_AT: %w", err)
+		}
+		secretRetiredAt = parsed
 	}
 
 	// Load JWT configuration
+	jwtExpire := GetEnvInt("JWT_EXPIRE", 15)
 	jwtConfig := JWTConfig{
-		Secret:     GetRequiredEnv("JWT_SECRET"),
-		ExpireTime: GetEnvInt("JWT_EXPIRE", 24),
+		Secret:            GetEnv("JWT_SECRET", ""),
+		ExpireTime:        jwtExpire,
+		RefreshExpireTime: GetEnvInt("JWT_REFRESH_EXPIRE", 10080),
+		SigningKeys:       signingKeys,
+		ActiveKey:         GetEnv("JWT_ACTIVE_KEY", ""),
+		RetiredKeys:       retiredKeys,
+		KeyGrace:          GetEnvInt("JWT_KEY_GRACE_PERIOD", jwtExpire),
+		SecretRetiredAt:   secretRetiredAt,
+	}
+	if jwtConfig.ActiveKey == "" && jwtConfig.Secret == "" {
+		return nil, fmt.Errorf("JWT_SECRET is required when no JWT_ACTIVE_KEY is set")
+	}
+	if jwtConfig.ActiveKey != "" && jwtConfig.Secret != "" && jwtConfig.SecretRetiredAt.IsZero() {
+		return nil, fmt.Errorf("JWT_SECRET_RETIRED_AT is required when JWT_SECRET and JWT_ACTIVE_KEY are both set")
+	}
+
+	// Load Sign-In with Ethereum configuration
+	siweConfig := SIWEConfig{
+		Domain:    GetEnv("SIWE_DOMAIN", "localhost:8080"),
+		URI:       GetEnv("SIWE_URI", "http://localhost:8080"),
+		Statement: GetEnv("SIWE_STATEMENT", "Sign in to the Lending & Borrowing platform."),
+		NonceTTL:  GetEnvInt("SIWE_NONCE_TTL", 5),
+	}
+
+	// Parse rate limit policies (format: NAME=REQUESTS/SECONDS,NAME2=REQUESTS/SECONDS)
+	rateLimitPolicies := map[string]RateLimitPolicy{
+		RateLimitDefault:       {Requests: 300, Window: 60},
+		RateLimitAuth:          {Requests: 10, Window: 60},
+		RateLimitMarket:        {Requests: 60, Window: 60},
+		RateLimitAuthenticated: {Requests: 120, Window: 60},
+	}
+	for pair := range strings.SplitSeq(GetEnv("RATE_LIMIT_POLICIES", ""), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		policy, err := parseRateLimitPolicy(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit policy %s: %w", name, err)
+		}
+		rateLimitPolicies[name] = policy
+	}
+
+	rateLimitConfig := RateLimitConfig{
+		Enabled:      GetEnvBool("RATE_LIMIT_ENABLED", true),
+		Policies:     rateLimitPolicies,
+		BanThreshold: GetEnvInt("RATE_LIMIT_BAN_THRESHOLD", 5),
+		BanWindow:    GetEnvInt("RATE_LIMIT_BAN_WINDOW", 15),
+		BanDuration:  GetEnvInt("RATE_LIMIT_BAN_DURATION", 60),
+	}
+
+	privacyConfig := PrivacyConfig{
+		ExportInterval:  GetEnvInt("PRIVACY_EXPORT_INTERVAL", 10),
+		ExportRetention: GetEnvInt("PRIVACY_EXPORT_RETENTION", 7),
+	}
+
+	paginationConfig := PaginationConfig{
+		CursorSecret: GetEnv("CURSOR_SECRET", jwtConfig.Secret),
+	}
+	if paginationConfig.CursorSecret == "" {
+		return nil, fmt.Errorf("CURSOR_SECRET is required when no JWT_SECRET is set")
+	}
+
+	graphQLConfig := GraphQLConfig{
+		MaxDepth:      GetEnvInt("GRAPHQL_MAX_DEPTH", 8),
+		MaxComplexity: GetEnvInt("GRAPHQL_MAX_COMPLEXITY", 1000),
+	}
+
+	liveConfig := LiveConfig{
+		PollInterval:   GetEnvInt("LIVE_POLL_INTERVAL", 2),
+		HealthInterval: GetEnvInt("LIVE_HEALTH_INTERVAL", 30),
+		Heartbeat:      GetEnvInt("LIVE_HEARTBEAT", 25),
+		BufferSize:     GetEnvInt("LIVE_BUFFER_SIZE", 64),
+	}
+
+	bundleConfig := BundleConfig{
+		ResumeInterval: GetEnvInt("BUNDLE_RESUME_INTERVAL", 60),
+	}
+
+	idempotencyConfig := IdempotencyConfig{
+		Retention: GetEnvInt("IDEMPOTENCY_RETENTION", 24),
 	}
 
 	// Load server configuration
@@ -140,13 +413,32 @@ func LoadConfig() (*Config, error) {
 		Port: GetEnvInt("SERVER_PORT", 8080),
 	}
 
+	for _, proxy := range GetEnvArray("TRUSTED_PROXIES", nil) {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			serverConfig.TrustedProxies = append(serverConfig.TrustedProxies, proxy)
+		}
+	}
+	if len(serverConfig.TrustedProxies) > 0 {
+		serverConfig.ProxyHeader = GetEnv("PROXY_HEADER", "X-Forwarded-For")
+	}
+
 	config := &Config{
-		App:        appConfig,
-		Database:   dbConfig,
-		Valkey:     valkeyConfig,
-		Blockchain: blockchainConfig,
-		JWT:        jwtConfig,
-		Server:     serverConfig,
+		App:         appConfig,
+		Database:    dbConfig,
+		Valkey:      valkeyConfig,
+		Blockchain:  blockchainConfig,
+		Oracle:      oracleConfig,
+		Solvency:    solvencyConfig,
+		JWT:         jwtConfig,
+		SIWE:        siweConfig,
+		RateLimit:   rateLimitConfig,
+		Privacy:     privacyConfig,
+		Pagination:  paginationConfig,
+		GraphQL:     graphQLConfig,
+		Live:        liveConfig,
+		Bundle:      bundleConfig,
+		Idempotency: idempotencyConfig,
+		Server:      serverConfig,
 	}
 
 	// Validate configuration
@@ -157,11 +449,65 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
+// parseMarkets groups the LendingPool, Borrowing and Collateral contract addresses by market
+func parseMarkets(contractAddresses map[string]common.Address, defaultMarket string) (map[string]MarketConfig, error) {
+	markets := make(map[string]MarketConfig)
+
+	for name, address := range contractAddresses {
+		market, contract, found := strings.Cut(name, ".")
+		if !found {
+			market, contract = defaultMarket, name
+		}
+
+		marketConfig := markets[market]
+		switch contract {
+		case "LendingPool":
+			marketConfig.LendingPool = address
+		case "Borrowing":
+			marketConfig.Borrowing = address
+		case "Collateral":
+			marketConfig.Collateral = address
+		default:
+			continue
+		}
+		markets[market] = marketConfig
+	}
+
+	for market, marketConfig := range markets {
+		if marketConfig.LendingPool == (common.Address{}) ||
+			marketConfig.Borrowing == (common.Address{}) ||
+			marketConfig.Collateral == (common.Address{}) {
+			return nil, fmt.Errorf("market %s requires LendingPool, Borrowing and Collateral addresses", market)
+		}
+	}
+
+	return markets, nil
+}
+
+// parseRateLimitPolicy parses a "REQUESTS/SECONDS" policy
+func parseRateLimitPolicy(value string) (RateLimitPolicy, error) {
+	requests, window, found := strings.Cut(value, "/")
+	if !found {
+		return RateLimitPolicy{}, fmt.Errorf("expected REQUESTS/SECONDS, got %s", value)
+	}
+
+	policy := RateLimitPolicy{}
+	var err error
+	if policy.Requests, err = strconv.Atoi(requests); err != nil || policy.Requests <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid request count %s", requests)
+	}
+	if policy.Window, err = strconv.Atoi(window); err != nil || policy.Window <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid window %s", window)
+	}
+
+	return policy, nil
+}
+
 func (c *Config) Validate() error {
 	// In production, ensure all security-critical settings are set
 	if c.App.Environment == "production" {
-		// Check JWT secret
-		if c.JWT.Secret == "" || c.JWT.Secret == "your-256-bit-secret" {
+		// Check JWT secret, which can still verify tokens once a signing key is active
+		if c.JWT.Secret == "your-256-bit-secret" || (c.JWT.ActiveKey == "" && c.JWT.Secret == "") {
 			return fmt.Errorf("production environment requires a secure JWT_SECRET")
 		}
 
@@ -176,6 +522,29 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Check JWT signing keys
+	if c.JWT.ActiveKey != "" {
+		if _, found := c.JWT.SigningKeys[c.JWT.ActiveKey]; !found {
+			return fmt.Errorf("active JWT key %s is not in JWT_SIGNING_KEYS", c.JWT.ActiveKey)
+		}
+		if _, retired := c.JWT.RetiredKeys[c.JWT.ActiveKey]; retired {
+			return fmt.Errorf("active JWT key %s is retired", c.JWT.ActiveKey)
+		}
+	}
+	for kid := range c.JWT.RetiredKeys {
+		if _, found := c.JWT.SigningKeys[kid]; !found {
+			return fmt.Errorf("retired JWT key %s is not in JWT_SIGNING_KEYS", kid)
+		}
+	}
+
+	// Check live feed connections can be kept alive and buffered
+	if c.Live.Heartbeat <= 0 {
+		return fmt.Errorf("LIVE_HEARTBEAT must be positive")
+	}
+	if c.Live.BufferSize <= 0 {
+		return fmt.Errorf("LIVE_BUFFER_SIZE must be positive")
+	}
+
 	return nil
 }
 
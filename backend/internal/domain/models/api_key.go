@@ -0,0 +1,127 @@
+package models
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIKeyScope names an operation an API key may perform
+type APIKeyScope string
+
+const (
+	// ScopeReadMarket allows reading market and protocol data
+	ScopeReadMarket APIKeyScope = "read:market"
+	// ScopeReadPositions allows reading the balances, positions and transactions of the key owner
+	ScopeReadPositions APIKeyScope = "read:positions"
+	// ScopeWriteLending allows depositing and withdrawing from lending pools
+	ScopeWriteLending APIKeyScope = "write:lending"
+	// ScopeWriteBorrowing allows borrowing and repaying
+	ScopeWriteBorrowing APIKeyScope = "write:borrowing"
+	// ScopeWriteCollateral allows depositing and withdrawing collateral
+	ScopeWriteCollateral APIKeyScope = "write:collateral"
+	// ScopeLiquidate allows liquidating undercollateralized positions
+	ScopeLiquidate APIKeyScope = "liquidate"
+	// ScopeAdmin allows the privileged operations permitted by the role of the key owner
+	ScopeAdmin APIKeyScope = "admin"
+)
+
+// APIKeyScopes lists every scope an API key can be granted
+var APIKeyScopes = []APIKeyScope{
+	ScopeReadMarket,
+	ScopeReadPositions,
+	ScopeWriteLending,
+	ScopeWriteBorrowing,
+	ScopeWriteCollateral,
+	ScopeLiquidate,
+	ScopeAdmin,
+}
+
+// APIKey is a long-lived credential letting machine clients act on behalf of a user
+// within its scopes. Only a hash of the secret is stored
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"userId" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16);uniqueIndex;not null"` // Public part of the key, used to look it up
+	SecretHash string     `json:"-" gorm:"type:varchar(64);not null"`                  // SHA-256 of the secret part of the key
+	Scopes     string     `json:"scopes" gorm:"type:varchar(255);not null"`            // Comma-separated scopes
+	AllowedIPs string     `json:"allowedIps" gorm:"type:varchar(1024)"`                // Comma-separated IPs or CIDRs, empty allows any
+	ExpiresAt  *time.Time `json:"expiresAt"`                                           // Optional
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP string     `json:"lastUsedIp" gorm:"type:varchar(45)"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// GetScopes returns the scopes granted to the key
+func (k *APIKey) GetScopes() []APIKeyScope {
+	var scopes []APIKeyScope
+	for _, scope := range splitList(k.Scopes) {
+		scopes = append(scopes, APIKeyScope(scope))
+	}
+	return scopes
+}
+
+// HasScope reports whether the key was granted a scope
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	return slices.Contains(k.GetScopes(), scope)
+}
+
+// GetAllowedIPs returns the IPs and CIDRs the key may be used from
+func (k *APIKey) GetAllowedIPs() []string {
+	return splitList(k.AllowedIPs)
+}
+
+// AllowsIP reports whether the key may be used from an IP address
+func (k *APIKey) AllowsIP(ip string) bool {
+	allowed := k.GetAllowedIPs()
+	if len(allowed) == 0 {
+		return true
+	}
+
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(parsedIP) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(parsedIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// PermissionScope returns the scope an API key needs to use a permission of its owner
+func PermissionScope(permission Permission) APIKeyScope {
+	if permission == PermLiquidationExecute {
+		return ScopeLiquidate
+	}
+	return ScopeAdmin
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// AuditAction names an admin or security-sensitive action recorded in the audit log
+type AuditAction string
+
+const (
+	// AuditUserList is recorded when an admin lists users
+	AuditUserList AuditAction = "user.list"
+	// AuditUserRead is recorded when an admin reads a user account
+	AuditUserRead AuditAction = "user.read"
+	// AuditUserVerify is recorded when a user is verified
+	AuditUserVerify AuditAction = "user.verify"
+	// AuditUserRoleAssign is recorded when the role of a user changes
+	AuditUserRoleAssign AuditAction = "user.role_assign"
+	// AuditUserDelete is recorded when a user account is deleted
+	AuditUserDelete AuditAction = "user.delete"
+	// AuditUserExport is recorded when a user requests an export of their personal data
+	AuditUserExport AuditAction = "user.export"
+	// AuditUserDeletionRequest is recorded when a user asks for their account to be purged
+	AuditUserDeletionRequest AuditAction = "user.deletion_request"
+	// AuditUserDeletionReject is recorded when an admin rejects an account deletion request
+	AuditUserDeletionReject AuditAction = "user.deletion_reject"
+	// AuditUserPurge is recorded when an account is purged, after its deletion request is approved
+	AuditUserPurge AuditAction = "user.purge"
+	// AuditAddressLink is recorded when an address is linked to a user account
+	AuditAddressLink AuditAction = "address.link"
+	// AuditAddressUnlink is recorded when an address is unlinked from a user account
+	AuditAddressUnlink AuditAction = "address.unlink"
+	// AuditAddressSetPrimary is recorded when the primary address of a user account changes
+	AuditAddressSetPrimary AuditAction = "address.set_primary"
+	// AuditSignIn is recorded when a user signs in
+	AuditSignIn AuditAction = "auth.sign_in"
+	// AuditSignInFailed is recorded when a sign-in message is rejected
+	AuditSignInFailed AuditAction = "auth.sign_in_failed"
+	// AuditRefreshTokenReuse is recorded when a used refresh token is presented again
+	AuditRefreshTokenReuse AuditAction = "auth.refresh_token_reuse"
+	// AuditSessionRevoke is recorded when a session is revoked
+	AuditSessionRevoke AuditAction = "session.revoke"
+	// AuditSessionRevokeAll is recorded when every session of a user is revoked
+	AuditSessionRevokeAll AuditAction = "session.revoke_all"
+	// AuditAPIKeyCreate is recorded when an API key is issued
+	AuditAPIKeyCreate AuditAction = "api_key.create"
+	// AuditAPIKeyRevoke is recorded when an API key is revoked
+	AuditAPIKeyRevoke AuditAction = "api_key.revoke"
+	// AuditLogRead is recorded when the audit log is queried
+	AuditLogRead AuditAction = "audit.read"
+	// AuditLogExport is recorded when the audit log is exported
+	AuditLogExport AuditAction = "audit.export"
+)
+
+// AuditLog is an append-only record of who did what. Each entry includes the hash of the
+// previous one, so altering or removing an entry breaks the chain
+type AuditLog struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	ActorID      *uint       `json:"actorId" gorm:"index"` // Nil for anonymous requests and background jobs
+	ActorAddress string      `json:"actorAddress" gorm:"type:varchar(42)"`
+	ActorRole    UserRole    `json:"actorRole" gorm:"type:varchar(20)"`
+	APIKeyID     *uint       `json:"apiKeyId"` // Set when the actor used an API key
+	Action       AuditAction `json:"action" gorm:"type:varchar(50);index;not null"`
+	TargetType   string      `json:"targetType" gorm:"type:varchar(50);index:idx_audit_logs_target"`
+	TargetID     string      `json:"targetId" gorm:"type:varchar(100);index:idx_audit_logs_target"`
+	RequestID    string      `json:"requestId" gorm:"type:varchar(64)"`
+	IP           string      `json:"ip" gorm:"type:varchar(45)"`
+	Status       int         `json:"status"`                   // HTTP status, for entries recorded by middleware
+	Changes      string      `json:"changes" gorm:"type:text"` // JSON diff of the target, as written by AuditDiff
+	PrevHash     string      `json:"prevHash" gorm:"type:varchar(64);not null"`
+	Hash         string      `json:"hash" gorm:"type:varchar(64);uniqueIndex;not null"`
+	CreatedAt    time.Time   `json:"createdAt" gorm:"index"`
+}
+
+// AuditActor describes who made a request, for the entries recorded while serving it
+type AuditActor struct {
+	UserID    *uint
+	Address   string
+	Role      UserRole
+	APIKeyID  *uint
+	RequestID string
+	IP        string
+}
+
+// AuditLogFilter selects audit log entries; zero fields match everything
+type AuditLogFilter struct {
+	ActorID    *uint
+	Action     AuditAction
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// ComputeHash returns the hash sealing the entry, covering every field but the ID and the hash itself
+func (l *AuditLog) ComputeHash() string {
+	payload, _ := json.Marshal(struct {
+		ActorID      *uint       `json:"actorId"`
+		ActorAddress string      `json:"actorAddress"`
+		ActorRole    UserRole    `json:"actorRole"`
+		APIKeyID     *uint       `json:"apiKeyId"`
+		Action       AuditAction `json:"action"`
+		TargetType   string      `json:"targetType"`
+		TargetID     string      `json:"targetId"`
+		RequestID    string      `json:"requestId"`
+		IP           string      `json:"ip"`
+		Status       int         `json:"status"`
+		Changes      string      `json:"changes"`
+		PrevHash     string      `json:"prevHash"`
+		CreatedAt    string      `json:"createdAt"`
+	}{
+		ActorID:      l.ActorID,
+		ActorAddress: l.ActorAddress,
+		ActorRole:    l.ActorRole,
+		APIKeyID:     l.APIKeyID,
+		Action:       l.Action,
+		TargetType:   l.TargetType,
+		TargetID:     l.TargetID,
+		RequestID:    l.RequestID,
+		IP:           l.IP,
+		Status:       l.Status,
+		Changes:      l.Changes,
+		PrevHash:     l.PrevHash,
+		CreatedAt:    l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	hash := sha256.Sum256(payload)
+	return hex.EncodeToString(hash[:])
+}
+
+// AuditChange is the before and after value of a changed field
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditDiff returns the fields that differ between two states of a target, as JSON.
+// Either state may be nil, for targets that are created or deleted
+func AuditDiff(before, after any) (string, error) {
+	if before == nil && after == nil {
+		return "", nil
+	}
+
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return "", err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return "", err
+	}
+
+	changes := make(map[string]AuditChange)
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			changes[field] = AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, found := beforeFields[field]; !found {
+			changes[field] = AuditChange{After: value}
+		}
+	}
+
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	diff, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+	return string(diff), nil
+}
+
+// auditFields flattens a value into its JSON fields, so that hidden fields stay out of the log
+func auditFields(value any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if value == nil || reflect.ValueOf(value).IsZero() {
+		return fields, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
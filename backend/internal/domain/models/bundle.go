@@ -0,0 +1,126 @@
+package models
+
+import "time"
+
+// BundleMode is how the steps of a bundle are sent
+type BundleMode string
+
+const (
+	// BundleModeSigner means the platform signs and sends every step, waiting for each receipt
+	BundleModeSigner BundleMode = "signer"
+	// BundleModeUnsigned means the steps are returned as unsigned transactions for the user's wallet to send
+	BundleModeUnsigned BundleMode = "unsigned"
+)
+
+// BundleStatus is the state of a bundle as a whole
+type BundleStatus string
+
+const (
+	// BundlePrepared means the unsigned transactions are waiting to be sent by the user
+	BundlePrepared BundleStatus = "prepared"
+	// BundleRunning means steps are being sent or mined
+	BundleRunning BundleStatus = "running"
+	// BundleCompleted means every step was mined successfully
+	BundleCompleted BundleStatus = "completed"
+	// BundleFailed means a step failed; the steps after it were skipped
+	BundleFailed BundleStatus = "failed"
+)
+
+// BundleAction is an operation a bundle step performs
+type BundleAction string
+
+const (
+	// ActionApprove allows a protocol contract to pull tokens from the user's wallet
+	ActionApprove BundleAction = "approve"
+	// ActionDepositCollateral deposits tokens as collateral
+	ActionDepositCollateral BundleAction = "depositCollateral"
+	// ActionBorrow borrows tokens against the collateral
+	ActionBorrow BundleAction = "borrow"
+	// ActionRepay repays borrowed tokens
+	ActionRepay BundleAction = "repay"
+	// ActionWithdraw withdraws collateral
+	ActionWithdraw BundleAction = "withdraw"
+)
+
+// BundleStepStatus is the state of a bundle step
+type BundleStepStatus string
+
+const (
+	// StepPending means the step has not been sent yet
+	StepPending BundleStepStatus = "pending"
+	// StepSubmitted means the step was sent and is waiting to be mined
+	StepSubmitted BundleStepStatus = "submitted"
+	// StepCompleted means the step was mined successfully
+	StepCompleted BundleStepStatus = "completed"
+	// StepFailed means the step could not be sent or reverted
+	StepFailed BundleStepStatus = "failed"
+	// StepSkipped means an earlier step failed so the step was never sent
+	StepSkipped BundleStepStatus = "skipped"
+)
+
+// Bundle is an ordered list of actions in a market, validated together and tracked as one
+type Bundle struct {
+	ID        uint         `json:"id" gorm:"primaryKey"`
+	UserID    uint         `json:"userId" gorm:"index;not null"`
+	MarketID  uint         `json:"marketId" gorm:"index"`
+	Address   string       `json:"address" gorm:"type:varchar(42);not null"` // Wallet the steps are sent from
+	Mode      BundleMode   `json:"mode" gorm:"type:varchar(10);not null"`
+	Status    BundleStatus `json:"status" gorm:"type:varchar(20);index;not null"`
+	Error     string       `json:"error" gorm:"type:text"`
+	Steps     []BundleStep `json:"steps" gorm:"foreignKey:BundleID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+
+	// Until when the server running a signer bundle holds it; once over, another server resumes the bundle
+	ClaimedUntil *time.Time `json:"-" gorm:"index"`
+}
+
+// BundleStep is one action of a bundle and the transaction that performs it
+type BundleStep struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	BundleID     uint             `json:"bundleId" gorm:"uniqueIndex:idx_bundle_steps_position;not null"`
+	Position     int              `json:"position" gorm:"uniqueIndex:idx_bundle_steps_position;not null"` // Order in the bundle, from 0
+	Action       BundleAction     `json:"action" gorm:"type:varchar(20);not null"`
+	Amount       string           `json:"amount" gorm:"type:varchar(78);not null"` // Big numbers stored as strings
+	Spender      string           `json:"spender" gorm:"type:varchar(42)"`         // Contract allowed to pull tokens, for approvals
+	Status       BundleStepStatus `json:"status" gorm:"type:varchar(20);not null"`
+	To           string           `json:"to" gorm:"type:varchar(42)"`
+	Data         string           `json:"data" gorm:"type:text"` // Hex encoded calldata
+	Nonce        uint64           `json:"nonce"`                 // Of unsigned transactions
+	Hash         string           `json:"hash" gorm:"type:varchar(66);index"`
+	BlockNumber  uint64           `json:"blockNumber"`
+	GasUsed      uint64           `json:"gasUsed"`
+	ErrorMessage string           `json:"errorMessage" gorm:"type:text"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+}
+
+// IsValid reports whether the mode is a known bundle mode
+func (m BundleMode) IsValid() bool {
+	return m == BundleModeSigner || m == BundleModeUnsigned
+}
+
+// IsValid reports whether the action is a known bundle action
+func (a BundleAction) IsValid() bool {
+	switch a {
+	case ActionApprove, ActionDepositCollateral, ActionBorrow, ActionRepay, ActionWithdraw:
+		return true
+	}
+	return false
+}
+
+// TransactionType returns the ledger type of the transactions performing the action,
+// false for approvals, which are not recorded in the ledger
+func (a BundleAction) TransactionType() (TransactionType, bool) {
+	switch a {
+	case ActionDepositCollateral:
+		return TransactionCollateralDeposit, true
+	case ActionBorrow:
+		return TransactionBorrow, true
+	case ActionRepay:
+		return TransactionRepay, true
+	case ActionWithdraw:
+		return TransactionCollateralWithdraw, true
+	}
+	return "", false
+}
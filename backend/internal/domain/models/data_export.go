@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// DataExportFormat is the archive format of a personal data export
+type DataExportFormat string
+
+const (
+	// ExportFormatJSON is a single JSON document
+	ExportFormatJSON DataExportFormat = "json"
+	// ExportFormatZIP is a ZIP archive with one JSON file per section
+	ExportFormatZIP DataExportFormat = "zip"
+)
+
+// DataExportStatus is the state of a personal data export
+type DataExportStatus string
+
+const (
+	// ExportPending means the export is waiting for the worker
+	ExportPending DataExportStatus = "pending"
+	// ExportProcessing means the worker is assembling the archive
+	ExportProcessing DataExportStatus = "processing"
+	// ExportReady means the archive can be downloaded
+	ExportReady DataExportStatus = "ready"
+	// ExportFailed means the archive could not be assembled
+	ExportFailed DataExportStatus = "failed"
+)
+
+// DataExport is a copy of the personal data of a user, assembled in the background
+type DataExport struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	UserID      uint             `json:"userId" gorm:"index;not null"`
+	Format      DataExportFormat `json:"format" gorm:"type:varchar(10);not null"`
+	Status      DataExportStatus `json:"status" gorm:"type:varchar(20);index;not null;default:'pending'"`
+	Archive     []byte           `json:"-" gorm:"type:bytea"`
+	Size        int64            `json:"size"` // In bytes, once ready
+	Error       string           `json:"error" gorm:"type:text"`
+	ExpiresAt   *time.Time       `json:"expiresAt" gorm:"index"` // When a ready archive is deleted
+	CompletedAt *time.Time       `json:"completedAt"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+// IsValid reports whether the format is supported
+func (f DataExportFormat) IsValid() bool {
+	return f == ExportFormatJSON || f == ExportFormatZIP
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// DeletionRequestStatus is the state of an account deletion request
+type DeletionRequestStatus string
+
+const (
+	// DeletionPending means the request awaits review by an admin
+	DeletionPending DeletionRequestStatus = "pending"
+	// DeletionApproved means the account was purged
+	DeletionApproved DeletionRequestStatus = "approved"
+	// DeletionRejected means an admin declined the request
+	DeletionRejected DeletionRequestStatus = "rejected"
+)
+
+// DeletionRequest is a request from a user to have their account purged, which an admin must approve
+type DeletionRequest struct {
+	ID         uint                  `json:"id" gorm:"primaryKey"`
+	UserID     uint                  `json:"userId" gorm:"index;not null"`
+	Reason     string                `json:"reason" gorm:"type:text"`
+	Status     DeletionRequestStatus `json:"status" gorm:"type:varchar(20);index;not null;default:'pending'"`
+	ReviewedBy *uint                 `json:"reviewedBy"`
+	ReviewNote string                `json:"reviewNote" gorm:"type:text"`
+	ReviewedAt *time.Time            `json:"reviewedAt"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	UpdatedAt  time.Time             `json:"updatedAt"`
+}
+
+// DeletionRequestFilter selects deletion requests; zero fields match everything
+type DeletionRequestFilter struct {
+	Statuses []DeletionRequestStatus
+	From     *time.Time // Submission date, inclusive
+	To       *time.Time // Submission date, exclusive
+}
+
+// IsValid reports whether the status is a known deletion request status
+func (s DeletionRequestStatus) IsValid() bool {
+	return s == DeletionPending || s == DeletionApproved || s == DeletionRejected
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Market is a set of protocol contracts sharing the same underlying token
+type Market struct {
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	Identifier         string    `json:"identifier" gorm:"type:varchar(50);uniqueIndex;not null"` // Short name used in routes, e.g. "lbt"
+	Name               string    `json:"name" gorm:"type:varchar(100)"`
+	TokenAddress       string    `json:"tokenAddress" gorm:"type:varchar(42);not null"` // Underlying token, read from the contracts
+	LendingPoolAddress string    `json:"lendingPoolAddress" gorm:"type:varchar(42);uniqueIndex;not null"`
+	BorrowingAddress   string    `json:"borrowingAddress" gorm:"type:varchar(42);not null"`
+	CollateralAddress  string    `json:"collateralAddress" gorm:"type:varchar(42);not null"`
+	Active             bool      `json:"active" gorm:"not null"` // Set on every write, so inactive markets can be created
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// GetTokenAddress returns the underlying token address as a common.Address
+func (m *Market) GetTokenAddress() common.Address {
+	return common.HexToAddress(m.TokenAddress)
+}
+
+// GetLendingPoolAddress returns the lending pool contract address as a common.Address
+func (m *Market) GetLendingPoolAddress() common.Address {
+	return common.HexToAddress(m.LendingPoolAddress)
+}
+
+// GetBorrowingAddress returns the borrowing contract address as a common.Address
+func (m *Market) GetBorrowingAddress() common.Address {
+	return common.HexToAddress(m.BorrowingAddress)
+}
+
+// GetCollateralAddress returns the collateral contract address as a common.Address
+func (m *Market) GetCollateralAddress() common.Address {
+	return common.HexToAddress(m.CollateralAddress)
+}
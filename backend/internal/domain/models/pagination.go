@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Cursor is a position in a list ordered by creation date then ID. Unlike an offset, it keeps pointing at
+// the same row while rows are inserted before it
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Backward  bool // Read the page before the position instead of the page after it
+}
@@ -0,0 +1,80 @@
+package models
+
+import "slices"
+
+// Permission names an operation that is only allowed to some roles
+type Permission string
+
+const (
+	// PermUsersRead allows reading any user account
+	PermUsersRead Permission = "users.read"
+	// PermUsersVerify allows verifying user accounts
+	PermUsersVerify Permission = "users.verify"
+	// PermUsersDelete allows deleting any user account
+	PermUsersDelete Permission = "users.delete"
+	// PermUsersPurge allows reviewing account deletion requests, approving one purges the account
+	PermUsersPurge Permission = "users.purge"
+	// PermUsersAssignRoles allows changing the role of any user
+	PermUsersAssignRoles Permission = "users.assign_roles"
+	// PermPositionsReadAll allows reading the positions and collateral of every user
+	PermPositionsReadAll Permission = "positions.read_all"
+	// PermLiquidationExecute allows liquidating undercollateralized positions
+	PermLiquidationExecute Permission = "liquidation.execute"
+	// PermSystemRead allows reading protocol health reports, such as solvency
+	PermSystemRead Permission = "system.read"
+	// PermSystemConfigure allows changing protocol and platform settings
+	PermSystemConfigure Permission = "system.configure"
+	// PermAuditRead allows querying, exporting and verifying the audit log
+	PermAuditRead Permission = "audit.read"
+)
+
+// RolePermissions maps each built-in role to the permissions it grants.
+// Every authenticated user can manage their own account and positions without any permission
+var RolePermissions = map[UserRole][]Permission{
+	RoleUser: {},
+	RoleAdmin: {
+		PermUsersRead,
+		PermUsersVerify,
+		PermUsersDelete,
+		PermUsersPurge,
+		PermUsersAssignRoles,
+		PermPositionsReadAll,
+		PermLiquidationExecute,
+		PermSystemRead,
+		PermSystemConfigure,
+		PermAuditRead,
+	},
+	RoleOperator: {
+		PermUsersRead,
+		PermUsersVerify,
+		PermPositionsReadAll,
+		PermLiquidationExecute,
+		PermSystemRead,
+		PermSystemConfigure,
+	},
+	RoleAuditor: {
+		PermUsersRead,
+		PermPositionsReadAll,
+		PermSystemRead,
+		PermAuditRead,
+	},
+	RoleLiquidator: {
+		PermLiquidationExecute,
+	},
+}
+
+// IsValid reports whether the role is a built-in role
+func (r UserRole) IsValid() bool {
+	_, found := RolePermissions[r]
+	return found
+}
+
+// Permissions returns the permissions granted by the role
+func (r UserRole) Permissions() []Permission {
+	return RolePermissions[r]
+}
+
+// HasPermission reports whether the role grants a permission
+func (r UserRole) HasPermission(permission Permission) bool {
+	return slices.Contains(RolePermissions[r], permission)
+}
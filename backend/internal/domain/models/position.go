@@ -22,9 +22,10 @@ const (
 
 // Position represents a user's lending or borrowing position
 type Position struct {
-	ID                 uint           `json:"id" gorm:"primaryKey"`
+	ID                 uint           `json:"id" gorm:"primaryKey;index:idx_positions_created_at_id,priority:2"`
 	UserID             uint           `json:"userId" gorm:"index;not null"`
 	User               *User          `json:"user" gorm:"foreignKey:UserID"`
+	MarketID           uint           `json:"marketId" gorm:"index"`                             // Made NOT NULL by database.BackfillMarketIDs
 	CollateralAmount   string         `json:"collateralAmount" gorm:"type:varchar(78);not null"` // Big numbers stored as strings
 	CollateralToken    string         `json:"collateralToken" gorm:"type:varchar(42);not null"`
 	BorrowedAmount     string         `json:"borrowedAmount" gorm:"type:varchar(78);not null"` // Big numbers stored as strings
@@ -34,11 +35,46 @@ type Position struct {
 	Status             PositionStatus `json:"status" gorm:"type:varchar(20);default:'active'"`
 	HealthFactor       string         `json:"healthFactor" gorm:"type:varchar(78)"`     // Current health factor of the position
 	LiquidationPrice   string         `json:"liquidationPrice" gorm:"type:varchar(78)"` // Price at which position is liquidated
-	CreatedAt          time.Time      `json:"createdAt"`
+	CreatedAt          time.Time      `json:"createdAt" gorm:"index:idx_positions_created_at_id,priority:1"`
 	UpdatedAt          time.Time      `json:"updatedAt"`
 	DeletedAt          gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
 
+// PositionSort is the order positions are listed in
+type PositionSort string
+
+const (
+	// SortByCreatedAt orders positions by opening date
+	SortByCreatedAt PositionSort = "createdAt"
+	// SortByHealthFactor orders positions by health factor
+	SortByHealthFactor PositionSort = "healthFactor"
+	// SortBySize orders positions by borrowed amount
+	SortBySize PositionSort = "size"
+)
+
+// PositionFilter selects and orders positions; zero fields match everything
+type PositionFilter struct {
+	UserID          *uint
+	MarketID        *uint
+	Statuses        []PositionStatus
+	MinHealthFactor string     // Decimal, in the unit of HealthFactor, inclusive
+	MaxHealthFactor string     // Decimal, in the unit of HealthFactor, inclusive
+	From            *time.Time // Opening date, inclusive
+	To              *time.Time // Opening date, exclusive
+	SortBy          PositionSort
+	Ascending       bool // Positions are listed in descending order by default
+}
+
+// IsValid reports whether the status is a known position status
+func (s PositionStatus) IsValid() bool {
+	return s == StatusActive || s == StatusLiquidated || s == StatusClosed
+}
+
+// IsValid reports whether positions can be sorted that way
+func (s PositionSort) IsValid() bool {
+	return s == SortByCreatedAt || s == SortByHealthFactor || s == SortBySize
+}
+
 // CollateralBigInt converts the collateral amount to a big.Int
 func (p *Position) CollateralBigInt() (*big.Int, bool) {
 	amount := new(big.Int)
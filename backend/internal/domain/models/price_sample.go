@@ -0,0 +1,30 @@
+package models
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PriceSample is a USD price observed for a token at a point in time, used to build TWAPs
+type PriceSample struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TokenAddress string    `json:"tokenAddress" gorm:"type:varchar(42);index:idx_price_samples_token_time;not null"`
+	Price        string    `json:"price" gorm:"type:varchar(78);not null"` // USD price in 1e18 precision stored as string
+	Source       string    `json:"source" gorm:"type:varchar(50)"`
+	SampledAt    time.Time `json:"sampledAt" gorm:"index:idx_price_samples_token_time;not null"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// PriceBigInt converts the sampled price to a big.Int
+func (s *PriceSample) PriceBigInt() (*big.Int, bool) {
+	price := new(big.Int)
+	price, success := price.SetString(s.Price, 10)
+	return price, success
+}
+
+// GetTokenAddress returns the token address as a common.Address
+func (s *PriceSample) GetTokenAddress() common.Address {
+	return common.HexToAddress(s.TokenAddress)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// SolvencyStatus defines how well the protocol covers what it owes
+type SolvencyStatus string
+
+const (
+	// SolvencyHealthy means every threshold is met
+	SolvencyHealthy SolvencyStatus = "healthy"
+	// SolvencyWarning means a warning threshold has been crossed
+	SolvencyWarning SolvencyStatus = "warning"
+	// SolvencyCritical means a critical threshold has been crossed
+	SolvencyCritical SolvencyStatus = "critical"
+)
+
+// SolvencySnapshot records the solvency figures of a market at a point in time
+type SolvencySnapshot struct {
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	MarketID            uint           `json:"marketId" gorm:"index:idx_solvency_snapshots_market_time;not null"`
+	LendingLiability    string         `json:"lendingLiability" gorm:"type:varchar(78);not null"` // Big numbers stored as strings
+	LenderInterest      string         `json:"lenderInterest" gorm:"type:varchar(79);not null"`
+	CollateralLiability string         `json:"collateralLiability" gorm:"type:varchar(78);not null"`
+	LendingPoolBalance  string         `json:"lendingPoolBalance" gorm:"type:varchar(78);not null"`
+	BorrowingBalance    string         `json:"borrowingBalance" gorm:"type:varchar(78);not null"`
+	CollateralBalance   string         `json:"collateralBalance" gorm:"type:varchar(78);not null"`
+	TotalBorrowed       string         `json:"totalBorrowed" gorm:"type:varchar(78);not null"`
+	BorrowInterest      string         `json:"borrowInterest" gorm:"type:varchar(78);not null"`
+	NetReserves         string         `json:"netReserves" gorm:"type:varchar(79);not null"` // Signed, negative when liabilities exceed assets
+	CoverageRatio       string         `json:"coverageRatio" gorm:"type:varchar(78);not null"`
+	LiquidityRatio      string         `json:"liquidityRatio" gorm:"type:varchar(78);not null"`
+	Status              SolvencyStatus `json:"status" gorm:"type:varchar(20);not null"`
+	BlockNumber         uint64         `json:"blockNumber"`
+	CreatedAt           time.Time      `json:"createdAt" gorm:"index:idx_solvency_snapshots_market_time"`
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"math/big"
+	"slices"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,10 +13,14 @@ import (
 type TransactionType string
 
 const (
-	// TransactionDeposit represents a deposit of collateral
+	// TransactionDeposit represents a deposit into the lending pool
 	TransactionDeposit TransactionType = "deposit"
-	// TransactionWithdraw represents a withdrawal of collateral
+	// TransactionWithdraw represents a withdrawal from the lending pool
 	TransactionWithdraw TransactionType = "withdraw"
+	// TransactionCollateralDeposit represents a deposit of collateral
+	TransactionCollateralDeposit TransactionType = "collateral_deposit"
+	// TransactionCollateralWithdraw represents a withdrawal of collateral
+	TransactionCollateralWithdraw TransactionType = "collateral_withdraw"
 	// TransactionBorrow represents borrowing tokens
 	TransactionBorrow TransactionType = "borrow"
 	// TransactionRepay represents repaying borrowed tokens
@@ -24,6 +29,31 @@ const (
 	TransactionLiquidate TransactionType = "liquidate"
 )
 
+// TransactionContract is the protocol contract a transaction was sent to
+type TransactionContract string
+
+const (
+	// ContractLendingPool receives deposits and withdrawals of lenders
+	ContractLendingPool TransactionContract = "lending_pool"
+	// ContractBorrowing receives borrows and repayments
+	ContractBorrowing TransactionContract = "borrowing"
+	// ContractCollateral receives collateral moves and liquidations
+	ContractCollateral TransactionContract = "collateral"
+)
+
+// TransactionRole is the part a user played in a transaction.
+// A transaction has one ledger entry per role, all sharing its hash
+type TransactionRole string
+
+const (
+	// TxRoleOwner is the user who sent the transaction on their own behalf
+	TxRoleOwner TransactionRole = "owner"
+	// TxRoleLiquidator is the user who repaid the debt of a liquidated position
+	TxRoleLiquidator TransactionRole = "liquidator"
+	// TxRoleBorrower is the user whose position was liquidated
+	TxRoleBorrower TransactionRole = "borrower"
+)
+
 // TransactionStatus defines the status of a blockchain transaction
 type TransactionStatus string
 
@@ -38,23 +68,111 @@ const (
 
 // Transaction represents a blockchain transaction in the platform
 type Transaction struct {
-	ID           uint              `json:"id" gorm:"primaryKey"`
+	ID           uint              `json:"id" gorm:"primaryKey;index:idx_transactions_created_at_id,priority:2"`
 	UserID       uint              `json:"userId" gorm:"index;not null"`
 	User         *User             `json:"user" gorm:"foreignKey:UserID"`
+	MarketID     uint              `json:"marketId" gorm:"index"` // Made NOT NULL by database.BackfillMarketIDs
 	Type         TransactionType   `json:"type" gorm:"type:varchar(20);not null"`
 	Status       TransactionStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
-	Hash         string            `json:"hash" gorm:"type:varchar(66);unique"`
+	Role         TransactionRole   `json:"role" gorm:"type:varchar(20);not null;default:'owner';uniqueIndex:idx_transactions_hash_role"`
+	Hash         string            `json:"hash" gorm:"type:varchar(66);uniqueIndex:idx_transactions_hash_role,priority:1"`
 	BlockNumber  uint64           `json:"blockNumber"`
 	Amount       string            `json:"amount" gorm:"type:varchar(78);not null"` // Big numbers stored as strings
 	TokenAddress string            `json:"tokenAddress" gorm:"type:varchar(42);not null"`
 	GasUsed      uint64           `json:"gasUsed"`
 	GasPrice     string            `json:"gasPrice" gorm:"type:varchar(78)"` // Big numbers stored as strings
 	ErrorMessage string            `json:"errorMessage" gorm:"type:text"`
-	CreatedAt    time.Time         `json:"createdAt"`
+	CreatedAt    time.Time         `json:"createdAt" gorm:"index:idx_transactions_created_at_id,priority:1"`
 	UpdatedAt    time.Time         `json:"updatedAt"`
 	DeletedAt    gorm.DeletedAt    `json:"deletedAt" gorm:"index"`
 }
 
+// TransactionFilter selects transactions, most recent first; zero fields match everything
+type TransactionFilter struct {
+	UserID   *uint
+	MarketID *uint
+	Types    []TransactionType
+	Statuses []TransactionStatus
+	Roles    []TransactionRole
+	From     *time.Time // Inclusive
+	To       *time.Time // Exclusive
+}
+
+// RestrictTypes narrows the filter to the allowed types, keeping those requested among them.
+// It reports false when no requested type is allowed, in which case nothing can match
+func (f *TransactionFilter) RestrictTypes(allowed []TransactionType) bool {
+	if len(f.Types) == 0 {
+		f.Types = allowed
+		return true
+	}
+
+	var types []TransactionType
+	for _, t := range f.Types {
+		if slices.Contains(allowed, t) {
+			types = append(types, t)
+		}
+	}
+
+	f.Types = types
+	return len(types) > 0
+}
+
+// transactionContracts maps each transaction type to the contract it is sent to
+var transactionContracts = map[TransactionType]TransactionContract{
+	TransactionDeposit:            ContractLendingPool,
+	TransactionWithdraw:           ContractLendingPool,
+	TransactionBorrow:             ContractBorrowing,
+	TransactionRepay:              ContractBorrowing,
+	TransactionCollateralDeposit:  ContractCollateral,
+	TransactionCollateralWithdraw: ContractCollateral,
+	TransactionLiquidate:          ContractCollateral,
+}
+
+// IsValid reports whether the type is a known transaction type
+func (t TransactionType) IsValid() bool {
+	_, found := transactionContracts[t]
+	return found
+}
+
+// Contract returns the contract transactions of this type are sent to
+func (t TransactionType) Contract() TransactionContract {
+	return transactionContracts[t]
+}
+
+// IsValid reports whether the contract is a known protocol contract
+func (c TransactionContract) IsValid() bool {
+	return c == ContractLendingPool || c == ContractBorrowing || c == ContractCollateral
+}
+
+// Types returns the transaction types sent to the contract
+func (c TransactionContract) Types() []TransactionType {
+	var types []TransactionType
+	for _, t := range []TransactionType{
+		TransactionDeposit,
+		TransactionWithdraw,
+		TransactionBorrow,
+		TransactionRepay,
+		TransactionCollateralDeposit,
+		TransactionCollateralWithdraw,
+		TransactionLiquidate,
+	} {
+		if t.Contract() == c {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// IsValid reports whether the role is a known transaction role
+func (r TransactionRole) IsValid() bool {
+	return r == TxRoleOwner || r == TxRoleLiquidator || r == TxRoleBorrower
+}
+
+// IsValid reports whether the status is a known transaction status
+func (s TransactionStatus) IsValid() bool {
+	return s == StatusPending || s == StatusCompleted || s == StatusFailed
+}
+
 // BigIntAmount converts the amount string to a big.Int
 func (t *Transaction) BigIntAmount() (*big.Int, bool) {
 	amount := new(big.Int)
@@ -14,18 +14,32 @@ const (
 	RoleUser UserRole = "user"
 	// RoleAdmin is an admin user with special privileges
 	RoleAdmin UserRole = "admin"
+	// RoleOperator runs the platform day to day, without managing roles
+	RoleOperator UserRole = "operator"
+	// RoleAuditor has read-only access across users and protocol reports
+	RoleAuditor UserRole = "auditor"
+	// RoleLiquidator can liquidate undercollateralized positions
+	RoleLiquidator UserRole = "liquidator"
 )
 
 // User represents a user in the lending/borrowing platform
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
+	ID        uint           `json:"id" gorm:"primaryKey;index:idx_users_created_at_id,priority:2"`
 	Address   string         `json:"address" gorm:"type:varchar(42);unique;not null"`
 	Username  string         `json:"username" gorm:"type:varchar(50);unique"`
 	Role      UserRole       `json:"role" gorm:"type:varchar(20);default:'user'"`
 	Verified  bool           `json:"verified" gorm:"default:false"`
-	Nonce     string         `json:"nonce" gorm:"type:varchar(100)"` // For signature verification
 	LastLogin *time.Time     `json:"lastLogin"`
-	CreatedAt time.Time      `json:"createdAt"`
+	CreatedAt time.Time      `json:"createdAt" gorm:"index:idx_users_created_at_id,priority:1"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
+
+// UserFilter selects users; zero fields match everything
+type UserFilter struct {
+	Roles          []UserRole
+	Verified       *bool
+	From           *time.Time // Registration date, inclusive
+	To             *time.Time // Registration date, exclusive
+	LastLoginAfter *time.Time // Inclusive
+}
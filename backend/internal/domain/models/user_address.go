@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// UserAddress is a wallet address linked to a user account. Every account has one primary
+// address, mirrored in User.Address, and any number of secondary ones
+type UserAddress struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"userId" gorm:"index;not null"`
+	Address   string    `json:"address" gorm:"type:varchar(42);uniqueIndex;not null"`
+	IsPrimary bool      `json:"primary" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"createdAt"`
+}
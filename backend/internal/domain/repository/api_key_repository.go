@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// APIKeyRepository defines the interface for API key data access
+type APIKeyRepository interface {
+	// Create inserts a new API key into the database
+	Create(ctx context.Context, key *models.APIKey) error
+
+	// FindByID retrieves an API key by ID
+	FindByID(ctx context.Context, id uint) (*models.APIKey, error)
+
+	// FindByPrefix retrieves an API key by its public prefix
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+
+	// ListByUser retrieves the API keys of a user, including revoked ones
+	ListByUser(ctx context.Context, userID uint) ([]*models.APIKey, error)
+
+	// Revoke marks an API key as revoked
+	Revoke(ctx context.Context, id uint, revokedAt time.Time) error
+
+	// UpdateLastUsed records when and from where an API key was last used
+	UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time, ip string) error
+}
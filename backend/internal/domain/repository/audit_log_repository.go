@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// AuditLogRepository defines the interface for audit log data access. Entries can only be appended
+type AuditLogRepository interface {
+	// Append chains an entry to the last one, seals it with its hash and inserts it
+	Append(ctx context.Context, entry *models.AuditLog) error
+
+	// List retrieves the entries matching a filter, newest first
+	List(ctx context.Context, filter models.AuditLogFilter, offset, limit int) ([]*models.AuditLog, error)
+
+	// Count returns the number of entries matching a filter
+	Count(ctx context.Context, filter models.AuditLogFilter) (int64, error)
+
+	// ListAfter retrieves up to limit entries matching a filter with an ID above afterID, oldest first
+	ListAfter(ctx context.Context, filter models.AuditLogFilter, afterID uint, limit int) ([]*models.AuditLog, error)
+}
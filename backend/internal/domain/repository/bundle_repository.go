@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// BundleRepository defines the interface for action bundle access
+type BundleRepository interface {
+	// Create inserts a new bundle and its steps into the database
+	Create(ctx context.Context, bundle *models.Bundle) error
+	// FindByID retrieves a bundle by ID with its steps in order
+	FindByID(ctx context.Context, id uint) (*models.Bundle, error)
+	// ListByUser retrieves the bundles of a user with their steps, newest first, with pagination
+	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*models.Bundle, error)
+	// CountByUser returns the number of bundles of a user
+	CountByUser(ctx context.Context, userID uint) (int64, error)
+	// Update updates an existing bundle, without its steps
+	Update(ctx context.Context, bundle *models.Bundle) error
+	// UpdateStep updates an existing bundle step
+	UpdateStep(ctx context.Context, step *models.BundleStep) error
+	// ClaimUnheld marks the running signer bundles that no server holds, or whose claim expired, as claimed
+	// until a given time and returns them with their steps, oldest first
+	ClaimUnheld(ctx context.Context, until time.Time) ([]*models.Bundle, error)
+}
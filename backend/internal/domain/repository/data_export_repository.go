@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// DataExportRepository defines the interface for personal data export access
+type DataExportRepository interface {
+	// Create inserts a new export into the database
+	Create(ctx context.Context, export *models.DataExport) error
+	// FindByID retrieves an export by ID, including its archive
+	FindByID(ctx context.Context, id uint) (*models.DataExport, error)
+	// ListByUser retrieves the exports of a user without their archives, newest first
+	ListByUser(ctx context.Context, userID uint) ([]*models.DataExport, error)
+	// ClaimPending marks up to limit pending exports, and those left processing since before staleBefore,
+	// as processing and returns them
+	ClaimPending(ctx context.Context, staleBefore time.Time, limit int) ([]*models.DataExport, error)
+	// Update updates an existing export
+	Update(ctx context.Context, export *models.DataExport) error
+	// DeleteExpired removes the exports whose archive expired before a given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
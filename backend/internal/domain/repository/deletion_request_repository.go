@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// DeletionRequestRepository defines the interface for account deletion request data access
+type DeletionRequestRepository interface {
+	// Create inserts a new deletion request into the database
+	Create(ctx context.Context, request *models.DeletionRequest) error
+	// FindByID retrieves a deletion request by ID
+	FindByID(ctx context.Context, id uint) (*models.DeletionRequest, error)
+	// FindLatestByUser retrieves the most recent deletion request of a user
+	FindLatestByUser(ctx context.Context, userID uint) (*models.DeletionRequest, error)
+	// List retrieves deletion requests with optional filtering and pagination, oldest first
+	List(ctx context.Context, filter models.DeletionRequestFilter, offset, limit int) ([]*models.DeletionRequest, error)
+	// Count returns the number of deletion requests matching the filter
+	Count(ctx context.Context, filter models.DeletionRequestFilter) (int64, error)
+	// Update updates an existing deletion request
+	Update(ctx context.Context, request *models.DeletionRequest) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// MarketRepository defines the interface for market data access
+type MarketRepository interface {
+	// Create inserts a new market into the database
+	Create(ctx context.Context, market *models.Market) error
+
+	// FindByID retrieves a market by ID
+	FindByID(ctx context.Context, id uint) (*models.Market, error)
+
+	// FindByIdentifier retrieves a market by its route identifier
+	FindByIdentifier(ctx context.Context, identifier string) (*models.Market, error)
+
+	// Update updates an existing market
+	Update(ctx context.Context, market *models.Market) error
+
+	// ListActive retrieves all active markets
+	ListActive(ctx context.Context) ([]*models.Market, error)
+}
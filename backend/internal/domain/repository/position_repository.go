@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 )
@@ -20,18 +21,28 @@ type PositionRepository interface {
 	// FindActiveByUserID retrieves all active positions for a specific user
 	FindActiveByUserID(ctx context.Context, userID uint) ([]*models.Position, error)
 
+	// FindActiveByUserAndMarket retrieves the active positions of a user in a market
+	FindActiveByUserAndMarket(ctx context.Context, userID, marketID uint) ([]*models.Position, error)
+
 	// Update updates an existing position
 	Update(ctx context.Context, position *models.Position) error
 
 	// UpdateStatus updates the status of a position
 	UpdateStatus(ctx context.Context, id uint, status models.PositionStatus) error
 
-	// FindAtRisk finds all positions at risk of liquidation
-	FindAtRisk(ctx context.Context, healthFactorThreshold string) ([]*models.Position, error)
+	// FindAtRisk finds all positions of a market at risk of liquidation
+	FindAtRisk(ctx context.Context, marketID uint, healthFactorThreshold string) ([]*models.Position, error)
+
+	// List retrieves the positions matching a filter, in its order, with pagination
+	List(ctx context.Context, filter models.PositionFilter, offset, limit int) ([]*models.Position, error)
+
+	// ListByCursor retrieves up to limit positions matching a filter, by opening date in the filter's direction,
+	// from a cursor. A nil cursor starts at the first page; the filter's sort order is ignored
+	ListByCursor(ctx context.Context, filter models.PositionFilter, cursor *models.Cursor, limit int) ([]*models.Position, error)
 
-	// List retrieves all positions with optional filtering and pagination
-	List(ctx context.Context, filter map[string]any, offset, limit int) ([]*models.Position, error)
+	// SumCurrentCollateral returns the sum of the latest recorded collateral amount of every user in a market
+	SumCurrentCollateral(ctx context.Context, marketID uint) (*big.Int, error)
 
-	// Count returns the total number of positions matching the filter
-	Count(ctx context.Context, filter map[string]any) (int64, error)
+	// Count returns the number of positions matching a filter
+	Count(ctx context.Context, filter models.PositionFilter) (int64, error)
 }
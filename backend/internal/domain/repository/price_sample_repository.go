@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// PriceSampleRepository defines the interface for price sample data access
+type PriceSampleRepository interface {
+	// Create inserts a new price sample into the database
+	Create(ctx context.Context, sample *models.PriceSample) error
+
+	// FindInRange retrieves the samples of a token taken within [from, to], oldest first
+	FindInRange(ctx context.Context, tokenAddress string, from, to time.Time) ([]*models.PriceSample, error)
+
+	// FindLatestBefore retrieves the most recent sample of a token taken before a given time
+	FindLatestBefore(ctx context.Context, tokenAddress string, before time.Time) (*models.PriceSample, error)
+
+	// DeleteOlderThan removes every sample taken before a given time
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
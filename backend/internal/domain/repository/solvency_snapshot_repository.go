@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// SolvencySnapshotRepository defines the interface for solvency snapshot data access
+type SolvencySnapshotRepository interface {
+	// Create inserts a new solvency snapshot into the database
+	Create(ctx context.Context, snapshot *models.SolvencySnapshot) error
+
+	// FindInRange retrieves the snapshots of a market taken within [from, to], oldest first
+	FindInRange(ctx context.Context, marketID uint, from, to time.Time) ([]*models.SolvencySnapshot, error)
+
+	// DeleteOlderThan removes every snapshot taken before a given time
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 )
@@ -14,18 +15,26 @@ type TransactionRepository interface {
 	// FindByID retrieves a transaction by ID
 	FindByID(ctx context.Context, id uint) (*models.Transaction, error)
 
-	// FindByHash retrieves a transaction by blockchain transaction hash
-	FindByHash(ctx context.Context, hash string) (*models.Transaction, error)
+	// FindByHash retrieves the ledger entries of a blockchain transaction, one per role
+	FindByHash(ctx context.Context, hash string) ([]*models.Transaction, error)
 
 	// FindByUserID retrieves all transactions for a specific user
 	FindByUserID(ctx context.Context, userID uint, offset, limit int) ([]*models.Transaction, error)
 
+	// FindByUserAndMarket retrieves the transactions of some types made by a user in a market within
+	// a time window, oldest first. A nil end leaves the window open
+	FindByUserAndMarket(ctx context.Context, userID, marketID uint, types []models.TransactionType, from time.Time, to *time.Time) ([]*models.Transaction, error)
+
 	// Update updates an existing transaction
 	Update(ctx context.Context, transaction *models.Transaction) error
 
-	// List retrieves all transactions with optional filtering and pagination
-	List(ctx context.Context, filter map[string]any, offset, limit int) ([]*models.Transaction, error)
+	// List retrieves the transactions matching a filter, most recent first, with pagination
+	List(ctx context.Context, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error)
+
+	// ListByCursor retrieves up to limit transactions matching a filter, most recent first, from a cursor.
+	// A nil cursor starts at the first page
+	ListByCursor(ctx context.Context, filter models.TransactionFilter, cursor *models.Cursor, limit int) ([]*models.Transaction, error)
 
-	// Count returns the total number of transactions matching the filter
-	Count(ctx context.Context, filter map[string]any) (int64, error)
+	// Count returns the number of transactions matching a filter
+	Count(ctx context.Context, filter models.TransactionFilter) (int64, error)
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// UserAddressRepository defines the interface for linked wallet address data access
+type UserAddressRepository interface {
+	// Create links a new address to a user
+	Create(ctx context.Context, address *models.UserAddress) error
+
+	// FindByAddress retrieves a linked address, whatever its case
+	FindByAddress(ctx context.Context, address string) (*models.UserAddress, error)
+
+	// ListByUser retrieves the addresses linked to a user, primary first
+	ListByUser(ctx context.Context, userID uint) ([]*models.UserAddress, error)
+
+	// Delete unlinks an address
+	Delete(ctx context.Context, id uint) error
+
+	// SetPrimary makes a linked address the primary address of its user, including User.Address
+	SetPrimary(ctx context.Context, address *models.UserAddress) error
+}
@@ -8,13 +8,16 @@ import (
 
 // UserRepository defines the interface for user data access
 type UserRepository interface {
-	// Create inserts a new user into the database
+	// Create inserts a new user into the database along with its primary address
 	Create(ctx context.Context, user *models.User) error
 
 	// FindByID retrieves a user by ID
 	FindByID(ctx context.Context, id uint) (*models.User, error)
 
-	// FindByAddress retrieves a user by Ethereum address
+	// FindByIDs retrieves the users with the given IDs, in no particular order; unknown IDs are skipped
+	FindByIDs(ctx context.Context, ids []uint) ([]*models.User, error)
+
+	// FindByAddress retrieves a user by any of its linked Ethereum addresses, whatever their case
 	FindByAddress(ctx context.Context, address string) (*models.User, error)
 
 	// Update updates an existing user
@@ -23,12 +26,21 @@ type UserRepository interface {
 	// Delete marks a user as deleted (soft delete)
 	Delete(ctx context.Context, id uint) error
 
-	// List retrieves all users with optional pagination
-	List(ctx context.Context, offset, limit int) ([]*models.User, error)
+	// Purge anonymises a user, deleted or not, and erases its off-chain records: linked addresses, API keys
+	// and data exports. Transactions and positions are kept for accounting, with their hashes and amounts.
+	// Audit log entries keep the addresses and IPs they recorded: they are hash-chained and append-only
+	Purge(ctx context.Context, id uint) error
+
+	// List retrieves the users matching a filter, oldest first, with pagination
+	List(ctx context.Context, filter models.UserFilter, offset, limit int) ([]*models.User, error)
+
+	// ListByCursor retrieves up to limit users matching a filter, oldest first, from a cursor.
+	// A nil cursor starts at the first page
+	ListByCursor(ctx context.Context, filter models.UserFilter, cursor *models.Cursor, limit int) ([]*models.User, error)
 
 	// Count returns the total number of users
 	Count(ctx context.Context) (int64, error)
 
 	// CountWithFilter counts users that match the given filter criteria
-	CountWithFilter(ctx context.Context, filter map[string]any) (int64, error)
+	CountWithFilter(ctx context.Context, filter models.UserFilter) (int64, error)
 }
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+var (
+	// ErrInvalidAPIKey is returned when an API key is unknown, revoked, expired or used from a disallowed IP
+	ErrInvalidAPIKey = NewError(CodeInvalidAPIKey, "invalid API key")
+	// ErrAPIKeyNotFound is returned when a user has no API key with a given ID
+	ErrAPIKeyNotFound = NewError(CodeAPIKeyNotFound, "API key not found")
+	// ErrInvalidAPIKeyRequest is returned when the settings of a new API key are rejected
+	ErrInvalidAPIKeyRequest = NewError(CodeInvalidAPIKeyRequest, "invalid API key request")
+)
+
+// APIKeyRequest holds the settings of a new API key
+type APIKeyRequest struct {
+	Name       string
+	Scopes     []models.APIKeyScope
+	AllowedIPs []string   // IPs or CIDRs, empty allows any
+	ExpiresAt  *time.Time // Optional
+}
+
+// APIKeyService defines the interface for managing API keys; they are validated by AuthService
+type APIKeyService interface {
+	// CreateKey issues an API key to a user and returns it with its plaintext value, which is never shown again
+	CreateKey(ctx context.Context, user *models.User, req *APIKeyRequest) (*models.APIKey, string, error)
+
+	// ListKeys returns the API keys of a user, including revoked ones
+	ListKeys(ctx context.Context, userID uint) ([]*models.APIKey, error)
+
+	// RevokeKey revokes an API key of a user
+	RevokeKey(ctx context.Context, userID, keyID uint) error
+}
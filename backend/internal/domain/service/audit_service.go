@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// AuditActorKey is the context key holding the *models.AuditActor of a request.
+// Handlers pass the Fiber request context to services, so middleware sets it with c.Locals
+const AuditActorKey = "auditActor"
+
+// AuditActorFromContext returns the actor of the request a context belongs to, or nil outside of requests
+func AuditActorFromContext(ctx context.Context) *models.AuditActor {
+	actor, _ := ctx.Value(AuditActorKey).(*models.AuditActor)
+	return actor
+}
+
+// AuditTarget identifies what an audited action applied to
+type AuditTarget struct {
+	Type string
+	ID   string
+}
+
+// AuditVerification is the result of checking the hash chain of the audit log
+type AuditVerification struct {
+	Valid    bool
+	Entries  int64  // Number of entries checked
+	HeadHash string // Hash of the last entry, to be kept by reviewers so that truncation can be detected
+	BrokenAt *uint  // ID of the first entry that does not match, if any
+	Reason   string
+}
+
+// AuditService defines the interface for recording and reviewing the audit log
+type AuditService interface {
+	// Record appends an entry for an action performed by the actor of the context, with the diff
+	// between the states of its target before and after; either may be nil
+	Record(ctx context.Context, action models.AuditAction, target AuditTarget, before, after any) error
+
+	// RecordRequest appends an entry for a request, as seen by middleware
+	RecordRequest(ctx context.Context, action models.AuditAction, target AuditTarget, status int) error
+
+	// List retrieves the entries matching a filter, newest first
+	List(ctx context.Context, filter models.AuditLogFilter, offset, limit int) ([]*models.AuditLog, error)
+
+	// Count returns the number of entries matching a filter
+	Count(ctx context.Context, filter models.AuditLogFilter) (int64, error)
+
+	// Export calls fn for every entry matching a filter, oldest first
+	Export(ctx context.Context, filter models.AuditLogFilter, fn func(entry *models.AuditLog) error) error
+
+	// Verify recomputes the hash chain of the whole audit log
+	Verify(ctx context.Context) (*AuditVerification, error)
+}
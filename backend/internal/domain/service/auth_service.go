@@ -2,22 +2,112 @@ package service
 
 import (
 	"context"
+	"crypto"
 	"time"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 )
 
+var (
+	// ErrInvalidSignIn is returned when a sign-in message or its signature is rejected
+	ErrInvalidSignIn = NewError(CodeInvalidSignIn, "invalid sign-in")
+	// ErrInvalidSignature is returned when a signature was not made by the expected address
+	ErrInvalidSignature = NewError(CodeInvalidSignature, "invalid signature")
+	// ErrInvalidRefreshToken is returned when a refresh token is malformed or its session is over
+	ErrInvalidRefreshToken = NewError(CodeInvalidRefreshToken, "invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented;
+	// its session is revoked since the token has leaked
+	ErrRefreshTokenReused = NewError(CodeRefreshTokenReused, "refresh token reused")
+	// ErrSessionNotFound is returned when a session does not exist or is already revoked
+	ErrSessionNotFound = NewError(CodeSessionNotFound, "session not found")
+	// ErrInvalidLinkChallenge is returned when an address link challenge is unknown, expired
+	// or not signed by both addresses
+	ErrInvalidLinkChallenge = NewError(CodeInvalidLinkChallenge, "invalid address link challenge")
+)
+
+// SignInChallenge is a single-use nonce issued to an address, along with
+// the Sign-In with Ethereum (EIP-4361) message to sign with it
+type SignInChallenge struct {
+	Nonce     string
+	Message   string
+	ExpiresAt time.Time
+}
+
+// LinkChallenge is a single-use nonce issued to a user, along with the message that both its
+// primary address and the address to link must sign
+type LinkChallenge struct {
+	Nonce     string
+	Message   string
+	ExpiresAt time.Time
+}
+
+// TokenPair is a short-lived access token and the refresh token used to renew it
+type TokenPair struct {
+	SessionID        string
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// Session is a login of a user on one device, kept alive by refreshing its tokens
+type Session struct {
+	ID        string
+	Device    string // User agent the session was created from
+	IP        string // Address of the last sign-in or refresh
+	CreatedAt time.Time
+	LastSeen  time.Time
+	ExpiresAt time.Time
+}
+
+// VerificationKey is a public key access tokens may be signed with, published so that
+// other services can verify them
+type VerificationKey struct {
+	ID        string // Sent in the "kid" header of the tokens it verifies
+	Algorithm string // RS256 or EdDSA
+	PublicKey crypto.PublicKey
+}
+
 // AuthService defines the interface for authentication operations
 type AuthService interface {
-	// GenerateToken creates a JWT token for a user
-	GenerateToken(ctx context.Context, user *models.User) (string, time.Time, error)
+	// CreateSignInChallenge issues a nonce to an address, whether or not it is registered
+	CreateSignInChallenge(ctx context.Context, address string) (*SignInChallenge, error)
+
+	// VerifySignIn validates a signed SIWE message, consumes its nonce and returns the signing address
+	VerifySignIn(ctx context.Context, message, signature string) (string, error)
+
+	// VerifyMessageSignature checks that a personal signature of any message was made by an address,
+	// which may be an EOA or an EIP-1271 contract wallet
+	VerifyMessageSignature(ctx context.Context, address, message, signature string) error
+
+	// CreateLinkChallenge issues the message a user must sign with its primary address and with
+	// another address to link the latter to its account
+	CreateLinkChallenge(ctx context.Context, user *models.User, address string) (*LinkChallenge, error)
+
+	// VerifyLinkChallenge consumes a link challenge and checks that it was signed by both addresses
+	VerifyLinkChallenge(ctx context.Context, user *models.User, address, nonce, primarySignature, addressSignature string) error
+
+	// CreateSession starts a session for a user and issues its first token pair
+	CreateSession(ctx context.Context, user *models.User, device, ip string) (*TokenPair, error)
+
+	// RefreshSession rotates a refresh token and issues a new token pair for its session
+	RefreshSession(ctx context.Context, refreshToken, ip string) (*TokenPair, error)
+
+	// ValidateToken validates an access token and returns the user and its session ID
+	ValidateToken(ctx context.Context, tokenString string) (*models.User, string, error)
+
+	// ValidateAPIKey checks a plaintext API key used from an IP, records its use and returns its owner
+	ValidateAPIKey(ctx context.Context, rawKey, ip string) (*models.User, *models.APIKey, error)
+
+	// ListSessions returns the live sessions of a user
+	ListSessions(ctx context.Context, userID uint) ([]*Session, error)
 
-	// ValidateToken validates a JWT token and returns the user
-	ValidateToken(ctx context.Context, tokenString string) (*models.User, error)
+	// RevokeSession ends a session of a user
+	RevokeSession(ctx context.Context, userID uint, sessionID string) error
 
-	// InvalidateToken removes a specific token from valid tokens
-	InvalidateToken(ctx context.Context, userID uint, tokenID string) error
+	// RevokeAllSessions ends every session of a user
+	RevokeAllSessions(ctx context.Context, userID uint) error
 
-	// InvalidateAllUserTokens removes all valid tokens for a user
-	InvalidateAllUserTokens(ctx context.Context, userID uint) error
+	// VerificationKeys returns the public keys of the active signing key and of the keys still in their grace period
+	VerificationKeys() []VerificationKey
 }
@@ -8,29 +8,42 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+var (
+	// ErrBorrowLimitExceeded is returned when a borrow exceeds what the collateral of the user allows
+	ErrBorrowLimitExceeded = NewError(CodeBorrowLimitExceeded, "borrow amount exceeds maximum borrowable amount")
+	// ErrRepayExceedsDebt is returned when a repayment exceeds the amount borrowed
+	ErrRepayExceedsDebt = NewError(CodeRepayExceedsDebt, "repay amount exceeds borrowed amount")
+	// ErrNoDebt is returned when there is no debt to repay or liquidate
+	ErrNoDebt = NewError(CodeNoDebt, "no outstanding debt")
+)
+
 // BorrowingService defines the interface for borrowing business logic
+// Every method operates on the market with the given identifier; an empty identifier selects the default market
 type BorrowingService interface {
 	// Borrow allows users to borrow tokens based on their collateral
-	Borrow(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error)
+	Borrow(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error)
 
 	// Repay allows users to repay borrowed tokens
-	Repay(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error)
+	Repay(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error)
 
 	// GetBorrowedAmount returns the amount borrowed by a user
-	GetBorrowedAmount(ctx context.Context, userAddress common.Address) (*big.Int, error)
+	GetBorrowedAmount(ctx context.Context, market string, userAddress common.Address) (*big.Int, error)
 
 	// GetTotalBorrowed returns the total amount borrowed from the protocol
-	GetTotalBorrowed(ctx context.Context) (*big.Int, error)
+	GetTotalBorrowed(ctx context.Context, market string) (*big.Int, error)
+
+	// GetUnderlyingToken returns the address of the token that is borrowed
+	GetUnderlyingToken(ctx context.Context, market string) (common.Address, error)
 
 	// GetCurrentInterestRate returns the current interest rate for borrowing
-	GetCurrentInterestRate(ctx context.Context) (*big.Int, error)
+	GetCurrentInterestRate(ctx context.Context, market string) (*big.Int, error)
 
 	// GetUserInterestAccrued returns the interest accrued by a user on borrowed amount
-	GetUserInterestAccrued(ctx context.Context, userAddress common.Address) (*big.Int, error)
+	GetUserInterestAccrued(ctx context.Context, market string, userAddress common.Address) (*big.Int, error)
 
-	// GetUserTransactionHistory returns a user's borrowing transaction history
-	GetUserTransactionHistory(ctx context.Context, userAddress common.Address, offset, limit int) ([]*models.Transaction, error)
+	// GetUserTransactionHistory returns a user's borrowing transaction history matching a filter
+	GetUserTransactionHistory(ctx context.Context, market string, userAddress common.Address, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error)
 
-	// CountUserTransactions counts the number of transactions for a user with optional filtering
-	CountUserTransactions(ctx context.Context, address common.Address, filter map[string]any) (int64, error)
+	// CountUserTransactions counts the borrowing transactions of a user matching a filter
+	CountUserTransactions(ctx context.Context, market string, address common.Address, filter models.TransactionFilter) (int64, error)
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+var (
+	// ErrBundleNotFound is returned when a user has no bundle with a given ID
+	ErrBundleNotFound = NewError(CodeBundleNotFound, "bundle not found")
+	// ErrStepNotNext is returned when submitting a step of an unsigned bundle other than the next pending one
+	ErrStepNotNext = NewError(CodeBundleStepNotNext, "only the next pending step of an unsigned bundle can be submitted")
+	// ErrStepTransactionNotFound is returned when the node does not know the transaction submitted for a step
+	ErrStepTransactionNotFound = NewError(CodeBundleTransactionNotFound, "transaction not found on chain")
+	// ErrStepMismatch is returned when the transaction submitted for a step does not perform it
+	ErrStepMismatch = NewError(CodeBundleStepMismatch, "transaction does not match the step")
+)
+
+// BundleActionRequest is an action to run as a step of a bundle
+type BundleActionRequest struct {
+	Action  models.BundleAction
+	Amount  *big.Int
+	Spender models.TransactionContract // Contract an approval is for; the next step pulling tokens when empty
+}
+
+// BundleStepError reports the first step of a bundle that would fail, given the state left by the steps before it
+func BundleStepError(position int, action models.BundleAction, reason string) *Error {
+	return &Error{
+		Code:    CodeBundleStepWouldFail,
+		Message: fmt.Sprintf("step %d (%s): %s", position, action, reason),
+		Details: map[string]any{"position": position, "action": action, "reason": reason},
+	}
+}
+
+// BundleService defines the interface for multi-step action bundles.
+// Every method operates on the market with the given identifier; an empty identifier selects the default market
+type BundleService interface {
+	// CreateBundle validates a list of actions against the simulated state of the user's wallet and position
+	// after each step. Signer bundles are then sent in the background, each step once the previous one is mined;
+	// unsigned bundles hold the transactions for the user's wallet to sign and send in order
+	CreateBundle(ctx context.Context, market string, userID uint, userAddress common.Address, mode models.BundleMode, actions []BundleActionRequest) (*models.Bundle, error)
+
+	// GetBundle returns a bundle of a user, after checking whether its submitted steps were mined
+	GetBundle(ctx context.Context, userID, bundleID uint) (*models.Bundle, error)
+
+	// ListBundles returns the bundles of a user, newest first, with pagination
+	ListBundles(ctx context.Context, userID uint, offset, limit int) ([]*models.Bundle, error)
+
+	// CountBundles returns the number of bundles of a user
+	CountBundles(ctx context.Context, userID uint) (int64, error)
+
+	// SubmitStep records the hash of the transaction the user sent for the next step of an unsigned bundle
+	SubmitStep(ctx context.Context, userID, bundleID uint, position int, hash string) (*models.Bundle, error)
+
+	// ResumeBundles runs in the background the signer bundles that no server holds anymore, once the steps
+	// they had sent are settled from their receipts
+	ResumeBundles(ctx context.Context) error
+
+	// Shutdown stops the signer bundles running in the background and releases them for the next server,
+	// waiting until they stopped or the context is done
+	Shutdown(ctx context.Context) error
+}
@@ -4,35 +4,65 @@ import (
 	"context"
 	"math/big"
 
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/ethereum/go-ethereum/common"
 )
 
+var (
+	// ErrInsufficientCollateral is returned when a withdrawal exceeds the collateral deposited
+	ErrInsufficientCollateral = NewError(CodeInsufficientCollateral, "withdrawal exceeds collateral balance")
+	// ErrCollateralRatioTooLow is returned when a withdrawal would leave the position below the minimum collateral ratio
+	ErrCollateralRatioTooLow = NewError(CodeCollateralRatioTooLow, "withdrawal would put position at risk of liquidation")
+)
+
+// CollateralReconciliation compares the collateral held by the Collateral contract
+// with the sum of per-user collateral balances recorded in the database
+type CollateralReconciliation struct {
+	OnChain    *big.Int // Token balance of the Collateral contract
+	Indexed    *big.Int // Sum of the latest recorded collateral balance of every user
+	Difference *big.Int // OnChain - Indexed
+	Diverged   bool
+}
+
 // CollateralService defines the interface for collateral business logic
+// Every method operates on the market with the given identifier; an empty identifier selects the default market
 type CollateralService interface {
 	// DepositCollateral allows users to deposit tokens as collateral
-	DepositCollateral(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error)
+	DepositCollateral(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error)
 
 	// WithdrawCollateral allows users to withdraw tokens from their collateral
-	WithdrawCollateral(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error)
+	WithdrawCollateral(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error)
 
 	// GetCollateralBalance returns the collateral balance of a user
-	GetCollateralBalance(ctx context.Context, userAddress common.Address) (*big.Int, error)
+	GetCollateralBalance(ctx context.Context, market string, userAddress common.Address) (*big.Int, error)
 
 	// GetTotalCollateral returns the total collateral in the protocol
-	GetTotalCollateral(ctx context.Context) (*big.Int, error)
+	GetTotalCollateral(ctx context.Context, market string) (*big.Int, error)
+
+	// GetUnderlyingToken returns the address of the token accepted as collateral
+	GetUnderlyingToken(ctx context.Context, market string) (common.Address, error)
+
+	// GetUserTransactionHistory returns a user's collateral transaction history, liquidations included matching a filter
+	GetUserTransactionHistory(ctx context.Context, market string, userAddress common.Address, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error)
+
+	// CountUserTransactions counts the collateral transactions of a user matching a filter
+	CountUserTransactions(ctx context.Context, market string, address common.Address, filter models.TransactionFilter) (int64, error)
+
+	// ReconcileTotalCollateral cross-checks the on-chain collateral total against indexed user balances
+	ReconcileTotalCollateral(ctx context.Context, market string) (*CollateralReconciliation, error)
 
 	// GetCollateralRatio returns the collateral ratio for a user
-	GetCollateralRatio(ctx context.Context, userAddress common.Address) (*big.Int, error)
+	GetCollateralRatio(ctx context.Context, market string, userAddress common.Address) (*big.Int, error)
 
 	// GetMinCollateralRatio returns the minimum collateral ratio required
-	GetMinCollateralRatio(ctx context.Context) (*big.Int, error)
+	GetMinCollateralRatio(ctx context.Context, market string) (*big.Int, error)
 
 	// GetLiquidationThreshold returns the threshold at which a position can be liquidated
-	GetLiquidationThreshold(ctx context.Context) (*big.Int, error)
+	GetLiquidationThreshold(ctx context.Context, market string) (*big.Int, error)
 
 	// GetMaxBorrowableAmount returns the maximum amount a user can borrow based on their collateral
-	GetMaxBorrowableAmount(ctx context.Context, userAddress common.Address) (*big.Int, error)
+	GetMaxBorrowableAmount(ctx context.Context, market string, userAddress common.Address) (*big.Int, error)
 
 	// IsAtRisk checks if a user's position is at risk of liquidation
-	IsAtRisk(ctx context.Context, userAddress common.Address) (bool, error)
+	IsAtRisk(ctx context.Context, market string, userAddress common.Address) (bool, error)
 }
@@ -0,0 +1,105 @@
+package service
+
+import "maps"
+
+// ErrorCode identifies a failure clients can act on. Codes are part of the API and never change meaning
+type ErrorCode string
+
+// Error codes of the failures returned by services
+const (
+	CodeInvalidAmount             ErrorCode = "INVALID_AMOUNT"
+	CodeInsufficientTokenBalance  ErrorCode = "INSUFFICIENT_TOKEN_BALANCE"
+	CodeInsufficientAllowance     ErrorCode = "INSUFFICIENT_ALLOWANCE"
+	CodeTokenTransferFailed       ErrorCode = "TOKEN_TRANSFER_FAILED"
+	CodeInsufficientDeposit       ErrorCode = "INSUFFICIENT_DEPOSIT"
+	CodeBorrowLimitExceeded       ErrorCode = "BORROW_LIMIT_EXCEEDED"
+	CodeRepayExceedsDebt          ErrorCode = "REPAY_EXCEEDS_DEBT"
+	CodeNoDebt                    ErrorCode = "NO_DEBT"
+	CodeInsufficientCollateral    ErrorCode = "INSUFFICIENT_COLLATERAL"
+	CodeCollateralRatioTooLow     ErrorCode = "COLLATERAL_RATIO_TOO_LOW"
+	CodeNotLiquidatable           ErrorCode = "NOT_LIQUIDATABLE"
+	CodePriceUnavailable          ErrorCode = "PRICE_UNAVAILABLE"
+	CodeContractUnauthorized      ErrorCode = "CONTRACT_UNAUTHORIZED"
+	CodeContractReverted          ErrorCode = "CONTRACT_REVERTED"
+	CodeSigningUnavailable        ErrorCode = "SIGNING_UNAVAILABLE"
+	CodeMarketNotFound            ErrorCode = "MARKET_NOT_FOUND"
+	CodePositionNotFound          ErrorCode = "POSITION_NOT_FOUND"
+	CodeTransactionNotFound       ErrorCode = "TRANSACTION_NOT_FOUND"
+	CodeBundleNotFound            ErrorCode = "BUNDLE_NOT_FOUND"
+	CodeBundleStepWouldFail       ErrorCode = "BUNDLE_STEP_WOULD_FAIL"
+	CodeBundleStepNotNext         ErrorCode = "BUNDLE_STEP_NOT_NEXT"
+	CodeBundleStepMismatch        ErrorCode = "BUNDLE_STEP_MISMATCH"
+	CodeBundleTransactionNotFound ErrorCode = "BUNDLE_TRANSACTION_NOT_FOUND"
+	CodeInvalidSignIn             ErrorCode = "INVALID_SIGN_IN"
+	CodeInvalidSignature          ErrorCode = "INVALID_SIGNATURE"
+	CodeInvalidRefreshToken       ErrorCode = "INVALID_REFRESH_TOKEN"
+	CodeRefreshTokenReused        ErrorCode = "REFRESH_TOKEN_REUSED"
+	CodeSessionNotFound           ErrorCode = "SESSION_NOT_FOUND"
+	CodeInvalidLinkChallenge      ErrorCode = "INVALID_LINK_CHALLENGE"
+	CodeInvalidAPIKey             ErrorCode = "INVALID_API_KEY"
+	CodeAPIKeyNotFound            ErrorCode = "API_KEY_NOT_FOUND"
+	CodeInvalidAPIKeyRequest      ErrorCode = "INVALID_API_KEY_REQUEST"
+	CodeInvalidRole               ErrorCode = "INVALID_ROLE"
+	CodeLastAdmin                 ErrorCode = "LAST_ADMIN"
+	CodeAddressInUse              ErrorCode = "ADDRESS_IN_USE"
+	CodeAddressNotLinked          ErrorCode = "ADDRESS_NOT_LINKED"
+	CodePrimaryAddress            ErrorCode = "PRIMARY_ADDRESS"
+	CodeInvalidExportFormat       ErrorCode = "INVALID_EXPORT_FORMAT"
+	CodeExportNotFound            ErrorCode = "EXPORT_NOT_FOUND"
+	CodeExportNotReady            ErrorCode = "EXPORT_NOT_READY"
+	CodeDeletionPending           ErrorCode = "DELETION_PENDING"
+	CodeDeletionRequestNotFound   ErrorCode = "DELETION_REQUEST_NOT_FOUND"
+	CodeDeletionReviewed          ErrorCode = "DELETION_REVIEWED"
+)
+
+var (
+	// ErrInvalidAmount is returned when an amount is zero where the contracts require more
+	ErrInvalidAmount = NewError(CodeInvalidAmount, "amount must be greater than 0")
+	// ErrInsufficientTokenBalance is returned when the wallet holds fewer tokens than a transfer needs
+	ErrInsufficientTokenBalance = NewError(CodeInsufficientTokenBalance, "insufficient token balance")
+	// ErrInsufficientAllowance is returned when a contract may not pull as many tokens as a transfer needs
+	ErrInsufficientAllowance = NewError(CodeInsufficientAllowance, "insufficient token allowance")
+	// ErrTokenTransferFailed is returned when a contract could not move tokens
+	ErrTokenTransferFailed = NewError(CodeTokenTransferFailed, "token transfer failed")
+	// ErrPriceUnavailable is returned when the price feed has no data yet
+	ErrPriceUnavailable = NewError(CodePriceUnavailable, "price data unavailable")
+	// ErrContractUnauthorized is returned when a contract refuses a call from the platform
+	ErrContractUnauthorized = NewError(CodeContractUnauthorized, "contract call not authorized")
+	// ErrContractReverted is returned when a contract rejects a transaction for a reason with no code of its own
+	ErrContractReverted = NewError(CodeContractReverted, "transaction reverted")
+	// ErrSigningUnavailable is returned when the platform cannot sign transactions for the user
+	ErrSigningUnavailable = NewError(CodeSigningUnavailable, "transaction signing not implemented")
+)
+
+// Error is a failure with a stable code, whose message can be shown to clients
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details map[string]any // Values clients may use to correct the request, e.g. the maximum amount
+}
+
+// NewError creates an error with a code
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors with the same code, so that errors carrying details still match their sentinel
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of the error carrying details, merged with any it already has
+func (e *Error) WithDetails(details map[string]any) *Error {
+	merged := maps.Clone(e.Details)
+	if merged == nil {
+		merged = make(map[string]any, len(details))
+	}
+	maps.Copy(merged, details)
+
+	return &Error{Code: e.Code, Message: e.Message, Details: merged}
+}
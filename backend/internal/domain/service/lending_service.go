@@ -8,29 +8,36 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// ErrInsufficientDeposit is returned when a withdrawal exceeds the tokens deposited in the lending pool
+var ErrInsufficientDeposit = NewError(CodeInsufficientDeposit, "withdrawal exceeds deposited balance")
+
 // LendingService defines the interface for lending business logic
+// Every method operates on the market with the given identifier; an empty identifier selects the default market
 type LendingService interface {
 	// Deposit allows users to deposit tokens into the lending pool
-	Deposit(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error)
+	Deposit(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error)
 
 	// Withdraw allows users to withdraw tokens from the lending pool
-	Withdraw(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error)
+	Withdraw(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error)
 
 	// GetUserBalance returns the user's balance in the lending pool
-	GetUserBalance(ctx context.Context, userAddress common.Address) (*big.Int, error)
+	GetUserBalance(ctx context.Context, market string, userAddress common.Address) (*big.Int, error)
 
 	// GetTotalDeposited returns the total amount deposited in the lending pool
-	GetTotalDeposited(ctx context.Context) (*big.Int, error)
+	GetTotalDeposited(ctx context.Context, market string) (*big.Int, error)
+
+	// GetUnderlyingToken returns the address of the token deposited in the lending pool
+	GetUnderlyingToken(ctx context.Context, market string) (common.Address, error)
 
 	// GetCurrentInterestRate returns the current interest rate for lending
-	GetCurrentInterestRate(ctx context.Context) (*big.Int, error)
+	GetCurrentInterestRate(ctx context.Context, market string) (*big.Int, error)
 
 	// GetUserInterestEarned returns the interest earned by a user
-	GetUserInterestEarned(ctx context.Context, userAddress common.Address) (*big.Int, error)
+	GetUserInterestEarned(ctx context.Context, market string, userAddress common.Address) (*big.Int, error)
 
-	// GetUserTransactionHistory returns a user's lending transaction history
-	GetUserTransactionHistory(ctx context.Context, userAddress common.Address, offset, limit int) ([]*models.Transaction, error)
+	// GetUserTransactionHistory returns a user's lending transaction history matching a filter
+	GetUserTransactionHistory(ctx context.Context, market string, userAddress common.Address, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error)
 
-	// CountUserTransactions counts the number of transactions for a user with optional filtering
-	CountUserTransactions(ctx context.Context, address common.Address, filter map[string]any) (int64, error)
+	// CountUserTransactions counts the lending transactions of a user matching a filter
+	CountUserTransactions(ctx context.Context, market string, address common.Address, filter models.TransactionFilter) (int64, error)
 }
@@ -8,20 +8,24 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// ErrNotLiquidatable is returned when a position is collateralized enough not to be liquidated
+var ErrNotLiquidatable = NewError(CodeNotLiquidatable, "position is not eligible for liquidation")
+
 // LiquidationService defines the interface for liquidation business logic
+// Every method operates on the market with the given identifier; an empty identifier selects the default market
 type LiquidationService interface {
 	// Liquidate allows liquidators to liquidate an under-collateralized position
-	Liquidate(ctx context.Context, liquidatorAddress, borrowerAddress common.Address, repayAmount *big.Int) (string, error)
+	Liquidate(ctx context.Context, market string, liquidatorAddress, borrowerAddress common.Address, repayAmount *big.Int) (string, error)
 
 	// GetLiquidatablePositions returns all positions that can be liquidated
-	GetLiquidatablePositions(ctx context.Context) ([]*models.Position, error)
+	GetLiquidatablePositions(ctx context.Context, market string) ([]*models.Position, error)
 
 	// GetLiquidationBonus returns the bonus a liquidator receives for liquidating a position
-	GetLiquidationBonus(ctx context.Context) (*big.Int, error)
+	GetLiquidationBonus(ctx context.Context, market string) (*big.Int, error)
 
-	// GetLiquidationHistory returns the history of liquidation events
-	GetLiquidationHistory(ctx context.Context, offset, limit int) ([]*models.Transaction, error)
+	// GetLiquidationHistory returns the history of liquidation events matching a filter
+	GetLiquidationHistory(ctx context.Context, market string, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error)
 
-	// CountLiquidations counts the total number of liquidation transactions
-	CountLiquidations(ctx context.Context) (int64, error)
+	// CountLiquidations counts the liquidation transactions matching a filter
+	CountLiquidations(ctx context.Context, market string, filter models.TransactionFilter) (int64, error)
 }
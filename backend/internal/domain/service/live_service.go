@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrSlowSubscriber ends the subscriptions of clients that do not read their events as fast as they are sent
+var ErrSlowSubscriber = errors.New("subscriber too slow, events were dropped")
+
+// LiveTopic is a stream of live events clients can subscribe to
+type LiveTopic string
+
+const (
+	// TopicHealth carries the health factor changes of the subscriber's positions
+	TopicHealth LiveTopic = "health"
+	// TopicTransactions carries the status changes of the subscriber's transactions
+	TopicTransactions LiveTopic = "transactions"
+	// TopicBlocks carries every new block
+	TopicBlocks LiveTopic = "blocks"
+	// TopicRates carries the lending and borrowing rate changes of every market
+	TopicRates LiveTopic = "rates"
+)
+
+// LiveTopics lists every topic, the default subscription
+var LiveTopics = []LiveTopic{TopicHealth, TopicTransactions, TopicBlocks, TopicRates}
+
+// IsValid reports whether the topic is a known live topic
+func (t LiveTopic) IsValid() bool {
+	return t == TopicHealth || t == TopicTransactions || t == TopicBlocks || t == TopicRates
+}
+
+// LiveEvent is an update pushed to the subscribers of a topic
+type LiveEvent struct {
+	Topic  LiveTopic       `json:"topic"`
+	UserID uint            `json:"userId,omitempty"` // Only user to receive the event, 0 for every subscriber
+	Data   json.RawMessage `json:"data"`
+	Time   time.Time       `json:"time"`
+}
+
+// HealthEvent reports the new health factor of a position
+type HealthEvent struct {
+	PositionID   uint   `json:"positionId"`
+	Market       string `json:"market"`
+	HealthFactor string `json:"healthFactor"`
+	Previous     string `json:"previous"`
+}
+
+// TransactionStatusEvent reports a transaction mined or failed
+type TransactionStatusEvent struct {
+	Hash        string `json:"hash"`
+	Type        string `json:"type"`
+	Role        string `json:"role"`
+	Market      string `json:"market"`
+	Status      string `json:"status"`
+	BlockNumber uint64 `json:"blockNumber"`
+	GasUsed     uint64 `json:"gasUsed"`
+}
+
+// BlockEvent reports a new block
+type BlockEvent struct {
+	Number    uint64 `json:"number"`
+	Hash      string `json:"hash"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+// RateEvent reports the new rates of a market
+type RateEvent struct {
+	Market        string `json:"market"`
+	LendingRate   string `json:"lendingRate"`   // Annual rate in 1e18 precision
+	BorrowingRate string `json:"borrowingRate"` // Current rate in 1e18 precision
+	BlockNumber   uint64 `json:"blockNumber"`
+}
+
+// LiveSubscription receives the events of some topics for a user
+type LiveSubscription interface {
+	// Events returns the buffered events of the subscription
+	Events() <-chan *LiveEvent
+
+	// Done is closed when the subscription ends, because it was closed or fell behind
+	Done() <-chan struct{}
+
+	// Err returns why the subscription ended, nil when it was closed
+	Err() error
+
+	// Close ends the subscription
+	Close()
+}
+
+// LiveService defines the interface for live position, transaction and market updates.
+// Events are published through Valkey so that every replica delivers them to its own clients
+type LiveService interface {
+	// Subscribe starts receiving the events of some topics for a user
+	Subscribe(userID uint, topics []LiveTopic) LiveSubscription
+
+	// Receive delivers the events published by every replica to local subscribers, until the context
+	// is cancelled or the connection to Valkey is lost
+	Receive(ctx context.Context) error
+
+	// PollChain publishes the latest block when there is a new one, along with the transactions it
+	// settled and the rates that changed. A block is only processed by one replica
+	PollChain(ctx context.Context) error
+
+	// RefreshHealthFactors reads the health factor of every active position and publishes those that changed
+	RefreshHealthFactors(ctx context.Context) error
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// ErrMarketNotFound is returned when no active market matches an identifier
+var ErrMarketNotFound = NewError(CodeMarketNotFound, "market not found")
+
+// MarketRegistry defines the interface for resolving the markets served by the protocol
+type MarketRegistry interface {
+	// GetMarket returns an active market by identifier; an empty identifier selects the default market
+	GetMarket(ctx context.Context, identifier string) (*models.Market, error)
+
+	// ListMarkets returns every active market
+	ListMarkets(ctx context.Context) ([]*models.Market, error)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenMarketData holds on-chain market figures for a single token
+type TokenMarketData struct {
+	Market             string // Identifier of the market the token is lent and borrowed in
+	Address            common.Address
+	Name               string
+	Symbol             string
+	Decimals           uint8
+	TotalSupply        *big.Int
+	TotalDeposited     *big.Int
+	TotalBorrowed      *big.Int
+	AvailableLiquidity *big.Int
+	LendingRate        *big.Int // Annual rate in 1e18 precision
+	BorrowingRate      *big.Int // Current rate in 1e18 precision
+	CollateralFactor   *big.Int // Share of collateral that can be borrowed, in 1e18 precision
+	BlockNumber        uint64   // Block the figures were read at
+}
+
+// MarketService defines the interface for protocol market data
+type MarketService interface {
+	// GetTokensMarketData returns market data for the token of every active market
+	GetTokensMarketData(ctx context.Context) ([]*TokenMarketData, error)
+}
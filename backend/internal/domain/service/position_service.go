@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// ErrPositionNotFound is returned when a position does not exist or belongs to another user
+var ErrPositionNotFound = NewError(CodePositionNotFound, "position not found")
+
+// PositionService defines the interface for reading lending/borrowing positions and their history
+type PositionService interface {
+	// ListPositions retrieves the positions matching a filter, in its order, with pagination
+	ListPositions(ctx context.Context, filter models.PositionFilter, offset, limit int) ([]*models.Position, error)
+
+	// ListPositionsByCursor retrieves up to limit positions matching a filter, by opening date, from a cursor
+	ListPositionsByCursor(ctx context.Context, filter models.PositionFilter, cursor *models.Cursor, limit int) ([]*models.Position, error)
+
+	// CountPositions returns the number of positions matching a filter
+	CountPositions(ctx context.Context, filter models.PositionFilter) (int64, error)
+
+	// GetPosition retrieves a position along with the transactions that opened, changed or closed it.
+	// A non-nil userID restricts the lookup to the positions of that user
+	GetPosition(ctx context.Context, id uint, userID *uint) (*models.Position, []*models.Transaction, error)
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenPrice is the USD price of a token as reported by the oracle
+type TokenPrice struct {
+	Token     common.Address
+	Price     *big.Int // USD per whole token, in 1e18 precision
+	UpdatedAt time.Time
+	Source    string
+}
+
+// PriceService defines the interface for asset pricing
+type PriceService interface {
+	// GetTokenPrice returns the current USD price of a token
+	GetTokenPrice(ctx context.Context, token common.Address) (*TokenPrice, error)
+
+	// ValueInUSD returns the USD value of a token amount, in 1e18 precision
+	ValueInUSD(ctx context.Context, token common.Address, amount *big.Int) (*big.Int, error)
+
+	// RecordSamples stores the current price of every known token for TWAP computation
+	RecordSamples(ctx context.Context) error
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+var (
+	// ErrInvalidExportFormat is returned when a data export is requested in an unsupported format
+	ErrInvalidExportFormat = NewError(CodeInvalidExportFormat, "invalid export format, expected json or zip")
+	// ErrExportNotFound is returned when a user has no data export with a given ID
+	ErrExportNotFound = NewError(CodeExportNotFound, "data export not found")
+	// ErrExportNotReady is returned when downloading a data export that is not assembled yet
+	ErrExportNotReady = NewError(CodeExportNotReady, "data export is not ready")
+	// ErrDeletionPending is returned when a user already has a deletion request awaiting review
+	ErrDeletionPending = NewError(CodeDeletionPending, "a deletion request is already pending")
+	// ErrDeletionRequestNotFound is returned when a deletion request does not exist
+	ErrDeletionRequestNotFound = NewError(CodeDeletionRequestNotFound, "deletion request not found")
+	// ErrDeletionReviewed is returned when reviewing a deletion request that is no longer pending
+	ErrDeletionReviewed = NewError(CodeDeletionReviewed, "deletion request has already been reviewed")
+)
+
+// PrivacyService defines the interface for personal data exports and account purges
+type PrivacyService interface {
+	// RequestExport queues an export of the personal data of a user, assembled by ProcessExports
+	RequestExport(ctx context.Context, userID uint, format models.DataExportFormat) (*models.DataExport, error)
+
+	// ListExports returns the data exports of a user, newest first
+	ListExports(ctx context.Context, userID uint) ([]*models.DataExport, error)
+
+	// GetExport returns a data export of a user, including its archive once ready
+	GetExport(ctx context.Context, userID, exportID uint) (*models.DataExport, error)
+
+	// ProcessExports assembles the pending data exports and deletes expired archives
+	ProcessExports(ctx context.Context) error
+
+	// RequestDeletion asks for the account of a user to be purged, which an admin must approve
+	RequestDeletion(ctx context.Context, userID uint, reason string) (*models.DeletionRequest, error)
+
+	// GetDeletionRequest returns the most recent deletion request of a user, or nil
+	GetDeletionRequest(ctx context.Context, userID uint) (*models.DeletionRequest, error)
+
+	// ListDeletionRequests retrieves deletion requests with optional filtering and pagination, oldest first
+	ListDeletionRequests(ctx context.Context, filter models.DeletionRequestFilter, offset, limit int) ([]*models.DeletionRequest, error)
+
+	// CountDeletionRequests returns the number of deletion requests matching the filter
+	CountDeletionRequests(ctx context.Context, filter models.DeletionRequestFilter) (int64, error)
+
+	// ApproveDeletion approves a pending deletion request, revoking every token of its user and purging the account
+	ApproveDeletion(ctx context.Context, reviewerID, requestID uint, note string) (*models.DeletionRequest, error)
+
+	// RejectDeletion rejects a pending deletion request
+	RejectDeletion(ctx context.Context, reviewerID, requestID uint, note string) (*models.DeletionRequest, error)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// SolvencyAlert reports a solvency ratio below one of its thresholds
+type SolvencyAlert struct {
+	Level     models.SolvencyStatus
+	Metric    string   // "coverage" or "liquidity"
+	Ratio     *big.Int // In 1e18 precision
+	Threshold *big.Int // In 1e18 precision
+}
+
+// SolvencyReport compares what a market owes with the tokens it holds and is owed.
+//
+// LendingPool credits lenders interest by minting dTokens without receiving any
+// underlying, while borrows are paid out of the Borrowing contract's own balance,
+// so the lending liability is only fully backed if the Borrowing side makes up the gap
+type SolvencyReport struct {
+	Market      string
+	Token       common.Address // Underlying token every amount is denominated in
+	BlockNumber uint64
+
+	// Liabilities
+	LendingLiability    *big.Int // LendingPool totalLending: deposits plus interest credited to lenders
+	LenderInterest      *big.Int // Interest credited to lenders and not backed by deposits: totalLending - LendingPool balance
+	CollateralLiability *big.Int // Collateral owed back to borrowers, from indexed positions
+
+	// Assets
+	LendingPoolBalance *big.Int // Token balance of the LendingPool contract
+	BorrowingBalance   *big.Int // Token balance of the Borrowing contract
+	CollateralBalance  *big.Int // Token balance of the Collateral contract
+	TotalBorrowed      *big.Int // Borrowing totalBorrowed: outstanding principal plus interest applied on-chain
+	BorrowInterest     *big.Int // Interest accrued by borrowers and not yet applied on-chain
+
+	TotalAssets      *big.Int
+	TotalLiabilities *big.Int
+	NetReserves      *big.Int // TotalAssets - TotalLiabilities
+	CoverageRatio    *big.Int // TotalAssets / TotalLiabilities, in 1e18 precision
+	LiquidityRatio   *big.Int // LendingPoolBalance / LendingLiability, in 1e18 precision
+
+	Status      models.SolvencyStatus
+	Alerts      []SolvencyAlert
+	GeneratedAt time.Time
+}
+
+// SolvencyService defines the interface for protocol solvency monitoring
+// Every method operates on the market with the given identifier; an empty identifier selects the default market
+type SolvencyService interface {
+	// GetReport computes the current solvency report of a market
+	GetReport(ctx context.Context, market string) (*SolvencyReport, error)
+
+	// RecordSnapshot computes the current solvency report of a market and stores it in the time series
+	RecordSnapshot(ctx context.Context, market string) (*SolvencyReport, error)
+
+	// GetHistory returns the solvency snapshots of a market taken within [from, to], oldest first
+	GetHistory(ctx context.Context, market string, from, to time.Time) ([]*models.SolvencySnapshot, error)
+}
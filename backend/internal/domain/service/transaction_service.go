@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// ErrTransactionNotFound is returned when a transaction hash has no ledger entry visible to the user
+var ErrTransactionNotFound = NewError(CodeTransactionNotFound, "transaction not found")
+
+// TransactionService defines the interface for reading the transaction ledger across all contracts
+type TransactionService interface {
+	// ListTransactions retrieves the ledger entries matching a filter, most recent first, with pagination
+	ListTransactions(ctx context.Context, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error)
+
+	// ListTransactionsByCursor retrieves up to limit ledger entries matching a filter, most recent first, from a cursor
+	ListTransactionsByCursor(ctx context.Context, filter models.TransactionFilter, cursor *models.Cursor, limit int) ([]*models.Transaction, error)
+
+	// CountTransactions returns the number of ledger entries matching a filter
+	CountTransactions(ctx context.Context, filter models.TransactionFilter) (int64, error)
+
+	// GetTransaction retrieves the ledger entries of an on-chain transaction, one per role.
+	// A non-nil userID restricts the lookup to the entries of that user
+	GetTransaction(ctx context.Context, hash string, userID *uint) ([]*models.Transaction, error)
+}
@@ -2,11 +2,23 @@ package service
 
 import (
 	"context"
-	"time"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 )
 
+var (
+	// ErrInvalidRole is returned when assigning a role that is not a built-in role
+	ErrInvalidRole = NewError(CodeInvalidRole, "invalid role")
+	// ErrLastAdmin is returned when a change would leave the platform without an admin
+	ErrLastAdmin = NewError(CodeLastAdmin, "cannot remove the last admin")
+	// ErrAddressInUse is returned when linking an address that already belongs to an account
+	ErrAddressInUse = NewError(CodeAddressInUse, "address already belongs to an account")
+	// ErrAddressNotLinked is returned when an address is not linked to the account of a user
+	ErrAddressNotLinked = NewError(CodeAddressNotLinked, "address not linked to this account")
+	// ErrPrimaryAddress is returned when unlinking the primary address of an account
+	ErrPrimaryAddress = NewError(CodePrimaryAddress, "cannot unlink the primary address")
+)
+
 // UserService defines the interface for user business logic
 type UserService interface {
 	// Register creates a new user
@@ -21,24 +33,36 @@ type UserService interface {
 	// Update updates an existing user
 	Update(ctx context.Context, user *models.User) error
 
+	// Verify marks a user as verified
+	Verify(ctx context.Context, id uint) (*models.User, error)
+
 	// Delete marks a user as deleted (soft delete)
 	Delete(ctx context.Context, id uint) error
 
-	// VerifySignature verifies that a signature was created by the user's address
-	VerifySignature(ctx context.Context, address, message, signature string) (bool, error)
+	// AssignRole changes the role of a user
+	AssignRole(ctx context.Context, id uint, role models.UserRole) (*models.User, error)
+
+	// ListAddresses returns the addresses linked to a user, primary first
+	ListAddresses(ctx context.Context, userID uint) ([]*models.UserAddress, error)
+
+	// LinkAddress links a secondary address to a user, once it has proven ownership of both
+	LinkAddress(ctx context.Context, userID uint, address string) (*models.UserAddress, error)
+
+	// UnlinkAddress unlinks a secondary address from a user
+	UnlinkAddress(ctx context.Context, userID uint, address string) error
 
-	// GenerateAuthToken generates an authentication token for a user
-	GenerateAuthToken(ctx context.Context, user *models.User) (string, time.Time, error)
+	// SetPrimaryAddress makes a linked address the primary address of a user
+	SetPrimaryAddress(ctx context.Context, userID uint, address string) (*models.User, error)
 
-	// ListUsers retrieves all users with optional pagination
-	ListUsers(ctx context.Context, offset, limit int) ([]*models.User, error)
+	// ListUsers retrieves the users matching a filter, oldest first, with pagination
+	ListUsers(ctx context.Context, filter models.UserFilter, offset, limit int) ([]*models.User, error)
 
-	// GenerateNonce generates a new nonce for signature verification
-	GenerateNonce(address string) string
+	// ListUsersByCursor retrieves up to limit users matching a filter, oldest first, from a cursor
+	ListUsersByCursor(ctx context.Context, filter models.UserFilter, cursor *models.Cursor, limit int) ([]*models.User, error)
 
 	// Count returns the total number of users
 	Count(ctx context.Context) (int64, error)
 
 	// CountWithFilter counts users that match the given filter criteria
-	CountWithFilter(ctx context.Context, filter map[string]any) (int64, error)
+	CountWithFilter(ctx context.Context, filter models.UserFilter) (int64, error)
 }
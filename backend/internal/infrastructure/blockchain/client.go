@@ -154,6 +154,14 @@ func (ec *EthClient) GetContractAddress(name string) (common.Address, error) {
 	return address, nil
 }
 
+// BlockNumber returns the number of the most recent block
+func (ec *EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	if !ec.initialized {
+		return 0, errors.New("ethereum client not initialized")
+	}
+	return ec.client.BlockNumber(ctx)
+}
+
 // Close closes the client connection
 func (ec *EthClient) Close() {
 	ec.mu.Lock()
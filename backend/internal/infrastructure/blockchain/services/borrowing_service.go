@@ -24,12 +24,18 @@ type BorrowingService struct {
 // NewBorrowingService creates a new instance of BorrowingService
 func NewBorrowingService() (*BorrowingService, error) {
 	ethClient := blockchain.GetInstance()
-	client, err := ethClient.GetClient()
+	address, err := ethClient.GetContractAddress("Borrowing")
 	if err != nil {
 		return nil, err
 	}
 
-	address, err := ethClient.GetContractAddress("Borrowing")
+	return NewBorrowingServiceAt(address)
+}
+
+// NewBorrowingServiceAt creates a new instance of BorrowingService bound to the borrowing contract at the given address
+func NewBorrowingServiceAt(address common.Address) (*BorrowingService, error) {
+	ethClient := blockchain.GetInstance()
+	client, err := ethClient.GetClient()
 	if err != nil {
 		return nil, err
 	}
@@ -64,46 +70,52 @@ func (s *BorrowingService) ReduceDebt(auth *bind.TransactOpts, borrower common.A
 
 // GetBorrowToken returns the amount borrowed by a user
 func (s *BorrowingService) GetBorrowToken(ctx context.Context, user common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.GetBorrowToken(opts, user)
 }
 
 // GetAllBorrowToken returns the total amount borrowed from the protocol
 func (s *BorrowingService) GetAllBorrowToken(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.GetAllBorrowToken(opts)
 }
 
 // GetCurrentRate returns the current interest rate for borrowing
 func (s *BorrowingService) GetCurrentRate(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.GetCurrentRate(opts)
 }
 
 // GetBorrowedPrincipal returns the principal amount borrowed by a user
 func (s *BorrowingService) GetBorrowedPrincipal(ctx context.Context, user common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.BorrowedPrincipal(opts, user)
 }
 
 // GetTotalBorrowed returns the total amount borrowed from the protocol
 func (s *BorrowingService) GetTotalBorrowed(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.TotalBorrowed(opts)
 }
 
 // GetMinInterestRate returns the minimum interest rate
 func (s *BorrowingService) GetMinInterestRate(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.RMin(opts)
 }
 
 // GetMaxInterestRate returns the maximum interest rate
 func (s *BorrowingService) GetMaxInterestRate(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.RMax(opts)
 }
 
+// GetToken returns the address of the borrowed token
+func (s *BorrowingService) GetToken(ctx context.Context) (common.Address, error) {
+	opts := callOpts(ctx)
+	return s.contract.Token(opts)
+}
+
 // ContractAddress returns the address of the borrowing contract
 func (s *BorrowingService) ContractAddress() common.Address {
 	return s.address
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// blockNumberKey is the context key of the block contract reads are pinned to
+type blockNumberKey struct{}
+
+// AtBlock returns a context whose contract reads see the state at a block rather than the latest state,
+// so that figures read one after another stay consistent
+func AtBlock(ctx context.Context, blockNumber uint64) context.Context {
+	return context.WithValue(ctx, blockNumberKey{}, new(big.Int).SetUint64(blockNumber))
+}
+
+// callOpts returns the options of a contract read, pinned to the block of the context if any
+func callOpts(ctx context.Context) *bind.CallOpts {
+	blockNumber, _ := ctx.Value(blockNumberKey{}).(*big.Int)
+	return &bind.CallOpts{Context: ctx, BlockNumber: blockNumber}
+}
@@ -24,12 +24,18 @@ type CollateralService struct {
 // NewCollateralService creates a new instance of CollateralService
 func NewCollateralService() (*CollateralService, error) {
 	ethClient := blockchain.GetInstance()
-	client, err := ethClient.GetClient()
+	address, err := ethClient.GetContractAddress("Collateral")
 	if err != nil {
 		return nil, err
 	}
 
-	address, err := ethClient.GetContractAddress("Collateral")
+	return NewCollateralServiceAt(address)
+}
+
+// NewCollateralServiceAt creates a new instance of CollateralService bound to the collateral contract at the given address
+func NewCollateralServiceAt(address common.Address) (*CollateralService, error) {
+	ethClient := blockchain.GetInstance()
+	client, err := ethClient.GetClient()
 	if err != nil {
 		return nil, err
 	}
@@ -64,46 +70,58 @@ func (s *CollateralService) Liquidate(auth *bind.TransactOpts, borrower common.A
 
 // GetCollateralRatio returns the collateral ratio for a specific user
 func (s *CollateralService) GetCollateralRatio(ctx context.Context, user common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.GetCollateralRatio(opts, user)
 }
 
 // GetMaxBorrowableAmount returns the maximum amount a user can borrow
 func (s *CollateralService) GetMaxBorrowableAmount(ctx context.Context, user common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.GetMaxBorrowableAmount(opts, user)
 }
 
 // CanBorrow checks if a user can borrow a specific amount
 func (s *CollateralService) CanBorrow(ctx context.Context, user common.Address, borrowAmount *big.Int) (bool, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.CanBorrow(opts, user, borrowAmount)
 }
 
 // GetCollateralBalance returns the collateral balance of a user
 func (s *CollateralService) GetCollateralBalance(ctx context.Context, user common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.CollateralBalance(opts, user)
 }
 
 // GetMinCollateralRatio returns the minimum collateral ratio required
 func (s *CollateralService) GetMinCollateralRatio(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.MINCOLLATERALRATIO(opts)
 }
 
 // GetLiquidationThreshold returns the liquidation threshold
 func (s *CollateralService) GetLiquidationThreshold(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.LIQUIDATIONTHRESHOLD(opts)
 }
 
+// GetMaxBorrowingPercentage returns the maximum percentage of collateral that can be borrowed
+func (s *CollateralService) GetMaxBorrowingPercentage(ctx context.Context) (*big.Int, error) {
+	opts := callOpts(ctx)
+	return s.contract.MAXBORROWINGPERCENTAGE(opts)
+}
+
 // GetLiquidationBonus returns the liquidation bonus
 func (s *CollateralService) GetLiquidationBonus(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.LIQUIDATIONBONUS(opts)
 }
 
+// GetToken returns the address of the collateral token
+func (s *CollateralService) GetToken(ctx context.Context) (common.Address, error) {
+	opts := callOpts(ctx)
+	return s.contract.Token(opts)
+}
+
 // ContractAddress returns the address of the collateral contract
 func (s *CollateralService) ContractAddress() common.Address {
 	return s.address
@@ -24,12 +24,18 @@ type LendingPoolService struct {
 // NewLendingPoolService creates a new instance of LendingPoolService
 func NewLendingPoolService() (*LendingPoolService, error) {
 	ethClient := blockchain.GetInstance()
-	client, err := ethClient.GetClient()
+	address, err := ethClient.GetContractAddress("LendingPool")
 	if err != nil {
 		return nil, err
 	}
 
-	address, err := ethClient.GetContractAddress("LendingPool")
+	return NewLendingPoolServiceAt(address)
+}
+
+// NewLendingPoolServiceAt creates a new instance of LendingPoolService bound to the lending pool contract at the given address
+func NewLendingPoolServiceAt(address common.Address) (*LendingPoolService, error) {
+	ethClient := blockchain.GetInstance()
+	client, err := ethClient.GetClient()
 	if err != nil {
 		return nil, err
 	}
@@ -59,19 +65,19 @@ func (s *LendingPoolService) Withdraw(auth *bind.TransactOpts, amount *big.Int)
 
 // GetLendingToken returns the user's lent token amount
 func (s *LendingPoolService) GetLendingToken(ctx context.Context, user common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.GetLendingToken(opts, user)
 }
 
 // GetAllLendingToken returns the total amount of tokens lent to the pool
 func (s *LendingPoolService) GetAllLendingToken(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.GetAllLendingToken(opts)
 }
 
 // GetAnnualInterestRate returns the current annual interest rate
 func (s *LendingPoolService) GetAnnualInterestRate(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.AnnualInterestRate(opts)
 }
 
@@ -82,13 +88,13 @@ func (s *LendingPoolService) UpdateUserInterest(auth *bind.TransactOpts, user co
 
 // GetTotalLending returns the total amount of tokens lent
 func (s *LendingPoolService) GetTotalLending(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.TotalLending(opts)
 }
 
 // GetUnderlying returns the address of the underlying token
 func (s *LendingPoolService) GetUnderlying(ctx context.Context) (common.Address, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.Underlying(opts)
 }
 
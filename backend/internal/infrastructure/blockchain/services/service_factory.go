@@ -2,6 +2,8 @@ package services
 
 import (
 	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // ServiceFactory provides a centralized way to access all contract services
@@ -15,6 +17,17 @@ type ServiceFactory struct {
 	lendingOnce    sync.Once
 	borrowingOnce  sync.Once
 	collateralOnce sync.Once
+
+	marketsMu sync.Mutex
+	markets   map[common.Address]*MarketContracts // Keyed by lending pool address
+}
+
+// MarketContracts groups the contract services of a single market
+type MarketContracts struct {
+	Token       *TokenService
+	LendingPool *LendingPoolService
+	Borrowing   *BorrowingService
+	Collateral  *CollateralService
 }
 
 var (
@@ -90,3 +103,48 @@ func (f *ServiceFactory) GetCollateralService() (*CollateralService, error) {
 
 	return f.collateralService, nil
 }
+
+// GetMarketContracts returns the contract services of the market formed by the given
+// contracts, binding them on first use. The token is the lending pool's underlying token
+func (f *ServiceFactory) GetMarketContracts(token, lendingPool, borrowing, collateral common.Address) (*MarketContracts, error) {
+	f.marketsMu.Lock()
+	defer f.marketsMu.Unlock()
+
+	if contracts, exists := f.markets[lendingPool]; exists {
+		return contracts, nil
+	}
+
+	tokenService, err := NewTokenServiceAt(token)
+	if err != nil {
+		return nil, err
+	}
+
+	lendingPoolService, err := NewLendingPoolServiceAt(lendingPool)
+	if err != nil {
+		return nil, err
+	}
+
+	borrowingService, err := NewBorrowingServiceAt(borrowing)
+	if err != nil {
+		return nil, err
+	}
+
+	collateralService, err := NewCollateralServiceAt(collateral)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := &MarketContracts{
+		Token:       tokenService,
+		LendingPool: lendingPoolService,
+		Borrowing:   borrowingService,
+		Collateral:  collateralService,
+	}
+
+	if f.markets == nil {
+		f.markets = make(map[common.Address]*MarketContracts)
+	}
+	f.markets[lendingPool] = contracts
+
+	return contracts, nil
+}
@@ -24,12 +24,18 @@ type TokenService struct {
 // NewTokenService creates a new instance of TokenService
 func NewTokenService() (*TokenService, error) {
 	ethClient := blockchain.GetInstance()
-	client, err := ethClient.GetClient()
+	address, err := ethClient.GetContractAddress("Token")
 	if err != nil {
 		return nil, err
 	}
 
-	address, err := ethClient.GetContractAddress("Token")
+	return NewTokenServiceAt(address)
+}
+
+// NewTokenServiceAt creates a new instance of TokenService bound to the ERC20 token at the given address
+func NewTokenServiceAt(address common.Address) (*TokenService, error) {
+	ethClient := blockchain.GetInstance()
+	client, err := ethClient.GetClient()
 	if err != nil {
 		return nil, err
 	}
@@ -49,31 +55,31 @@ func NewTokenService() (*TokenService, error) {
 
 // BalanceOf retrieves the token balance of a specific address
 func (s *TokenService) BalanceOf(ctx context.Context, address common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.BalanceOf(opts, address)
 }
 
 // TotalSupply returns the total token supply
 func (s *TokenService) TotalSupply(ctx context.Context) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.TotalSupply(opts)
 }
 
 // Name returns the name of the token
 func (s *TokenService) Name(ctx context.Context) (string, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.Name(opts)
 }
 
 // Symbol returns the symbol of the token
 func (s *TokenService) Symbol(ctx context.Context) (string, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.Symbol(opts)
 }
 
 // Decimals returns the number of decimals of the token
 func (s *TokenService) Decimals(ctx context.Context) (uint8, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.Decimals(opts)
 }
 
@@ -94,7 +100,7 @@ func (s *TokenService) TransferFrom(auth *bind.TransactOpts, from common.Address
 
 // Allowance returns the remaining number of tokens that spender will be allowed to spend on behalf of owner
 func (s *TokenService) Allowance(ctx context.Context, owner common.Address, spender common.Address) (*big.Int, error) {
-	opts := &bind.CallOpts{Context: ctx}
+	opts := callOpts(ctx)
 	return s.contract.Allowance(opts, owner, spender)
 }
 
@@ -0,0 +1,122 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// eip1271MagicValue is returned by isValidSignature when a contract wallet accepts a signature
+var eip1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+const eip1271ABI = `[{"inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+
+var eip1271Contract = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(eip1271ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// HashPersonalMessage returns the EIP-191 hash of a message, as signed by personal_sign
+func HashPersonalMessage(message []byte) common.Hash {
+	return common.BytesToHash(accounts.TextHash(message))
+}
+
+// VerifyPersonalSignature checks that an EIP-191 personal signature of a message was made by an address
+func (ec *EthClient) VerifyPersonalSignature(ctx context.Context, signer common.Address, message, signature []byte) (bool, error) {
+	return ec.VerifySignature(ctx, signer, HashPersonalMessage(message), signature)
+}
+
+// VerifySignature checks that a signature of a hash was made by an address. Signatures
+// recovering to the address are accepted, and addresses with code are asked whether they
+// accept the signature through EIP-1271, so contract wallets such as Safe can sign too
+func (ec *EthClient) VerifySignature(ctx context.Context, signer common.Address, hash common.Hash, signature []byte) (bool, error) {
+	if recoverSigner(hash, signature) == signer {
+		return true, nil
+	}
+
+	if !ec.initialized {
+		return false, errors.New("ethereum client not initialized")
+	}
+
+	code, err := ec.client.CodeAt(ctx, signer, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get code of %s: %w", signer.Hex(), err)
+	}
+
+	// Externally owned accounts can only sign with their key
+	if len(code) == 0 {
+		return false, nil
+	}
+
+	return ec.isValidSignature(ctx, signer, hash, signature)
+}
+
+// isValidSignature calls the EIP-1271 isValidSignature(bytes32,bytes) method of a contract wallet
+func (ec *EthClient) isValidSignature(ctx context.Context, wallet common.Address, hash common.Hash, signature []byte) (bool, error) {
+	data, err := eip1271Contract.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := ec.client.CallContract(ctx, ethereum.CallMsg{To: &wallet, Data: data}, nil)
+	if isRevert(err) {
+		// Wallets revert on signatures they reject
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to call isValidSignature of %s: %w", wallet.Hex(), err)
+	}
+
+	// The magic value is returned as a left-aligned bytes4 in a 32 byte word
+	return len(result) >= 4 && bytes.Equal(result[:4], eip1271MagicValue[:]), nil
+}
+
+// revertErrorCode is the JSON-RPC error code of calls that reverted
+const revertErrorCode = 3
+
+// isRevert reports whether a call failed because the contract reverted, as opposed to the node failing
+// to run it. Every node response error carries a code, so reverts are told apart by theirs, or by the
+// message on nodes that use a generic code
+func isRevert(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == revertErrorCode {
+		return true
+	}
+	return strings.Contains(err.Error(), "execution reverted")
+}
+
+// recoverSigner returns the address that made a 65 byte ECDSA signature of a hash, or the zero address
+func recoverSigner(hash common.Hash, signature []byte) common.Address {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}
+	}
+
+	// Work on a copy so the caller's signature is left untouched
+	sig := append([]byte{}, signature...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return common.Address{}
+	}
+
+	return crypto.PubkeyToAddress(*pubKey)
+}
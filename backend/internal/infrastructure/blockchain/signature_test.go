@@ -0,0 +1,85 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// rpcError is an error response of a node
+type rpcError struct {
+	code    int
+	message string
+}
+
+func (e *rpcError) Error() string  { return e.message }
+func (e *rpcError) ErrorCode() int { return e.code }
+
+func TestIsRevert(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error", err: nil, want: false},
+		{name: "revert code", err: &rpcError{code: 3, message: "execution reverted: GS026"}, want: true},
+		{name: "revert without reason", err: &rpcError{code: -32000, message: "execution reverted"}, want: true},
+		{name: "wrapped revert", err: fmt.Errorf("call failed: %w", &rpcError{code: 3, message: "execution reverted"}), want: true},
+		{name: "node error", err: &rpcError{code: -32000, message: "header not found"}, want: false},
+		{name: "rate limited node", err: &rpcError{code: 429, message: "too many requests"}, want: false},
+		{name: "network failure", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: false},
+		{name: "timeout", err: context.DeadlineExceeded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRevert(tt.err); got != tt.want {
+				t.Errorf("isRevert(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoverSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	hash := HashPersonalMessage([]byte("hello"))
+
+	signature, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletSignature := append([]byte{}, signature...)
+	walletSignature[crypto.RecoveryIDOffset] += 27
+
+	tests := []struct {
+		name      string
+		signature []byte
+		want      common.Address
+	}{
+		{name: "recovery id 0 or 1", signature: signature, want: signer},
+		{name: "recovery id 27 or 28", signature: walletSignature, want: signer},
+		{name: "too short", signature: signature[:64], want: common.Address{}},
+		{name: "empty", signature: nil, want: common.Address{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recoverSigner(hash, tt.signature); got != tt.want {
+				t.Errorf("got %s, want %s", got.Hex(), tt.want.Hex())
+			}
+		})
+	}
+
+	if walletSignature[crypto.RecoveryIDOffset] < 27 {
+		t.Error("recoverSigner changed the signature of the caller")
+	}
+}
@@ -0,0 +1,110 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/contracts/generated"
+)
+
+// aggregator is a bound Chainlink-style price feed contract
+type aggregator struct {
+	contract *generated.AggregatorV3
+	decimals uint8
+}
+
+// AggregatorFeed reads prices from Chainlink-style AggregatorV3 contracts,
+// one aggregator per token
+type AggregatorFeed struct {
+	client      *ethclient.Client
+	addresses   map[common.Address]common.Address // token -> aggregator
+	mu          sync.Mutex
+	aggregators map[common.Address]*aggregator
+}
+
+// NewAggregatorFeed creates a feed backed by the given token to aggregator mapping
+func NewAggregatorFeed(client *ethclient.Client, aggregators map[common.Address]common.Address) *AggregatorFeed {
+	return &AggregatorFeed{
+		client:      client,
+		addresses:   aggregators,
+		aggregators: make(map[common.Address]*aggregator),
+	}
+}
+
+// Name returns the identifier of the feed
+func (f *AggregatorFeed) Name() string {
+	return "aggregator"
+}
+
+// LatestPrice returns the answer of the latest round of the token's aggregator
+func (f *AggregatorFeed) LatestPrice(ctx context.Context, token common.Address) (*Price, error) {
+	agg, err := f.getAggregator(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	round, err := agg.contract.LatestRoundData(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read latest round: %w", err)
+	}
+
+	if round.Answer == nil || round.Answer.Sign() <= 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	// An answer carried over from an earlier round has not been refreshed
+	if round.AnsweredInRound.Cmp(round.RoundId) < 0 {
+		return nil, fmt.Errorf("%w: answered in round %s, latest round %s", ErrStalePrice, round.AnsweredInRound, round.RoundId)
+	}
+
+	if round.UpdatedAt == nil || round.UpdatedAt.Sign() == 0 {
+		return nil, fmt.Errorf("%w: round %s is incomplete", ErrStalePrice, round.RoundId)
+	}
+
+	return &Price{
+		Token:     token,
+		Value:     NormalizeDecimals(round.Answer, agg.decimals),
+		UpdatedAt: time.Unix(round.UpdatedAt.Int64(), 0),
+		Source:    f.Name(),
+	}, nil
+}
+
+// getAggregator binds the aggregator of a token and caches its decimals
+func (f *AggregatorFeed) getAggregator(ctx context.Context, token common.Address) (*aggregator, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if agg, exists := f.aggregators[token]; exists {
+		return agg, nil
+	}
+
+	address, exists := f.addresses[token]
+	if !exists {
+		return nil, ErrPriceNotFound
+	}
+
+	contract, err := generated.NewAggregatorV3(address, f.client)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := contract.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aggregator decimals: %w", err)
+	}
+
+	agg := &aggregator{
+		contract: contract,
+		decimals: decimals,
+	}
+	f.aggregators[token] = agg
+
+	return agg, nil
+}
@@ -0,0 +1,106 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MedianOracle combines several feeds and reports the median of their fresh prices
+type MedianOracle struct {
+	feeds  []PriceFeed
+	maxAge time.Duration
+}
+
+// NewMedianOracle creates an oracle over the given feeds; prices older than maxAge are ignored
+func NewMedianOracle(maxAge time.Duration, feeds ...PriceFeed) *MedianOracle {
+	return &MedianOracle{
+		feeds:  feeds,
+		maxAge: maxAge,
+	}
+}
+
+// Name returns the identifier of the oracle
+func (o *MedianOracle) Name() string {
+	return "median"
+}
+
+// LatestPrice returns the median of the fresh prices reported by the feeds
+func (o *MedianOracle) LatestPrice(ctx context.Context, token common.Address) (*Price, error) {
+	if len(o.feeds) == 0 {
+		return nil, ErrPriceNotFound
+	}
+
+	now := time.Now()
+	var prices []*Price
+	var failures []string
+	stale := false
+
+	for _, feed := range o.feeds {
+		price, err := feed.LatestPrice(ctx, token)
+		if err != nil {
+			if errors.Is(err, ErrStalePrice) {
+				stale = true
+			}
+			if !errors.Is(err, ErrPriceNotFound) {
+				failures = append(failures, fmt.Sprintf("%s: %v", feed.Name(), err))
+			}
+			continue
+		}
+
+		if price.IsStale(o.maxAge, now) {
+			stale = true
+			failures = append(failures, fmt.Sprintf("%s: %v (updated %s)", feed.Name(), ErrStalePrice, price.UpdatedAt.Format(time.RFC3339)))
+			continue
+		}
+
+		prices = append(prices, price)
+	}
+
+	if len(prices) == 0 {
+		switch {
+		case stale:
+			return nil, fmt.Errorf("%w for %s: %s", ErrStalePrice, token.Hex(), strings.Join(failures, "; "))
+		case len(failures) > 0:
+			return nil, fmt.Errorf("no price available for %s: %s", token.Hex(), strings.Join(failures, "; "))
+		default:
+			return nil, ErrPriceNotFound
+		}
+	}
+
+	return median(token, prices), nil
+}
+
+// median returns the median price; with an even number of prices the two middle values are averaged
+func median(token common.Address, prices []*Price) *Price {
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Value.Cmp(prices[j].Value) < 0
+	})
+
+	middle := len(prices) / 2
+	value := new(big.Int).Set(prices[middle].Value)
+	updatedAt := prices[middle].UpdatedAt
+	sources := []string{prices[middle].Source}
+
+	if len(prices)%2 == 0 {
+		lower := prices[middle-1]
+		value.Add(value, lower.Value).Quo(value, big.NewInt(2))
+		if lower.UpdatedAt.Before(updatedAt) {
+			updatedAt = lower.UpdatedAt
+		}
+		sources = append([]string{lower.Source}, sources...)
+	}
+
+	return &Price{
+		Token:     token,
+		Value:     value,
+		UpdatedAt: updatedAt,
+		Source:    strings.Join(sources, ","),
+	}
+}
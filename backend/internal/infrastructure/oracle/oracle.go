@@ -0,0 +1,123 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PriceDecimals is the precision every feed normalises its prices to
+const PriceDecimals = 18
+
+var (
+	// ErrPriceNotFound is returned when a feed has no price for a token
+	ErrPriceNotFound = errors.New("price not found")
+	// ErrStalePrice is returned when the freshest available price is older than allowed
+	ErrStalePrice = errors.New("price is stale")
+	// ErrInvalidPrice is returned when a feed reports a zero or negative price
+	ErrInvalidPrice = errors.New("invalid price")
+)
+
+// Price is a USD price normalised to PriceDecimals decimals
+type Price struct {
+	Token     common.Address
+	Value     *big.Int  // USD per whole token, in 1e18 precision
+	UpdatedAt time.Time // When the source last updated the price
+	Source    string    // Name of the feed that produced the price
+}
+
+// IsStale reports whether the price is older than maxAge at the given time
+func (p *Price) IsStale(maxAge time.Duration, now time.Time) bool {
+	if maxAge <= 0 {
+		return false
+	}
+	return now.Sub(p.UpdatedAt) > maxAge
+}
+
+// PriceFeed is a source of USD prices for tokens
+type PriceFeed interface {
+	// Name returns a short identifier of the feed
+	Name() string
+
+	// LatestPrice returns the most recent price known for a token
+	LatestPrice(ctx context.Context, token common.Address) (*Price, error)
+}
+
+// NormalizeDecimals rescales a value expressed with the given decimals to PriceDecimals
+func NormalizeDecimals(value *big.Int, decimals uint8) *big.Int {
+	result := new(big.Int).Set(value)
+
+	switch {
+	case decimals < PriceDecimals:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(PriceDecimals-decimals)), nil)
+		result.Mul(result, scale)
+	case decimals > PriceDecimals:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-PriceDecimals)), nil)
+		result.Quo(result, scale)
+	}
+
+	return result
+}
+
+// ParseDecimal parses a decimal string such as "1234.56" into a PriceDecimals fixed-point value
+func ParseDecimal(value string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("invalid decimal value: %s", value)
+	}
+
+	if len(fraction) > PriceDecimals {
+		fraction = fraction[:PriceDecimals]
+	}
+	fraction += strings.Repeat("0", PriceDecimals-len(fraction))
+
+	result, success := new(big.Int).SetString(whole+fraction, 10)
+	if !success {
+		return nil, fmt.Errorf("invalid decimal value: %s", value)
+	}
+
+	return result, nil
+}
+
+// FormatDecimal formats a PriceDecimals fixed-point value with the given number of decimal places
+func FormatDecimal(value *big.Int, places int) string {
+	negative := value.Sign() < 0
+	abs := new(big.Int).Abs(value)
+
+	// Round half up to the requested number of places
+	if places < PriceDecimals {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(PriceDecimals-places)), nil)
+		half := new(big.Int).Quo(scale, big.NewInt(2))
+		abs.Add(abs, half).Quo(abs, scale)
+	} else {
+		places = PriceDecimals
+	}
+
+	digits := abs.String()
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+
+	result := digits
+	if places > 0 {
+		result = digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+	}
+
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// ValueInUSD converts a token amount expressed with tokenDecimals into a USD value in 1e18 precision
+func ValueInUSD(amount *big.Int, tokenDecimals uint8, price *Price) *big.Int {
+	value := new(big.Int).Mul(amount, price.Value)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(tokenDecimals)), nil)
+	return value.Quo(value, scale)
+}
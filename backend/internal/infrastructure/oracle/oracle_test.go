@@ -0,0 +1,464 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+var testToken = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+// fakeFeed reports a fixed price, or a fixed error
+type fakeFeed struct {
+	name  string
+	price string // Decimal USD price
+	age   time.Duration
+	err   error
+}
+
+func (f *fakeFeed) Name() string {
+	return f.name
+}
+
+func (f *fakeFeed) LatestPrice(ctx context.Context, token common.Address) (*Price, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	value, err := ParseDecimal(f.price)
+	if err != nil {
+		return nil, err
+	}
+	return &Price{Token: token, Value: value, UpdatedAt: time.Now().Add(-f.age), Source: f.name}, nil
+}
+
+// fakeSamples serves price samples from memory
+type fakeSamples struct {
+	samples []*models.PriceSample // Oldest first
+}
+
+func (r *fakeSamples) Create(ctx context.Context, sample *models.PriceSample) error {
+	r.samples = append(r.samples, sample)
+	return nil
+}
+
+func (r *fakeSamples) FindInRange(ctx context.Context, tokenAddress string, from, to time.Time) ([]*models.PriceSample, error) {
+	var found []*models.PriceSample
+	for _, sample := range r.samples {
+		if sample.TokenAddress == tokenAddress && !sample.SampledAt.Before(from) && !sample.SampledAt.After(to) {
+			found = append(found, sample)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeSamples) FindLatestBefore(ctx context.Context, tokenAddress string, before time.Time) (*models.PriceSample, error) {
+	var latest *models.PriceSample
+	for _, sample := range r.samples {
+		if sample.TokenAddress == tokenAddress && sample.SampledAt.Before(before) {
+			latest = sample
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeSamples) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func mustDecimal(t *testing.T, value string) *big.Int {
+	t.Helper()
+	parsed, err := ParseDecimal(value)
+	if err != nil {
+		t.Fatalf("ParseDecimal(%q): %v", value, err)
+	}
+	return parsed
+}
+
+func TestMedianOracle(t *testing.T) {
+	tests := []struct {
+		name    string
+		feeds   []PriceFeed
+		want    string // Decimal price, when no error is expected
+		source  string
+		wantErr error
+	}{
+		{
+			name:   "single feed",
+			feeds:  []PriceFeed{&fakeFeed{name: "a", price: "2000"}},
+			want:   "2000",
+			source: "a",
+		},
+		{
+			name: "odd number of feeds takes the middle price",
+			feeds: []PriceFeed{
+				&fakeFeed{name: "a", price: "2100"},
+				&fakeFeed{name: "b", price: "1900"},
+				&fakeFeed{name: "c", price: "2000"},
+			},
+			want:   "2000",
+			source: "c",
+		},
+		{
+			name: "even number of feeds averages the middle prices",
+			feeds: []PriceFeed{
+				&fakeFeed{name: "a", price: "1"},
+				&fakeFeed{name: "b", price: "2"},
+			},
+			want:   "1.5",
+			source: "a,b",
+		},
+		{
+			name: "stale prices are left out",
+			feeds: []PriceFeed{
+				&fakeFeed{name: "a", price: "1"},
+				&fakeFeed{name: "b", price: "500", age: time.Hour},
+			},
+			want:   "1",
+			source: "a",
+		},
+		{
+			name: "failing feeds are left out",
+			feeds: []PriceFeed{
+				&fakeFeed{name: "a", err: errors.New("node unreachable")},
+				&fakeFeed{name: "b", price: "3"},
+			},
+			want:   "3",
+			source: "b",
+		},
+		{
+			name:    "only stale prices",
+			feeds:   []PriceFeed{&fakeFeed{name: "a", price: "1", age: time.Hour}},
+			wantErr: ErrStalePrice,
+		},
+		{
+			name:    "stale price reported by a feed",
+			feeds:   []PriceFeed{&fakeFeed{name: "a", err: ErrStalePrice}},
+			wantErr: ErrStalePrice,
+		},
+		{
+			name:    "no feed knows the token",
+			feeds:   []PriceFeed{&fakeFeed{name: "a", err: ErrPriceNotFound}},
+			wantErr: ErrPriceNotFound,
+		},
+		{
+			name:    "no feeds",
+			wantErr: ErrPriceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := NewMedianOracle(time.Minute, tt.feeds...).LatestPrice(context.Background(), testToken)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := mustDecimal(t, tt.want); price.Value.Cmp(want) != 0 {
+				t.Errorf("got price %s, want %s", price.Value, want)
+			}
+			if price.Source != tt.source {
+				t.Errorf("got source %q, want %q", price.Source, tt.source)
+			}
+		})
+	}
+}
+
+func TestMedianOracleFeedErrorWithoutPrice(t *testing.T) {
+	oracle := NewMedianOracle(time.Minute, &fakeFeed{name: "a", err: errors.New("node unreachable")})
+	_, err := oracle.LatestPrice(context.Background(), testToken)
+	if err == nil || errors.Is(err, ErrPriceNotFound) || errors.Is(err, ErrStalePrice) {
+		t.Fatalf("got error %v, want the failure of the feed", err)
+	}
+}
+
+func TestPriceIsStale(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		age    time.Duration
+		maxAge time.Duration
+		want   bool
+	}{
+		{"fresh", time.Second, time.Minute, false},
+		{"exactly max age", time.Minute, time.Minute, false},
+		{"older than max age", time.Minute + time.Second, time.Minute, true},
+		{"no max age", 24 * time.Hour, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := &Price{UpdatedAt: now.Add(-tt.age)}
+			if got := price.IsStale(tt.maxAge, now); got != tt.want {
+				t.Errorf("IsStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeWeightedAverage(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+	sample := func(price string, at time.Duration) *models.PriceSample {
+		return &models.PriceSample{Price: price, SampledAt: start.Add(at)}
+	}
+
+	tests := []struct {
+		name    string
+		samples []*models.PriceSample
+		want    int64
+		wantErr error
+	}{
+		{
+			name:    "single sample covers the window",
+			samples: []*models.PriceSample{sample("100", 0)},
+			want:    100,
+		},
+		{
+			name:    "samples weighted by how long they held",
+			samples: []*models.PriceSample{sample("100", 0), sample("200", 5*time.Minute)},
+			want:    150,
+		},
+		{
+			name:    "opening sample only counts from the start of the window",
+			samples: []*models.PriceSample{sample("1000", -time.Hour), sample("100", 8*time.Minute)},
+			want:    820, // 1000 for 8 minutes, 100 for 2
+		},
+		{
+			name:    "sample at the very end of the window",
+			samples: []*models.PriceSample{sample("300", 10*time.Minute)},
+			want:    300,
+		},
+		{
+			name:    "malformed sample",
+			samples: []*models.PriceSample{sample("not a number", 0)},
+			wantErr: ErrInvalidPrice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := timeWeightedAverage(tt.samples, start, end)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Cmp(big.NewInt(tt.want)) != 0 {
+				t.Errorf("got %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTWAPFeed(t *testing.T) {
+	now := time.Now()
+	samples := &fakeSamples{samples: []*models.PriceSample{
+		{TokenAddress: testToken.Hex(), Price: "100", SampledAt: now.Add(-2 * time.Hour)},
+		{TokenAddress: testToken.Hex(), Price: "200", SampledAt: now.Add(-30 * time.Minute)},
+	}}
+	feed := NewTWAPFeed(samples, time.Hour)
+
+	price, err := feed.LatestPrice(context.Background(), testToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 100 held for the first half of the window, 200 for the second; allow for the clock moving on
+	if price.Value.Cmp(big.NewInt(149)) < 0 || price.Value.Cmp(big.NewInt(151)) > 0 {
+		t.Errorf("got price %s, want about 150", price.Value)
+	}
+	if !price.UpdatedAt.Equal(samples.samples[1].SampledAt) {
+		t.Errorf("got updated at %s, want the latest sample", price.UpdatedAt)
+	}
+
+	if _, err := feed.LatestPrice(context.Background(), common.HexToAddress("0x01")); !errors.Is(err, ErrPriceNotFound) {
+		t.Errorf("got error %v for a token without samples, want %v", err, ErrPriceNotFound)
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "1", want: "1000000000000000000"},
+		{value: "1234.56", want: "1234560000000000000000"},
+		{value: " 0.5 ", want: "500000000000000000"},
+		{value: "0.0000000000000000019", want: "1"}, // Digits past 18 decimals are dropped
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+		{value: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDecimal(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+	}{
+		{value: "1234.56", places: 2, want: "1234.56"},
+		{value: "1.005", places: 2, want: "1.01"}, // Rounds half up
+		{value: "0.004", places: 2, want: "0.00"},
+		{value: "42", places: 0, want: "42"},
+		{value: "0.000000000000000001", places: 18, want: "0.000000000000000001"},
+		{value: "-2.5", places: 1, want: "-2.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := FormatDecimal(mustDecimal(t, tt.value), tt.places); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDecimals(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    int64
+		decimals uint8
+		want     string
+	}{
+		{name: "chainlink 8 decimals", value: 200000000000, decimals: 8, want: "2000000000000000000000"},
+		{name: "already 18 decimals", value: 5, decimals: 18, want: "5"},
+		{name: "more than 18 decimals", value: 1234, decimals: 20, want: "12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeDecimals(big.NewInt(tt.value), tt.decimals); got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueInUSD(t *testing.T) {
+	price := &Price{Value: mustDecimal(t, "2000")}
+
+	tests := []struct {
+		name     string
+		amount   string
+		decimals uint8
+		want     string
+	}{
+		{name: "whole token", amount: "1000000000000000000", decimals: 18, want: "2000"},
+		{name: "six decimals", amount: "500000", decimals: 6, want: "1000"},
+		{name: "nothing", amount: "0", decimals: 18, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, _ := new(big.Int).SetString(tt.amount, 10)
+			if got := ValueInUSD(amount, tt.decimals, price); got.Cmp(mustDecimal(t, tt.want)) != 0 {
+				t.Errorf("got %s, want %s", got, mustDecimal(t, tt.want))
+			}
+		})
+	}
+}
+
+func TestStaticFeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"`+testToken.Hex()+`": "1.25"}`, time.Now().Add(-time.Minute))
+	feed, err := NewStaticFeed(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	price, err := feed.LatestPrice(context.Background(), testToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if price.Value.Cmp(mustDecimal(t, "1.25")) != 0 || price.Source != "static" {
+		t.Errorf("got %s from %s, want 1.25 from static", price.Value, price.Source)
+	}
+	if price.IsStale(time.Second, time.Now()) {
+		t.Error("static prices should always be fresh")
+	}
+
+	if _, err := feed.LatestPrice(context.Background(), common.HexToAddress("0x01")); !errors.Is(err, ErrPriceNotFound) {
+		t.Errorf("got error %v for an unknown token, want %v", err, ErrPriceNotFound)
+	}
+
+	// Changes to the file are picked up
+	write(`{"`+testToken.Hex()+`": "3"}`, time.Now())
+	price, err = feed.LatestPrice(context.Background(), testToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if price.Value.Cmp(mustDecimal(t, "3")) != 0 {
+		t.Errorf("got %s after reload, want 3", price.Value)
+	}
+}
+
+func TestStaticFeedInvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "not json", content: `prices`},
+		{name: "invalid address", content: `{"0x123": "1"}`},
+		{name: "invalid price", content: `{"` + testToken.Hex() + `": "one"}`},
+		{name: "zero price", content: `{"` + testToken.Hex() + `": "0"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prices.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewStaticFeed(path); err == nil {
+				t.Error("got no error, want the file rejected")
+			}
+		})
+	}
+}
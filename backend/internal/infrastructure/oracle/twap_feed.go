@@ -0,0 +1,112 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+// TWAPFeed computes time-weighted average prices from stored price samples
+type TWAPFeed struct {
+	samples repository.PriceSampleRepository
+	window  time.Duration
+}
+
+// NewTWAPFeed creates a TWAP feed averaging samples over the given window
+func NewTWAPFeed(samples repository.PriceSampleRepository, window time.Duration) *TWAPFeed {
+	return &TWAPFeed{
+		samples: samples,
+		window:  window,
+	}
+}
+
+// Name returns the identifier of the feed
+func (f *TWAPFeed) Name() string {
+	return "twap"
+}
+
+// LatestPrice returns the time-weighted average price of a token over the window ending now
+func (f *TWAPFeed) LatestPrice(ctx context.Context, token common.Address) (*Price, error) {
+	now := time.Now()
+	start := now.Add(-f.window)
+	tokenAddress := token.Hex()
+
+	samples, err := f.samples.FindInRange(ctx, tokenAddress, start, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price samples: %w", err)
+	}
+
+	// The sample in force when the window opened covers the time up to the first sample inside it
+	previous, err := f.samples.FindLatestBefore(ctx, tokenAddress, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price samples: %w", err)
+	}
+	if previous != nil {
+		samples = append([]*models.PriceSample{previous}, samples...)
+	}
+
+	if len(samples) == 0 {
+		return nil, ErrPriceNotFound
+	}
+
+	value, err := timeWeightedAverage(samples, start, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Price{
+		Token:     token,
+		Value:     value,
+		UpdatedAt: samples[len(samples)-1].SampledAt,
+		Source:    f.Name(),
+	}, nil
+}
+
+// timeWeightedAverage weights each sample by how long it stayed the latest one within [start, end].
+// Samples must be ordered oldest first
+func timeWeightedAverage(samples []*models.PriceSample, start, end time.Time) (*big.Int, error) {
+	weighted := new(big.Int)
+	totalWeight := new(big.Int)
+
+	for i, sample := range samples {
+		price, success := sample.PriceBigInt()
+		if !success {
+			return nil, fmt.Errorf("%w: malformed sample %d", ErrInvalidPrice, sample.ID)
+		}
+
+		from := sample.SampledAt
+		if from.Before(start) {
+			from = start
+		}
+
+		to := end
+		if i+1 < len(samples) {
+			to = samples[i+1].SampledAt
+		}
+
+		if !to.After(from) {
+			continue
+		}
+
+		weight := big.NewInt(int64(to.Sub(from) / time.Millisecond))
+		weighted.Add(weighted, new(big.Int).Mul(price, weight))
+		totalWeight.Add(totalWeight, weight)
+	}
+
+	// Every sample was taken at the very end of the window: use the latest one as is
+	if totalWeight.Sign() == 0 {
+		price, success := samples[len(samples)-1].PriceBigInt()
+		if !success {
+			return nil, ErrInvalidPrice
+		}
+		return price, nil
+	}
+
+	return weighted.Quo(weighted, totalWeight), nil
+}
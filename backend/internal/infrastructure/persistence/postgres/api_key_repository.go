@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new PostgreSQL implementation of APIKeyRepository
+func NewAPIKeyRepository(db *gorm.DB) repository.APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new API key into the database
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// FindByID retrieves an API key by ID
+func (r *apiKeyRepository) FindByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.WithContext(ctx).First(&key, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+// FindByPrefix retrieves an API key by its public prefix
+func (r *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+// ListByUser retrieves the API keys of a user, including revoked ones
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID uint) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marks an API key as revoked
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uint, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("revoked_at", revokedAt).Error
+}
+
+// UpdateLastUsed records when and from where an API key was last used
+func (r *apiKeyRepository) UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time, ip string) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Updates(map[string]any{
+		"last_used_at": usedAt,
+		"last_used_ip": ip,
+	}).Error
+}
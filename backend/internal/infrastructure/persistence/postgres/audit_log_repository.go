@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+// auditLogLockKey is the advisory lock serializing appends, so that each entry chains to the last one
+const auditLogLockKey = 0x61756469
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new PostgreSQL implementation of AuditLogRepository
+func NewAuditLogRepository(db *gorm.DB) repository.AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+// Append chains an entry to the last one, seals it with its hash and inserts it
+func (r *auditLogRepository) Append(ctx context.Context, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLogLockKey).Error; err != nil {
+			return err
+		}
+
+		var last models.AuditLog
+		err := tx.Select("hash").Order("id DESC").First(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		entry.PrevHash = last.Hash
+		entry.Hash = entry.ComputeHash()
+		return tx.Create(entry).Error
+	})
+}
+
+// List retrieves the entries matching a filter, newest first
+func (r *auditLogRepository) List(ctx context.Context, filter models.AuditLogFilter, offset, limit int) ([]*models.AuditLog, error) {
+	var entries []*models.AuditLog
+	query := applyAuditLogFilter(r.db.WithContext(ctx).Model(&models.AuditLog{}), filter).Order("id DESC")
+
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Count returns the number of entries matching a filter
+func (r *auditLogRepository) Count(ctx context.Context, filter models.AuditLogFilter) (int64, error) {
+	var count int64
+	err := applyAuditLogFilter(r.db.WithContext(ctx).Model(&models.AuditLog{}), filter).Count(&count).Error
+	return count, err
+}
+
+// ListAfter retrieves up to limit entries matching a filter with an ID above afterID, oldest first
+func (r *auditLogRepository) ListAfter(ctx context.Context, filter models.AuditLogFilter, afterID uint, limit int) ([]*models.AuditLog, error) {
+	var entries []*models.AuditLog
+	err := applyAuditLogFilter(r.db.WithContext(ctx).Model(&models.AuditLog{}), filter).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func applyAuditLogFilter(query *gorm.DB, filter models.AuditLogFilter) *gorm.DB {
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	return query
+}
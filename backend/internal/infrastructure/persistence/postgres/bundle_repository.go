@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type bundleRepository struct {
+	db *gorm.DB
+}
+
+// NewBundleRepository creates a new PostgreSQL implementation of BundleRepository
+func NewBundleRepository(db *gorm.DB) repository.BundleRepository {
+	return &bundleRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new bundle and its steps into the database
+func (r *bundleRepository) Create(ctx context.Context, bundle *models.Bundle) error {
+	return r.db.WithContext(ctx).Create(bundle).Error
+}
+
+// FindByID retrieves a bundle by ID with its steps in order
+func (r *bundleRepository) FindByID(ctx context.Context, id uint) (*models.Bundle, error) {
+	var bundle models.Bundle
+	result := r.db.WithContext(ctx).Preload("Steps", orderSteps).First(&bundle, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &bundle, nil
+}
+
+// ListByUser retrieves the bundles of a user with their steps, newest first, with pagination
+func (r *bundleRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*models.Bundle, error) {
+	var bundles []*models.Bundle
+	if err := r.db.WithContext(ctx).
+		Preload("Steps", orderSteps).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&bundles).Error; err != nil {
+		return nil, err
+	}
+	return bundles, nil
+}
+
+// CountByUser returns the number of bundles of a user
+func (r *bundleRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Bundle{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Update updates an existing bundle, without its steps
+func (r *bundleRepository) Update(ctx context.Context, bundle *models.Bundle) error {
+	return r.db.WithContext(ctx).Omit("Steps").Save(bundle).Error
+}
+
+// UpdateStep updates an existing bundle step
+func (r *bundleRepository) UpdateStep(ctx context.Context, step *models.BundleStep) error {
+	return r.db.WithContext(ctx).Save(step).Error
+}
+
+// ClaimUnheld marks the running signer bundles that no server holds, or whose claim expired, as claimed
+// until a given time and returns them with their steps, oldest first
+func (r *bundleRepository) ClaimUnheld(ctx context.Context, until time.Time) ([]*models.Bundle, error) {
+	var bundles []*models.Bundle
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Skip the bundles being claimed by other instances
+		var ids []uint
+		if err := tx.Model(&models.Bundle{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND mode = ?", models.BundleRunning, models.BundleModeSigner).
+			Where("claimed_until IS NULL OR claimed_until < ?", time.Now()).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.Bundle{}).Where("id IN ?", ids).UpdateColumn("claimed_until", until).Error; err != nil {
+			return err
+		}
+
+		return tx.Preload("Steps", orderSteps).Where("id IN ?", ids).Order("created_at, id").Find(&bundles).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bundles, nil
+}
+
+// orderSteps loads the steps of a bundle in the order they run
+func orderSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
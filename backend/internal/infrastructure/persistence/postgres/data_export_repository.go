@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportRepository creates a new PostgreSQL implementation of DataExportRepository
+func NewDataExportRepository(db *gorm.DB) repository.DataExportRepository {
+	return &dataExportRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new export into the database
+func (r *dataExportRepository) Create(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+// FindByID retrieves an export by ID, including its archive
+func (r *dataExportRepository) FindByID(ctx context.Context, id uint) (*models.DataExport, error) {
+	var export models.DataExport
+	result := r.db.WithContext(ctx).First(&export, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &export, nil
+}
+
+// ListByUser retrieves the exports of a user without their archives, newest first
+func (r *dataExportRepository) ListByUser(ctx context.Context, userID uint) ([]*models.DataExport, error) {
+	var exports []*models.DataExport
+	if err := r.db.WithContext(ctx).Omit("archive").Where("user_id = ?", userID).Order("created_at DESC").Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// ClaimPending marks up to limit pending exports, and those left processing since before staleBefore,
+// as processing and returns them
+func (r *dataExportRepository) ClaimPending(ctx context.Context, staleBefore time.Time, limit int) ([]*models.DataExport, error) {
+	var exports []*models.DataExport
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Skip the exports claimed by other instances
+		if err := tx.Omit("archive").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND updated_at < ?)", models.ExportPending, models.ExportProcessing, staleBefore).
+			Order("created_at").
+			Limit(limit).
+			Find(&exports).Error; err != nil {
+			return err
+		}
+
+		if len(exports) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(exports))
+		for i, export := range exports {
+			export.Status = models.ExportProcessing
+			ids[i] = export.ID
+		}
+
+		return tx.Model(&models.DataExport{}).Where("id IN ?", ids).Update("status", models.ExportProcessing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// Update updates an existing export
+func (r *dataExportRepository) Update(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Save(export).Error
+}
+
+// DeleteExpired removes the exports whose archive expired before a given time
+func (r *dataExportRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&models.DataExport{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type deletionRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewDeletionRequestRepository creates a new PostgreSQL implementation of DeletionRequestRepository
+func NewDeletionRequestRepository(db *gorm.DB) repository.DeletionRequestRepository {
+	return &deletionRequestRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new deletion request into the database
+func (r *deletionRequestRepository) Create(ctx context.Context, request *models.DeletionRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+// FindByID retrieves a deletion request by ID
+func (r *deletionRequestRepository) FindByID(ctx context.Context, id uint) (*models.DeletionRequest, error) {
+	var request models.DeletionRequest
+	result := r.db.WithContext(ctx).First(&request, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &request, nil
+}
+
+// FindLatestByUser retrieves the most recent deletion request of a user
+func (r *deletionRequestRepository) FindLatestByUser(ctx context.Context, userID uint) (*models.DeletionRequest, error) {
+	var request models.DeletionRequest
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").First(&request)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &request, nil
+}
+
+// List retrieves deletion requests with optional filtering and pagination, oldest first
+func (r *deletionRequestRepository) List(ctx context.Context, filter models.DeletionRequestFilter, offset, limit int) ([]*models.DeletionRequest, error) {
+	var requests []*models.DeletionRequest
+	query := applyDeletionRequestFilter(r.db.WithContext(ctx).Model(&models.DeletionRequest{}), filter)
+
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Order("created_at").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// Count returns the number of deletion requests matching the filter
+func (r *deletionRequestRepository) Count(ctx context.Context, filter models.DeletionRequestFilter) (int64, error) {
+	var count int64
+	err := applyDeletionRequestFilter(r.db.WithContext(ctx).Model(&models.DeletionRequest{}), filter).Count(&count).Error
+	return count, err
+}
+
+// applyDeletionRequestFilter restricts a deletion request query to the requests matching a filter
+func applyDeletionRequestFilter(query *gorm.DB, filter models.DeletionRequestFilter) *gorm.DB {
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return query
+}
+
+// Update updates an existing deletion request
+func (r *deletionRequestRepository) Update(ctx context.Context, request *models.DeletionRequest) error {
+	return r.db.WithContext(ctx).Save(request).Error
+}
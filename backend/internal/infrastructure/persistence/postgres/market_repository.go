@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type marketRepository struct {
+	db *gorm.DB
+}
+
+// NewMarketRepository creates a new PostgreSQL implementation of MarketRepository
+func NewMarketRepository(db *gorm.DB) repository.MarketRepository {
+	return &marketRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new market into the database
+func (r *marketRepository) Create(ctx context.Context, market *models.Market) error {
+	return r.db.WithContext(ctx).Create(market).Error
+}
+
+// FindByID retrieves a market by ID
+func (r *marketRepository) FindByID(ctx context.Context, id uint) (*models.Market, error) {
+	var market models.Market
+	result := r.db.WithContext(ctx).First(&market, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &market, nil
+}
+
+// FindByIdentifier retrieves a market by its route identifier
+func (r *marketRepository) FindByIdentifier(ctx context.Context, identifier string) (*models.Market, error) {
+	var market models.Market
+	result := r.db.WithContext(ctx).Where("identifier = ?", identifier).First(&market)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &market, nil
+}
+
+// Update updates an existing market
+func (r *marketRepository) Update(ctx context.Context, market *models.Market) error {
+	return r.db.WithContext(ctx).Save(market).Error
+}
+
+// ListActive retrieves all active markets
+func (r *marketRepository) ListActive(ctx context.Context) ([]*models.Market, error) {
+	var markets []*models.Market
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Order("id ASC").Find(&markets).Error; err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"slices"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// applyCursor orders a query by creation date then ID and starts it right after a cursor, if any.
+// A backward cursor reads in the opposite order, so its rows must then go through reverseBackward
+func applyCursor(query *gorm.DB, cursor *models.Cursor, descending bool) *gorm.DB {
+	if cursor != nil && cursor.Backward {
+		descending = !descending
+	}
+
+	if cursor != nil {
+		operator := ">"
+		if descending {
+			operator = "<"
+		}
+		query = query.Where("(created_at, id) "+operator+" (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	if descending {
+		return query.Order("created_at DESC").Order("id DESC")
+	}
+	return query.Order("created_at").Order("id")
+}
+
+// reverseBackward restores the list order of rows read with a backward cursor
+func reverseBackward[T any](rows []T, cursor *models.Cursor) {
+	if cursor != nil && cursor.Backward {
+		slices.Reverse(rows)
+	}
+}
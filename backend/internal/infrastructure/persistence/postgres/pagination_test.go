@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+)
+
+// dryRunDB builds queries without a database, to inspect the SQL they would run
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestApplyCursor(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		cursor     *models.Cursor
+		descending bool
+		wantWhere  string
+		wantOrder  string
+	}{
+		{name: "first page, newest first", descending: true, wantOrder: "ORDER BY created_at DESC,id DESC"},
+		{name: "first page, oldest first", wantOrder: "ORDER BY created_at,id"},
+		{name: "next page, newest first", cursor: &models.Cursor{CreatedAt: at, ID: 7}, descending: true, wantWhere: "(created_at, id) < ($1, $2)", wantOrder: "ORDER BY created_at DESC,id DESC"},
+		{name: "next page, oldest first", cursor: &models.Cursor{CreatedAt: at, ID: 7}, wantWhere: "(created_at, id) > ($1, $2)", wantOrder: "ORDER BY created_at,id"},
+		// Reading backward flips the order, so the rows nearest the cursor come first
+		{name: "previous page, newest first", cursor: &models.Cursor{CreatedAt: at, ID: 7, Backward: true}, descending: true, wantWhere: "(created_at, id) > ($1, $2)", wantOrder: "ORDER BY created_at,id"},
+		{name: "previous page, oldest first", cursor: &models.Cursor{CreatedAt: at, ID: 7, Backward: true}, wantWhere: "(created_at, id) < ($1, $2)", wantOrder: "ORDER BY created_at DESC,id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := applyCursor(dryRunDB(t).Model(&models.Transaction{}), tt.cursor, tt.descending).Find(&[]models.Transaction{}).Statement
+			sql := stmt.SQL.String()
+
+			if tt.wantWhere == "" && strings.Contains(sql, "(created_at, id)") {
+				t.Errorf("got %q, want no cursor condition", sql)
+			}
+			if tt.wantWhere != "" {
+				if !strings.Contains(sql, tt.wantWhere) {
+					t.Errorf("got %q, want the condition %q", sql, tt.wantWhere)
+				}
+				if len(stmt.Vars) != 2 || stmt.Vars[0] != at || stmt.Vars[1] != uint(7) {
+					t.Errorf("got arguments %v, want the cursor position", stmt.Vars)
+				}
+			}
+			if !strings.HasSuffix(sql, tt.wantOrder) {
+				t.Errorf("got %q, want it ordered by %q", sql, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestReverseBackward(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor *models.Cursor
+		want   []int
+	}{
+		{name: "no cursor", want: []int{1, 2, 3}},
+		{name: "forward cursor", cursor: &models.Cursor{ID: 1}, want: []int{1, 2, 3}},
+		{name: "backward cursor", cursor: &models.Cursor{ID: 1, Backward: true}, want: []int{3, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := []int{1, 2, 3}
+			reverseBackward(rows, tt.cursor)
+			if !slices.Equal(rows, tt.want) {
+				t.Errorf("got %v, want %v", rows, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/big"
 
 	"gorm.io/gorm"
 
@@ -57,6 +59,17 @@ func (r *positionRepository) FindActiveByUserID(ctx context.Context, userID uint
 	return positions, nil
 }
 
+// FindActiveByUserAndMarket retrieves the active positions of a user in a market
+func (r *positionRepository) FindActiveByUserAndMarket(ctx context.Context, userID, marketID uint) ([]*models.Position, error) {
+	var positions []*models.Position
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND market_id = ? AND status = ?", userID, marketID, models.StatusActive).
+		Find(&positions).Error; err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
 // Update updates an existing position
 func (r *positionRepository) Update(ctx context.Context, position *models.Position) error {
 	return r.db.WithContext(ctx).Save(position).Error
@@ -67,13 +80,13 @@ func (r *positionRepository) UpdateStatus(ctx context.Context, id uint, status m
 	return r.db.WithContext(ctx).Model(&models.Position{}).Where("id = ?", id).Update("status", status).Error
 }
 
-// FindAtRisk finds all positions at risk of liquidation
-func (r *positionRepository) FindAtRisk(ctx context.Context, healthFactorThreshold string) ([]*models.Position, error) {
+// FindAtRisk finds all positions of a market at risk of liquidation
+func (r *positionRepository) FindAtRisk(ctx context.Context, marketID uint, healthFactorThreshold string) ([]*models.Position, error) {
 	var positions []*models.Position
 
 	// Find positions with a health factor below the threshold and that are still active
 	if err := r.db.WithContext(ctx).
-		Where("health_factor <= ? AND status = ?", healthFactorThreshold, models.StatusActive).
+		Where("market_id = ? AND health_factor <= ? AND status = ?", marketID, healthFactorThreshold, models.StatusActive).
 		Find(&positions).Error; err != nil {
 		return nil, err
 	}
@@ -81,16 +94,29 @@ func (r *positionRepository) FindAtRisk(ctx context.Context, healthFactorThresho
 	return positions, nil
 }
 
-// List retrieves all positions with optional filtering and pagination
-func (r *positionRepository) List(ctx context.Context, filter map[string]any, offset, limit int) ([]*models.Position, error) {
+// positionSortExpressions maps each sort order to the SQL it orders by; amounts are compared as numbers
+var positionSortExpressions = map[models.PositionSort]string{
+	models.SortByCreatedAt:    "created_at",
+	models.SortByHealthFactor: "NULLIF(health_factor, '')::numeric",
+	models.SortBySize:         "borrowed_amount::numeric",
+}
+
+// List retrieves the positions matching a filter, in its order, with pagination
+func (r *positionRepository) List(ctx context.Context, filter models.PositionFilter, offset, limit int) ([]*models.Position, error) {
 	var positions []*models.Position
-	query := r.db.WithContext(ctx).Model(&models.Position{})
+	query := applyPositionFilter(r.db.WithContext(ctx).Model(&models.Position{}), filter)
 
-	// Apply filters
-	for key, value := range filter {
-		query = query.Where(key+" = ?", value)
+	sortExpression, found := positionSortExpressions[filter.SortBy]
+	if !found {
+		sortExpression = positionSortExpressions[models.SortByCreatedAt]
 	}
 
+	direction := "DESC"
+	if filter.Ascending {
+		direction = "ASC"
+	}
+	query = query.Order(sortExpression + " " + direction + " NULLS LAST").Order("id " + direction)
+
 	if offset >= 0 {
 		query = query.Offset(offset)
 	}
@@ -99,23 +125,89 @@ func (r *positionRepository) List(ctx context.Context, filter map[string]any, of
 		query = query.Limit(limit)
 	}
 
-	if err := query.Order("created_at DESC").Find(&positions).Error; err != nil {
+	if err := query.Find(&positions).Error; err != nil {
 		return nil, err
 	}
 
 	return positions, nil
 }
 
-// Count returns the total number of positions matching the filter
-func (r *positionRepository) Count(ctx context.Context, filter map[string]any) (int64, error) {
-	var count int64
-	query := r.db.WithContext(ctx).Model(&models.Position{})
+// ListByCursor retrieves up to limit positions matching a filter, by opening date in the filter's direction,
+// from a cursor. A nil cursor starts at the first page; the filter's sort order is ignored
+func (r *positionRepository) ListByCursor(ctx context.Context, filter models.PositionFilter, cursor *models.Cursor, limit int) ([]*models.Position, error) {
+	var positions []*models.Position
+	query := applyPositionFilter(r.db.WithContext(ctx).Model(&models.Position{}), filter)
+
+	if err := applyCursor(query, cursor, !filter.Ascending).Limit(limit).Find(&positions).Error; err != nil {
+		return nil, err
+	}
+
+	reverseBackward(positions, cursor)
+	return positions, nil
+}
+
+// SumCurrentCollateral returns the sum of the latest recorded collateral amount of every user in a market
+func (r *positionRepository) SumCurrentCollateral(ctx context.Context, marketID uint) (*big.Int, error) {
+	var total string
+
+	// Only the most recently updated position of each user reflects their current collateral
+	// balance; older closed or liquidated positions keep stale amounts
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COALESCE(SUM(collateral_amount::numeric), 0)::text
+		FROM (
+			SELECT DISTINCT ON (user_id) collateral_amount
+			FROM positions
+			WHERE deleted_at IS NULL AND market_id = ?
+			ORDER BY user_id, updated_at DESC
+		) latest`, marketID).Scan(&total).Error
+	if err != nil {
+		return nil, err
+	}
 
-	// Apply filters
-	for key, value := range filter {
-		query = query.Where(key+" = ?", value)
+	sum, success := new(big.Int).SetString(total, 10)
+	if !success {
+		return nil, fmt.Errorf("failed to parse collateral sum: %s", total)
 	}
 
-	err := query.Count(&count).Error
+	return sum, nil
+}
+
+// Count returns the number of positions matching a filter
+func (r *positionRepository) Count(ctx context.Context, filter models.PositionFilter) (int64, error) {
+	var count int64
+	err := applyPositionFilter(r.db.WithContext(ctx).Model(&models.Position{}), filter).Count(&count).Error
 	return count, err
 }
+
+// applyPositionFilter restricts a position query to the positions matching a filter
+func applyPositionFilter(query *gorm.DB, filter models.PositionFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	if filter.MarketID != nil {
+		query = query.Where("market_id = ?", *filter.MarketID)
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+
+	if filter.MinHealthFactor != "" {
+		query = query.Where("NULLIF(health_factor, '')::numeric >= ?::numeric", filter.MinHealthFactor)
+	}
+
+	if filter.MaxHealthFactor != "" {
+		query = query.Where("NULLIF(health_factor, '')::numeric <= ?::numeric", filter.MaxHealthFactor)
+	}
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return query
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type priceSampleRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceSampleRepository creates a new PostgreSQL implementation of PriceSampleRepository
+func NewPriceSampleRepository(db *gorm.DB) repository.PriceSampleRepository {
+	return &priceSampleRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new price sample into the database
+func (r *priceSampleRepository) Create(ctx context.Context, sample *models.PriceSample) error {
+	return r.db.WithContext(ctx).Create(sample).Error
+}
+
+// FindInRange retrieves the samples of a token taken within [from, to], oldest first
+func (r *priceSampleRepository) FindInRange(ctx context.Context, tokenAddress string, from, to time.Time) ([]*models.PriceSample, error) {
+	var samples []*models.PriceSample
+	err := r.db.WithContext(ctx).
+		Where("token_address = ? AND sampled_at >= ? AND sampled_at <= ?", tokenAddress, from, to).
+		Order("sampled_at ASC").
+		Find(&samples).Error
+	if err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// FindLatestBefore retrieves the most recent sample of a token taken before a given time
+func (r *priceSampleRepository) FindLatestBefore(ctx context.Context, tokenAddress string, before time.Time) (*models.PriceSample, error) {
+	var sample models.PriceSample
+	result := r.db.WithContext(ctx).
+		Where("token_address = ? AND sampled_at < ?", tokenAddress, before).
+		Order("sampled_at DESC").
+		First(&sample)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &sample, nil
+}
+
+// DeleteOlderThan removes every sample taken before a given time
+func (r *priceSampleRepository) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("sampled_at < ?", before).Delete(&models.PriceSample{})
+	return result.RowsAffected, result.Error
+}
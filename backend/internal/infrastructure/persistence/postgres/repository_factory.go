@@ -14,10 +14,28 @@ type RepositoryFactory struct {
 	userRepository  repository.UserRepository
 	transactionRepo repository.TransactionRepository
 	positionRepo    repository.PositionRepository
+	priceSampleRepo repository.PriceSampleRepository
+	marketRepo      repository.MarketRepository
+	solvencyRepo    repository.SolvencySnapshotRepository
+	apiKeyRepo      repository.APIKeyRepository
+	auditLogRepo    repository.AuditLogRepository
+	userAddressRepo repository.UserAddressRepository
+	dataExportRepo  repository.DataExportRepository
+	deletionRepo    repository.DeletionRequestRepository
+	bundleRepo      repository.BundleRepository
 
 	userOnce        sync.Once
 	transactionOnce sync.Once
 	positionOnce    sync.Once
+	priceSampleOnce sync.Once
+	marketOnce      sync.Once
+	solvencyOnce    sync.Once
+	apiKeyOnce      sync.Once
+	auditLogOnce    sync.Once
+	userAddressOnce sync.Once
+	dataExportOnce  sync.Once
+	deletionOnce    sync.Once
+	bundleOnce      sync.Once
 }
 
 // NewRepositoryFactory creates a new repository factory
@@ -50,3 +68,75 @@ func (f *RepositoryFactory) GetPositionRepository() repository.PositionRepositor
 	})
 	return f.positionRepo
 }
+
+// GetPriceSampleRepository returns a singleton instance of PriceSampleRepository
+func (f *RepositoryFactory) GetPriceSampleRepository() repository.PriceSampleRepository {
+	f.priceSampleOnce.Do(func() {
+		f.priceSampleRepo = NewPriceSampleRepository(f.db)
+	})
+	return f.priceSampleRepo
+}
+
+// GetMarketRepository returns a singleton instance of MarketRepository
+func (f *RepositoryFactory) GetMarketRepository() repository.MarketRepository {
+	f.marketOnce.Do(func() {
+		f.marketRepo = NewMarketRepository(f.db)
+	})
+	return f.marketRepo
+}
+
+// GetSolvencySnapshotRepository returns a singleton instance of SolvencySnapshotRepository
+func (f *RepositoryFactory) GetSolvencySnapshotRepository() repository.SolvencySnapshotRepository {
+	f.solvencyOnce.Do(func() {
+		f.solvencyRepo = NewSolvencySnapshotRepository(f.db)
+	})
+	return f.solvencyRepo
+}
+
+// GetAPIKeyRepository returns a singleton instance of APIKeyRepository
+func (f *RepositoryFactory) GetAPIKeyRepository() repository.APIKeyRepository {
+	f.apiKeyOnce.Do(func() {
+		f.apiKeyRepo = NewAPIKeyRepository(f.db)
+	})
+	return f.apiKeyRepo
+}
+
+// GetAuditLogRepository returns a singleton instance of AuditLogRepository
+func (f *RepositoryFactory) GetAuditLogRepository() repository.AuditLogRepository {
+	f.auditLogOnce.Do(func() {
+		f.auditLogRepo = NewAuditLogRepository(f.db)
+	})
+	return f.auditLogRepo
+}
+
+// GetUserAddressRepository returns a singleton instance of UserAddressRepository
+func (f *RepositoryFactory) GetUserAddressRepository() repository.UserAddressRepository {
+	f.userAddressOnce.Do(func() {
+		f.userAddressRepo = NewUserAddressRepository(f.db)
+	})
+	return f.userAddressRepo
+}
+
+// GetDataExportRepository returns a singleton instance of DataExportRepository
+func (f *RepositoryFactory) GetDataExportRepository() repository.DataExportRepository {
+	f.dataExportOnce.Do(func() {
+		f.dataExportRepo = NewDataExportRepository(f.db)
+	})
+	return f.dataExportRepo
+}
+
+// GetDeletionRequestRepository returns a singleton instance of DeletionRequestRepository
+func (f *RepositoryFactory) GetDeletionRequestRepository() repository.DeletionRequestRepository {
+	f.deletionOnce.Do(func() {
+		f.deletionRepo = NewDeletionRequestRepository(f.db)
+	})
+	return f.deletionRepo
+}
+
+// GetBundleRepository returns a singleton instance of BundleRepository
+func (f *RepositoryFactory) GetBundleRepository() repository.BundleRepository {
+	f.bundleOnce.Do(func() {
+		f.bundleRepo = NewBundleRepository(f.db)
+	})
+	return f.bundleRepo
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type solvencySnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewSolvencySnapshotRepository creates a new PostgreSQL implementation of SolvencySnapshotRepository
+func NewSolvencySnapshotRepository(db *gorm.DB) repository.SolvencySnapshotRepository {
+	return &solvencySnapshotRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new solvency snapshot into the database
+func (r *solvencySnapshotRepository) Create(ctx context.Context, snapshot *models.SolvencySnapshot) error {
+	return r.db.WithContext(ctx).Create(snapshot).Error
+}
+
+// FindInRange retrieves the snapshots of a market taken within [from, to], oldest first
+func (r *solvencySnapshotRepository) FindInRange(ctx context.Context, marketID uint, from, to time.Time) ([]*models.SolvencySnapshot, error) {
+	var snapshots []*models.SolvencySnapshot
+	err := r.db.WithContext(ctx).
+		Where("market_id = ? AND created_at >= ? AND created_at <= ?", marketID, from, to).
+		Order("created_at ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// DeleteOlderThan removes every snapshot taken before a given time
+func (r *solvencySnapshotRepository) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.SolvencySnapshot{})
+	return result.RowsAffected, result.Error
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -39,17 +40,13 @@ func (r *transactionRepository) FindByID(ctx context.Context, id uint) (*models.
 	return &transaction, nil
 }
 
-// FindByHash retrieves a transaction by blockchain transaction hash
-func (r *transactionRepository) FindByHash(ctx context.Context, hash string) (*models.Transaction, error) {
-	var transaction models.Transaction
-	result := r.db.WithContext(ctx).Where("hash = ?", hash).First(&transaction)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, nil
-	}
-	if result.Error != nil {
-		return nil, result.Error
+// FindByHash retrieves the ledger entries of a blockchain transaction, one per role
+func (r *transactionRepository) FindByHash(ctx context.Context, hash string) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	if err := r.db.WithContext(ctx).Where("hash = ?", hash).Order("id").Find(&transactions).Error; err != nil {
+		return nil, err
 	}
-	return &transaction, nil
+	return transactions, nil
 }
 
 // FindByUserID retrieves all transactions for a specific user
@@ -72,29 +69,33 @@ func (r *transactionRepository) FindByUserID(ctx context.Context, userID uint, o
 	return transactions, nil
 }
 
+// FindByUserAndMarket retrieves the transactions of some types made by a user in a market within
+// a time window, oldest first. A nil end leaves the window open
+func (r *transactionRepository) FindByUserAndMarket(ctx context.Context, userID, marketID uint, types []models.TransactionType, from time.Time, to *time.Time) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	query := r.db.WithContext(ctx).
+		Where("user_id = ? AND market_id = ? AND type IN ? AND created_at >= ?", userID, marketID, types, from)
+
+	if to != nil {
+		query = query.Where("created_at <= ?", *to)
+	}
+
+	if err := query.Order("created_at").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
 // Update updates an existing transaction
 func (r *transactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
 	return r.db.WithContext(ctx).Save(transaction).Error
 }
 
-// List retrieves all transactions with optional filtering and pagination
-func (r *transactionRepository) List(ctx context.Context, filter map[string]any, offset, limit int) ([]*models.Transaction, error) {
+// List retrieves the transactions matching a filter, most recent first, with pagination
+func (r *transactionRepository) List(ctx context.Context, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	query := r.db.WithContext(ctx).Model(&models.Transaction{})
-
-	// Apply filters
-	for key, value := range filter {
-		// Special handling for array values in filters (e.g., IN queries)
-		if key == "type" {
-			if types, ok := value.([]models.TransactionType); ok && len(types) > 0 {
-				query = query.Where("type IN ?", types)
-			} else {
-				query = query.Where("type = ?", value)
-			}
-		} else {
-			query = query.Where(key+" = ?", value)
-		}
-	}
+	query := applyTransactionFilter(r.db.WithContext(ctx).Model(&models.Transaction{}), filter)
 
 	if offset >= 0 {
 		query = query.Offset(offset)
@@ -104,32 +105,63 @@ func (r *transactionRepository) List(ctx context.Context, filter map[string]any,
 		query = query.Limit(limit)
 	}
 
-	if err := query.Order("created_at DESC").Find(&transactions).Error; err != nil {
+	if err := query.Order("created_at DESC, id DESC").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// ListByCursor retrieves up to limit transactions matching a filter, most recent first, from a cursor.
+// A nil cursor starts at the first page
+func (r *transactionRepository) ListByCursor(ctx context.Context, filter models.TransactionFilter, cursor *models.Cursor, limit int) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	query := applyTransactionFilter(r.db.WithContext(ctx).Model(&models.Transaction{}), filter)
+
+	if err := applyCursor(query, cursor, true).Limit(limit).Find(&transactions).Error; err != nil {
 		return nil, err
 	}
 
+	reverseBackward(transactions, cursor)
 	return transactions, nil
 }
 
-// Count returns the total number of transactions matching the filter
-func (r *transactionRepository) Count(ctx context.Context, filter map[string]any) (int64, error) {
+// Count returns the number of transactions matching a filter
+func (r *transactionRepository) Count(ctx context.Context, filter models.TransactionFilter) (int64, error) {
 	var count int64
-	query := r.db.WithContext(ctx).Model(&models.Transaction{})
-
-	// Apply filters
-	for key, value := range filter {
-		// Special handling for array values in filters (e.g., IN queries)
-		if key == "type" {
-			if types, ok := value.([]models.TransactionType); ok && len(types) > 0 {
-				query = query.Where("type IN ?", types)
-			} else {
-				query = query.Where("type = ?", value)
-			}
-		} else {
-			query = query.Where(key+" = ?", value)
-		}
-	}
-
-	err := query.Count(&count).Error
+	err := applyTransactionFilter(r.db.WithContext(ctx).Model(&models.Transaction{}), filter).Count(&count).Error
 	return count, err
 }
+
+// applyTransactionFilter restricts a transaction query to the transactions matching a filter
+func applyTransactionFilter(query *gorm.DB, filter models.TransactionFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	if filter.MarketID != nil {
+		query = query.Where("market_id = ?", *filter.MarketID)
+	}
+
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+
+	if len(filter.Roles) > 0 {
+		query = query.Where("role IN ?", filter.Roles)
+	}
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return query
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+)
+
+type userAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewUserAddressRepository creates a new PostgreSQL implementation of UserAddressRepository
+func NewUserAddressRepository(db *gorm.DB) repository.UserAddressRepository {
+	return &userAddressRepository{
+		db: db,
+	}
+}
+
+// Create links a new address to a user
+func (r *userAddressRepository) Create(ctx context.Context, address *models.UserAddress) error {
+	return r.db.WithContext(ctx).Create(address).Error
+}
+
+// FindByAddress retrieves a linked address, whatever its case
+func (r *userAddressRepository) FindByAddress(ctx context.Context, address string) (*models.UserAddress, error) {
+	var userAddress models.UserAddress
+	result := r.db.WithContext(ctx).Where("LOWER(address) = LOWER(?)", address).First(&userAddress)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &userAddress, nil
+}
+
+// ListByUser retrieves the addresses linked to a user, primary first
+func (r *userAddressRepository) ListByUser(ctx context.Context, userID uint) ([]*models.UserAddress, error) {
+	var addresses []*models.UserAddress
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_primary DESC, created_at ASC").
+		Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// Delete unlinks an address
+func (r *userAddressRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.UserAddress{}, id).Error
+}
+
+// SetPrimary makes a linked address the primary address of its user, including User.Address
+func (r *userAddressRepository) SetPrimary(ctx context.Context, address *models.UserAddress) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserAddress{}).
+			Where("user_id = ? AND id <> ?", address.UserID, address.ID).
+			Update("is_primary", false).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(address).Update("is_primary", true).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", address.UserID).Update("address", address.Address).Error
+	})
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -22,9 +23,20 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 	}
 }
 
-// Create inserts a new user into the database
+// Create inserts a new user into the database along with its primary address
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UserAddress{
+			UserID:    user.ID,
+			Address:   user.Address,
+			IsPrimary: true,
+			CreatedAt: user.CreatedAt,
+		}).Error
+	})
 }
 
 // FindByID retrieves a user by ID
@@ -40,10 +52,25 @@ func (r *userRepository) FindByID(ctx context.Context, id uint) (*models.User, e
 	return &user, nil
 }
 
-// FindByAddress retrieves a user by Ethereum address
+// FindByIDs retrieves the users with the given IDs, in no particular order; unknown IDs are skipped
+func (r *userRepository) FindByIDs(ctx context.Context, ids []uint) ([]*models.User, error) {
+	var users []*models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindByAddress retrieves a user by any of its linked Ethereum addresses, whatever their case
 func (r *userRepository) FindByAddress(ctx context.Context, address string) (*models.User, error) {
 	var user models.User
-	result := r.db.WithContext(ctx).Where("address = ?", address).First(&user)
+	result := r.db.WithContext(ctx).
+		Where("LOWER(address) = LOWER(?) OR id IN (SELECT user_id FROM user_addresses WHERE LOWER(address) = LOWER(?))", address, address).
+		First(&user)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -63,10 +90,46 @@ func (r *userRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
 }
 
+// Purge anonymises a user, deleted or not, and erases its off-chain records: linked addresses, API keys
+// and data exports. Transactions and positions are kept for accounting, with their hashes and amounts.
+// Audit log entries keep the addresses and IPs they recorded: they are hash-chained and append-only
+func (r *userRepository) Purge(ctx context.Context, id uint) error {
+	// The placeholder is unique and can never match an address, which frees the address for a new account
+	placeholder := fmt.Sprintf("purged-%d", id)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserAddress{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.APIKey{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.DataExport{}).Error; err != nil {
+			return err
+		}
+
+		// Error messages may quote the addresses of the user
+		if err := tx.Unscoped().Model(&models.Transaction{}).Where("user_id = ?", id).Update("error_message", "").Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&models.User{}).Where("id = ?", id).Updates(map[string]any{
+			"address":    placeholder,
+			"username":   placeholder,
+			"verified":   false,
+			"last_login": nil,
+			"role":       models.RoleUser,
+			"deleted_at": gorm.Expr("COALESCE(deleted_at, ?)", time.Now()),
+		}).Error
+	})
+}
+
 // List retrieves all users with optional pagination
-func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*models.User, error) {
+func (r *userRepository) List(ctx context.Context, filter models.UserFilter, offset, limit int) ([]*models.User, error) {
 	var users []*models.User
-	query := r.db.WithContext(ctx).Model(&models.User{})
+	query := applyUserFilter(r.db.WithContext(ctx).Model(&models.User{}), filter)
 
 	if offset >= 0 {
 		query = query.Offset(offset)
@@ -76,13 +139,27 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*models
 		query = query.Limit(limit)
 	}
 
-	if err := query.Find(&users).Error; err != nil {
+	if err := query.Order("created_at").Order("id").Find(&users).Error; err != nil {
 		return nil, err
 	}
 
 	return users, nil
 }
 
+// ListByCursor retrieves up to limit users matching a filter, oldest first, from a cursor.
+// A nil cursor starts at the first page
+func (r *userRepository) ListByCursor(ctx context.Context, filter models.UserFilter, cursor *models.Cursor, limit int) ([]*models.User, error) {
+	var users []*models.User
+	query := applyUserFilter(r.db.WithContext(ctx).Model(&models.User{}), filter)
+
+	if err := applyCursor(query, cursor, false).Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	reverseBackward(users, cursor)
+	return users, nil
+}
+
 // Count returns the total number of users
 func (r *userRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
@@ -91,26 +168,33 @@ func (r *userRepository) Count(ctx context.Context) (int64, error) {
 }
 
 // CountWithFilter counts users that match the given filter criteria
-func (r *userRepository) CountWithFilter(ctx context.Context, filter map[string]any) (int64, error) {
+func (r *userRepository) CountWithFilter(ctx context.Context, filter models.UserFilter) (int64, error) {
 	var count int64
-	query := r.db.WithContext(ctx).Model(&models.User{})
-
-	// Apply filters
-	for key, value := range filter {
-		// Special handling for time-based filters
-		if key == "last_login_after" {
-			if timeValue, ok := value.(time.Time); ok {
-				query = query.Where("last_login >= ?", timeValue)
-			}
-		} else if key == "last_login_before" {
-			if timeValue, ok := value.(time.Time); ok {
-				query = query.Where("last_login <= ?", timeValue)
-			}
-		} else {
-			query = query.Where(key+" = ?", value)
-		}
+	err := applyUserFilter(r.db.WithContext(ctx).Model(&models.User{}), filter).Count(&count).Error
+	return count, err
+}
+
+// applyUserFilter restricts a user query to the users matching a filter
+func applyUserFilter(query *gorm.DB, filter models.UserFilter) *gorm.DB {
+	if len(filter.Roles) > 0 {
+		query = query.Where("role IN ?", filter.Roles)
 	}
 
-	err := query.Count(&count).Error
-	return count, err
+	if filter.Verified != nil {
+		query = query.Where("verified = ?", *filter.Verified)
+	}
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	if filter.LastLoginAfter != nil {
+		query = query.Where("last_login >= ?", *filter.LastLoginAfter)
+	}
+
+	return query
 }
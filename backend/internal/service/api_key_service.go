@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// API keys are formatted as lbk_<prefix>_<secret>: the prefix is stored in clear to look the
+// key up, the secret only as a hash
+const apiKeyTag = "lbk"
+
+type apiKeyService struct {
+	apiKeyRepo   repository.APIKeyRepository
+	auditService service.AuditService
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, auditService service.AuditService) service.APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:   apiKeyRepo,
+		auditService: auditService,
+	}
+}
+
+// CreateKey issues an API key to a user and returns it with its plaintext value, which is never shown again
+func (s *apiKeyService) CreateKey(ctx context.Context, user *models.User, req *service.APIKeyRequest) (*models.APIKey, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", service.ErrInvalidAPIKeyRequest)
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", service.ErrInvalidAPIKeyRequest)
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			return nil, "", fmt.Errorf("%w: unknown scope %s", service.ErrInvalidAPIKeyRequest, scope)
+		}
+		if scope == models.ScopeAdmin && len(user.Role.Permissions()) == 0 {
+			return nil, "", fmt.Errorf("%w: the %s scope requires a privileged role", service.ErrInvalidAPIKeyRequest, scope)
+		}
+		if !slices.Contains(scopes, string(scope)) {
+			scopes = append(scopes, string(scope))
+		}
+	}
+
+	for _, entry := range req.AllowedIPs {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, "", fmt.Errorf("%w: invalid IP or CIDR %s", service.ErrInvalidAPIKeyRequest, entry)
+			}
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expiry must be in the future", service.ErrInvalidAPIKeyRequest)
+	}
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		UserID:     user.ID,
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: hashAPIKeySecret(secret),
+		Scopes:     strings.Join(scopes, ","),
+		AllowedIPs: strings.Join(req.AllowedIPs, ","),
+		ExpiresAt:  req.ExpiresAt,
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditAPIKeyCreate, apiKeyTarget(key.ID), nil, key)
+
+	return key, fmt.Sprintf("%s_%s_%s", apiKeyTag, prefix, secret), nil
+}
+
+// ListKeys returns the API keys of a user, including revoked ones
+func (s *apiKeyService) ListKeys(ctx context.Context, userID uint) ([]*models.APIKey, error) {
+	return s.apiKeyRepo.ListByUser(ctx, userID)
+}
+
+// RevokeKey revokes an API key of a user
+func (s *apiKeyService) RevokeKey(ctx context.Context, userID, keyID uint) error {
+	key, err := s.apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+
+	if key == nil || key.UserID != userID || key.RevokedAt != nil {
+		return service.ErrAPIKeyNotFound
+	}
+
+	if err := s.apiKeyRepo.Revoke(ctx, key.ID, time.Now()); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditAPIKeyRevoke, apiKeyTarget(key.ID), nil, nil)
+	return nil
+}
+
+// apiKeyTarget identifies an API key in the audit log
+func apiKeyTarget(id uint) service.AuditTarget {
+	return service.AuditTarget{Type: "api_key", ID: strconv.FormatUint(uint64(id), 10)}
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// auditBatchSize is the number of entries read at once when exporting or verifying the audit log
+const auditBatchSize = 500
+
+// errAuditChainBroken stops the walk of the audit log at the first broken entry
+var errAuditChainBroken = errors.New("audit log hash chain broken")
+
+type auditService struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditLogRepo repository.AuditLogRepository) service.AuditService {
+	return &auditService{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Record appends an entry for an action performed by the actor of the context, with the diff
+// between the states of its target before and after; either may be nil
+func (s *auditService) Record(ctx context.Context, action models.AuditAction, target service.AuditTarget, before, after any) error {
+	changes, err := models.AuditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry := newAuditEntry(ctx, action, target)
+	entry.Changes = changes
+	return s.auditLogRepo.Append(ctx, entry)
+}
+
+// RecordRequest appends an entry for a request, as seen by middleware
+func (s *auditService) RecordRequest(ctx context.Context, action models.AuditAction, target service.AuditTarget, status int) error {
+	entry := newAuditEntry(ctx, action, target)
+	entry.Status = status
+	return s.auditLogRepo.Append(ctx, entry)
+}
+
+// newAuditEntry creates an entry attributed to the actor of the context
+func newAuditEntry(ctx context.Context, action models.AuditAction, target service.AuditTarget) *models.AuditLog {
+	entry := &models.AuditLog{
+		Action:     action,
+		TargetType: target.Type,
+		TargetID:   target.ID,
+		// Postgres keeps microseconds, and the hash must match what is read back
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	if actor := service.AuditActorFromContext(ctx); actor != nil {
+		entry.ActorID = actor.UserID
+		entry.ActorAddress = actor.Address
+		entry.ActorRole = actor.Role
+		entry.APIKeyID = actor.APIKeyID
+		entry.RequestID = actor.RequestID
+		entry.IP = actor.IP
+	}
+
+	return entry
+}
+
+// List retrieves the entries matching a filter, newest first
+func (s *auditService) List(ctx context.Context, filter models.AuditLogFilter, offset, limit int) ([]*models.AuditLog, error) {
+	return s.auditLogRepo.List(ctx, filter, offset, limit)
+}
+
+// Count returns the number of entries matching a filter
+func (s *auditService) Count(ctx context.Context, filter models.AuditLogFilter) (int64, error) {
+	return s.auditLogRepo.Count(ctx, filter)
+}
+
+// Export calls fn for every entry matching a filter, oldest first
+func (s *auditService) Export(ctx context.Context, filter models.AuditLogFilter, fn func(entry *models.AuditLog) error) error {
+	var afterID uint
+	for {
+		entries, err := s.auditLogRepo.ListAfter(ctx, filter, afterID, auditBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < auditBatchSize {
+			return nil
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+}
+
+// Verify recomputes the hash chain of the whole audit log
+func (s *auditService) Verify(ctx context.Context) (*service.AuditVerification, error) {
+	result := &service.AuditVerification{Valid: true}
+
+	err := s.Export(ctx, models.AuditLogFilter{}, func(entry *models.AuditLog) error {
+		if entry.PrevHash != result.HeadHash {
+			result.Reason = fmt.Sprintf("entry %d does not chain to the previous entry", entry.ID)
+		} else if entry.Hash != entry.ComputeHash() {
+			result.Reason = fmt.Sprintf("entry %d has been altered", entry.ID)
+		}
+
+		if result.Reason != "" {
+			result.Valid = false
+			result.BrokenAt = &entry.ID
+			return errAuditChainBroken
+		}
+
+		result.Entries++
+		result.HeadHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAuditChainBroken) {
+		return nil, err
+	}
+
+	if !result.Valid {
+		log.Printf("ALERT: audit log hash chain broken: %s", result.Reason)
+	}
+
+	return result, nil
+}
+
+// recordAudit records an action without failing the operation it describes, which has already happened
+func recordAudit(ctx context.Context, auditService service.AuditService, action models.AuditAction, target service.AuditTarget, before, after any) {
+	if err := auditService.Record(ctx, action, target, before, after); err != nil {
+		log.Printf("Failed to record %s audit entry: %v", action, err)
+	}
+}
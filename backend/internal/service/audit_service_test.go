@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// auditTable holds audit log entries in memory, chaining them as the audit_logs table does
+type auditTable struct {
+	entries []*models.AuditLog
+}
+
+func (r *auditTable) Append(ctx context.Context, entry *models.AuditLog) error {
+	entry.ID = uint(len(r.entries) + 1)
+	if len(r.entries) > 0 {
+		entry.PrevHash = r.entries[len(r.entries)-1].Hash
+	}
+	entry.Hash = entry.ComputeHash()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *auditTable) List(ctx context.Context, filter models.AuditLogFilter, offset, limit int) ([]*models.AuditLog, error) {
+	return nil, nil
+}
+
+func (r *auditTable) Count(ctx context.Context, filter models.AuditLogFilter) (int64, error) {
+	return int64(len(r.entries)), nil
+}
+
+func (r *auditTable) ListAfter(ctx context.Context, filter models.AuditLogFilter, afterID uint, limit int) ([]*models.AuditLog, error) {
+	var entries []*models.AuditLog
+	for _, entry := range r.entries {
+		if entry.ID > afterID && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func TestAuditVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		tamper      func(table *auditTable)
+		wantBroken  uint // Zero when the chain holds
+		wantReason  string
+		wantEntries int64 // Entries verified before the broken one
+	}{
+		{name: "untouched", tamper: func(table *auditTable) {}, wantEntries: 4},
+		{
+			name:        "field changed",
+			tamper:      func(table *auditTable) { table.entries[1].ActorRole = models.RoleAdmin },
+			wantBroken:  2,
+			wantReason:  "altered",
+			wantEntries: 1,
+		},
+		{
+			name: "field changed and hash recomputed",
+			tamper: func(table *auditTable) {
+				table.entries[1].TargetID = "3"
+				table.entries[1].Hash = table.entries[1].ComputeHash()
+			},
+			wantBroken:  3,
+			wantReason:  "does not chain",
+			wantEntries: 2,
+		},
+		{
+			name:        "row removed",
+			tamper:      func(table *auditTable) { table.entries = slices.Delete(table.entries, 1, 2) },
+			wantBroken:  3,
+			wantReason:  "does not chain",
+			wantEntries: 1,
+		},
+		{
+			name:        "first row removed",
+			tamper:      func(table *auditTable) { table.entries = table.entries[1:] },
+			wantBroken:  2,
+			wantReason:  "does not chain",
+			wantEntries: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &auditTable{}
+			s := NewAuditService(table)
+
+			actorID := uint(1)
+			ctx := context.WithValue(context.Background(), service.AuditActorKey, &models.AuditActor{UserID: &actorID, Role: models.RoleUser})
+			for _, targetID := range []string{"1", "2", "3", "4"} {
+				if err := s.Record(ctx, models.AuditUserRead, service.AuditTarget{Type: "user", ID: targetID}, nil, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tt.tamper(table)
+
+			result, err := s.Verify(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Entries != tt.wantEntries {
+				t.Errorf("got %d entries verified, want %d", result.Entries, tt.wantEntries)
+			}
+
+			if tt.wantBroken == 0 {
+				if !result.Valid || result.HeadHash != table.entries[len(table.entries)-1].Hash {
+					t.Errorf("got %+v, want the chain verified up to the last hash", result)
+				}
+				return
+			}
+
+			if result.Valid || result.BrokenAt == nil || *result.BrokenAt != tt.wantBroken {
+				t.Fatalf("got %+v, want the chain broken at entry %d", result, tt.wantBroken)
+			}
+			if !strings.Contains(result.Reason, tt.wantReason) {
+				t.Errorf("got reason %q, want it to say %q", result.Reason, tt.wantReason)
+			}
+		})
+	}
+}
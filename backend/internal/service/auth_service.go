@@ -2,9 +2,20 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 
@@ -13,30 +24,356 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
 	valkey "github.com/Mattouff/Lending-Borrowing/pkg/cache"
+	"github.com/Mattouff/Lending-Borrowing/pkg/siwe"
 )
 
+// lastUsedResolution bounds how often the last use of an API key is written to the database
+const lastUsedResolution = time.Minute
+
 // authService implements the AuthService interface
 type authService struct {
 	cfg          *config.Config
 	userRepo     repository.UserRepository
+	apiKeyRepo   repository.APIKeyRepository
+	auditService service.AuditService
 	valkeyClient *valkey.Client
+	ethClient    *blockchain.EthClient
+	jwtKeys      *jwtKeySet
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(cfg *config.Config, userRepo repository.UserRepository, valkeyClient *valkey.Client) service.AuthService {
+func NewAuthService(cfg *config.Config, userRepo repository.UserRepository, apiKeyRepo repository.APIKeyRepository, auditService service.AuditService, valkeyClient *valkey.Client) (service.AuthService, error) {
+	jwtKeys, err := newJWTKeySet(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+
 	return &authService{
 		cfg:          cfg,
 		userRepo:     userRepo,
+		apiKeyRepo:   apiKeyRepo,
+		auditService: auditService,
 		valkeyClient: valkeyClient,
+		ethClient:    blockchain.GetInstance(),
+		jwtKeys:      jwtKeys,
+	}, nil
+}
+
+// VerifyMessageSignature checks that an EIP-191 personal signature of a message was made by an address,
+// either by its key or, for contract wallets, through EIP-1271
+func (s *authService) VerifyMessageSignature(ctx context.Context, address, message, signature string) error {
+	if !common.IsHexAddress(address) {
+		return errors.New("invalid ethereum address")
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) == 0 {
+		return fmt.Errorf("%w: signature must be a non-empty hex string", service.ErrInvalidSignature)
+	}
+
+	valid, err := s.ethClient.VerifyPersonalSignature(ctx, common.HexToAddress(address), []byte(message), sig)
+	if err != nil {
+		return err
+	}
+
+	if !valid {
+		return service.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// CreateSignInChallenge issues a nonce to an address, whether or not it is registered
+func (s *authService) CreateSignInChallenge(ctx context.Context, address string) (*service.SignInChallenge, error) {
+	if !common.IsHexAddress(address) {
+		return nil, errors.New("invalid ethereum address")
+	}
+
+	nonce, err := siwe.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(s.cfg.SIWE.NonceTTL) * time.Minute
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	expiresAt := issuedAt.Add(ttl)
+
+	if err := s.valkeyClient.StoreNonce(ctx, nonce, common.HexToAddress(address).Hex(), ttl); err != nil {
+		return nil, err
+	}
+
+	// Prepare the message so clients only have to sign it
+	message := &siwe.Message{
+		Domain:         s.cfg.SIWE.Domain,
+		Address:        common.HexToAddress(address),
+		Statement:      s.cfg.SIWE.Statement,
+		URI:            s.cfg.SIWE.URI,
+		Version:        siwe.Version,
+		ChainID:        s.cfg.Blockchain.ChainID,
+		Nonce:          nonce,
+		IssuedAt:       issuedAt,
+		ExpirationTime: &expiresAt,
 	}
+
+	return &service.SignInChallenge{
+		Nonce:     nonce,
+		Message:   message.String(),
+		ExpiresAt: expiresAt,
+	}, nil
 }
 
-// GenerateToken creates a JWT token for a user
-func (s *authService) GenerateToken(ctx context.Context, user *models.User) (string, time.Time, error) {
-	// Generate unique token ID
-	tokenID := uuid.New().String()
+// CreateLinkChallenge issues the message a user must sign with its primary address and with
+// another address to link the latter to its account
+func (s *authService) CreateLinkChallenge(ctx context.Context, user *models.User, address string) (*service.LinkChallenge, error) {
+	if !common.IsHexAddress(address) {
+		return nil, errors.New("invalid ethereum address")
+	}
+
+	nonce, err := siwe.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(s.cfg.SIWE.NonceTTL) * time.Minute
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	expiresAt := issuedAt.Add(ttl)
+	message := formatLinkMessage(s.cfg.SIWE.Domain, user, common.HexToAddress(address).Hex(), nonce, issuedAt, expiresAt)
+
+	if err := s.valkeyClient.StoreLinkChallenge(ctx, user.ID, address, nonce, message, ttl); err != nil {
+		return nil, err
+	}
 
+	return &service.LinkChallenge{
+		Nonce:     nonce,
+		Message:   message,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyLinkChallenge consumes a link challenge and checks that it was signed by both addresses
+func (s *authService) VerifyLinkChallenge(ctx context.Context, user *models.User, address, nonce, primarySignature, addressSignature string) error {
+	// The challenge is consumed first, so that each one can only be tried once
+	message, err := s.valkeyClient.ConsumeLinkChallenge(ctx, user.ID, address, nonce)
+	if err != nil {
+		return err
+	}
+
+	if message == "" {
+		return fmt.Errorf("%w: unknown, expired or already used nonce", service.ErrInvalidLinkChallenge)
+	}
+
+	for _, signer := range []struct{ address, signature string }{
+		{user.Address, primarySignature},
+		{address, addressSignature},
+	} {
+		err := s.VerifyMessageSignature(ctx, signer.address, message, signer.signature)
+		if errors.Is(err, service.ErrInvalidSignature) {
+			return fmt.Errorf("%w: not signed by %s", service.ErrInvalidLinkChallenge, signer.address)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatLinkMessage writes the message both addresses sign to link the second one to an account
+func formatLinkMessage(domain string, user *models.User, address, nonce string, issuedAt, expiresAt time.Time) string {
+	return fmt.Sprintf(
+		"%s wants you to link a wallet to your account:\nPrimary address: %s\nAddress to link: %s\n\nNonce: %s\nIssued At: %s\nExpiration Time: %s",
+		domain,
+		common.HexToAddress(user.Address).Hex(),
+		address,
+		nonce,
+		issuedAt.Format(time.RFC3339),
+		expiresAt.Format(time.RFC3339),
+	)
+}
+
+// VerifySignIn validates a signed SIWE message, consumes its nonce and returns the signing address
+func (s *authService) VerifySignIn(ctx context.Context, rawMessage, signature string) (string, error) {
+	address, err := s.verifySignIn(ctx, rawMessage, signature)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSignIn) {
+			recordAudit(ctx, s.auditService, models.AuditSignInFailed, service.AuditTarget{Type: "address", ID: address}, nil, map[string]string{"reason": err.Error()})
+		}
+		return "", err
+	}
+	return address, nil
+}
+
+// verifySignIn performs the checks of VerifySignIn. The claimed address is returned alongside
+// rejections, once the message could be parsed
+func (s *authService) verifySignIn(ctx context.Context, rawMessage, signature string) (string, error) {
+	message, err := siwe.ParseMessage(rawMessage)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", service.ErrInvalidSignIn, err)
+	}
+
+	if message.Domain != s.cfg.SIWE.Domain {
+		return message.Address.Hex(), fmt.Errorf("%w: unexpected domain %s", service.ErrInvalidSignIn, message.Domain)
+	}
+
+	if !sameOrigin(message.URI, s.cfg.SIWE.URI) {
+		return message.Address.Hex(), fmt.Errorf("%w: unexpected URI %s", service.ErrInvalidSignIn, message.URI)
+	}
+
+	if message.ChainID != s.cfg.Blockchain.ChainID {
+		return message.Address.Hex(), fmt.Errorf("%w: unexpected chain ID %d", service.ErrInvalidSignIn, message.ChainID)
+	}
+
+	now := time.Now()
+	if message.ExpirationTime != nil && !now.Before(*message.ExpirationTime) {
+		return message.Address.Hex(), fmt.Errorf("%w: message has expired", service.ErrInvalidSignIn)
+	}
+
+	if message.NotBefore != nil && now.Before(*message.NotBefore) {
+		return message.Address.Hex(), fmt.Errorf("%w: message is not valid yet", service.ErrInvalidSignIn)
+	}
+
+	// Contract wallets are supported through EIP-1271
+	err = message.VerifySignature(ctx, rawMessage, signature, s.ethClient)
+	if errors.Is(err, siwe.ErrInvalidSignature) {
+		return message.Address.Hex(), fmt.Errorf("%w: %v", service.ErrInvalidSignIn, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The nonce is consumed last so that a forged message cannot burn someone else's nonce
+	issued, err := s.valkeyClient.ConsumeNonce(ctx, message.Nonce, message.Address.Hex())
+	if err != nil {
+		return "", err
+	}
+
+	if !issued {
+		return message.Address.Hex(), fmt.Errorf("%w: unknown, expired or already used nonce", service.ErrInvalidSignIn)
+	}
+
+	return message.Address.Hex(), nil
+}
+
+// sameOrigin reports whether a URI has the scheme and host of the expected origin
+func sameOrigin(uri, origin string) bool {
+	parsedURI, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	parsedOrigin, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return parsedURI.Scheme == parsedOrigin.Scheme && parsedURI.Host == parsedOrigin.Host
+}
+
+// CreateSession starts a session for a user and issues its first token pair
+func (s *authService) CreateSession(ctx context.Context, user *models.User, device, ip string) (*service.TokenPair, error) {
+	secret, refreshHash, err := newRefreshSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refreshTTL := time.Duration(s.cfg.JWT.RefreshExpireTime) * time.Minute
+	session := &valkey.Session{
+		ID:          uuid.New().String(),
+		Device:      device,
+		IP:          ip,
+		RefreshHash: refreshHash,
+		CreatedAt:   now,
+		LastSeen:    now,
+		ExpiresAt:   now.Add(refreshTTL),
+	}
+
+	// Store session in Valkey
+	if err := s.valkeyClient.StoreSession(ctx, user.ID, session, refreshTTL); err != nil {
+		return nil, err
+	}
+
+	accessToken, accessExpiresAt, err := s.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update user's last login time
+	user.LastLogin = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		// Non-critical error, we can still return the tokens
+		log.Printf("Failed to update last login of user %d: %v", user.ID, err)
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditSignIn, sessionTarget(session.ID), nil, map[string]any{
+		"userId": user.ID,
+		"device": device,
+		"ip":     ip,
+	})
+
+	return &service.TokenPair{
+		SessionID:        session.ID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     formatRefreshToken(user.ID, session.ID, secret),
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// RefreshSession rotates a refresh token and issues a new token pair for its session
+func (s *authService) RefreshSession(ctx context.Context, refreshToken, ip string) (*service.TokenPair, error) {
+	userID, sessionID, presentedSecret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.DeletedAt.Valid {
+		return nil, service.ErrInvalidRefreshToken
+	}
+
+	secret, refreshHash, err := newRefreshSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTTL := time.Duration(s.cfg.JWT.RefreshExpireTime) * time.Minute
+	result, err := s.valkeyClient.RotateRefreshToken(ctx, userID, sessionID, hashRefreshSecret(presentedSecret), refreshHash, ip, refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch result {
+	case valkey.RotationSessionNotFound:
+		return nil, service.ErrInvalidRefreshToken
+	case valkey.RotationReused:
+		log.Printf("ALERT: refresh token reuse detected for user %d, session %s revoked", userID, sessionID)
+		recordAudit(ctx, s.auditService, models.AuditRefreshTokenReuse, sessionTarget(sessionID), map[string]any{"userId": userID}, nil)
+		return nil, service.ErrRefreshTokenReused
+	}
+
+	accessToken, accessExpiresAt, err := s.generateAccessToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.TokenPair{
+		SessionID:        sessionID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     formatRefreshToken(userID, sessionID, secret),
+		RefreshExpiresAt: time.Now().Add(refreshTTL),
+	}, nil
+}
+
+// generateAccessToken creates a JWT access token bound to a session
+func (s *authService) generateAccessToken(user *models.User, sessionID string) (string, time.Time, error) {
 	// Set expiration time
 	expireTime := time.Duration(s.cfg.JWT.ExpireTime) * time.Minute
 	expirationTime := time.Now().Add(expireTime)
@@ -44,95 +381,206 @@ func (s *authService) GenerateToken(ctx context.Context, user *models.User) (str
 	// Create claims
 	claims := &middleware.AuthClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        tokenID, // JWT ID (jti)
+			ID:        uuid.New().String(), // JWT ID (jti)
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-		UserID:  user.ID,
-		Address: user.Address,
-		Role:    user.Role,
+		SessionID: sessionID,
+		UserID:    user.ID,
+		Address:   user.Address,
+		Role:      user.Role,
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Generate the JWT
-	tokenString, err := token.SignedString([]byte(s.cfg.JWT.Secret))
+	// Sign the JWT with the active key
+	tokenString, err := s.jwtKeys.sign(claims)
 	if err != nil {
 		return "", time.Time{}, err
 	}
 
-	// Store token in Valkey
-	err = s.valkeyClient.StoreValidToken(ctx, user.ID, tokenID, expireTime)
-	if err != nil {
-		return "", time.Time{}, err
-	}
-
-	// Update user's last login time
-	now := time.Now()
-	user.LastLogin = &now
-	err = s.userRepo.Update(ctx, user)
-	if err != nil {
-		// Non-critical error, we can still return the token
-		// but should log this error
-	}
-
 	return tokenString, expirationTime, nil
 }
 
-// ValidateToken validates a JWT token and returns the user
-func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*models.User, error) {
+// ValidateToken validates an access token and returns the user and its session ID
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*models.User, string, error) {
 	// Parse the JWT
 	claims := &middleware.AuthClaims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(s.cfg.JWT.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.jwtKeys.keyFunc)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, "", errors.New("invalid token")
 	}
 
-	// Check if token is still valid in Valkey
-	valid, err := s.valkeyClient.IsValidToken(ctx, claims.UserID, claims.RegisteredClaims.ID)
+	if claims.SessionID == "" {
+		return nil, "", errors.New("token is not bound to a session")
+	}
+
+	// Check if the session is still valid in Valkey
+	valid, err := s.valkeyClient.IsValidToken(ctx, claims.UserID, claims.SessionID)
 	if err != nil {
 		// If Valkey is down, we'll fall back to just checking if the user exists and is not deleted
 		// Log this error but continue for availability reasons
 	} else if !valid {
-		return nil, errors.New("token has been revoked")
+		return nil, "", errors.New("session has been revoked")
 	}
 
 	// Get user from database
 	user, err := s.userRepo.FindByID(ctx, claims.UserID)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, "", errors.New("user not found")
 	}
 
 	// Check if user is deleted
 	if user.DeletedAt.Valid {
-		return nil, errors.New("user account has been deleted")
+		return nil, "", errors.New("user account has been deleted")
+	}
+
+	return user, claims.SessionID, nil
+}
+
+// ValidateAPIKey checks a plaintext API key used from an IP, records its use and returns its owner
+func (s *authService) ValidateAPIKey(ctx context.Context, rawKey, ip string) (*models.User, *models.APIKey, error) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != apiKeyTag {
+		return nil, nil, fmt.Errorf("%w: malformed key", service.ErrInvalidAPIKey)
 	}
 
-	return user, nil
+	key, err := s.apiKeyRepo.FindByPrefix(ctx, parts[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashAPIKeySecret(parts[2]))) != 1 {
+		return nil, nil, fmt.Errorf("%w: unknown key", service.ErrInvalidAPIKey)
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, nil, fmt.Errorf("%w: key is revoked or expired", service.ErrInvalidAPIKey)
+	}
+
+	if !key.AllowsIP(ip) {
+		return nil, nil, fmt.Errorf("%w: key cannot be used from %s", service.ErrInvalidAPIKey, ip)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user == nil || user.DeletedAt.Valid {
+		return nil, nil, fmt.Errorf("%w: key owner no longer exists", service.ErrInvalidAPIKey)
+	}
+
+	// Keys used by bots can see many requests per second, so the last use is only written periodically
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution || key.LastUsedIP != ip {
+		if err := s.apiKeyRepo.UpdateLastUsed(ctx, key.ID, now, ip); err != nil {
+			log.Printf("Failed to record use of API key %d: %v", key.ID, err)
+		}
+		key.LastUsedAt = &now
+		key.LastUsedIP = ip
+	}
+
+	return user, key, nil
 }
 
-// InvalidateToken removes a specific token from valid tokens
-func (s *authService) InvalidateToken(ctx context.Context, userID uint, tokenID string) error {
-	return s.valkeyClient.InvalidateToken(ctx, userID, tokenID)
+// ListSessions returns the live sessions of a user
+func (s *authService) ListSessions(ctx context.Context, userID uint) ([]*service.Session, error) {
+	stored, err := s.valkeyClient.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*service.Session, len(stored))
+	for i, session := range stored {
+		sessions[i] = &service.Session{
+			ID:        session.ID,
+			Device:    session.Device,
+			IP:        session.IP,
+			CreatedAt: session.CreatedAt,
+			LastSeen:  session.LastSeen,
+			ExpiresAt: session.ExpiresAt,
+		}
+	}
+
+	return sessions, nil
 }
 
-// InvalidateAllUserTokens removes all valid tokens for a user
-func (s *authService) InvalidateAllUserTokens(ctx context.Context, userID uint) error {
-	return s.valkeyClient.InvalidateAllUserTokens(ctx, userID)
+// RevokeSession ends a session of a user
+func (s *authService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	existed, err := s.valkeyClient.InvalidateToken(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if !existed {
+		return service.ErrSessionNotFound
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditSessionRevoke, sessionTarget(sessionID), map[string]any{"userId": userID}, nil)
+	return nil
+}
+
+// RevokeAllSessions ends every session of a user
+func (s *authService) RevokeAllSessions(ctx context.Context, userID uint) error {
+	if err := s.valkeyClient.InvalidateAllUserTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditSessionRevokeAll, userTarget(userID), nil, nil)
+	return nil
+}
+
+// VerificationKeys returns the public keys of the active signing key and of the keys still in their grace period
+func (s *authService) VerificationKeys() []service.VerificationKey {
+	return s.jwtKeys.verificationKeys()
+}
+
+// sessionTarget identifies a session in the audit log
+func sessionTarget(sessionID string) service.AuditTarget {
+	return service.AuditTarget{Type: "session", ID: sessionID}
+}
+
+// newRefreshSecret returns a random refresh token secret and the hash stored in its place
+func newRefreshSecret() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	secret := hex.EncodeToString(buf)
+	return secret, hashRefreshSecret(secret), nil
+}
+
+func hashRefreshSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// Refresh tokens are opaque to clients but carry the user and session they belong to,
+// formatted as <user ID>.<session ID>.<secret>
+func formatRefreshToken(userID uint, sessionID, secret string) string {
+	return fmt.Sprintf("%d.%s.%s", userID, sessionID, secret)
+}
+
+func parseRefreshToken(refreshToken string) (uint, string, string, error) {
+	parts := strings.Split(refreshToken, ".")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return 0, "", "", service.ErrInvalidRefreshToken
+	}
+
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", "", service.ErrInvalidRefreshToken
+	}
+
+	return uint(userID), parts[1], parts[2], nil
 }
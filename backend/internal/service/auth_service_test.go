@@ -0,0 +1,28 @@
+package service
+
+import "testing"
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		uri    string
+		origin string
+		want   bool
+	}{
+		{uri: "https://example.com/login", origin: "https://example.com", want: true},
+		{uri: "https://example.com", origin: "https://example.com/", want: true},
+		{uri: "http://localhost:3000/app", origin: "http://localhost:3000", want: true},
+		{uri: "http://example.com/login", origin: "https://example.com", want: false},
+		{uri: "https://evil.com/https://example.com", origin: "https://example.com", want: false},
+		{uri: "https://example.com.evil.com", origin: "https://example.com", want: false},
+		{uri: "http://localhost:3001", origin: "http://localhost:3000", want: false},
+		{uri: "://broken", origin: "https://example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			if got := sameOrigin(tt.uri, tt.origin); got != tt.want {
+				t.Errorf("sameOrigin(%q, %q) = %v, want %v", tt.uri, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
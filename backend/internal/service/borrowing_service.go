@@ -10,14 +10,13 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
-	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
 )
 
 type borrowingService struct {
 	transactionRepo   repository.TransactionRepository
 	userRepo          repository.UserRepository
 	positionRepo      repository.PositionRepository
-	borrowing         *services.BorrowingService
+	markets           service.MarketRegistry
 	collateralService service.CollateralService
 }
 
@@ -26,38 +25,38 @@ func NewBorrowingService(
 	transactionRepo repository.TransactionRepository,
 	userRepo repository.UserRepository,
 	positionRepo repository.PositionRepository,
+	markets service.MarketRegistry,
 	collateralService service.CollateralService,
 ) (service.BorrowingService, error) {
-	serviceFactory := services.GetInstance()
-	borrowing, err := serviceFactory.GetBorrowingService()
-	if err != nil {
-		return nil, err
-	}
-
 	return &borrowingService{
 		transactionRepo:   transactionRepo,
 		userRepo:          userRepo,
 		positionRepo:      positionRepo,
-		borrowing:         borrowing,
+		markets:           markets,
 		collateralService: collateralService,
 	}, nil
 }
 
 // Borrow allows users to borrow tokens based on their collateral
-func (s *borrowingService) Borrow(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error) {
+func (s *borrowingService) Borrow(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error) {
 	// Check if amount is valid
 	if amount.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("borrow amount must be greater than 0")
+		return "", service.ErrInvalidAmount
+	}
+
+	borrowingMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if user can borrow the requested amount
-	maxBorrowable, err := s.collateralService.GetMaxBorrowableAmount(ctx, userAddress)
+	maxBorrowable, err := s.collateralService.GetMaxBorrowableAmount(ctx, market, userAddress)
 	if err != nil {
 		return "", err
 	}
 
 	if maxBorrowable.Cmp(amount) < 0 {
-		return "", errors.New("borrow amount exceeds maximum borrowable amount")
+		return "", service.ErrBorrowLimitExceeded.WithDetails(map[string]any{"maxBorrowable": maxBorrowable.String()})
 	}
 
 	// Get auth for transaction
@@ -67,9 +66,9 @@ func (s *borrowingService) Borrow(ctx context.Context, userAddress common.Addres
 	}
 
 	// Execute the borrow transaction
-	tx, err := s.borrowing.Borrow(auth, amount)
+	tx, err := contracts.Borrowing.Borrow(auth, amount)
 	if err != nil {
-		return "", err
+		return "", contractError(err)
 	}
 
 	// Store transaction in database
@@ -80,11 +79,13 @@ func (s *borrowingService) Borrow(ctx context.Context, userAddress common.Addres
 
 	transaction := &models.Transaction{
 		UserID:       user.ID,
+		MarketID:     borrowingMarket.ID,
 		Type:         models.TransactionBorrow,
+		Role:         models.TxRoleOwner,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       amount.String(),
-		TokenAddress: s.borrowing.ContractAddress().Hex(),
+		TokenAddress: borrowingMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
@@ -92,32 +93,26 @@ func (s *borrowingService) Borrow(ctx context.Context, userAddress common.Addres
 	}
 
 	// Update or create position
-	positions, err := s.positionRepo.FindActiveByUserID(ctx, user.ID)
+	positions, err := s.positionRepo.FindActiveByUserAndMarket(ctx, user.ID, borrowingMarket.ID)
 	if err != nil {
 		return "", err
 	}
 
 	// Get collateral balance
-	collateralBalance, err := s.collateralService.GetCollateralBalance(ctx, userAddress)
-	if err != nil {
-		return "", err
-	}
-
-	// Get token address for underlying collateral
-	serviceFactory := services.GetInstance()
-	collateralService, err := serviceFactory.GetCollateralService()
+	collateralBalance, err := contracts.Collateral.GetCollateralBalance(ctx, userAddress)
 	if err != nil {
 		return "", err
 	}
 
 	if len(positions) == 0 {
-		// Create new position
+		// Create new position; collateral and debt are both in the market's underlying token
 		position := &models.Position{
 			UserID:           user.ID,
+			MarketID:         borrowingMarket.ID,
 			CollateralAmount: collateralBalance.String(),
-			CollateralToken:  collateralService.ContractAddress().Hex(),
+			CollateralToken:  borrowingMarket.TokenAddress,
 			BorrowedAmount:   amount.String(),
-			BorrowedToken:    s.borrowing.ContractAddress().Hex(),
+			BorrowedToken:    borrowingMarket.TokenAddress,
 			InterestRate:     "0", // Will be updated later
 			Status:           models.StatusActive,
 			HealthFactor:     "0", // Will be updated later
@@ -149,21 +144,26 @@ func (s *borrowingService) Borrow(ctx context.Context, userAddress common.Addres
 }
 
 // Repay allows users to repay borrowed tokens
-func (s *borrowingService) Repay(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error) {
+func (s *borrowingService) Repay(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error) {
 	// Check if amount is valid
 	if amount.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("repay amount must be greater than 0")
+		return "", service.ErrInvalidAmount
+	}
+
+	borrowingMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
 	}
 
 	// Get current borrowed amount
-	borrowed, err := s.GetBorrowedAmount(ctx, userAddress)
+	borrowed, err := contracts.Borrowing.GetBorrowToken(ctx, userAddress)
 	if err != nil {
 		return "", err
 	}
 
 	// Check if repay amount is valid
 	if borrowed.Cmp(amount) < 0 {
-		return "", errors.New("repay amount exceeds borrowed amount")
+		return "", service.ErrRepayExceedsDebt.WithDetails(map[string]any{"borrowed": borrowed.String()})
 	}
 
 	// Get auth for transaction
@@ -173,9 +173,9 @@ func (s *borrowingService) Repay(ctx context.Context, userAddress common.Address
 	}
 
 	// Execute the repay transaction
-	tx, err := s.borrowing.Repay(auth, amount)
+	tx, err := contracts.Borrowing.Repay(auth, amount)
 	if err != nil {
-		return "", err
+		return "", contractError(err)
 	}
 
 	// Store transaction in database
@@ -186,11 +186,13 @@ func (s *borrowingService) Repay(ctx context.Context, userAddress common.Address
 
 	transaction := &models.Transaction{
 		UserID:       user.ID,
+		MarketID:     borrowingMarket.ID,
 		Type:         models.TransactionRepay,
+		Role:         models.TxRoleOwner,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       amount.String(),
-		TokenAddress: s.borrowing.ContractAddress().Hex(),
+		TokenAddress: borrowingMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
@@ -198,7 +200,7 @@ func (s *borrowingService) Repay(ctx context.Context, userAddress common.Address
 	}
 
 	// Update position
-	positions, err := s.positionRepo.FindActiveByUserID(ctx, user.ID)
+	positions, err := s.positionRepo.FindActiveByUserAndMarket(ctx, user.ID, borrowingMarket.ID)
 	if err != nil {
 		return "", err
 	}
@@ -229,62 +231,66 @@ func (s *borrowingService) Repay(ctx context.Context, userAddress common.Address
 }
 
 // GetBorrowedAmount returns the amount borrowed by a user
-func (s *borrowingService) GetBorrowedAmount(ctx context.Context, userAddress common.Address) (*big.Int, error) {
-	return s.borrowing.GetBorrowToken(ctx, userAddress)
+func (s *borrowingService) GetBorrowedAmount(ctx context.Context, market string, userAddress common.Address) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.Borrowing.GetBorrowToken(ctx, userAddress)
+}
+
+// GetTotalBorrowed returns the total amount borrowed from the market
+func (s *borrowingService) GetTotalBorrowed(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.Borrowing.GetTotalBorrowed(ctx)
 }
 
-// GetTotalBorrowed returns the total amount borrowed from the protocol
-func (s *borrowingService) GetTotalBorrowed(ctx context.Context) (*big.Int, error) {
-	return s.borrowing.GetTotalBorrowed(ctx)
+// GetUnderlyingToken returns the address of the token that is borrowed
+func (s *borrowingService) GetUnderlyingToken(ctx context.Context, market string) (common.Address, error) {
+	borrowingMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return borrowingMarket.GetTokenAddress(), nil
 }
 
 // GetCurrentInterestRate returns the current interest rate for borrowing
-func (s *borrowingService) GetCurrentInterestRate(ctx context.Context) (*big.Int, error) {
-	return s.borrowing.GetCurrentRate(ctx)
+func (s *borrowingService) GetCurrentInterestRate(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.Borrowing.GetCurrentRate(ctx)
 }
 
 // GetUserInterestAccrued returns the interest accrued by a user on borrowed amount
 // Note: This is a simplified implementation. In reality, you might need to calculate this based on time and rate
-func (s *borrowingService) GetUserInterestAccrued(ctx context.Context, userAddress common.Address) (*big.Int, error) {
+func (s *borrowingService) GetUserInterestAccrued(ctx context.Context, market string, userAddress common.Address) (*big.Int, error) {
 	// Implementation depends on your contract design
 	// For this example, we'll just return 0 as a placeholder
 	return big.NewInt(0), nil
 }
 
-// GetUserTransactionHistory returns a user's borrowing transaction history
-func (s *borrowingService) GetUserTransactionHistory(ctx context.Context, userAddress common.Address, offset, limit int) ([]*models.Transaction, error) {
-	user, err := s.userRepo.FindByAddress(ctx, userAddress.Hex())
-	if err != nil {
+// GetUserTransactionHistory returns a user's borrowing transaction history matching a filter
+func (s *borrowingService) GetUserTransactionHistory(ctx context.Context, market string, userAddress common.Address, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error) {
+	// Borrowing history only covers borrows and repayments
+	matches, err := scopeUserTransactions(ctx, s.markets, s.userRepo, market, userAddress, &filter, models.ContractBorrowing.Types())
+	if err != nil || !matches {
 		return nil, err
 	}
 
-	// Get transactions with filter for borrow and repay types
-	filter := map[string]any{
-		"user_id": user.ID,
-		"type":    []models.TransactionType{models.TransactionBorrow, models.TransactionRepay},
-	}
-
 	return s.transactionRepo.List(ctx, filter, offset, limit)
 }
 
-// CountUserTransactions counts the number of transactions for a user with optional filtering
-func (s *borrowingService) CountUserTransactions(ctx context.Context, address common.Address, filter map[string]any) (int64, error) {
-	// Find the user by address
-	user, err := s.userRepo.FindByAddress(ctx, address.Hex())
-	if err != nil {
+// CountUserTransactions counts the borrowing transactions of a user matching a filter
+func (s *borrowingService) CountUserTransactions(ctx context.Context, market string, address common.Address, filter models.TransactionFilter) (int64, error) {
+	matches, err := scopeUserTransactions(ctx, s.markets, s.userRepo, market, address, &filter, models.ContractBorrowing.Types())
+	if err != nil || !matches {
 		return 0, err
 	}
 
-	if user == nil {
-		return 0, errors.New("user not found")
-	}
-
-	// Add the user ID to the filter
-	if filter == nil {
-		filter = make(map[string]any)
-	}
-	filter["user_id"] = user.ID
-
-	// Count the transactions
 	return s.transactionRepo.Count(ctx, filter)
 }
@@ -0,0 +1,841 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/contracts/generated"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
+)
+
+const (
+	// maxBundleSteps is the largest number of actions a bundle may hold
+	maxBundleSteps = 10
+	// bundleReceiptTimeout is how long a signer bundle waits for a step to be mined
+	bundleReceiptTimeout = 5 * time.Minute
+	// bundleReceiptPoll is how often a signer bundle checks whether a step was mined
+	bundleReceiptPoll = 2 * time.Second
+	// bundleClaim is how long a server holds a signer bundle after starting a step: long enough to send
+	// the step and wait for its receipt
+	bundleClaim = bundleReceiptTimeout + time.Minute
+)
+
+var (
+	// errStepReverted is recorded when a bundle step was mined but reverted
+	errStepReverted = errors.New("transaction reverted")
+	// errStepDropped is recorded when the node no longer knows the transaction of a submitted step
+	errStepDropped = errors.New("transaction dropped")
+)
+
+type bundleService struct {
+	bundleRepo        repository.BundleRepository
+	transactionRepo   repository.TransactionRepository
+	markets           service.MarketRegistry
+	collateralService service.CollateralService
+	borrowingService  service.BorrowingService
+	ethClient         *blockchain.EthClient
+
+	// Signer bundles run until the service shuts down
+	runCtx   context.Context
+	stopRuns context.CancelFunc
+	runs     sync.WaitGroup
+}
+
+// NewBundleService creates a new bundle service running steps through the collateral and borrowing services
+func NewBundleService(
+	bundleRepo repository.BundleRepository,
+	transactionRepo repository.TransactionRepository,
+	markets service.MarketRegistry,
+	collateralService service.CollateralService,
+	borrowingService service.BorrowingService,
+) (service.BundleService, error) {
+	runCtx, stopRuns := context.WithCancel(context.Background())
+	return &bundleService{
+		bundleRepo:        bundleRepo,
+		transactionRepo:   transactionRepo,
+		markets:           markets,
+		collateralService: collateralService,
+		borrowingService:  borrowingService,
+		ethClient:         blockchain.GetInstance(),
+		runCtx:            runCtx,
+		stopRuns:          stopRuns,
+	}, nil
+}
+
+// CreateBundle validates a list of actions against the simulated state after each step, then stores it
+// and, in signer mode, starts sending its steps
+func (s *bundleService) CreateBundle(ctx context.Context, market string, userID uint, userAddress common.Address, mode models.BundleMode, actions []service.BundleActionRequest) (*models.Bundle, error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("invalid mode %s, expected signer or unsigned", mode)
+	}
+
+	if len(actions) == 0 || len(actions) > maxBundleSteps {
+		return nil, fmt.Errorf("a bundle holds between 1 and %d actions", maxBundleSteps)
+	}
+
+	bundleMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := loadBundleState(ctx, contracts, userAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]models.BundleStep, len(actions))
+	for i, action := range actions {
+		if !action.Action.IsValid() {
+			return nil, service.BundleStepError(i, action.Action, "unknown action")
+		}
+
+		var spender models.TransactionContract
+		if action.Action == models.ActionApprove {
+			var found bool
+			if spender, found = approvalSpender(actions, i); !found {
+				return nil, service.BundleStepError(i, action.Action, "approval spender must be collateral or borrowing, or followed by a depositCollateral or repay step")
+			}
+		}
+
+		if reason := state.apply(action.Action, action.Amount, spender); reason != "" {
+			return nil, service.BundleStepError(i, action.Action, reason)
+		}
+
+		to, data, err := bundleCalldata(contracts, action.Action, spender, action.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		steps[i] = models.BundleStep{
+			Position: i,
+			Action:   action.Action,
+			Amount:   action.Amount.String(),
+			Status:   models.StepPending,
+			To:       to.Hex(),
+			Data:     hexutil.Encode(data),
+		}
+		if spender != "" {
+			steps[i].Spender = spenderAddress(contracts, spender).Hex()
+		}
+	}
+
+	bundle := &models.Bundle{
+		UserID:   userID,
+		MarketID: bundleMarket.ID,
+		Address:  userAddress.Hex(),
+		Mode:     mode,
+		Status:   models.BundleRunning,
+		Steps:    steps,
+	}
+
+	// Unsigned transactions use consecutive nonces so that wallets mine them in order
+	if mode == models.BundleModeUnsigned {
+		client, err := s.ethClient.GetClient()
+		if err != nil {
+			return nil, err
+		}
+
+		nonce, err := client.PendingNonceAt(ctx, userAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range bundle.Steps {
+			bundle.Steps[i].Nonce = nonce + uint64(i)
+		}
+		bundle.Status = models.BundlePrepared
+	}
+
+	// Signer bundles are held by this server from the start, so that no other one resumes them
+	if mode == models.BundleModeSigner {
+		claimedUntil := time.Now().Add(bundleClaim)
+		bundle.ClaimedUntil = &claimedUntil
+	}
+
+	if err := s.bundleRepo.Create(ctx, bundle); err != nil {
+		return nil, err
+	}
+
+	if mode == models.BundleModeSigner {
+		s.start(bundle, bundleMarket.Identifier)
+	}
+
+	return bundle, nil
+}
+
+// GetBundle returns a bundle of a user, after checking whether its submitted steps were mined
+func (s *bundleService) GetBundle(ctx context.Context, userID, bundleID uint) (*models.Bundle, error) {
+	bundle, err := s.findBundle(ctx, userID, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Signer bundles are kept up to date as they run
+	if bundle.Mode == models.BundleModeUnsigned && bundle.Status == models.BundleRunning {
+		if err := s.refresh(ctx, bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	return bundle, nil
+}
+
+// ListBundles returns the bundles of a user, newest first, with pagination
+func (s *bundleService) ListBundles(ctx context.Context, userID uint, offset, limit int) ([]*models.Bundle, error) {
+	return s.bundleRepo.ListByUser(ctx, userID, offset, limit)
+}
+
+// CountBundles returns the number of bundles of a user
+func (s *bundleService) CountBundles(ctx context.Context, userID uint) (int64, error) {
+	return s.bundleRepo.CountByUser(ctx, userID)
+}
+
+// SubmitStep records the hash of the transaction the user sent for the next step of an unsigned bundle.
+// The transaction must be known to the node and match the step
+func (s *bundleService) SubmitStep(ctx context.Context, userID, bundleID uint, position int, hash string) (*models.Bundle, error) {
+	bundle, err := s.findBundle(ctx, userID, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bundle.Mode != models.BundleModeUnsigned || (bundle.Status != models.BundlePrepared && bundle.Status != models.BundleRunning) {
+		return nil, service.ErrStepNotNext
+	}
+
+	var step *models.BundleStep
+	for i := range bundle.Steps {
+		if bundle.Steps[i].Status == models.StepPending {
+			step = &bundle.Steps[i]
+			break
+		}
+	}
+	if step == nil || step.Position != position {
+		return nil, service.ErrStepNotNext
+	}
+
+	client, err := s.ethClient.GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, _, err := client.TransactionByHash(ctx, common.HexToHash(hash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, service.ErrStepTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reason := matchStep(tx, bundle, step); reason != "" {
+		return nil, service.ErrStepMismatch.WithDetails(map[string]any{"position": step.Position, "reason": reason})
+	}
+
+	step.Hash = tx.Hash().Hex()
+	step.Status = models.StepSubmitted
+	if err := s.bundleRepo.UpdateStep(ctx, step); err != nil {
+		return nil, err
+	}
+
+	// Record the step in the ledger, as the collateral and borrowing services do for signer bundles
+	if transactionType, recorded := step.Action.TransactionType(); recorded {
+		market, err := s.findMarket(ctx, bundle.MarketID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.transactionRepo.Create(ctx, &models.Transaction{
+			UserID:       bundle.UserID,
+			MarketID:     bundle.MarketID,
+			Type:         transactionType,
+			Role:         models.TxRoleOwner,
+			Status:       models.StatusPending,
+			Hash:         step.Hash,
+			Amount:       step.Amount,
+			TokenAddress: market.TokenAddress,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	bundle.Status = models.BundleRunning
+	if err := s.bundleRepo.Update(ctx, bundle); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// findBundle returns a bundle of a user
+func (s *bundleService) findBundle(ctx context.Context, userID, bundleID uint) (*models.Bundle, error) {
+	bundle, err := s.bundleRepo.FindByID(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bundle == nil || bundle.UserID != userID {
+		return nil, service.ErrBundleNotFound
+	}
+
+	return bundle, nil
+}
+
+// ResumeBundles runs in the background the signer bundles that no server holds anymore, once the steps
+// they had sent are settled from their receipts
+func (s *bundleService) ResumeBundles(ctx context.Context) error {
+	bundles, err := s.bundleRepo.ClaimUnheld(ctx, time.Now().Add(bundleClaim))
+	if err != nil {
+		return err
+	}
+
+	for _, bundle := range bundles {
+		market, err := s.findMarket(ctx, bundle.MarketID)
+		if errors.Is(err, service.ErrMarketNotFound) {
+			s.fail(ctx, bundle, firstUnsettledStep(bundle), err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Resuming bundle %d", bundle.ID)
+		s.start(bundle, market.Identifier)
+	}
+
+	return nil
+}
+
+// Shutdown stops the signer bundles running in the background and releases them for the next server,
+// waiting until they stopped or the context is done
+func (s *bundleService) Shutdown(ctx context.Context) error {
+	s.stopRuns()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StartBundleResumer periodically resumes the signer bundles that no server holds anymore, until the context
+// is cancelled
+func StartBundleResumer(ctx context.Context, bundleService service.BundleService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := bundleService.ResumeBundles(ctx); err != nil {
+					log.Printf("Failed to resume bundles: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// start runs a signer bundle in the background until it is over or the service shuts down
+func (s *bundleService) start(bundle *models.Bundle, market string) {
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		s.run(s.runCtx, bundle, market)
+	}()
+}
+
+// run sends the steps of a signer bundle in order, each once the previous one is mined, and stops at the first failure.
+// Steps sent before are settled from their receipts instead. When the context is cancelled, the bundle is left
+// running and released for the next server to resume
+func (s *bundleService) run(ctx context.Context, bundle *models.Bundle, market string) {
+	// Records outlive the cancellation: a transaction sent must be recorded
+	store := context.WithoutCancel(ctx)
+
+	userAddress := common.HexToAddress(bundle.Address)
+	for i := range bundle.Steps {
+		step := &bundle.Steps[i]
+		if step.Status == models.StepCompleted {
+			continue
+		}
+
+		// Held for as long as the step may take
+		claimedUntil := time.Now().Add(bundleClaim)
+		bundle.ClaimedUntil = &claimedUntil
+		if err := s.bundleRepo.Update(store, bundle); err != nil {
+			log.Printf("Failed to update bundle %d: %v", bundle.ID, err)
+			return
+		}
+
+		err := s.runStep(ctx, store, market, userAddress, step)
+		if ctx.Err() != nil {
+			s.release(store, bundle)
+			return
+		}
+		if err != nil {
+			s.fail(store, bundle, i, err)
+			return
+		}
+	}
+
+	bundle.Status = models.BundleCompleted
+	bundle.ClaimedUntil = nil
+	if err := s.bundleRepo.Update(store, bundle); err != nil {
+		log.Printf("Failed to update bundle %d: %v", bundle.ID, err)
+	}
+}
+
+// runStep sends the transaction of a signer bundle step, unless it was sent before, and waits for it to be mined.
+// Outcomes are recorded with the store context
+func (s *bundleService) runStep(ctx, store context.Context, market string, userAddress common.Address, step *models.BundleStep) error {
+	client, err := s.ethClient.GetClient()
+	if err != nil {
+		return err
+	}
+
+	switch step.Status {
+	case models.StepPending:
+		hash, err := s.sendStep(ctx, market, userAddress, step)
+		if err != nil {
+			return err
+		}
+
+		step.Hash = hash
+		step.Status = models.StepSubmitted
+		if err := s.bundleRepo.UpdateStep(store, step); err != nil {
+			return err
+		}
+
+	case models.StepSubmitted:
+		// Sent by a server that stopped: the transaction may have been mined, be pending or be gone
+		if _, _, err := client.TransactionByHash(ctx, common.HexToHash(step.Hash)); errors.Is(err, ethereum.NotFound) {
+			return errStepDropped
+		} else if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unexpected step status %s", step.Status)
+	}
+
+	receiptCtx, cancel := context.WithTimeout(ctx, bundleReceiptTimeout)
+	defer cancel()
+
+	receipt, err := waitForReceipt(receiptCtx, client, common.HexToHash(step.Hash))
+	if err != nil {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	return s.settle(store, step, receipt)
+}
+
+// sendStep sends the transaction of a signer bundle step and returns its hash
+func (s *bundleService) sendStep(ctx context.Context, market string, userAddress common.Address, step *models.BundleStep) (string, error) {
+	amount, ok := new(big.Int).SetString(step.Amount, 10)
+	if !ok {
+		return "", errors.New("failed to parse step amount")
+	}
+
+	switch step.Action {
+	case models.ActionApprove:
+		return s.approve(ctx, market, userAddress, common.HexToAddress(step.Spender), amount)
+	case models.ActionDepositCollateral:
+		return s.collateralService.DepositCollateral(ctx, market, userAddress, amount)
+	case models.ActionBorrow:
+		return s.borrowingService.Borrow(ctx, market, userAddress, amount)
+	case models.ActionRepay:
+		return s.borrowingService.Repay(ctx, market, userAddress, amount)
+	case models.ActionWithdraw:
+		return s.collateralService.WithdrawCollateral(ctx, market, userAddress, amount)
+	}
+	return "", fmt.Errorf("unknown action %s", step.Action)
+}
+
+// release lets another server resume a signer bundle this one stopped running
+func (s *bundleService) release(ctx context.Context, bundle *models.Bundle) {
+	bundle.ClaimedUntil = nil
+	if err := s.bundleRepo.Update(ctx, bundle); err != nil {
+		log.Printf("Failed to release bundle %d: %v", bundle.ID, err)
+		return
+	}
+	log.Printf("Stopped bundle %d, released for the next server to resume", bundle.ID)
+}
+
+// approve allows a contract to pull tokens of the market from the user's wallet
+func (s *bundleService) approve(ctx context.Context, market string, userAddress, spender common.Address, amount *big.Int) (string, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
+	}
+
+	auth, err := getTransactOpts(ctx, userAddress)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := contracts.Token.Approve(auth, spender, amount)
+	if err != nil {
+		return "", contractError(err)
+	}
+
+	return tx.Hash().Hex(), nil
+}
+
+// refresh settles the submitted steps of an unsigned bundle that were mined
+func (s *bundleService) refresh(ctx context.Context, bundle *models.Bundle) error {
+	client, err := s.ethClient.GetClient()
+	if err != nil {
+		return err
+	}
+
+	for i := range bundle.Steps {
+		step := &bundle.Steps[i]
+		if step.Status != models.StepSubmitted {
+			continue
+		}
+
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(step.Hash))
+		if errors.Is(err, ethereum.NotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.settle(ctx, step, receipt); err != nil {
+			s.fail(ctx, bundle, i, err)
+			return nil
+		}
+	}
+
+	// Done once every step was submitted and mined
+	for _, step := range bundle.Steps {
+		if step.Status != models.StepCompleted {
+			return nil
+		}
+	}
+
+	bundle.Status = models.BundleCompleted
+	return s.bundleRepo.Update(ctx, bundle)
+}
+
+// settle records the outcome of a mined step, on the step and its ledger entries
+func (s *bundleService) settle(ctx context.Context, step *models.BundleStep, receipt *types.Receipt) error {
+	step.BlockNumber = receipt.BlockNumber.Uint64()
+	step.GasUsed = receipt.GasUsed
+	step.Status = models.StepCompleted
+
+	status := models.StatusCompleted
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		step.Status = models.StepFailed
+		step.ErrorMessage = errStepReverted.Error()
+		status = models.StatusFailed
+	}
+
+	entries, err := s.transactionRepo.FindByHash(ctx, step.Hash)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entry.Status = status
+		entry.BlockNumber = step.BlockNumber
+		entry.GasUsed = step.GasUsed
+		entry.ErrorMessage = step.ErrorMessage
+		if err := s.transactionRepo.Update(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := s.bundleRepo.UpdateStep(ctx, step); err != nil {
+		return err
+	}
+
+	if step.Status == models.StepFailed {
+		return errStepReverted
+	}
+	return nil
+}
+
+// fail marks a step as failed, skips the steps after it and fails the bundle
+func (s *bundleService) fail(ctx context.Context, bundle *models.Bundle, position int, cause error) {
+	for i := position; i < len(bundle.Steps); i++ {
+		step := &bundle.Steps[i]
+		if i == position {
+			step.Status = models.StepFailed
+			if step.ErrorMessage == "" {
+				step.ErrorMessage = cause.Error()
+			}
+		} else {
+			step.Status = models.StepSkipped
+		}
+
+		if err := s.bundleRepo.UpdateStep(ctx, step); err != nil {
+			log.Printf("Failed to update step %d of bundle %d: %v", i, bundle.ID, err)
+		}
+	}
+
+	bundle.Status = models.BundleFailed
+	bundle.ClaimedUntil = nil
+	bundle.Error = fmt.Sprintf("step %d (%s) failed: %v", position, bundle.Steps[position].Action, cause)
+	if err := s.bundleRepo.Update(ctx, bundle); err != nil {
+		log.Printf("Failed to update bundle %d: %v", bundle.ID, err)
+	}
+}
+
+// findMarket returns an active market by ID
+func (s *bundleService) findMarket(ctx context.Context, marketID uint) (*models.Market, error) {
+	activeMarkets, err := s.markets.ListMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, market := range activeMarkets {
+		if market.ID == marketID {
+			return market, nil
+		}
+	}
+	return nil, service.ErrMarketNotFound
+}
+
+// firstUnsettledStep returns the position of the first step of a bundle that was not mined successfully
+func firstUnsettledStep(bundle *models.Bundle) int {
+	for i, step := range bundle.Steps {
+		if step.Status != models.StepCompleted {
+			return i
+		}
+	}
+	return len(bundle.Steps) - 1
+}
+
+// bundleState is the simulated state of a user's wallet and position in a market, mirroring the contract checks
+type bundleState struct {
+	wallet     *big.Int                                // Token balance of the user
+	allowances map[models.TransactionContract]*big.Int // Tokens each contract may pull from the user
+	collateral *big.Int
+	debt       *big.Int // Including accrued interest
+	liquidity  *big.Int // Token balance of the Borrowing contract, which pays out borrows
+	minRatio   *big.Int // Collateral to debt, in percent
+	maxBorrow  *big.Int // Share of collateral that can be borrowed, in percent
+}
+
+// loadBundleState reads the current state of a user's wallet and position in a market
+func loadBundleState(ctx context.Context, contracts *services.MarketContracts, userAddress common.Address) (*bundleState, error) {
+	state := &bundleState{allowances: make(map[models.TransactionContract]*big.Int)}
+
+	var err error
+	if state.wallet, err = contracts.Token.BalanceOf(ctx, userAddress); err != nil {
+		return nil, err
+	}
+	if state.allowances[models.ContractCollateral], err = contracts.Token.Allowance(ctx, userAddress, contracts.Collateral.ContractAddress()); err != nil {
+		return nil, err
+	}
+	if state.allowances[models.ContractBorrowing], err = contracts.Token.Allowance(ctx, userAddress, contracts.Borrowing.ContractAddress()); err != nil {
+		return nil, err
+	}
+	if state.collateral, err = contracts.Collateral.GetCollateralBalance(ctx, userAddress); err != nil {
+		return nil, err
+	}
+	if state.debt, err = contracts.Borrowing.GetBorrowToken(ctx, userAddress); err != nil {
+		return nil, err
+	}
+	if state.liquidity, err = contracts.Token.BalanceOf(ctx, contracts.Borrowing.ContractAddress()); err != nil {
+		return nil, err
+	}
+	if state.minRatio, err = contracts.Collateral.GetMinCollateralRatio(ctx); err != nil {
+		return nil, err
+	}
+	if state.maxBorrow, err = contracts.Collateral.GetMaxBorrowingPercentage(ctx); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// apply simulates an action, returning why the contracts would reject it or an empty string
+func (st *bundleState) apply(action models.BundleAction, amount *big.Int, spender models.TransactionContract) string {
+	if amount == nil || amount.Sign() < 0 || (amount.Sign() == 0 && action != models.ActionApprove) {
+		return "amount must be greater than 0"
+	}
+
+	hundred := big.NewInt(100)
+
+	switch action {
+	case models.ActionApprove:
+		st.allowances[spender] = new(big.Int).Set(amount)
+
+	case models.ActionDepositCollateral:
+		if st.wallet.Cmp(amount) < 0 {
+			return "insufficient wallet balance"
+		}
+		if st.allowances[models.ContractCollateral].Cmp(amount) < 0 {
+			return "insufficient allowance for the collateral contract, approve it first"
+		}
+		st.wallet.Sub(st.wallet, amount)
+		st.allowances[models.ContractCollateral].Sub(st.allowances[models.ContractCollateral], amount)
+		st.collateral.Add(st.collateral, amount)
+
+	case models.ActionBorrow:
+		// Collateral.canBorrow: the collateral covers the minimum ratio and the debt stays within the maximum share
+		total := new(big.Int).Add(st.debt, amount)
+		covered := new(big.Int).Mul(st.collateral, hundred).Cmp(new(big.Int).Mul(total, st.minRatio)) >= 0
+		withinShare := total.Cmp(new(big.Int).Div(new(big.Int).Mul(st.collateral, st.maxBorrow), hundred)) <= 0
+		if !covered || !withinShare {
+			return "borrow amount exceeds maximum borrowable amount"
+		}
+		if st.liquidity.Cmp(amount) < 0 {
+			return "insufficient liquidity"
+		}
+		st.debt = total
+		st.wallet.Add(st.wallet, amount)
+		st.liquidity.Sub(st.liquidity, amount)
+
+	case models.ActionRepay:
+		if st.debt.Cmp(amount) < 0 {
+			return "repay amount exceeds borrowed amount"
+		}
+		if st.wallet.Cmp(amount) < 0 {
+			return "insufficient wallet balance"
+		}
+		if st.allowances[models.ContractBorrowing].Cmp(amount) < 0 {
+			return "insufficient allowance for the borrowing contract, approve it first"
+		}
+		st.debt.Sub(st.debt, amount)
+		st.wallet.Sub(st.wallet, amount)
+		st.allowances[models.ContractBorrowing].Sub(st.allowances[models.ContractBorrowing], amount)
+		st.liquidity.Add(st.liquidity, amount)
+
+	case models.ActionWithdraw:
+		if st.collateral.Cmp(amount) < 0 {
+			return "withdrawal exceeds collateral balance"
+		}
+		remaining := new(big.Int).Sub(st.collateral, amount)
+		if st.debt.Sign() > 0 && new(big.Int).Mul(remaining, hundred).Cmp(new(big.Int).Mul(st.debt, st.minRatio)) < 0 {
+			return "collateral ratio too low after withdrawal"
+		}
+		st.collateral = remaining
+		st.wallet.Add(st.wallet, amount)
+	}
+
+	return ""
+}
+
+// approvalSpender returns the contract an approval is for: the one requested, or else the contract
+// of the next step pulling tokens from the wallet
+func approvalSpender(actions []service.BundleActionRequest, position int) (models.TransactionContract, bool) {
+	if spender := actions[position].Spender; spender != "" {
+		return spender, spender == models.ContractCollateral || spender == models.ContractBorrowing
+	}
+
+	for _, next := range actions[position+1:] {
+		switch next.Action {
+		case models.ActionDepositCollateral:
+			return models.ContractCollateral, true
+		case models.ActionRepay:
+			return models.ContractBorrowing, true
+		}
+	}
+	return "", false
+}
+
+// spenderAddress returns the address of the contract an approval is for
+func spenderAddress(contracts *services.MarketContracts, spender models.TransactionContract) common.Address {
+	if spender == models.ContractBorrowing {
+		return contracts.Borrowing.ContractAddress()
+	}
+	return contracts.Collateral.ContractAddress()
+}
+
+// bundleCalldata returns the contract an action is sent to and its calldata
+func bundleCalldata(contracts *services.MarketContracts, action models.BundleAction, spender models.TransactionContract, amount *big.Int) (common.Address, []byte, error) {
+	switch action {
+	case models.ActionApprove:
+		data, err := packCall(generated.TokenMetaData, "approve", spenderAddress(contracts, spender), amount)
+		return contracts.Token.ContractAddress(), data, err
+	case models.ActionDepositCollateral:
+		data, err := packCall(generated.CollateralMetaData, "depositCollateral", amount)
+		return contracts.Collateral.ContractAddress(), data, err
+	case models.ActionBorrow:
+		data, err := packCall(generated.BorrowingMetaData, "borrow", amount)
+		return contracts.Borrowing.ContractAddress(), data, err
+	case models.ActionRepay:
+		data, err := packCall(generated.BorrowingMetaData, "repay", amount)
+		return contracts.Borrowing.ContractAddress(), data, err
+	case models.ActionWithdraw:
+		data, err := packCall(generated.CollateralMetaData, "withdrawCollateral", amount)
+		return contracts.Collateral.ContractAddress(), data, err
+	}
+	return common.Address{}, nil, fmt.Errorf("unknown action %s", action)
+}
+
+// packCall encodes a call to a contract method
+func packCall(metaData *bind.MetaData, method string, args ...any) ([]byte, error) {
+	parsed, err := metaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Pack(method, args...)
+}
+
+// matchStep returns why a transaction does not perform a bundle step, or an empty string
+func matchStep(tx *types.Transaction, bundle *models.Bundle, step *models.BundleStep) string {
+	if tx.To() == nil || *tx.To() != common.HexToAddress(step.To) || hexutil.Encode(tx.Data()) != step.Data {
+		return "recipient or calldata differ from the step"
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil || sender != common.HexToAddress(bundle.Address) {
+		return "transaction was not sent by the bundle address"
+	}
+
+	return ""
+}
+
+// waitForReceipt polls for the receipt of a transaction until it is mined or the context is done
+func waitForReceipt(ctx context.Context, client *ethclient.Client, hash common.Hash) (*types.Receipt, error) {
+	ticker := time.NewTicker(bundleReceiptPoll)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
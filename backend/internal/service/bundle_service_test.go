@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
+)
+
+// bundleTable holds bundles in memory, as the bundles table would
+type bundleTable struct {
+	mu      sync.Mutex
+	bundles map[uint]*models.Bundle
+	unheld  []*models.Bundle // Returned by the next ClaimUnheld
+}
+
+func (r *bundleTable) Create(ctx context.Context, bundle *models.Bundle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bundles[bundle.ID] = bundle
+	return nil
+}
+
+func (r *bundleTable) FindByID(ctx context.Context, id uint) (*models.Bundle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bundles[id], nil
+}
+
+func (r *bundleTable) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*models.Bundle, error) {
+	return nil, nil
+}
+
+func (r *bundleTable) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	return 0, nil
+}
+
+func (r *bundleTable) Update(ctx context.Context, bundle *models.Bundle) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bundles[bundle.ID] = bundle
+	return nil
+}
+
+func (r *bundleTable) UpdateStep(ctx context.Context, step *models.BundleStep) error {
+	return ctx.Err()
+}
+
+func (r *bundleTable) ClaimUnheld(ctx context.Context, until time.Time) ([]*models.Bundle, error) {
+	for _, bundle := range r.unheld {
+		bundle.ClaimedUntil = &until
+	}
+	return r.unheld, nil
+}
+
+func runningBundle(id, marketID uint) *models.Bundle {
+	claimedUntil := time.Now().Add(bundleClaim)
+	return &models.Bundle{
+		ID:           id,
+		MarketID:     marketID,
+		Mode:         models.BundleModeSigner,
+		Status:       models.BundleRunning,
+		ClaimedUntil: &claimedUntil,
+		Steps: []models.BundleStep{
+			{Position: 0, Action: models.ActionApprove, Amount: "1", Status: models.StepCompleted},
+			{Position: 1, Action: models.ActionDepositCollateral, Amount: "1", Status: models.StepPending},
+			{Position: 2, Action: models.ActionBorrow, Amount: "1", Status: models.StepPending},
+		},
+	}
+}
+
+func TestBundleRunStoppedOnShutdownIsReleased(t *testing.T) {
+	table := &bundleTable{bundles: make(map[uint]*models.Bundle)}
+	s := &bundleService{bundleRepo: table, ethClient: blockchain.GetInstance()}
+	bundle := runningBundle(1, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.run(ctx, bundle, "lbt")
+
+	if bundle.Status != models.BundleRunning || bundle.ClaimedUntil != nil {
+		t.Errorf("got bundle %s claimed until %v, want it running and released", bundle.Status, bundle.ClaimedUntil)
+	}
+	for _, step := range bundle.Steps[1:] {
+		if step.Status != models.StepPending {
+			t.Errorf("got step %d %s, want it left pending for the next server", step.Position, step.Status)
+		}
+	}
+}
+
+func TestBundleRunFailure(t *testing.T) {
+	table := &bundleTable{bundles: make(map[uint]*models.Bundle)}
+	s := &bundleService{bundleRepo: table, ethClient: blockchain.GetInstance()}
+	bundle := runningBundle(1, 1)
+
+	// The client of the node is not initialized, so the first step left fails
+	s.run(context.Background(), bundle, "lbt")
+
+	if bundle.Status != models.BundleFailed || bundle.ClaimedUntil != nil {
+		t.Fatalf("got bundle %s claimed until %v, want it failed and released", bundle.Status, bundle.ClaimedUntil)
+	}
+	want := []models.BundleStepStatus{models.StepCompleted, models.StepFailed, models.StepSkipped}
+	for i, step := range bundle.Steps {
+		if step.Status != want[i] {
+			t.Errorf("got step %d %s, want %s", i, step.Status, want[i])
+		}
+	}
+}
+
+func TestResumeBundlesOfInactiveMarket(t *testing.T) {
+	bundle := runningBundle(1, 2)
+	table := &bundleTable{bundles: make(map[uint]*models.Bundle), unheld: []*models.Bundle{bundle}}
+	s := &bundleService{
+		bundleRepo: table,
+		markets:    marketList{{ID: 1, Identifier: "lbt"}},
+		ethClient:  blockchain.GetInstance(),
+		runCtx:     context.Background(),
+	}
+
+	if err := s.ResumeBundles(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.runs.Wait()
+
+	if bundle.Status != models.BundleFailed || bundle.Steps[1].Status != models.StepFailed {
+		t.Errorf("got bundle %s with step 1 %s, want the first unsettled step failed", bundle.Status, bundle.Steps[1].Status)
+	}
+}
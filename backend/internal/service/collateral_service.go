@@ -2,22 +2,22 @@ package service
 
 import (
 	"context"
-	"errors"
+	"log"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
-	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
 )
 
 type collateralService struct {
 	transactionRepo repository.TransactionRepository
 	userRepo        repository.UserRepository
 	positionRepo    repository.PositionRepository
-	collateral      *services.CollateralService
+	markets         service.MarketRegistry
 }
 
 // NewCollateralService creates a new collateral service
@@ -25,26 +25,26 @@ func NewCollateralService(
 	transactionRepo repository.TransactionRepository,
 	userRepo repository.UserRepository,
 	positionRepo repository.PositionRepository,
+	markets service.MarketRegistry,
 ) (service.CollateralService, error) {
-	serviceFactory := services.GetInstance()
-	collateral, err := serviceFactory.GetCollateralService()
-	if err != nil {
-		return nil, err
-	}
-
 	return &collateralService{
 		transactionRepo: transactionRepo,
 		userRepo:        userRepo,
 		positionRepo:    positionRepo,
-		collateral:      collateral,
+		markets:         markets,
 	}, nil
 }
 
 // DepositCollateral allows users to deposit tokens as collateral
-func (s *collateralService) DepositCollateral(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error) {
+func (s *collateralService) DepositCollateral(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error) {
 	// Check if amount is valid
 	if amount.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("deposit amount must be greater than 0")
+		return "", service.ErrInvalidAmount
+	}
+
+	collateralMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
 	}
 
 	// Get auth for transaction
@@ -54,9 +54,9 @@ func (s *collateralService) DepositCollateral(ctx context.Context, userAddress c
 	}
 
 	// Execute the deposit collateral transaction
-	tx, err := s.collateral.DepositCollateral(auth, amount)
+	tx, err := contracts.Collateral.DepositCollateral(auth, amount)
 	if err != nil {
-		return "", err
+		return "", contractError(err)
 	}
 
 	// Store transaction in database
@@ -67,11 +67,13 @@ func (s *collateralService) DepositCollateral(ctx context.Context, userAddress c
 
 	transaction := &models.Transaction{
 		UserID:       user.ID,
-		Type:         models.TransactionDeposit,
+		MarketID:     collateralMarket.ID,
+		Type:         models.TransactionCollateralDeposit,
+		Role:         models.TxRoleOwner,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       amount.String(),
-		TokenAddress: s.collateral.ContractAddress().Hex(),
+		TokenAddress: collateralMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
@@ -79,13 +81,13 @@ func (s *collateralService) DepositCollateral(ctx context.Context, userAddress c
 	}
 
 	// Update or create position
-	positions, err := s.positionRepo.FindActiveByUserID(ctx, user.ID)
+	positions, err := s.positionRepo.FindActiveByUserAndMarket(ctx, user.ID, collateralMarket.ID)
 	if err != nil {
 		return "", err
 	}
 
 	// Get current collateral balance
-	newCollateralBalance, err := s.GetCollateralBalance(ctx, userAddress)
+	newCollateralBalance, err := contracts.Collateral.GetCollateralBalance(ctx, userAddress)
 	if err != nil {
 		return "", err
 	}
@@ -94,10 +96,11 @@ func (s *collateralService) DepositCollateral(ctx context.Context, userAddress c
 		// Create new position if user doesn't have one
 		position := &models.Position{
 			UserID:           user.ID,
+			MarketID:         collateralMarket.ID,
 			CollateralAmount: newCollateralBalance.String(),
-			CollateralToken:  s.collateral.ContractAddress().Hex(),
+			CollateralToken:  collateralMarket.TokenAddress,
 			BorrowedAmount:   "0", // No borrowing yet
-			BorrowedToken:    "",  // Will be set when borrowing
+			BorrowedToken:    collateralMarket.TokenAddress,
 			InterestRate:     "0", // Will be set when borrowing
 			Status:           models.StatusActive,
 			HealthFactor:     "0", // Will be calculated later
@@ -112,7 +115,7 @@ func (s *collateralService) DepositCollateral(ctx context.Context, userAddress c
 		position.CollateralAmount = newCollateralBalance.String()
 
 		// Recalculate health factor
-		healthFactor, err := s.GetCollateralRatio(ctx, userAddress)
+		healthFactor, err := contracts.Collateral.GetCollateralRatio(ctx, userAddress)
 		if err == nil && healthFactor != nil {
 			position.HealthFactor = healthFactor.String()
 		}
@@ -126,31 +129,30 @@ func (s *collateralService) DepositCollateral(ctx context.Context, userAddress c
 }
 
 // WithdrawCollateral allows users to withdraw tokens from their collateral
-func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error) {
+func (s *collateralService) WithdrawCollateral(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error) {
 	// Check if amount is valid
 	if amount.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("withdrawal amount must be greater than 0")
+		return "", service.ErrInvalidAmount
+	}
+
+	collateralMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if user has enough collateral
-	balance, err := s.GetCollateralBalance(ctx, userAddress)
+	balance, err := contracts.Collateral.GetCollateralBalance(ctx, userAddress)
 	if err != nil {
 		return "", err
 	}
 
 	if balance.Cmp(amount) < 0 {
-		return "", errors.New("insufficient collateral balance for withdrawal")
+		return "", service.ErrInsufficientCollateral.WithDetails(map[string]any{"balance": balance.String()})
 	}
 
 	// Check if withdrawal would put user's position at risk
 	// First get current borrowed amount
-	serviceFactory := services.GetInstance()
-	borrowingService, err := serviceFactory.GetBorrowingService()
-	if err != nil {
-		return "", err
-	}
-
-	borrowedAmount, err := borrowingService.GetBorrowToken(ctx, userAddress)
+	borrowedAmount, err := contracts.Borrowing.GetBorrowToken(ctx, userAddress)
 	if err != nil {
 		return "", err
 	}
@@ -161,7 +163,7 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 		newBalance := new(big.Int).Sub(balance, amount)
 
 		// Calculate minimum required collateral based on borrowed amount and min ratio
-		minRatio, err := s.GetMinCollateralRatio(ctx)
+		minRatio, err := contracts.Collateral.GetMinCollateralRatio(ctx)
 		if err != nil {
 			return "", err
 		}
@@ -172,7 +174,7 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 		minCollateral = minCollateral.Div(minCollateral, divisor)
 
 		if newBalance.Cmp(minCollateral) < 0 {
-			return "", errors.New("withdrawal would put position at risk of liquidation")
+			return "", service.ErrCollateralRatioTooLow.WithDetails(map[string]any{"minCollateral": minCollateral.String()})
 		}
 	}
 
@@ -183,9 +185,9 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 	}
 
 	// Execute the withdraw collateral transaction
-	tx, err := s.collateral.WithdrawCollateral(auth, amount)
+	tx, err := contracts.Collateral.WithdrawCollateral(auth, amount)
 	if err != nil {
-		return "", err
+		return "", contractError(err)
 	}
 
 	// Store transaction in database
@@ -196,11 +198,13 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 
 	transaction := &models.Transaction{
 		UserID:       user.ID,
-		Type:         models.TransactionWithdraw,
+		MarketID:     collateralMarket.ID,
+		Type:         models.TransactionCollateralWithdraw,
+		Role:         models.TxRoleOwner,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       amount.String(),
-		TokenAddress: s.collateral.ContractAddress().Hex(),
+		TokenAddress: collateralMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
@@ -208,7 +212,7 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 	}
 
 	// Update position
-	positions, err := s.positionRepo.FindActiveByUserID(ctx, user.ID)
+	positions, err := s.positionRepo.FindActiveByUserAndMarket(ctx, user.ID, collateralMarket.ID)
 	if err != nil {
 		return "", err
 	}
@@ -217,7 +221,7 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 		position := positions[0]
 
 		// Get new collateral balance
-		newCollateralBalance, err := s.GetCollateralBalance(ctx, userAddress)
+		newCollateralBalance, err := contracts.Collateral.GetCollateralBalance(ctx, userAddress)
 		if err != nil {
 			return "", err
 		}
@@ -225,7 +229,7 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 		position.CollateralAmount = newCollateralBalance.String()
 
 		// Recalculate health factor
-		healthFactor, err := s.GetCollateralRatio(ctx, userAddress)
+		healthFactor, err := contracts.Collateral.GetCollateralRatio(ctx, userAddress)
 		if err == nil && healthFactor != nil {
 			position.HealthFactor = healthFactor.String()
 		}
@@ -247,46 +251,170 @@ func (s *collateralService) WithdrawCollateral(ctx context.Context, userAddress
 }
 
 // GetCollateralBalance returns the collateral balance of a user
-func (s *collateralService) GetCollateralBalance(ctx context.Context, userAddress common.Address) (*big.Int, error) {
-	return s.collateral.GetCollateralBalance(ctx, userAddress)
+func (s *collateralService) GetCollateralBalance(ctx context.Context, market string, userAddress common.Address) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.Collateral.GetCollateralBalance(ctx, userAddress)
+}
+
+// GetTotalCollateral returns the total collateral in the market
+func (s *collateralService) GetTotalCollateral(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+
+	// The contract keeps no running total, but it holds every deposited token
+	return contracts.Token.BalanceOf(ctx, contracts.Collateral.ContractAddress())
+}
+
+// GetUnderlyingToken returns the address of the token accepted as collateral
+func (s *collateralService) GetUnderlyingToken(ctx context.Context, market string) (common.Address, error) {
+	collateralMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return collateralMarket.GetTokenAddress(), nil
+}
+
+// GetUserTransactionHistory returns a user's collateral transaction history, liquidations included matching a filter
+func (s *collateralService) GetUserTransactionHistory(ctx context.Context, market string, userAddress common.Address, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error) {
+	// Collateral history covers collateral moves and liquidations
+	matches, err := scopeUserTransactions(ctx, s.markets, s.userRepo, market, userAddress, &filter, models.ContractCollateral.Types())
+	if err != nil || !matches {
+		return nil, err
+	}
+
+	return s.transactionRepo.List(ctx, filter, offset, limit)
+}
+
+// CountUserTransactions counts the collateral transactions of a user matching a filter
+func (s *collateralService) CountUserTransactions(ctx context.Context, market string, address common.Address, filter models.TransactionFilter) (int64, error) {
+	matches, err := scopeUserTransactions(ctx, s.markets, s.userRepo, market, address, &filter, models.ContractCollateral.Types())
+	if err != nil || !matches {
+		return 0, err
+	}
+
+	return s.transactionRepo.Count(ctx, filter)
+}
+
+// ReconcileTotalCollateral cross-checks the on-chain collateral total against indexed user balances
+func (s *collateralService) ReconcileTotalCollateral(ctx context.Context, market string) (*service.CollateralReconciliation, error) {
+	collateralMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+
+	onChain, err := s.GetTotalCollateral(ctx, collateralMarket.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed, err := s.positionRepo.SumCurrentCollateral(ctx, collateralMarket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	difference := new(big.Int).Sub(onChain, indexed)
+	reconciliation := &service.CollateralReconciliation{
+		OnChain:    onChain,
+		Indexed:    indexed,
+		Difference: difference,
+		Diverged:   difference.Sign() != 0,
+	}
+
+	// Divergence means tokens were moved to or from the contract outside of the
+	// API (e.g. a direct transfer) or that positions are out of date
+	if reconciliation.Diverged {
+		log.Printf(
+			"ALERT: collateral divergence detected in market %s: on-chain=%s indexed=%s difference=%s",
+			collateralMarket.Identifier, onChain.String(), indexed.String(), difference.String(),
+		)
+	}
+
+	return reconciliation, nil
 }
 
-// GetTotalCollateral returns the total collateral in the protocol
-func (s *collateralService) GetTotalCollateral(ctx context.Context) (*big.Int, error) {
-	// This is just a placeholder. The actual implementation depends on your contract
-	return big.NewInt(0), errors.New("not implemented in contract")
+// StartCollateralMonitor periodically reconciles the collateral total of every market until the context is cancelled
+func StartCollateralMonitor(ctx context.Context, collateralService service.CollateralService, markets service.MarketRegistry, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				activeMarkets, err := markets.ListMarkets(ctx)
+				if err != nil {
+					log.Printf("Failed to list markets: %v", err)
+					continue
+				}
+
+				for _, market := range activeMarkets {
+					if _, err := collateralService.ReconcileTotalCollateral(ctx, market.Identifier); err != nil {
+						log.Printf("Failed to reconcile collateral of market %s: %v", market.Identifier, err)
+					}
+				}
+			}
+		}
+	}()
 }
 
 // GetCollateralRatio returns the collateral ratio for a user
-func (s *collateralService) GetCollateralRatio(ctx context.Context, userAddress common.Address) (*big.Int, error) {
-	return s.collateral.GetCollateralRatio(ctx, userAddress)
+func (s *collateralService) GetCollateralRatio(ctx context.Context, market string, userAddress common.Address) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	ratio, err := contracts.Collateral.GetCollateralRatio(ctx, userAddress)
+	return ratio, contractError(err)
 }
 
 // GetMinCollateralRatio returns the minimum collateral ratio required
-func (s *collateralService) GetMinCollateralRatio(ctx context.Context) (*big.Int, error) {
-	return s.collateral.GetMinCollateralRatio(ctx)
+func (s *collateralService) GetMinCollateralRatio(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.Collateral.GetMinCollateralRatio(ctx)
 }
 
 // GetLiquidationThreshold returns the threshold at which a position can be liquidated
-func (s *collateralService) GetLiquidationThreshold(ctx context.Context) (*big.Int, error) {
-	return s.collateral.GetLiquidationThreshold(ctx)
+func (s *collateralService) GetLiquidationThreshold(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.Collateral.GetLiquidationThreshold(ctx)
 }
 
 // GetMaxBorrowableAmount returns the maximum amount a user can borrow based on their collateral
-func (s *collateralService) GetMaxBorrowableAmount(ctx context.Context, userAddress common.Address) (*big.Int, error) {
-	return s.collateral.GetMaxBorrowableAmount(ctx, userAddress)
+func (s *collateralService) GetMaxBorrowableAmount(ctx context.Context, market string, userAddress common.Address) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	maxBorrowable, err := contracts.Collateral.GetMaxBorrowableAmount(ctx, userAddress)
+	return maxBorrowable, contractError(err)
 }
 
 // IsAtRisk checks if a user's position is at risk of liquidation
-func (s *collateralService) IsAtRisk(ctx context.Context, userAddress common.Address) (bool, error) {
-	// Check if user has any borrowings
-	serviceFactory := services.GetInstance()
-	borrowingService, err := serviceFactory.GetBorrowingService()
+func (s *collateralService) IsAtRisk(ctx context.Context, market string, userAddress common.Address) (bool, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
 	if err != nil {
 		return false, err
 	}
 
-	borrowedAmount, err := borrowingService.GetBorrowToken(ctx, userAddress)
+	// Check if user has any borrowings
+	borrowedAmount, err := contracts.Borrowing.GetBorrowToken(ctx, userAddress)
 	if err != nil {
 		return false, err
 	}
@@ -297,13 +425,13 @@ func (s *collateralService) IsAtRisk(ctx context.Context, userAddress common.Add
 	}
 
 	// Get current collateral ratio
-	ratio, err := s.GetCollateralRatio(ctx, userAddress)
+	ratio, err := contracts.Collateral.GetCollateralRatio(ctx, userAddress)
 	if err != nil {
 		return false, err
 	}
 
 	// Get liquidation threshold
-	threshold, err := s.GetLiquidationThreshold(ctx)
+	threshold, err := contracts.Collateral.GetLiquidationThreshold(ctx)
 	if err != nil {
 		return false, err
 	}
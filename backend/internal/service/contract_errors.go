@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/contracts/generated"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// revertPrefix starts the message of nodes rejecting a call that reverted with a reason
+const revertPrefix = "execution reverted"
+
+// revertReasons maps the require messages of the contracts to the domain errors they stand for
+var revertReasons = map[string]*service.Error{
+	// Borrowing
+	"Amount must be greater than zero":      service.ErrInvalidAmount,
+	"Insufficient collateral":               service.ErrBorrowLimitExceeded,
+	"Borrow transfer failed":                service.ErrTokenTransferFailed,
+	"Repay amount exceeds borrowed balance": service.ErrRepayExceedsDebt,
+	"Repay transfer failed":                 service.ErrTokenTransferFailed,
+	"Not authorized":                        service.ErrContractUnauthorized,
+	"Insufficient debt":                     service.ErrNoDebt,
+
+	// Collateral
+	"Collateral transfer failed":                     service.ErrTokenTransferFailed,
+	"Withdrawal exceeds collateral balance":          service.ErrInsufficientCollateral,
+	"Collateral ratio too low after withdrawal":      service.ErrCollateralRatioTooLow,
+	"Collateral withdrawal transfer failed":          service.ErrTokenTransferFailed,
+	"Repay amount must be greater than zero":         service.ErrInvalidAmount,
+	"Borrower has no debt":                           service.ErrNoDebt,
+	"Collateral ratio is sufficient for liquidation": service.ErrNotLiquidatable,
+	"Repayment transfer failed":                      service.ErrTokenTransferFailed,
+	"Collateral transfer to liquidator failed":       service.ErrTokenTransferFailed,
+
+	// LendingPool
+	"Token transfer failed":        service.ErrTokenTransferFailed,
+	"Insufficient deposit balance": service.ErrInsufficientDeposit,
+
+	// PriceAggregator
+	"No data present": service.ErrPriceUnavailable,
+}
+
+// tokenErrors maps the custom errors of the ERC-20 token to the domain errors they stand for
+var tokenErrors = map[string]*service.Error{
+	"ERC20InsufficientBalance":   service.ErrInsufficientTokenBalance,
+	"ERC20InsufficientAllowance": service.ErrInsufficientAllowance,
+}
+
+// contractError returns the domain error of a contract call that reverted, with the revert reason in its details.
+// Errors that are not reverts, e.g. a node that cannot be reached, are returned as is
+func contractError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if revertData, decodeErr := hexutil.Decode(data); decodeErr == nil && len(revertData) >= 4 {
+				return revertError(revertData)
+			}
+		}
+	}
+
+	// Nodes that do not return the revert data still name the reason in the message
+	_, reason, found := strings.Cut(err.Error(), revertPrefix)
+	if !found {
+		return err
+	}
+	return reasonError(strings.TrimSpace(strings.TrimPrefix(reason, ":")))
+}
+
+// revertError decodes the data of a revert: a require message or a custom error of the token
+func revertError(data []byte) error {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reasonError(reason)
+	}
+
+	if parsed, err := generated.TokenMetaData.GetAbi(); err == nil {
+		if abiError, err := parsed.ErrorByID([4]byte(data[:4])); err == nil {
+			details := map[string]any{"reason": abiError.Name}
+			if values, err := abiError.Unpack(data); err == nil {
+				for i, value := range values.([]any) {
+					details[abiError.Inputs[i].Name] = detailValue(value)
+				}
+			}
+
+			if domainErr, ok := tokenErrors[abiError.Name]; ok {
+				return domainErr.WithDetails(details)
+			}
+			return service.ErrContractReverted.WithDetails(details)
+		}
+	}
+
+	return service.ErrContractReverted.WithDetails(map[string]any{"data": hexutil.Encode(data)})
+}
+
+// reasonError returns the domain error of a require message
+func reasonError(reason string) error {
+	if reason == "" {
+		return service.ErrContractReverted
+	}
+
+	details := map[string]any{"reason": reason}
+	if domainErr, ok := revertReasons[reason]; ok {
+		return domainErr.WithDetails(details)
+	}
+	return service.ErrContractReverted.WithDetails(details)
+}
+
+// detailValue formats a custom error argument for clients: amounts as decimal strings, addresses as hex
+func detailValue(value any) any {
+	if stringer, ok := value.(interface{ String() string }); ok {
+		return stringer.String()
+	}
+	return value
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// jwtKey is an asymmetric key access tokens are signed with
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.PrivateKey
+	public    crypto.PublicKey
+	expiresAt *time.Time // End of the grace period of a retired key, nil while it is not retired
+}
+
+// usable reports whether tokens signed by the key are still accepted
+func (k *jwtKey) usable(now time.Time) bool {
+	return k.expiresAt == nil || now.Before(*k.expiresAt)
+}
+
+// jwtKeySet signs access tokens with the active key and verifies them by their "kid" header.
+// Tokens without a key ID were signed with the HS256 secret before a key became active
+type jwtKeySet struct {
+	active      *jwtKey
+	keys        map[string]*jwtKey // By key ID
+	secret      []byte
+	legacyUntil time.Time // When HS256 tokens stop being accepted, once a key is active
+}
+
+// newJWTKeySet loads the configured signing keys
+func newJWTKeySet(cfg config.JWTConfig) (*jwtKeySet, error) {
+	keySet := &jwtKeySet{
+		keys:   make(map[string]*jwtKey),
+		secret: []byte(cfg.Secret),
+	}
+
+	grace := time.Duration(cfg.KeyGrace) * time.Minute
+	for kid, path := range cfg.SigningKeys {
+		key, err := loadJWTKey(kid, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if retiredAt, retired := cfg.RetiredKeys[kid]; retired {
+			expiresAt := retiredAt.Add(grace)
+			key.expiresAt = &expiresAt
+		}
+
+		keySet.keys[kid] = key
+	}
+
+	if cfg.ActiveKey != "" {
+		keySet.active = keySet.keys[cfg.ActiveKey]
+		if keySet.active == nil {
+			return nil, fmt.Errorf("active JWT key %s is not configured", cfg.ActiveKey)
+		}
+
+		// Like a retired key, the secret verifies the tokens it signed for a grace period after the switch.
+		// The switch is configured rather than taken as the start of the process, so that restarts do not
+		// extend the grace period
+		if cfg.Secret != "" && cfg.SecretRetiredAt.IsZero() {
+			return nil, fmt.Errorf("the time JWT_SECRET stopped signing tokens is required once JWT key %s is active", cfg.ActiveKey)
+		}
+		keySet.legacyUntil = cfg.SecretRetiredAt.Add(grace)
+	}
+
+	return keySet, nil
+}
+
+// loadJWTKey reads a PEM private key, signing with RS256 for RSA keys and EdDSA for Ed25519 keys
+func loadJWTKey(kid, path string) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key %s: %w", kid, err)
+	}
+
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return &jwtKey{id: kid, method: jwt.SigningMethodRS256, private: rsaKey, public: &rsaKey.PublicKey}, nil
+	}
+
+	edKey, err := jwt.ParseEdPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %s must be a PEM encoded RSA or Ed25519 private key", kid)
+	}
+
+	return &jwtKey{id: kid, method: jwt.SigningMethodEdDSA, private: edKey, public: edKey.(ed25519.PrivateKey).Public()}, nil
+}
+
+// sign signs claims with the active key, or with the HS256 secret when no key is active
+func (s *jwtKeySet) sign(claims jwt.Claims) (string, error) {
+	if s.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	}
+
+	token := jwt.NewWithClaims(s.active.method, claims)
+	token.Header["kid"] = s.active.id
+	return token.SignedString(s.active.private)
+}
+
+// keyFunc returns the key verifying a parsed token
+func (s *jwtKeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		if len(s.secret) == 0 || (s.active != nil && !time.Now().Before(s.legacyUntil)) {
+			return nil, errors.New("HS256 tokens are no longer accepted")
+		}
+		return s.secret, nil
+	}
+
+	key, found := s.keys[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	if !key.usable(time.Now()) {
+		return nil, fmt.Errorf("signing key %s has been retired", kid)
+	}
+
+	return key.public, nil
+}
+
+// verificationKeys returns the public keys tokens may currently be signed with, sorted by key ID
+func (s *jwtKeySet) verificationKeys() []service.VerificationKey {
+	now := time.Now()
+
+	keys := make([]service.VerificationKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if !key.usable(now) {
+			continue
+		}
+		keys = append(keys, service.VerificationKey{
+			ID:        key.id,
+			Algorithm: key.method.Alg(),
+			PublicKey: key.public,
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+)
+
+// writeEd25519Key writes a PEM encoded Ed25519 private key and returns its path
+func writeEd25519Key(t *testing.T) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJWTKeySetLegacySecret(t *testing.T) {
+	keyPath := writeEd25519Key(t)
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		activeKey       string
+		secretRetiredAt time.Time
+		wantAccepted    bool
+		wantErr         bool // Switching without a configured time would reject every session at once
+	}{
+		{name: "no active key", wantAccepted: true},
+		{name: "switched within the grace period", activeKey: "k1", secretRetiredAt: now.Add(-5 * time.Minute), wantAccepted: true},
+		{name: "switched before the grace period", activeKey: "k1", secretRetiredAt: now.Add(-20 * time.Minute), wantAccepted: false},
+		{name: "switch time not configured", activeKey: "k1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.JWTConfig{
+				Secret:          "secret",
+				ExpireTime:      15,
+				SigningKeys:     map[string]string{"k1": keyPath},
+				ActiveKey:       tt.activeKey,
+				KeyGrace:        15,
+				SecretRetiredAt: tt.secretRetiredAt,
+			}
+
+			// The grace period must not depend on when the key set is loaded, i.e. on restarts
+			for range 2 {
+				keySet, err := newJWTKeySet(cfg)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatal("got a key set, want an error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				}).SignedString([]byte("secret"))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				_, err = jwt.Parse(legacy, keySet.keyFunc)
+				if accepted := err == nil; accepted != tt.wantAccepted {
+					t.Fatalf("HS256 token accepted = %v (%v), want %v", accepted, err, tt.wantAccepted)
+				}
+			}
+		})
+	}
+}
+
+func TestJWTKeySetSignsWithActiveKey(t *testing.T) {
+	keySet, err := newJWTKeySet(config.JWTConfig{
+		SigningKeys: map[string]string{"k1": writeEd25519Key(t)},
+		ActiveKey:   "k1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signed, err := keySet.sign(jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, err := jwt.Parse(signed, keySet.keyFunc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Header["kid"] != "k1" || token.Method.Alg() != jwt.SigningMethodEdDSA.Alg() {
+		t.Errorf("got kid %v signed with %s, want k1 with EdDSA", token.Header["kid"], token.Method.Alg())
+	}
+}
+
+func TestJWTKeySetRetiredKey(t *testing.T) {
+	path := writeEd25519Key(t)
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		retiredAt    time.Time
+		wantAccepted bool
+	}{
+		{name: "within the grace period", retiredAt: now.Add(-5 * time.Minute), wantAccepted: true},
+		{name: "after the grace period", retiredAt: now.Add(-20 * time.Minute), wantAccepted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signing, err := newJWTKeySet(config.JWTConfig{SigningKeys: map[string]string{"old": path}, ActiveKey: "old"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			signed, err := signing.sign(jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour))})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			verifying, err := newJWTKeySet(config.JWTConfig{
+				SigningKeys: map[string]string{"old": path, "new": writeEd25519Key(t)},
+				ActiveKey:   "new",
+				RetiredKeys: map[string]time.Time{"old": tt.retiredAt},
+				KeyGrace:    15,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = jwt.Parse(signed, verifying.keyFunc)
+			if accepted := err == nil; accepted != tt.wantAccepted {
+				t.Errorf("token of the retired key accepted = %v (%v), want %v", accepted, err, tt.wantAccepted)
+			}
+		})
+	}
+}
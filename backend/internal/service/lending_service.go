@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -11,38 +10,37 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
-	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
 )
 
 type lendingService struct {
 	transactionRepo repository.TransactionRepository
 	userRepo        repository.UserRepository
-	lendingPool     *services.LendingPoolService
+	markets         service.MarketRegistry
 }
 
 // NewLendingService creates a new lending service
 func NewLendingService(
 	transactionRepo repository.TransactionRepository,
 	userRepo repository.UserRepository,
+	markets service.MarketRegistry,
 ) (service.LendingService, error) {
-	serviceFactory := services.GetInstance()
-	lendingPool, err := serviceFactory.GetLendingPoolService()
-	if err != nil {
-		return nil, err
-	}
-
 	return &lendingService{
 		transactionRepo: transactionRepo,
 		userRepo:        userRepo,
-		lendingPool:     lendingPool,
+		markets:         markets,
 	}, nil
 }
 
 // Deposit allows users to deposit tokens into the lending pool
-func (s *lendingService) Deposit(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error) {
+func (s *lendingService) Deposit(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error) {
 	// Check if amount is valid
 	if amount.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("deposit amount must be greater than 0")
+		return "", service.ErrInvalidAmount
+	}
+
+	lendingMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
 	}
 
 	// Get auth for transaction
@@ -52,9 +50,9 @@ func (s *lendingService) Deposit(ctx context.Context, userAddress common.Address
 	}
 
 	// Execute the deposit transaction
-	tx, err := s.lendingPool.Deposit(auth, amount)
+	tx, err := contracts.LendingPool.Deposit(auth, amount)
 	if err != nil {
-		return "", err
+		return "", contractError(err)
 	}
 
 	// Store transaction in database
@@ -65,11 +63,13 @@ func (s *lendingService) Deposit(ctx context.Context, userAddress common.Address
 
 	transaction := &models.Transaction{
 		UserID:       user.ID,
+		MarketID:     lendingMarket.ID,
 		Type:         models.TransactionDeposit,
+		Role:         models.TxRoleOwner,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       amount.String(),
-		TokenAddress: s.lendingPool.ContractAddress().Hex(),
+		TokenAddress: lendingMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
@@ -80,20 +80,25 @@ func (s *lendingService) Deposit(ctx context.Context, userAddress common.Address
 }
 
 // Withdraw allows users to withdraw tokens from the lending pool
-func (s *lendingService) Withdraw(ctx context.Context, userAddress common.Address, amount *big.Int) (string, error) {
+func (s *lendingService) Withdraw(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error) {
 	// Check if amount is valid
 	if amount.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("withdrawal amount must be greater than 0")
+		return "", service.ErrInvalidAmount
+	}
+
+	lendingMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if user has enough balance
-	balance, err := s.GetUserBalance(ctx, userAddress)
+	balance, err := contracts.LendingPool.GetLendingToken(ctx, userAddress)
 	if err != nil {
 		return "", err
 	}
 
 	if balance.Cmp(amount) < 0 {
-		return "", errors.New("insufficient balance for withdrawal")
+		return "", service.ErrInsufficientDeposit.WithDetails(map[string]any{"balance": balance.String()})
 	}
 
 	// Get auth for transaction
@@ -103,9 +108,9 @@ func (s *lendingService) Withdraw(ctx context.Context, userAddress common.Addres
 	}
 
 	// Execute the withdraw transaction
-	tx, err := s.lendingPool.Withdraw(auth, amount)
+	tx, err := contracts.LendingPool.Withdraw(auth, amount)
 	if err != nil {
-		return "", err
+		return "", contractError(err)
 	}
 
 	// Store transaction in database
@@ -116,11 +121,13 @@ func (s *lendingService) Withdraw(ctx context.Context, userAddress common.Addres
 
 	transaction := &models.Transaction{
 		UserID:       user.ID,
+		MarketID:     lendingMarket.ID,
 		Type:         models.TransactionWithdraw,
+		Role:         models.TxRoleOwner,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       amount.String(),
-		TokenAddress: s.lendingPool.ContractAddress().Hex(),
+		TokenAddress: lendingMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
@@ -131,41 +138,57 @@ func (s *lendingService) Withdraw(ctx context.Context, userAddress common.Addres
 }
 
 // GetUserBalance returns the user's balance in the lending pool
-func (s *lendingService) GetUserBalance(ctx context.Context, userAddress common.Address) (*big.Int, error) {
-	return s.lendingPool.GetLendingToken(ctx, userAddress)
+func (s *lendingService) GetUserBalance(ctx context.Context, market string, userAddress common.Address) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.LendingPool.GetLendingToken(ctx, userAddress)
 }
 
 // GetTotalDeposited returns the total amount deposited in the lending pool
-func (s *lendingService) GetTotalDeposited(ctx context.Context) (*big.Int, error) {
-	return s.lendingPool.GetTotalLending(ctx)
+func (s *lendingService) GetTotalDeposited(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.LendingPool.GetTotalLending(ctx)
+}
+
+// GetUnderlyingToken returns the address of the token deposited in the lending pool
+func (s *lendingService) GetUnderlyingToken(ctx context.Context, market string) (common.Address, error) {
+	lendingMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return lendingMarket.GetTokenAddress(), nil
 }
 
 // GetCurrentInterestRate returns the current interest rate for lending
-func (s *lendingService) GetCurrentInterestRate(ctx context.Context) (*big.Int, error) {
-	return s.lendingPool.GetAnnualInterestRate(ctx)
+func (s *lendingService) GetCurrentInterestRate(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.LendingPool.GetAnnualInterestRate(ctx)
 }
 
 // GetUserInterestEarned returns the interest earned by a user
 // Note: This is a simplified implementation. In reality, you might need to calculate this based on time and rate
-func (s *lendingService) GetUserInterestEarned(ctx context.Context, userAddress common.Address) (*big.Int, error) {
+func (s *lendingService) GetUserInterestEarned(ctx context.Context, market string, userAddress common.Address) (*big.Int, error) {
 	// Implementation depends on your contract design
 	// For this example, we'll just return 0 as a placeholder
 	return big.NewInt(0), nil
 }
 
-// GetUserTransactionHistory returns a user's lending transaction history
-func (s *lendingService) GetUserTransactionHistory(ctx context.Context, userAddress common.Address, offset, limit int) ([]*models.Transaction, error) {
-	user, err := s.userRepo.FindByAddress(ctx, userAddress.Hex())
-	if err != nil {
+// GetUserTransactionHistory returns a user's lending transaction history matching a filter
+func (s *lendingService) GetUserTransactionHistory(ctx context.Context, market string, userAddress common.Address, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error) {
+	// Lending history only covers deposits into and withdrawals from the pool
+	matches, err := scopeUserTransactions(ctx, s.markets, s.userRepo, market, userAddress, &filter, models.ContractLendingPool.Types())
+	if err != nil || !matches {
 		return nil, err
 	}
 
-	// Get transactions with filter for deposit and withdraw types
-	filter := map[string]any{
-		"user_id": user.ID,
-		"type":    []models.TransactionType{models.TransactionDeposit, models.TransactionWithdraw},
-	}
-
 	return s.transactionRepo.List(ctx, filter, offset, limit)
 }
 
@@ -173,27 +196,15 @@ func (s *lendingService) GetUserTransactionHistory(ctx context.Context, userAddr
 func getTransactOpts(ctx context.Context, userAddress common.Address) (*bind.TransactOpts, error) {
 	// In a real implementation, this would use the user's private key or a signing service
 	// This is just a placeholder
-	return nil, errors.New("transaction signing not implemented")
+	return nil, service.ErrSigningUnavailable
 }
 
-// CountUserTransactions counts the number of transactions for a user with optional filtering
-func (s *lendingService) CountUserTransactions(ctx context.Context, address common.Address, filter map[string]any) (int64, error) {
-	// Find the user by address
-	user, err := s.userRepo.FindByAddress(ctx, address.Hex())
-	if err != nil {
+// CountUserTransactions counts the lending transactions of a user matching a filter
+func (s *lendingService) CountUserTransactions(ctx context.Context, market string, address common.Address, filter models.TransactionFilter) (int64, error) {
+	matches, err := scopeUserTransactions(ctx, s.markets, s.userRepo, market, address, &filter, models.ContractLendingPool.Types())
+	if err != nil || !matches {
 		return 0, err
 	}
 
-	if user == nil {
-		return 0, errors.New("user not found")
-	}
-
-	// Add the user ID to the filter
-	if filter == nil {
-		filter = make(map[string]any)
-	}
-	filter["user_id"] = user.ID
-
-	// Count the transactions
 	return s.transactionRepo.Count(ctx, filter)
 }
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -10,15 +9,13 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
 	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
-	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
 )
 
 type liquidationService struct {
 	transactionRepo   repository.TransactionRepository
 	userRepo          repository.UserRepository
 	positionRepo      repository.PositionRepository
-	collateral        *services.CollateralService
-	borrowing         *services.BorrowingService
+	markets           service.MarketRegistry
 	collateralService service.CollateralService
 }
 
@@ -27,45 +24,38 @@ func NewLiquidationService(
 	transactionRepo repository.TransactionRepository,
 	userRepo repository.UserRepository,
 	positionRepo repository.PositionRepository,
+	markets service.MarketRegistry,
 	collateralService service.CollateralService,
 ) (service.LiquidationService, error) {
-	serviceFactory := services.GetInstance()
-
-	collateral, err := serviceFactory.GetCollateralService()
-	if err != nil {
-		return nil, err
-	}
-
-	borrowing, err := serviceFactory.GetBorrowingService()
-	if err != nil {
-		return nil, err
-	}
-
 	return &liquidationService{
 		transactionRepo:   transactionRepo,
 		userRepo:          userRepo,
 		positionRepo:      positionRepo,
-		collateral:        collateral,
-		borrowing:         borrowing,
+		markets:           markets,
 		collateralService: collateralService,
 	}, nil
 }
 
 // Liquidate allows liquidators to liquidate an under-collateralized position
-func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, borrowerAddress common.Address, repayAmount *big.Int) (string, error) {
+func (s *liquidationService) Liquidate(ctx context.Context, market string, liquidatorAddress, borrowerAddress common.Address, repayAmount *big.Int) (string, error) {
 	// Check if amount is valid
 	if repayAmount.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("liquidation amount must be greater than 0")
+		return "", service.ErrInvalidAmount
+	}
+
+	liquidationMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if the position is eligible for liquidation
-	isAtRisk, err := s.collateralService.IsAtRisk(ctx, borrowerAddress)
+	isAtRisk, err := s.collateralService.IsAtRisk(ctx, market, borrowerAddress)
 	if err != nil {
 		return "", err
 	}
 
 	if !isAtRisk {
-		return "", errors.New("position is not eligible for liquidation")
+		return "", service.ErrNotLiquidatable
 	}
 
 	// Get auth for transaction
@@ -75,9 +65,9 @@ func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, b
 	}
 
 	// Execute the liquidation transaction
-	tx, err := s.collateral.Liquidate(auth, borrowerAddress, repayAmount)
+	tx, err := contracts.Collateral.Liquidate(auth, borrowerAddress, repayAmount)
 	if err != nil {
-		return "", err
+		return "", contractError(err)
 	}
 
 	// Store transaction in database for both liquidator and borrower
@@ -94,11 +84,13 @@ func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, b
 	// Record liquidation transaction for liquidator
 	liquidatorTx := &models.Transaction{
 		UserID:       liquidator.ID,
+		MarketID:     liquidationMarket.ID,
 		Type:         models.TransactionLiquidate,
+		Role:         models.TxRoleLiquidator,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       repayAmount.String(),
-		TokenAddress: s.borrowing.ContractAddress().Hex(),
+		TokenAddress: liquidationMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, liquidatorTx); err != nil {
@@ -108,11 +100,13 @@ func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, b
 	// Record liquidation transaction for borrower
 	borrowerTx := &models.Transaction{
 		UserID:       borrower.ID,
+		MarketID:     liquidationMarket.ID,
 		Type:         models.TransactionLiquidate,
+		Role:         models.TxRoleBorrower,
 		Status:       models.StatusPending,
 		Hash:         tx.Hash().Hex(),
 		Amount:       repayAmount.String(),
-		TokenAddress: s.collateral.ContractAddress().Hex(),
+		TokenAddress: liquidationMarket.TokenAddress,
 	}
 
 	if err := s.transactionRepo.Create(ctx, borrowerTx); err != nil {
@@ -120,7 +114,7 @@ func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, b
 	}
 
 	// Update borrower's position
-	positions, err := s.positionRepo.FindActiveByUserID(ctx, borrower.ID)
+	positions, err := s.positionRepo.FindActiveByUserAndMarket(ctx, borrower.ID, liquidationMarket.ID)
 	if err != nil {
 		return "", err
 	}
@@ -129,13 +123,13 @@ func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, b
 		position := positions[0]
 
 		// Get updated collateral balance
-		collateralBalance, err := s.collateral.GetCollateralBalance(ctx, borrowerAddress)
+		collateralBalance, err := contracts.Collateral.GetCollateralBalance(ctx, borrowerAddress)
 		if err != nil {
 			return "", err
 		}
 
 		// Get updated borrowed amount
-		borrowedAmount, err := s.borrowing.GetBorrowToken(ctx, borrowerAddress)
+		borrowedAmount, err := contracts.Borrowing.GetBorrowToken(ctx, borrowerAddress)
 		if err != nil {
 			return "", err
 		}
@@ -145,7 +139,7 @@ func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, b
 		position.BorrowedAmount = borrowedAmount.String()
 
 		// Recalculate health factor
-		healthFactor, err := s.collateral.GetCollateralRatio(ctx, borrowerAddress)
+		healthFactor, err := contracts.Collateral.GetCollateralRatio(ctx, borrowerAddress)
 		if err == nil && healthFactor != nil {
 			position.HealthFactor = healthFactor.String()
 		}
@@ -164,35 +158,59 @@ func (s *liquidationService) Liquidate(ctx context.Context, liquidatorAddress, b
 }
 
 // GetLiquidatablePositions returns all positions that can be liquidated
-func (s *liquidationService) GetLiquidatablePositions(ctx context.Context) ([]*models.Position, error) {
+func (s *liquidationService) GetLiquidatablePositions(ctx context.Context, market string) ([]*models.Position, error) {
+	liquidationMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get liquidation threshold from contract
-	threshold, err := s.collateral.GetLiquidationThreshold(ctx)
+	threshold, err := contracts.Collateral.GetLiquidationThreshold(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Find positions with health factor below liquidation threshold
-	return s.positionRepo.FindAtRisk(ctx, threshold.String())
+	return s.positionRepo.FindAtRisk(ctx, liquidationMarket.ID, threshold.String())
 }
 
 // GetLiquidationBonus returns the bonus a liquidator receives for liquidating a position
-func (s *liquidationService) GetLiquidationBonus(ctx context.Context) (*big.Int, error) {
-	return s.collateral.GetLiquidationBonus(ctx)
+func (s *liquidationService) GetLiquidationBonus(ctx context.Context, market string) (*big.Int, error) {
+	_, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.Collateral.GetLiquidationBonus(ctx)
 }
 
-// GetLiquidationHistory returns the history of liquidation events
-func (s *liquidationService) GetLiquidationHistory(ctx context.Context, offset, limit int) ([]*models.Transaction, error) {
-	filter := map[string]any{
-		"type": models.TransactionLiquidate,
+// GetLiquidationHistory returns the history of liquidation events matching a filter
+func (s *liquidationService) GetLiquidationHistory(ctx context.Context, market string, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error) {
+	liquidationMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each liquidation is listed once, by its liquidator entry
+	filter.MarketID = &liquidationMarket.ID
+	filter.Roles = []models.TransactionRole{models.TxRoleLiquidator}
+	if !filter.RestrictTypes([]models.TransactionType{models.TransactionLiquidate}) {
+		return nil, nil
 	}
 
 	return s.transactionRepo.List(ctx, filter, offset, limit)
 }
 
-// CountLiquidations counts the total number of liquidation transactions
-func (s *liquidationService) CountLiquidations(ctx context.Context) (int64, error) {
-	filter := map[string]any{
-		"type": models.TransactionLiquidate,
+// CountLiquidations counts the liquidation transactions matching a filter
+func (s *liquidationService) CountLiquidations(ctx context.Context, market string, filter models.TransactionFilter) (int64, error) {
+	liquidationMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return 0, err
+	}
+
+	filter.MarketID = &liquidationMarket.ID
+	filter.Roles = []models.TransactionRole{models.TxRoleLiquidator}
+	if !filter.RestrictTypes([]models.TransactionType{models.TransactionLiquidate}) {
+		return 0, nil
 	}
 
 	// Count the liquidation transactions
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
+	valkey "github.com/Mattouff/Lending-Borrowing/pkg/cache"
+)
+
+const (
+	// liveChannel is the Valkey channel live events are published on
+	liveChannel = "live:events"
+	// liveBatchSize is the number of rows read at once when scanning pending transactions and active positions
+	liveBatchSize = 100
+	// liveRatesExpiration is how long the last published rates of a market are kept; rates left unchanged
+	// for longer are published again
+	liveRatesExpiration = 24 * time.Hour
+)
+
+type liveService struct {
+	cache           *valkey.Client
+	ethClient       *blockchain.EthClient
+	transactionRepo repository.TransactionRepository
+	positionRepo    repository.PositionRepository
+	userRepo        repository.UserRepository
+	markets         service.MarketRegistry
+	marketService   service.MarketService
+
+	bufferSize     int
+	healthInterval time.Duration
+
+	mu          sync.Mutex
+	subscribers map[*liveSubscription]struct{}
+
+	// Only used by the chain poller
+	lastBlock uint64
+}
+
+// NewLiveService creates a new live service publishing through Valkey
+func NewLiveService(
+	cfg *config.Config,
+	cache *valkey.Client,
+	transactionRepo repository.TransactionRepository,
+	positionRepo repository.PositionRepository,
+	userRepo repository.UserRepository,
+	markets service.MarketRegistry,
+	marketService service.MarketService,
+) service.LiveService {
+	return &liveService{
+		cache:           cache,
+		ethClient:       blockchain.GetInstance(),
+		transactionRepo: transactionRepo,
+		positionRepo:    positionRepo,
+		userRepo:        userRepo,
+		markets:         markets,
+		marketService:   marketService,
+		bufferSize:      cfg.Live.BufferSize,
+		healthInterval:  time.Duration(cfg.Live.HealthInterval) * time.Second,
+		subscribers:     make(map[*liveSubscription]struct{}),
+	}
+}
+
+// liveSubscription buffers the events of a subscriber. Events are never sent to a full buffer:
+// the subscription ends instead, so a slow client cannot hold up the others
+type liveSubscription struct {
+	service *liveService
+	userID  uint
+	topics  map[service.LiveTopic]bool
+	events  chan *service.LiveEvent
+	done    chan struct{}
+	once    sync.Once
+	err     error
+}
+
+func (s *liveSubscription) Events() <-chan *service.LiveEvent {
+	return s.events
+}
+
+func (s *liveSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *liveSubscription) Err() error {
+	<-s.done
+	return s.err
+}
+
+func (s *liveSubscription) Close() {
+	s.service.mu.Lock()
+	delete(s.service.subscribers, s)
+	s.service.mu.Unlock()
+
+	s.end(nil)
+}
+
+// end marks the subscription as over, keeping the first reason given
+func (s *liveSubscription) end(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+// Subscribe starts receiving the events of some topics for a user
+func (s *liveService) Subscribe(userID uint, topics []service.LiveTopic) service.LiveSubscription {
+	subscription := &liveSubscription{
+		service: s,
+		userID:  userID,
+		topics:  make(map[service.LiveTopic]bool, len(topics)),
+		events:  make(chan *service.LiveEvent, s.bufferSize),
+		done:    make(chan struct{}),
+	}
+	for _, topic := range topics {
+		subscription.topics[topic] = true
+	}
+
+	s.mu.Lock()
+	s.subscribers[subscription] = struct{}{}
+	s.mu.Unlock()
+
+	return subscription
+}
+
+// Receive delivers the events published by every replica to local subscribers
+func (s *liveService) Receive(ctx context.Context) error {
+	return s.cache.Subscribe(ctx, liveChannel, func(message string) {
+		var event service.LiveEvent
+		if err := json.Unmarshal([]byte(message), &event); err != nil {
+			log.Printf("Failed to decode live event: %v", err)
+			return
+		}
+		s.dispatch(&event)
+	})
+}
+
+// dispatch hands an event to the local subscribers of its topic, ending the subscriptions with a full buffer
+func (s *liveService) dispatch(event *service.LiveEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscription := range s.subscribers {
+		if !subscription.topics[event.Topic] || (event.UserID != 0 && event.UserID != subscription.userID) {
+			continue
+		}
+
+		select {
+		case subscription.events <- event:
+		default:
+			delete(s.subscribers, subscription)
+			subscription.end(service.ErrSlowSubscriber)
+		}
+	}
+}
+
+// publish sends an event to the subscribers of every replica
+func (s *liveService) publish(ctx context.Context, topic service.LiveTopic, userID uint, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	message, err := json.Marshal(&service.LiveEvent{
+		Topic:  topic,
+		UserID: userID,
+		Data:   payload,
+		Time:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Publish(ctx, liveChannel, string(message))
+}
+
+// PollChain publishes the latest block when there is a new one, along with what it changed.
+// Blocks mined between two polls are not published, but the transactions they settled are
+func (s *liveService) PollChain(ctx context.Context) error {
+	blockNumber, err := s.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	if blockNumber <= s.lastBlock {
+		return nil
+	}
+	s.lastBlock = blockNumber
+
+	// Every replica polls, the first to see a block processes it
+	claimed, err := s.cache.ClaimOnce(ctx, fmt.Sprintf("live:block:%d", blockNumber), time.Hour)
+	if err != nil || !claimed {
+		return err
+	}
+
+	client, err := s.ethClient.GetClient()
+	if err != nil {
+		return err
+	}
+
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return err
+	}
+
+	if err := s.publish(ctx, service.TopicBlocks, 0, &service.BlockEvent{
+		Number:    blockNumber,
+		Hash:      header.Hash().Hex(),
+		Timestamp: header.Time,
+	}); err != nil {
+		return err
+	}
+
+	if err := s.settleTransactions(ctx); err != nil {
+		return fmt.Errorf("failed to settle transactions: %w", err)
+	}
+
+	if err := s.publishRates(ctx); err != nil {
+		return fmt.Errorf("failed to publish rates: %w", err)
+	}
+
+	return nil
+}
+
+// settleTransactions records the outcome of the pending transactions that were mined and notifies their users
+func (s *liveService) settleTransactions(ctx context.Context) error {
+	client, err := s.ethClient.GetClient()
+	if err != nil {
+		return err
+	}
+
+	identifiers, err := s.marketIdentifiers(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter := models.TransactionFilter{Statuses: []models.TransactionStatus{models.StatusPending}}
+	receipts := make(map[string]*types.Receipt)
+
+	// Settled transactions leave the filter, so only those still pending are skipped
+	offset := 0
+	for {
+		transactions, err := s.transactionRepo.List(ctx, filter, offset, liveBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, transaction := range transactions {
+			receipt, fetched := receipts[transaction.Hash]
+			if !fetched {
+				receipt, err = client.TransactionReceipt(ctx, common.HexToHash(transaction.Hash))
+				if err != nil && !errors.Is(err, ethereum.NotFound) {
+					return err
+				}
+				receipts[transaction.Hash] = receipt
+			}
+
+			if receipt == nil {
+				offset++
+				continue
+			}
+
+			transaction.BlockNumber = receipt.BlockNumber.Uint64()
+			transaction.GasUsed = receipt.GasUsed
+			transaction.Status = models.StatusCompleted
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				transaction.Status = models.StatusFailed
+				transaction.ErrorMessage = "transaction reverted"
+			}
+
+			if err := s.transactionRepo.Update(ctx, transaction); err != nil {
+				return err
+			}
+
+			if err := s.publish(ctx, service.TopicTransactions, transaction.UserID, &service.TransactionStatusEvent{
+				Hash:        transaction.Hash,
+				Type:        string(transaction.Type),
+				Role:        string(transaction.Role),
+				Market:      identifiers[transaction.MarketID],
+				Status:      string(transaction.Status),
+				BlockNumber: transaction.BlockNumber,
+				GasUsed:     transaction.GasUsed,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(transactions) < liveBatchSize {
+			return nil
+		}
+	}
+}
+
+// publishRates publishes the rates of the markets that changed since they were last published. The last
+// published rates are kept in Valkey, as the replica that claims the next block may not be this one
+func (s *liveService) publishRates(ctx context.Context) error {
+	tokens, err := s.marketService.GetTokensMarketData(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		rates := service.RateEvent{
+			Market:        token.Market,
+			LendingRate:   formatRate(token.LendingRate),
+			BorrowingRate: formatRate(token.BorrowingRate),
+			BlockNumber:   token.BlockNumber,
+		}
+
+		key := "live:rates:" + token.Market
+		state := rates.LendingRate + "/" + rates.BorrowingRate
+
+		last, err := s.cache.LoadJobState(ctx, key)
+		if err != nil {
+			return err
+		}
+		if last == state {
+			continue
+		}
+
+		if err := s.publish(ctx, service.TopicRates, 0, &rates); err != nil {
+			return err
+		}
+		if err := s.cache.StoreJobState(ctx, key, state, liveRatesExpiration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefreshHealthFactors reads the health factor of every active position and publishes those that changed
+func (s *liveService) RefreshHealthFactors(ctx context.Context) error {
+	// Claimed for half an interval, so that one replica refreshes per interval
+	claimed, err := s.cache.ClaimOnce(ctx, "live:health", s.healthInterval/2)
+	if err != nil || !claimed {
+		return err
+	}
+
+	activeMarkets, err := s.markets.ListMarkets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, market := range activeMarkets {
+		if err := s.refreshMarketHealthFactors(ctx, market); err != nil {
+			return fmt.Errorf("market %s: %w", market.Identifier, err)
+		}
+	}
+
+	return nil
+}
+
+// refreshMarketHealthFactors reads the health factor of the active positions of a market
+func (s *liveService) refreshMarketHealthFactors(ctx context.Context, market *models.Market) error {
+	_, contracts, err := marketContracts(ctx, s.markets, market.Identifier)
+	if err != nil {
+		return err
+	}
+
+	filter := models.PositionFilter{
+		MarketID: &market.ID,
+		Statuses: []models.PositionStatus{models.StatusActive},
+		SortBy:   models.SortByCreatedAt,
+	}
+
+	for offset := 0; ; offset += liveBatchSize {
+		positions, err := s.positionRepo.List(ctx, filter, offset, liveBatchSize)
+		if err != nil {
+			return err
+		}
+
+		userIDs := make([]uint, len(positions))
+		for i, position := range positions {
+			userIDs[i] = position.UserID
+		}
+
+		users, err := s.userRepo.FindByIDs(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		addresses := make(map[uint]common.Address, len(users))
+		for _, user := range users {
+			addresses[user.ID] = common.HexToAddress(user.Address)
+		}
+
+		for _, position := range positions {
+			address, found := addresses[position.UserID]
+			if !found {
+				continue
+			}
+
+			healthFactor, err := contracts.Collateral.GetCollateralRatio(ctx, address)
+			if err != nil {
+				return err
+			}
+
+			if healthFactor.String() == position.HealthFactor {
+				continue
+			}
+
+			previous := position.HealthFactor
+			position.HealthFactor = healthFactor.String()
+			if err := s.positionRepo.Update(ctx, position); err != nil {
+				return err
+			}
+
+			if err := s.publish(ctx, service.TopicHealth, position.UserID, &service.HealthEvent{
+				PositionID:   position.ID,
+				Market:       market.Identifier,
+				HealthFactor: position.HealthFactor,
+				Previous:     previous,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(positions) < liveBatchSize {
+			return nil
+		}
+	}
+}
+
+// marketIdentifiers returns the identifier of every active market by ID
+func (s *liveService) marketIdentifiers(ctx context.Context) (map[uint]string, error) {
+	activeMarkets, err := s.markets.ListMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	identifiers := make(map[uint]string, len(activeMarkets))
+	for _, market := range activeMarkets {
+		identifiers[market.ID] = market.Identifier
+	}
+	return identifiers, nil
+}
+
+// formatRate formats a rate read from the contracts, which may be missing
+func formatRate(rate *big.Int) string {
+	if rate == nil {
+		return "0"
+	}
+	return rate.String()
+}
+
+// StartLiveFeed receives the live events of every replica and periodically polls the chain and refreshes
+// health factors, until the context is cancelled. A zero interval disables the matching job
+func StartLiveFeed(ctx context.Context, liveService service.LiveService, pollInterval, healthInterval time.Duration) {
+	go func() {
+		for {
+			err := liveService.Receive(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Printf("Live event subscription lost, retrying: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+
+	startLiveJob(ctx, pollInterval, "poll the chain for live events", liveService.PollChain)
+	startLiveJob(ctx, healthInterval, "refresh health factors", liveService.RefreshHealthFactors)
+}
+
+// startLiveJob runs a live feed job on every tick of an interval
+func startLiveJob(ctx context.Context, interval time.Duration, name string, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					log.Printf("Failed to %s: %v", name, err)
+				}
+			}
+		}
+	}()
+}
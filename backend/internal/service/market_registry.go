@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
+)
+
+// marketCacheTTL is how long a market is served from memory before the markets table is read again,
+// which bounds how long a deactivated market keeps resolving
+const marketCacheTTL = 30 * time.Second
+
+type marketRegistry struct {
+	marketRepo    repository.MarketRepository
+	defaultMarket string
+
+	mu      sync.RWMutex
+	markets map[string]cachedMarket
+}
+
+// cachedMarket is a market along with when it was read from the markets table
+type cachedMarket struct {
+	market   *models.Market
+	loadedAt time.Time
+}
+
+// NewMarketRegistry creates a new market registry. Markets declared in the configuration
+// are registered in the markets table, which remains the source of truth for lookups
+func NewMarketRegistry(ctx context.Context, cfg *config.Config, marketRepo repository.MarketRepository) (service.MarketRegistry, error) {
+	registry := &marketRegistry{
+		marketRepo:    marketRepo,
+		defaultMarket: cfg.Blockchain.DefaultMarket,
+		markets:       make(map[string]cachedMarket),
+	}
+
+	for identifier, marketConfig := range cfg.Blockchain.Markets {
+		if err := registry.registerMarket(ctx, identifier, marketConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// GetMarket returns an active market by identifier; an empty identifier selects the default market
+func (r *marketRegistry) GetMarket(ctx context.Context, identifier string) (*models.Market, error) {
+	if identifier == "" {
+		identifier = r.defaultMarket
+	}
+
+	r.mu.RLock()
+	cached, exists := r.markets[identifier]
+	r.mu.RUnlock()
+	if exists && cached.market.Active && time.Since(cached.loadedAt) < marketCacheTTL {
+		return cached.market, nil
+	}
+
+	// Markets can be added to, or deactivated in, the table while the server is running
+	market, err := r.marketRepo.FindByIdentifier(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if market == nil || !market.Active {
+		r.mu.Lock()
+		delete(r.markets, identifier)
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", service.ErrMarketNotFound, identifier)
+	}
+
+	r.cache(market)
+
+	return market, nil
+}
+
+// ListMarkets returns every active market
+func (r *marketRegistry) ListMarkets(ctx context.Context) ([]*models.Market, error) {
+	markets, err := r.marketRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh the cache so deactivated markets stop resolving
+	now := time.Now()
+	cache := make(map[string]cachedMarket, len(markets))
+	for _, market := range markets {
+		cache[market.Identifier] = cachedMarket{market: market, loadedAt: now}
+	}
+
+	r.mu.Lock()
+	r.markets = cache
+	r.mu.Unlock()
+
+	return markets, nil
+}
+
+// registerMarket creates or updates a configured market, reading its underlying token from the contracts
+func (r *marketRegistry) registerMarket(ctx context.Context, identifier string, marketConfig config.MarketConfig) error {
+	lendingPool, err := services.NewLendingPoolServiceAt(marketConfig.LendingPool)
+	if err != nil {
+		return err
+	}
+
+	borrowing, err := services.NewBorrowingServiceAt(marketConfig.Borrowing)
+	if err != nil {
+		return err
+	}
+
+	collateral, err := services.NewCollateralServiceAt(marketConfig.Collateral)
+	if err != nil {
+		return err
+	}
+
+	underlying, err := lendingPool.GetUnderlying(ctx)
+	if err != nil {
+		return fmt.Errorf("market %s: failed to read lending pool underlying: %w", identifier, err)
+	}
+
+	borrowedToken, err := borrowing.GetToken(ctx)
+	if err != nil {
+		return fmt.Errorf("market %s: failed to read borrowing token: %w", identifier, err)
+	}
+
+	collateralToken, err := collateral.GetToken(ctx)
+	if err != nil {
+		return fmt.Errorf("market %s: failed to read collateral token: %w", identifier, err)
+	}
+
+	// A market lends, borrows and takes collateral in a single token
+	if borrowedToken != underlying || collateralToken != underlying {
+		return fmt.Errorf(
+			"market %s: contracts use different tokens (lending pool %s, borrowing %s, collateral %s)",
+			identifier, underlying.Hex(), borrowedToken.Hex(), collateralToken.Hex(),
+		)
+	}
+
+	token, err := services.NewTokenServiceAt(underlying)
+	if err != nil {
+		return err
+	}
+
+	name, err := token.Symbol(ctx)
+	if err != nil {
+		return fmt.Errorf("market %s: failed to read token symbol: %w", identifier, err)
+	}
+
+	market, err := r.marketRepo.FindByIdentifier(ctx, identifier)
+	if err != nil {
+		return err
+	}
+
+	if market == nil {
+		market = &models.Market{Identifier: identifier}
+	}
+
+	market.Name = name
+	market.TokenAddress = underlying.Hex()
+	market.LendingPoolAddress = marketConfig.LendingPool.Hex()
+	market.BorrowingAddress = marketConfig.Borrowing.Hex()
+	market.CollateralAddress = marketConfig.Collateral.Hex()
+	market.Active = true
+
+	if market.ID == 0 {
+		err = r.marketRepo.Create(ctx, market)
+	} else {
+		err = r.marketRepo.Update(ctx, market)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.cache(market)
+	return nil
+}
+
+// cache keeps a market just read from the markets table
+func (r *marketRegistry) cache(market *models.Market) {
+	r.mu.Lock()
+	r.markets[market.Identifier] = cachedMarket{market: market, loadedAt: time.Now()}
+	r.mu.Unlock()
+}
+
+// marketContracts resolves a market and returns the contract services bound to it
+func marketContracts(ctx context.Context, markets service.MarketRegistry, identifier string) (*models.Market, *services.MarketContracts, error) {
+	market, err := markets.GetMarket(ctx, identifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contracts, err := services.GetInstance().GetMarketContracts(
+		market.GetTokenAddress(),
+		market.GetLendingPoolAddress(),
+		market.GetBorrowingAddress(),
+		market.GetCollateralAddress(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return market, contracts, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// marketTable holds markets in memory, as the markets table would
+type marketTable struct {
+	markets map[string]models.Market
+	reads   int
+}
+
+func (r *marketTable) Create(ctx context.Context, market *models.Market) error {
+	r.markets[market.Identifier] = *market
+	return nil
+}
+
+func (r *marketTable) FindByID(ctx context.Context, id uint) (*models.Market, error) {
+	for _, market := range r.markets {
+		if market.ID == id {
+			return &market, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *marketTable) FindByIdentifier(ctx context.Context, identifier string) (*models.Market, error) {
+	r.reads++
+	market, exists := r.markets[identifier]
+	if !exists {
+		return nil, nil
+	}
+	return &market, nil
+}
+
+func (r *marketTable) Update(ctx context.Context, market *models.Market) error {
+	r.markets[market.Identifier] = *market
+	return nil
+}
+
+func (r *marketTable) ListActive(ctx context.Context) ([]*models.Market, error) {
+	var markets []*models.Market
+	for _, market := range r.markets {
+		if market.Active {
+			markets = append(markets, &market)
+		}
+	}
+	return markets, nil
+}
+
+func TestMarketRegistryGetMarket(t *testing.T) {
+	table := &marketTable{markets: map[string]models.Market{
+		"lbt":    {ID: 1, Identifier: "lbt", Active: true},
+		"legacy": {ID: 2, Identifier: "legacy", Active: false},
+	}}
+	registry := &marketRegistry{marketRepo: table, defaultMarket: "lbt", markets: make(map[string]cachedMarket)}
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		identifier string
+		wantID     uint
+		wantErr    error
+	}{
+		{name: "default market", identifier: "", wantID: 1},
+		{name: "by identifier", identifier: "lbt", wantID: 1},
+		{name: "inactive market", identifier: "legacy", wantErr: service.ErrMarketNotFound},
+		{name: "unknown market", identifier: "nope", wantErr: service.ErrMarketNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			market, err := registry.GetMarket(ctx, tt.identifier)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if market.ID != tt.wantID {
+				t.Errorf("got market %d, want %d", market.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestMarketRegistryDeactivatedMarketStopsResolving(t *testing.T) {
+	table := &marketTable{markets: map[string]models.Market{
+		"lbt": {ID: 1, Identifier: "lbt", Active: true},
+	}}
+	registry := &marketRegistry{marketRepo: table, markets: make(map[string]cachedMarket)}
+	ctx := context.Background()
+
+	if _, err := registry.GetMarket(ctx, "lbt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := registry.GetMarket(ctx, "lbt"); err != nil || table.reads != 1 {
+		t.Fatalf("got error %v after %d reads, want the market served from the cache", err, table.reads)
+	}
+
+	// An admin deactivates the market in the table
+	table.markets["lbt"] = models.Market{ID: 1, Identifier: "lbt", Active: false}
+
+	// Once the cached entry is older than the TTL, the table is read again
+	registry.markets["lbt"] = cachedMarket{market: registry.markets["lbt"].market, loadedAt: time.Now().Add(-marketCacheTTL)}
+	if _, err := registry.GetMarket(ctx, "lbt"); !errors.Is(err, service.ErrMarketNotFound) {
+		t.Fatalf("got error %v, want %v once the cache expired", err, service.ErrMarketNotFound)
+	}
+	if _, cached := registry.markets["lbt"]; cached {
+		t.Error("deactivated market left in the cache")
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
+)
+
+type marketService struct {
+	ethClient *blockchain.EthClient
+	markets   service.MarketRegistry
+
+	// Market data only changes when a new block is mined, so the last
+	// result is kept until the chain head moves
+	mu          sync.Mutex
+	cachedBlock uint64
+	cached      []*service.TokenMarketData
+}
+
+// NewMarketService creates a new market data service
+func NewMarketService(markets service.MarketRegistry) (service.MarketService, error) {
+	return &marketService{
+		ethClient: blockchain.GetInstance(),
+		markets:   markets,
+	}, nil
+}
+
+// GetTokensMarketData returns market data for the token of every active market, cached per block
+func (s *marketService) GetTokensMarketData(ctx context.Context) ([]*service.TokenMarketData, error) {
+	blockNumber, err := s.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.cached != nil && s.cachedBlock == blockNumber {
+		cached := s.cached
+		s.mu.Unlock()
+		return cached, nil
+	}
+	s.mu.Unlock()
+
+	markets, err := s.markets.ListMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*service.TokenMarketData, 0, len(markets))
+	for _, market := range markets {
+		data, err := s.readTokenMarketData(ctx, market.Identifier, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, data)
+	}
+
+	s.mu.Lock()
+	if blockNumber >= s.cachedBlock {
+		s.cachedBlock = blockNumber
+		s.cached = tokens
+	}
+	s.mu.Unlock()
+
+	return tokens, nil
+}
+
+// readTokenMarketData reads the market figures of a market's token from its contracts, every one of them
+// at the block the result is cached for
+func (s *marketService) readTokenMarketData(ctx context.Context, market string, blockNumber uint64) (*service.TokenMarketData, error) {
+	tokenMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = services.AtBlock(ctx, blockNumber)
+
+	name, err := contracts.Token.Name(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := contracts.Token.Symbol(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := contracts.Token.Decimals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totalSupply, err := contracts.Token.TotalSupply(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totalDeposited, err := contracts.LendingPool.GetTotalLending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lendingRate, err := contracts.LendingPool.GetAnnualInterestRate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totalBorrowed, err := contracts.Borrowing.GetTotalBorrowed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	borrowingRate, err := contracts.Borrowing.GetCurrentRate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Liquidity is whatever the lending pool and borrowing contracts actually hold
+	poolBalance, err := contracts.Token.BalanceOf(ctx, contracts.LendingPool.ContractAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	borrowingBalance, err := contracts.Token.BalanceOf(ctx, contracts.Borrowing.ContractAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	collateralFactor, err := s.getCollateralFactor(ctx, contracts.Collateral)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.TokenMarketData{
+		Market:             tokenMarket.Identifier,
+		Address:            contracts.Token.ContractAddress(),
+		Name:               name,
+		Symbol:             symbol,
+		Decimals:           decimals,
+		TotalSupply:        totalSupply,
+		TotalDeposited:     totalDeposited,
+		TotalBorrowed:      totalBorrowed,
+		AvailableLiquidity: new(big.Int).Add(poolBalance, borrowingBalance),
+		LendingRate:        lendingRate,
+		BorrowingRate:      borrowingRate,
+		CollateralFactor:   collateralFactor,
+		BlockNumber:        blockNumber,
+	}, nil
+}
+
+// getCollateralFactor returns the share of collateral that can be borrowed in 1e18 precision.
+// The Collateral contract caps borrowing both by MIN_COLLATERAL_RATIO and by
+// MAX_BORROWING_PERCENTAGE (both in percent), so the effective factor is the lower of the two
+func (s *marketService) getCollateralFactor(ctx context.Context, collateral *services.CollateralService) (*big.Int, error) {
+	minRatio, err := collateral.GetMinCollateralRatio(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPercentage, err := collateral.GetMaxBorrowingPercentage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// percent * 1e16 = fraction in 1e18 precision
+	percentScale := new(big.Int).Exp(big.NewInt(10), big.NewInt(16), nil)
+	byMaxPercentage := new(big.Int).Mul(maxPercentage, percentScale)
+
+	if minRatio.Sign() == 0 {
+		return byMaxPercentage, nil
+	}
+
+	// 100 / minRatio as a fraction in 1e18 precision
+	byMinRatio := new(big.Int).Mul(big.NewInt(100), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	byMinRatio.Div(byMinRatio, minRatio)
+
+	if byMinRatio.Cmp(byMaxPercentage) < 0 {
+		return byMinRatio, nil
+	}
+	return byMaxPercentage, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// positionOpeningSlack covers the transaction that opens a position, which is recorded just before it
+const positionOpeningSlack = 5 * time.Second
+
+// positionTransactionTypes are the transactions that change a position
+var positionTransactionTypes = []models.TransactionType{
+	models.TransactionBorrow,
+	models.TransactionRepay,
+	models.TransactionLiquidate,
+	models.TransactionCollateralDeposit,
+	models.TransactionCollateralWithdraw,
+}
+
+type positionService struct {
+	positionRepo    repository.PositionRepository
+	transactionRepo repository.TransactionRepository
+}
+
+// NewPositionService creates a new position service
+func NewPositionService(positionRepo repository.PositionRepository, transactionRepo repository.TransactionRepository) service.PositionService {
+	return &positionService{
+		positionRepo:    positionRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// ListPositions retrieves the positions matching a filter, in its order, with pagination
+func (s *positionService) ListPositions(ctx context.Context, filter models.PositionFilter, offset, limit int) ([]*models.Position, error) {
+	return s.positionRepo.List(ctx, filter, offset, limit)
+}
+
+// ListPositionsByCursor retrieves up to limit positions matching a filter, by opening date, from a cursor
+func (s *positionService) ListPositionsByCursor(ctx context.Context, filter models.PositionFilter, cursor *models.Cursor, limit int) ([]*models.Position, error) {
+	return s.positionRepo.ListByCursor(ctx, filter, cursor, limit)
+}
+
+// CountPositions returns the number of positions matching a filter
+func (s *positionService) CountPositions(ctx context.Context, filter models.PositionFilter) (int64, error) {
+	return s.positionRepo.Count(ctx, filter)
+}
+
+// GetPosition retrieves a position along with the transactions that opened, changed or closed it.
+// A non-nil userID restricts the lookup to the positions of that user
+func (s *positionService) GetPosition(ctx context.Context, id uint, userID *uint) (*models.Position, []*models.Transaction, error) {
+	position, err := s.positionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if position == nil || (userID != nil && position.UserID != *userID) {
+		return nil, nil, service.ErrPositionNotFound
+	}
+
+	// Transactions are not linked to positions: a user has at most one active position per market,
+	// so those made in its market while it was open belong to it
+	var closedAt *time.Time
+	if position.Status != models.StatusActive {
+		closedAt = &position.UpdatedAt
+	}
+
+	transactions, err := s.transactionRepo.FindByUserAndMarket(ctx, position.UserID, position.MarketID, positionTransactionTypes, position.CreatedAt.Add(-positionOpeningSlack), closedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return position, transactions, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/contracts/generated"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/oracle"
+)
+
+type priceService struct {
+	sampleRepo repository.PriceSampleRepository
+	ethClient  *blockchain.EthClient
+	markets    service.MarketRegistry
+	feedTokens []common.Address // Tokens with a dedicated aggregator, sampled alongside market tokens
+
+	// spot combines the feeds that report current prices; samples are taken
+	// from it so the TWAP never feeds on its own output
+	spot   *oracle.MedianOracle
+	oracle *oracle.MedianOracle
+
+	twapWindow time.Duration
+	mu         sync.RWMutex
+	decimals   map[common.Address]uint8
+}
+
+// NewPriceService creates a new price service from the oracle configuration
+func NewPriceService(
+	cfg *config.Config,
+	sampleRepo repository.PriceSampleRepository,
+	markets service.MarketRegistry,
+) (service.PriceService, error) {
+	ethClient := blockchain.GetInstance()
+	client, err := ethClient.GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge := time.Duration(cfg.Oracle.MaxPriceAge) * time.Second
+	twapWindow := time.Duration(cfg.Oracle.TWAPWindow) * time.Minute
+
+	var spotFeeds []oracle.PriceFeed
+	if cfg.Oracle.StaticPricesFile != "" {
+		staticFeed, err := oracle.NewStaticFeed(cfg.Oracle.StaticPricesFile)
+		if err != nil {
+			return nil, err
+		}
+		spotFeeds = append(spotFeeds, staticFeed)
+	}
+
+	if len(cfg.Oracle.Aggregators) > 0 {
+		spotFeeds = append(spotFeeds, oracle.NewAggregatorFeed(client, cfg.Oracle.Aggregators))
+	}
+
+	feeds := append([]oracle.PriceFeed{}, spotFeeds...)
+	if twapWindow > 0 {
+		feeds = append(feeds, oracle.NewTWAPFeed(sampleRepo, twapWindow))
+	}
+
+	feedTokens := make([]common.Address, 0, len(cfg.Oracle.Aggregators))
+	for address := range cfg.Oracle.Aggregators {
+		feedTokens = append(feedTokens, address)
+	}
+
+	return &priceService{
+		sampleRepo: sampleRepo,
+		ethClient:  ethClient,
+		markets:    markets,
+		feedTokens: feedTokens,
+		spot:       oracle.NewMedianOracle(maxAge, spotFeeds...),
+		oracle:     oracle.NewMedianOracle(maxAge, feeds...),
+		twapWindow: twapWindow,
+		decimals:   make(map[common.Address]uint8),
+	}, nil
+}
+
+// GetTokenPrice returns the current USD price of a token
+func (s *priceService) GetTokenPrice(ctx context.Context, token common.Address) (*service.TokenPrice, error) {
+	price, err := s.oracle.LatestPrice(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.TokenPrice{
+		Token:     price.Token,
+		Price:     price.Value,
+		UpdatedAt: price.UpdatedAt,
+		Source:    price.Source,
+	}, nil
+}
+
+// ValueInUSD returns the USD value of a token amount, in 1e18 precision
+func (s *priceService) ValueInUSD(ctx context.Context, token common.Address, amount *big.Int) (*big.Int, error) {
+	price, err := s.oracle.LatestPrice(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := s.getDecimals(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return oracle.ValueInUSD(amount, decimals, price), nil
+}
+
+// RecordSamples stores the current price of every known token for TWAP computation
+func (s *priceService) RecordSamples(ctx context.Context) error {
+	now := time.Now()
+
+	tokens, err := s.sampledTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		price, err := s.spot.LatestPrice(ctx, token)
+		if err != nil {
+			log.Printf("Failed to sample price of %s: %v", token.Hex(), err)
+			continue
+		}
+
+		sample := &models.PriceSample{
+			TokenAddress: token.Hex(),
+			Price:        price.Value.String(),
+			Source:       price.Source,
+			SampledAt:    now,
+		}
+		if err := s.sampleRepo.Create(ctx, sample); err != nil {
+			return err
+		}
+	}
+
+	// Samples older than the window are only needed as the opening price of the window,
+	// so keep a second window's worth and drop the rest
+	if s.twapWindow > 0 {
+		if _, err := s.sampleRepo.DeleteOlderThan(ctx, now.Add(-2*s.twapWindow)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sampledTokens returns the underlying token of every active market and every token with an aggregator
+func (s *priceService) sampledTokens(ctx context.Context) ([]common.Address, error) {
+	markets, err := s.markets.ListMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[common.Address]bool)
+	var tokens []common.Address
+	for _, market := range markets {
+		token := market.GetTokenAddress()
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	for _, token := range s.feedTokens {
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
+
+// getDecimals returns the decimals of an ERC20 token, cached after the first read
+func (s *priceService) getDecimals(ctx context.Context, token common.Address) (uint8, error) {
+	s.mu.RLock()
+	decimals, exists := s.decimals[token]
+	s.mu.RUnlock()
+	if exists {
+		return decimals, nil
+	}
+
+	client, err := s.ethClient.GetClient()
+	if err != nil {
+		return 0, err
+	}
+
+	caller, err := generated.NewTokenCaller(token, client)
+	if err != nil {
+		return 0, err
+	}
+
+	decimals, err = caller.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.decimals[token] = decimals
+	s.mu.Unlock()
+
+	return decimals, nil
+}
+
+// StartPriceSampler periodically records price samples until the context is cancelled
+func StartPriceSampler(ctx context.Context, priceService service.PriceService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := priceService.RecordSamples(ctx); err != nil {
+					log.Printf("Failed to record price samples: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/oracle"
+)
+
+var (
+	marketToken = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	feedToken   = common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+)
+
+// priceFeed reports fixed prices per token
+type priceFeed struct {
+	name   string
+	prices map[common.Address]string
+	age    time.Duration
+}
+
+func (f *priceFeed) Name() string {
+	return f.name
+}
+
+func (f *priceFeed) LatestPrice(ctx context.Context, token common.Address) (*oracle.Price, error) {
+	price, exists := f.prices[token]
+	if !exists {
+		return nil, oracle.ErrPriceNotFound
+	}
+	value, err := oracle.ParseDecimal(price)
+	if err != nil {
+		return nil, err
+	}
+	return &oracle.Price{Token: token, Value: value, UpdatedAt: time.Now().Add(-f.age), Source: f.name}, nil
+}
+
+// marketList serves a fixed list of markets
+type marketList []*models.Market
+
+func (l marketList) GetMarket(ctx context.Context, identifier string) (*models.Market, error) {
+	for _, market := range l {
+		if market.Identifier == identifier {
+			return market, nil
+		}
+	}
+	return nil, errors.New("market not found")
+}
+
+func (l marketList) ListMarkets(ctx context.Context) ([]*models.Market, error) {
+	return l, nil
+}
+
+// sampleStore keeps price samples in memory
+type sampleStore struct {
+	samples []*models.PriceSample
+	deleted time.Time // Cutoff of the last DeleteOlderThan
+}
+
+func (r *sampleStore) Create(ctx context.Context, sample *models.PriceSample) error {
+	r.samples = append(r.samples, sample)
+	return nil
+}
+
+func (r *sampleStore) FindInRange(ctx context.Context, tokenAddress string, from, to time.Time) ([]*models.PriceSample, error) {
+	return nil, nil
+}
+
+func (r *sampleStore) FindLatestBefore(ctx context.Context, tokenAddress string, before time.Time) (*models.PriceSample, error) {
+	return nil, nil
+}
+
+func (r *sampleStore) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	r.deleted = before
+	return 0, nil
+}
+
+func decimal(t *testing.T, value string) *big.Int {
+	t.Helper()
+	parsed, err := oracle.ParseDecimal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestPriceServiceGetTokenPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		feeds   []oracle.PriceFeed
+		want    string
+		source  string
+		wantErr error
+	}{
+		{
+			name: "median of the feeds",
+			feeds: []oracle.PriceFeed{
+				&priceFeed{name: "static", prices: map[common.Address]string{marketToken: "1"}},
+				&priceFeed{name: "chainlink", prices: map[common.Address]string{marketToken: "1.02"}},
+				&priceFeed{name: "twap", prices: map[common.Address]string{marketToken: "0.99"}},
+			},
+			want:   "1",
+			source: "static",
+		},
+		{
+			name: "stale feed ignored",
+			feeds: []oracle.PriceFeed{
+				&priceFeed{name: "static", prices: map[common.Address]string{marketToken: "1"}},
+				&priceFeed{name: "chainlink", prices: map[common.Address]string{marketToken: "9"}, age: time.Hour},
+			},
+			want:   "1",
+			source: "static",
+		},
+		{
+			name: "every feed stale",
+			feeds: []oracle.PriceFeed{
+				&priceFeed{name: "chainlink", prices: map[common.Address]string{marketToken: "1"}, age: time.Hour},
+			},
+			wantErr: oracle.ErrStalePrice,
+		},
+		{
+			name: "unknown token",
+			feeds: []oracle.PriceFeed{
+				&priceFeed{name: "static", prices: map[common.Address]string{feedToken: "1"}},
+			},
+			wantErr: oracle.ErrPriceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &priceService{oracle: oracle.NewMedianOracle(time.Minute, tt.feeds...)}
+
+			price, err := s.GetTokenPrice(context.Background(), marketToken)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if price.Price.Cmp(decimal(t, tt.want)) != 0 || price.Source != tt.source {
+				t.Errorf("got %s from %s, want %s from %s", price.Price, price.Source, decimal(t, tt.want), tt.source)
+			}
+		})
+	}
+}
+
+func TestPriceServiceValueInUSD(t *testing.T) {
+	s := &priceService{
+		oracle: oracle.NewMedianOracle(time.Minute,
+			&priceFeed{name: "static", prices: map[common.Address]string{marketToken: "2000"}}),
+		decimals: map[common.Address]uint8{marketToken: 6},
+	}
+
+	value, err := s.ValueInUSD(context.Background(), marketToken, big.NewInt(1_500_000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value.Cmp(decimal(t, "3000")) != 0 {
+		t.Errorf("got %s, want 3000 in 1e18 precision", value)
+	}
+}
+
+func TestPriceServiceRecordSamples(t *testing.T) {
+	markets := marketList{
+		{Identifier: "lbt", TokenAddress: marketToken.Hex()},
+		{Identifier: "lbt-2", TokenAddress: marketToken.Hex()}, // Shares its token with the first market
+	}
+	samples := &sampleStore{}
+
+	// Only the spot feeds are sampled: the TWAP must never feed on its own output
+	spot := &priceFeed{name: "static", prices: map[common.Address]string{marketToken: "1", feedToken: "2000"}}
+	twap := &priceFeed{name: "twap", prices: map[common.Address]string{marketToken: "5", feedToken: "5"}}
+
+	s := &priceService{
+		sampleRepo: samples,
+		markets:    markets,
+		feedTokens: []common.Address{feedToken, marketToken},
+		spot:       oracle.NewMedianOracle(time.Minute, spot),
+		oracle:     oracle.NewMedianOracle(time.Minute, spot, twap),
+		twapWindow: 30 * time.Minute,
+	}
+
+	before := time.Now()
+	if err := s.RecordSamples(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{marketToken.Hex(): "1", feedToken.Hex(): "2000"}
+	if len(samples.samples) != len(want) {
+		t.Fatalf("got %d samples, want one per token (%d)", len(samples.samples), len(want))
+	}
+	for _, sample := range samples.samples {
+		if sample.Price != decimal(t, want[sample.TokenAddress]).String() || sample.Source != "static" {
+			t.Errorf("got sample %s from %s for %s, want the spot price %s", sample.Price, sample.Source, sample.TokenAddress, want[sample.TokenAddress])
+		}
+	}
+
+	// Two windows of samples are kept
+	if cutoff := before.Add(-2 * s.twapWindow); samples.deleted.Before(cutoff) || samples.deleted.After(time.Now().Add(-2*s.twapWindow)) {
+		t.Errorf("got samples deleted before %s, want two windows ago", samples.deleted)
+	}
+}
+
+func TestPriceServiceRecordSamplesSkipsMissingPrices(t *testing.T) {
+	samples := &sampleStore{}
+	s := &priceService{
+		sampleRepo: samples,
+		markets:    marketList{{Identifier: "lbt", TokenAddress: marketToken.Hex()}},
+		feedTokens: []common.Address{feedToken},
+		spot:       oracle.NewMedianOracle(time.Minute, &priceFeed{name: "static", prices: map[common.Address]string{feedToken: "2000"}}),
+	}
+
+	if err := s.RecordSamples(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples.samples) != 1 || samples.samples[0].TokenAddress != feedToken.Hex() {
+		t.Errorf("got samples %+v, want only the token with a price", samples.samples)
+	}
+	if !samples.deleted.IsZero() {
+		t.Error("samples were deleted without a TWAP window")
+	}
+}
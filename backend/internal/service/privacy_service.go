@@ -0,0 +1,450 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+const (
+	// exportBatchSize bounds how many exports are assembled per run of the worker
+	exportBatchSize = 10
+	// exportStaleAfter is how long an export can stay processing before another run picks it up again,
+	// e.g. after a restart
+	exportStaleAfter = 10 * time.Minute
+)
+
+type privacyService struct {
+	cfg             *config.Config
+	userRepo        repository.UserRepository
+	userAddressRepo repository.UserAddressRepository
+	apiKeyRepo      repository.APIKeyRepository
+	transactionRepo repository.TransactionRepository
+	positionRepo    repository.PositionRepository
+	dataExportRepo  repository.DataExportRepository
+	deletionRepo    repository.DeletionRequestRepository
+	authService     service.AuthService
+	auditService    service.AuditService
+}
+
+// NewPrivacyService creates a new personal data export and purge service
+func NewPrivacyService(
+	cfg *config.Config,
+	userRepo repository.UserRepository,
+	userAddressRepo repository.UserAddressRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	transactionRepo repository.TransactionRepository,
+	positionRepo repository.PositionRepository,
+	dataExportRepo repository.DataExportRepository,
+	deletionRepo repository.DeletionRequestRepository,
+	authService service.AuthService,
+	auditService service.AuditService,
+) service.PrivacyService {
+	return &privacyService{
+		cfg:             cfg,
+		userRepo:        userRepo,
+		userAddressRepo: userAddressRepo,
+		apiKeyRepo:      apiKeyRepo,
+		transactionRepo: transactionRepo,
+		positionRepo:    positionRepo,
+		dataExportRepo:  dataExportRepo,
+		deletionRepo:    deletionRepo,
+		authService:     authService,
+		auditService:    auditService,
+	}
+}
+
+// RequestExport queues an export of the personal data of a user, assembled by ProcessExports
+func (s *privacyService) RequestExport(ctx context.Context, userID uint, format models.DataExportFormat) (*models.DataExport, error) {
+	if !format.IsValid() {
+		return nil, service.ErrInvalidExportFormat
+	}
+
+	export := &models.DataExport{
+		UserID: userID,
+		Format: format,
+		Status: models.ExportPending,
+	}
+	if err := s.dataExportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditUserExport, userTarget(userID), nil, map[string]any{"exportId": export.ID, "format": format})
+	return export, nil
+}
+
+// ListExports returns the data exports of a user, newest first
+func (s *privacyService) ListExports(ctx context.Context, userID uint) ([]*models.DataExport, error) {
+	return s.dataExportRepo.ListByUser(ctx, userID)
+}
+
+// GetExport returns a data export of a user, including its archive once ready
+func (s *privacyService) GetExport(ctx context.Context, userID, exportID uint) (*models.DataExport, error) {
+	export, err := s.dataExportRepo.FindByID(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export == nil || export.UserID != userID {
+		return nil, service.ErrExportNotFound
+	}
+
+	return export, nil
+}
+
+// ProcessExports assembles the pending data exports and deletes expired archives
+func (s *privacyService) ProcessExports(ctx context.Context) error {
+	now := time.Now()
+	if _, err := s.dataExportRepo.DeleteExpired(ctx, now); err != nil {
+		return err
+	}
+
+	exports, err := s.dataExportRepo.ClaimPending(ctx, now.Add(-exportStaleAfter), exportBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		archive, err := s.buildExport(ctx, export)
+
+		completedAt := time.Now()
+		export.CompletedAt = &completedAt
+		if err != nil {
+			log.Printf("Failed to assemble data export %d: %v", export.ID, err)
+			export.Status = models.ExportFailed
+			export.Error = err.Error()
+		} else {
+			export.Status = models.ExportReady
+			export.Archive = archive
+			export.Size = int64(len(archive))
+			if s.cfg.Privacy.ExportRetention > 0 {
+				expiresAt := completedAt.AddDate(0, 0, s.cfg.Privacy.ExportRetention)
+				export.ExpiresAt = &expiresAt
+			}
+		}
+
+		if err := s.dataExportRepo.Update(ctx, export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportedSession is a session as written to data exports
+type exportedSession struct {
+	ID        string    `json:"id"`
+	Device    string    `json:"device"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// exportSection is a part of a data export, a file of its own in ZIP archives
+type exportSection struct {
+	name string
+	data any
+}
+
+// retainedRecord describes personal data a purge keeps, and why
+type retainedRecord struct {
+	Section string   `json:"section"`
+	Fields  []string `json:"fields"`
+	Reason  string   `json:"reason"`
+}
+
+// purgeRetention lists what a purge keeps, so that users know before filing a deletion request
+var purgeRetention = []retainedRecord{
+	{
+		Section: "transactions",
+		Fields:  []string{"hash", "blockNumber", "amount", "tokenAddress"},
+		Reason:  "Kept for accounting; the transactions are public on-chain",
+	},
+	{
+		Section: "positions",
+		Fields:  []string{"collateralAmount", "collateralToken", "borrowedAmount", "borrowedToken"},
+		Reason:  "Kept for accounting, as the balances the contracts hold",
+	},
+	{
+		Section: "auditEntries",
+		Fields:  []string{"actorAddress", "ip", "changes"},
+		Reason: "The audit log is evidence of admin and security actions: its entries are hash-chained and " +
+			"append-only, so erasing a field would break the chain for every later entry",
+	},
+}
+
+// buildExport assembles the personal data of the user of an export in its format
+func (s *privacyService) buildExport(ctx context.Context, export *models.DataExport) ([]byte, error) {
+	user, err := s.userRepo.FindByID(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user %d not found", export.UserID)
+	}
+
+	addresses, err := s.userAddressRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	liveSessions, err := s.authService.ListSessions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]exportedSession, 0, len(liveSessions))
+	for _, session := range liveSessions {
+		sessions = append(sessions, exportedSession{
+			ID:        session.ID,
+			Device:    session.Device,
+			IP:        session.IP,
+			CreatedAt: session.CreatedAt,
+			LastSeen:  session.LastSeen,
+			ExpiresAt: session.ExpiresAt,
+		})
+	}
+
+	apiKeys, err := s.apiKeyRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.FindByUserID(ctx, user.ID, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := s.positionRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	auditEntries, err := s.userAuditEntries(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []exportSection{
+		{name: "profile", data: user},
+		{name: "addresses", data: addresses},
+		{name: "sessions", data: sessions},
+		{name: "apiKeys", data: apiKeys},
+		{name: "transactions", data: transactions},
+		{name: "positions", data: positions},
+		{name: "auditEntries", data: auditEntries},
+		{name: "retainedAfterPurge", data: purgeRetention},
+	}
+
+	if export.Format == models.ExportFormatZIP {
+		return zipExport(sections)
+	}
+
+	document := map[string]any{"generatedAt": time.Now().UTC()}
+	for _, section := range sections {
+		document[section.name] = section.data
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// userAuditEntries returns the audit log entries a user made or was the target of, oldest first
+func (s *privacyService) userAuditEntries(ctx context.Context, userID uint) ([]*models.AuditLog, error) {
+	entries := []*models.AuditLog{}
+	collect := func(entry *models.AuditLog) error {
+		entries = append(entries, entry)
+		return nil
+	}
+
+	if err := s.auditService.Export(ctx, models.AuditLogFilter{ActorID: &userID}, collect); err != nil {
+		return nil, err
+	}
+
+	// Entries targeting the user, unless the user made them
+	target := userTarget(userID)
+	err := s.auditService.Export(ctx, models.AuditLogFilter{TargetType: target.Type, TargetID: target.ID}, func(entry *models.AuditLog) error {
+		if entry.ActorID != nil && *entry.ActorID == userID {
+			return nil
+		}
+		return collect(entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// zipExport writes each section of a data export to its own JSON file
+func zipExport(sections []exportSection) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, section := range sections {
+		file, err := archive.Create(section.name + ".json")
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RequestDeletion asks for the account of a user to be purged, which an admin must approve
+func (s *privacyService) RequestDeletion(ctx context.Context, userID uint, reason string) (*models.DeletionRequest, error) {
+	latest, err := s.deletionRepo.FindLatestByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if latest != nil && latest.Status == models.DeletionPending {
+		return nil, service.ErrDeletionPending
+	}
+
+	request := &models.DeletionRequest{
+		UserID: userID,
+		Reason: strings.TrimSpace(reason),
+		Status: models.DeletionPending,
+	}
+	if err := s.deletionRepo.Create(ctx, request); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditUserDeletionRequest, userTarget(userID), nil, map[string]any{"deletionRequestId": request.ID})
+	return request, nil
+}
+
+// GetDeletionRequest returns the most recent deletion request of a user, or nil
+func (s *privacyService) GetDeletionRequest(ctx context.Context, userID uint) (*models.DeletionRequest, error) {
+	return s.deletionRepo.FindLatestByUser(ctx, userID)
+}
+
+// ListDeletionRequests retrieves deletion requests with optional filtering and pagination, oldest first
+func (s *privacyService) ListDeletionRequests(ctx context.Context, filter models.DeletionRequestFilter, offset, limit int) ([]*models.DeletionRequest, error) {
+	return s.deletionRepo.List(ctx, filter, offset, limit)
+}
+
+// CountDeletionRequests returns the number of deletion requests matching the filter
+func (s *privacyService) CountDeletionRequests(ctx context.Context, filter models.DeletionRequestFilter) (int64, error) {
+	return s.deletionRepo.Count(ctx, filter)
+}
+
+// ApproveDeletion approves a pending deletion request, revoking every token of its user and purging the account
+func (s *privacyService) ApproveDeletion(ctx context.Context, reviewerID, requestID uint, note string) (*models.DeletionRequest, error) {
+	request, err := s.findPendingDeletion(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions live in Valkey; the purge deletes API keys along with the other off-chain records
+	if err := s.authService.RevokeAllSessions(ctx, request.UserID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := s.userRepo.Purge(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+
+	// The reason was written by the user and may identify them
+	request.Reason = ""
+	if err := s.reviewDeletion(ctx, request, models.DeletionApproved, reviewerID, note); err != nil {
+		return nil, err
+	}
+
+	// Entries already in the audit log keep the addresses and IPs they recorded, as the export states.
+	// Record no diff, which would copy the erased data into the immutable audit log
+	recordAudit(ctx, s.auditService, models.AuditUserPurge, userTarget(request.UserID), nil, map[string]any{"deletionRequestId": request.ID})
+	return request, nil
+}
+
+// RejectDeletion rejects a pending deletion request
+func (s *privacyService) RejectDeletion(ctx context.Context, reviewerID, requestID uint, note string) (*models.DeletionRequest, error) {
+	request, err := s.findPendingDeletion(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewDeletion(ctx, request, models.DeletionRejected, reviewerID, note); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditUserDeletionReject, deletionRequestTarget(request.ID), nil, map[string]any{"userId": request.UserID})
+	return request, nil
+}
+
+// findPendingDeletion returns a deletion request that awaits review
+func (s *privacyService) findPendingDeletion(ctx context.Context, requestID uint) (*models.DeletionRequest, error) {
+	request, err := s.deletionRepo.FindByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request == nil {
+		return nil, service.ErrDeletionRequestNotFound
+	}
+
+	if request.Status != models.DeletionPending {
+		return nil, service.ErrDeletionReviewed
+	}
+
+	return request, nil
+}
+
+// reviewDeletion records the outcome of a deletion request
+func (s *privacyService) reviewDeletion(ctx context.Context, request *models.DeletionRequest, status models.DeletionRequestStatus, reviewerID uint, note string) error {
+	reviewedAt := time.Now()
+	request.Status = status
+	request.ReviewedBy = &reviewerID
+	request.ReviewNote = strings.TrimSpace(note)
+	request.ReviewedAt = &reviewedAt
+	return s.deletionRepo.Update(ctx, request)
+}
+
+// deletionRequestTarget identifies a deletion request in the audit log
+func deletionRequestTarget(id uint) service.AuditTarget {
+	return service.AuditTarget{Type: "deletion_request", ID: strconv.FormatUint(uint64(id), 10)}
+}
+
+// StartDataExportWorker periodically assembles pending data exports until the context is cancelled
+func StartDataExportWorker(ctx context.Context, privacyService service.PrivacyService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := privacyService.ProcessExports(ctx); err != nil {
+					log.Printf("Failed to process data exports: %v", err)
+				}
+			}
+		}
+	}()
+}
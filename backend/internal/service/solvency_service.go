@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain"
+	"github.com/Mattouff/Lending-Borrowing/internal/infrastructure/blockchain/services"
+)
+
+type solvencyService struct {
+	snapshotRepo repository.SolvencySnapshotRepository
+	positionRepo repository.PositionRepository
+	userRepo     repository.UserRepository
+	markets      service.MarketRegistry
+	ethClient    *blockchain.EthClient
+
+	coverageWarning   *big.Int
+	coverageCritical  *big.Int
+	liquidityWarning  *big.Int
+	liquidityCritical *big.Int
+	retention         time.Duration
+}
+
+// NewSolvencyService creates a new solvency service using the configured thresholds
+func NewSolvencyService(
+	cfg *config.Config,
+	snapshotRepo repository.SolvencySnapshotRepository,
+	positionRepo repository.PositionRepository,
+	userRepo repository.UserRepository,
+	markets service.MarketRegistry,
+) (service.SolvencyService, error) {
+	return &solvencyService{
+		snapshotRepo:      snapshotRepo,
+		positionRepo:      positionRepo,
+		userRepo:          userRepo,
+		markets:           markets,
+		ethClient:         blockchain.GetInstance(),
+		coverageWarning:   percentToRatio(cfg.Solvency.CoverageWarning),
+		coverageCritical:  percentToRatio(cfg.Solvency.CoverageCritical),
+		liquidityWarning:  percentToRatio(cfg.Solvency.LiquidityWarning),
+		liquidityCritical: percentToRatio(cfg.Solvency.LiquidityCritical),
+		retention:         time.Duration(cfg.Solvency.Retention) * 24 * time.Hour,
+	}, nil
+}
+
+// GetReport computes the current solvency report of a market
+func (s *solvencyService) GetReport(ctx context.Context, market string) (*service.SolvencyReport, error) {
+	solvencyMarket, contracts, err := marketContracts(ctx, s.markets, market)
+	if err != nil {
+		return nil, err
+	}
+
+	blockNumber, err := s.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lendingLiability, err := contracts.LendingPool.GetTotalLending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lendingPoolBalance, err := contracts.Token.BalanceOf(ctx, contracts.LendingPool.ContractAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	borrowingBalance, err := contracts.Token.BalanceOf(ctx, contracts.Borrowing.ContractAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	collateralBalance, err := contracts.Token.BalanceOf(ctx, contracts.Collateral.ContractAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	totalBorrowed, err := contracts.Borrowing.GetTotalBorrowed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	borrowInterest, err := s.pendingBorrowInterest(ctx, solvencyMarket.ID, contracts.Borrowing)
+	if err != nil {
+		return nil, err
+	}
+
+	collateralLiability, err := s.positionRepo.SumCurrentCollateral(ctx, solvencyMarket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deposits stay in the LendingPool (borrows are paid by the Borrowing contract),
+	// so whatever the pool owes beyond its balance was credited as interest
+	lenderInterest := new(big.Int).Sub(lendingLiability, lendingPoolBalance)
+
+	totalAssets := new(big.Int).Add(lendingPoolBalance, borrowingBalance)
+	totalAssets.Add(totalAssets, collateralBalance)
+	totalAssets.Add(totalAssets, totalBorrowed)
+	totalAssets.Add(totalAssets, borrowInterest)
+
+	totalLiabilities := new(big.Int).Add(lendingLiability, collateralLiability)
+
+	report := &service.SolvencyReport{
+		Market:              solvencyMarket.Identifier,
+		Token:               solvencyMarket.GetTokenAddress(),
+		BlockNumber:         blockNumber,
+		LendingLiability:    lendingLiability,
+		LenderInterest:      lenderInterest,
+		CollateralLiability: collateralLiability,
+		LendingPoolBalance:  lendingPoolBalance,
+		BorrowingBalance:    borrowingBalance,
+		CollateralBalance:   collateralBalance,
+		TotalBorrowed:       totalBorrowed,
+		BorrowInterest:      borrowInterest,
+		TotalAssets:         totalAssets,
+		TotalLiabilities:    totalLiabilities,
+		NetReserves:         new(big.Int).Sub(totalAssets, totalLiabilities),
+		CoverageRatio:       ratio(totalAssets, totalLiabilities),
+		LiquidityRatio:      ratio(lendingPoolBalance, lendingLiability),
+		GeneratedAt:         time.Now(),
+	}
+
+	s.evaluateThresholds(report)
+
+	return report, nil
+}
+
+// RecordSnapshot computes the current solvency report of a market and stores it in the time series
+func (s *solvencyService) RecordSnapshot(ctx context.Context, market string) (*service.SolvencyReport, error) {
+	solvencyMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.GetReport(ctx, solvencyMarket.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.SolvencySnapshot{
+		MarketID:            solvencyMarket.ID,
+		LendingLiability:    report.LendingLiability.String(),
+		LenderInterest:      report.LenderInterest.String(),
+		CollateralLiability: report.CollateralLiability.String(),
+		LendingPoolBalance:  report.LendingPoolBalance.String(),
+		BorrowingBalance:    report.BorrowingBalance.String(),
+		CollateralBalance:   report.CollateralBalance.String(),
+		TotalBorrowed:       report.TotalBorrowed.String(),
+		BorrowInterest:      report.BorrowInterest.String(),
+		NetReserves:         report.NetReserves.String(),
+		CoverageRatio:       report.CoverageRatio.String(),
+		LiquidityRatio:      report.LiquidityRatio.String(),
+		Status:              report.Status,
+		BlockNumber:         report.BlockNumber,
+	}
+	if err := s.snapshotRepo.Create(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	if s.retention > 0 {
+		if _, err := s.snapshotRepo.DeleteOlderThan(ctx, time.Now().Add(-s.retention)); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// GetHistory returns the solvency snapshots of a market taken within [from, to], oldest first
+func (s *solvencyService) GetHistory(ctx context.Context, market string, from, to time.Time) ([]*models.SolvencySnapshot, error) {
+	solvencyMarket, err := s.markets.GetMarket(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.snapshotRepo.FindInRange(ctx, solvencyMarket.ID, from, to)
+}
+
+// pendingBorrowInterest sums the interest accrued by every active borrower of a market
+// since their debt was last updated on-chain; totalBorrowed only includes applied interest
+func (s *solvencyService) pendingBorrowInterest(ctx context.Context, marketID uint, borrowing *services.BorrowingService) (*big.Int, error) {
+	positions, err := s.positionRepo.List(ctx, models.PositionFilter{
+		MarketID: &marketID,
+		Statuses: []models.PositionStatus{models.StatusActive},
+	}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	seen := make(map[uint]bool)
+	for _, position := range positions {
+		if seen[position.UserID] {
+			continue
+		}
+		seen[position.UserID] = true
+
+		user, err := s.userRepo.FindByID(ctx, position.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			continue
+		}
+
+		borrower := common.HexToAddress(user.Address)
+		principal, err := borrowing.GetBorrowedPrincipal(ctx, borrower)
+		if err != nil {
+			return nil, err
+		}
+
+		debt, err := borrowing.GetBorrowToken(ctx, borrower)
+		if err != nil {
+			return nil, err
+		}
+
+		if debt.Cmp(principal) > 0 {
+			total.Add(total, new(big.Int).Sub(debt, principal))
+		}
+	}
+
+	return total, nil
+}
+
+// evaluateThresholds sets the status of a report and raises an alert for every threshold crossed
+func (s *solvencyService) evaluateThresholds(report *service.SolvencyReport) {
+	report.Status = models.SolvencyHealthy
+
+	check := func(metric string, value, warning, critical *big.Int) {
+		var level models.SolvencyStatus
+		var threshold *big.Int
+		switch {
+		case value.Cmp(critical) < 0:
+			level, threshold = models.SolvencyCritical, critical
+		case value.Cmp(warning) < 0:
+			level, threshold = models.SolvencyWarning, warning
+		default:
+			return
+		}
+
+		report.Alerts = append(report.Alerts, service.SolvencyAlert{
+			Level:     level,
+			Metric:    metric,
+			Ratio:     value,
+			Threshold: threshold,
+		})
+
+		if level == models.SolvencyCritical || report.Status == models.SolvencyHealthy {
+			report.Status = level
+		}
+
+		log.Printf(
+			"ALERT: %s %s ratio in market %s: ratio=%s threshold=%s",
+			level, metric, report.Market, value.String(), threshold.String(),
+		)
+	}
+
+	check("coverage", report.CoverageRatio, s.coverageWarning, s.coverageCritical)
+	check("liquidity", report.LiquidityRatio, s.liquidityWarning, s.liquidityCritical)
+}
+
+// ratio returns numerator / denominator in 1e18 precision; nothing owed counts as fully covered
+func ratio(numerator, denominator *big.Int) *big.Int {
+	if denominator.Sign() == 0 {
+		return percentToRatio(100)
+	}
+
+	result := new(big.Int).Mul(numerator, big.NewInt(1e18))
+	return result.Quo(result, denominator)
+}
+
+// percentToRatio converts a percentage to a ratio in 1e18 precision
+func percentToRatio(percent int) *big.Int {
+	return new(big.Int).Mul(big.NewInt(int64(percent)), big.NewInt(1e16))
+}
+
+// StartSolvencyMonitor periodically records a solvency snapshot of every market until the context is cancelled
+func StartSolvencyMonitor(ctx context.Context, solvencyService service.SolvencyService, markets service.MarketRegistry, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				activeMarkets, err := markets.ListMarkets(ctx)
+				if err != nil {
+					log.Printf("Failed to list markets: %v", err)
+					continue
+				}
+
+				for _, market := range activeMarkets {
+					if _, err := solvencyService.RecordSnapshot(ctx, market.Identifier); err != nil {
+						log.Printf("Failed to record solvency snapshot of market %s: %v", market.Identifier, err)
+					}
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+type transactionService struct {
+	transactionRepo repository.TransactionRepository
+}
+
+// NewTransactionService creates a new transaction service
+func NewTransactionService(transactionRepo repository.TransactionRepository) service.TransactionService {
+	return &transactionService{
+		transactionRepo: transactionRepo,
+	}
+}
+
+// ListTransactions retrieves the ledger entries matching a filter, most recent first, with pagination
+func (s *transactionService) ListTransactions(ctx context.Context, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error) {
+	return s.transactionRepo.List(ctx, filter, offset, limit)
+}
+
+// ListTransactionsByCursor retrieves up to limit ledger entries matching a filter, most recent first, from a cursor
+func (s *transactionService) ListTransactionsByCursor(ctx context.Context, filter models.TransactionFilter, cursor *models.Cursor, limit int) ([]*models.Transaction, error) {
+	return s.transactionRepo.ListByCursor(ctx, filter, cursor, limit)
+}
+
+// CountTransactions returns the number of ledger entries matching a filter
+func (s *transactionService) CountTransactions(ctx context.Context, filter models.TransactionFilter) (int64, error) {
+	return s.transactionRepo.Count(ctx, filter)
+}
+
+// GetTransaction retrieves the ledger entries of an on-chain transaction, one per role.
+// A non-nil userID restricts the lookup to the entries of that user
+func (s *transactionService) GetTransaction(ctx context.Context, hash string, userID *uint) ([]*models.Transaction, error) {
+	// Hashes are recorded in lowercase hex
+	transactions, err := s.transactionRepo.FindByHash(ctx, strings.ToLower(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := transactions
+	if userID != nil {
+		entries = nil
+		for _, tx := range transactions {
+			if tx.UserID == *userID {
+				entries = append(entries, tx)
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, service.ErrTransactionNotFound
+	}
+
+	return entries, nil
+}
+
+// scopeUserTransactions restricts a filter to the transactions of some types made by a user in a market.
+// It reports false when none of the requested types is allowed, in which case nothing matches
+func scopeUserTransactions(ctx context.Context, markets service.MarketRegistry, userRepo repository.UserRepository, market string, address common.Address, filter *models.TransactionFilter, allowed []models.TransactionType) (bool, error) {
+	scopedMarket, err := markets.GetMarket(ctx, market)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := userRepo.FindByAddress(ctx, address.Hex())
+	if err != nil {
+		return false, err
+	}
+
+	if user == nil {
+		return false, errors.New("user not found")
+	}
+
+	filter.UserID = &user.ID
+	filter.MarketID = &scopedMarket.ID
+	return filter.RestrictTypes(allowed), nil
+}
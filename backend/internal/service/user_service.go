@@ -3,10 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 
 	"fmt"
 
@@ -17,17 +17,21 @@ import (
 )
 
 type userService struct {
-	userRepo    repository.UserRepository
-	cfg         *config.Config
-	authService service.AuthService
+	userRepo        repository.UserRepository
+	userAddressRepo repository.UserAddressRepository
+	cfg             *config.Config
+	authService     service.AuthService
+	auditService    service.AuditService
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo repository.UserRepository, cfg *config.Config, authService service.AuthService) service.UserService {
+func NewUserService(userRepo repository.UserRepository, userAddressRepo repository.UserAddressRepository, cfg *config.Config, authService service.AuthService, auditService service.AuditService) service.UserService {
 	return &userService{
-		userRepo:    userRepo,
-		cfg:         cfg,
-		authService: authService,
+		userRepo:        userRepo,
+		userAddressRepo: userAddressRepo,
+		cfg:             cfg,
+		authService:     authService,
+		auditService:    auditService,
 	}
 }
 
@@ -44,15 +48,11 @@ func (s *userService) Register(ctx context.Context, address, username string) (*
 		return nil, errors.New("user with this address already exists")
 	}
 
-	// Create a random nonce for the user
-	nonce := crypto.Keccak256Hash([]byte(time.Now().String())).Hex()
-
 	user := &models.User{
 		Address:   address,
 		Username:  username,
 		Role:      models.RoleUser,
 		Verified:  false,
-		Nonce:     nonce,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -80,47 +80,169 @@ func (s *userService) Update(ctx context.Context, user *models.User) error {
 	return s.userRepo.Update(ctx, user)
 }
 
-// VerifySignature verifies that a signature was created by the user's address
-func (s *userService) VerifySignature(ctx context.Context, address, message, signature string) (bool, error) {
-	// Convert the signature to the appropriate format
-	sig := common.FromHex(signature)
-	if len(sig) != 65 {
-		return false, errors.New("invalid signature length")
+// AssignRole changes the role of a user
+func (s *userService) AssignRole(ctx context.Context, id uint, role models.UserRole) (*models.User, error) {
+	if !role.IsValid() {
+		return nil, service.ErrInvalidRole
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, nil
 	}
 
-	// Ethereum message prefix
-	prefix := "\x19Ethereum Signed Message:\n" + fmt.Sprint(len(message)) + message
-	prefixedHash := crypto.Keccak256Hash([]byte(prefix))
+	if user.Role == models.RoleAdmin && role != models.RoleAdmin {
+		admins, err := s.userRepo.CountWithFilter(ctx, models.UserFilter{Roles: []models.UserRole{models.RoleAdmin}})
+		if err != nil {
+			return nil, err
+		}
+		if admins <= 1 {
+			return nil, service.ErrLastAdmin
+		}
+	}
 
-	// Check if V needs adjustment for Ethereum's quirks
-	if sig[64] > 26 {
-		sig[64] -= 27
+	// The role is read from the database on every request, so the change applies immediately
+	before := *user
+	user.Role = role
+	if err := s.Update(ctx, user); err != nil {
+		return nil, err
 	}
 
-	// Recover the public key from the signature
-	pubKey, err := crypto.Ecrecover(prefixedHash.Bytes(), sig)
+	recordAudit(ctx, s.auditService, models.AuditUserRoleAssign, userTarget(user.ID), &before, user)
+	return user, nil
+}
+
+// Verify marks a user as verified
+func (s *userService) Verify(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	// Convert the public key to an Ethereum address
-	recoveredAddr := common.BytesToAddress(crypto.Keccak256(pubKey[1:])[12:])
+	if user == nil {
+		return nil, nil
+	}
 
-	// Compare with the provided address
-	return recoveredAddr.Hex() == common.HexToAddress(address).Hex(), nil
+	before := *user
+	user.Verified = true
+	if err := s.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditUserVerify, userTarget(user.ID), &before, user)
+	return user, nil
 }
 
-// ListUsers retrieves all users with optional pagination
-func (s *userService) ListUsers(ctx context.Context, offset, limit int) ([]*models.User, error) {
-	return s.userRepo.List(ctx, offset, limit)
+// ListAddresses returns the addresses linked to a user, primary first
+func (s *userService) ListAddresses(ctx context.Context, userID uint) ([]*models.UserAddress, error) {
+	return s.userAddressRepo.ListByUser(ctx, userID)
 }
 
-// GenerateNonce generates a new nonce for signature verification
-func (s *userService) GenerateNonce(address string) string {
-	// Create a random nonce by hashing the address with a timestamp
-	timestamp := time.Now().String()
-	data := address + timestamp
-	return crypto.Keccak256Hash([]byte(data)).Hex()
+// LinkAddress links a secondary address to a user, once it has proven ownership of both
+func (s *userService) LinkAddress(ctx context.Context, userID uint, address string) (*models.UserAddress, error) {
+	if !common.IsHexAddress(address) {
+		return nil, errors.New("invalid ethereum address")
+	}
+	address = common.HexToAddress(address).Hex()
+
+	owner, err := s.userRepo.FindByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted users keep their linked addresses until they are purged
+	linked, err := s.userAddressRepo.FindByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner != nil || linked != nil {
+		return nil, service.ErrAddressInUse
+	}
+
+	userAddress := &models.UserAddress{
+		UserID:    userID,
+		Address:   address,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.userAddressRepo.Create(ctx, userAddress); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditAddressLink, userTarget(userID), nil, userAddress)
+	return userAddress, nil
+}
+
+// UnlinkAddress unlinks a secondary address from a user
+func (s *userService) UnlinkAddress(ctx context.Context, userID uint, address string) error {
+	userAddress, err := s.findLinkedAddress(ctx, userID, address)
+	if err != nil {
+		return err
+	}
+
+	if userAddress.IsPrimary {
+		return service.ErrPrimaryAddress
+	}
+
+	if err := s.userAddressRepo.Delete(ctx, userAddress.ID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditAddressUnlink, userTarget(userID), userAddress, nil)
+	return nil
+}
+
+// SetPrimaryAddress makes a linked address the primary address of a user
+func (s *userService) SetPrimaryAddress(ctx context.Context, userID uint, address string) (*models.User, error) {
+	userAddress, err := s.findLinkedAddress(ctx, userID, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if !userAddress.IsPrimary {
+		user, err := s.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.userAddressRepo.SetPrimary(ctx, userAddress); err != nil {
+			return nil, err
+		}
+
+		recordAudit(ctx, s.auditService, models.AuditAddressSetPrimary, userTarget(userID),
+			map[string]string{"primary": user.Address}, map[string]string{"primary": userAddress.Address})
+	}
+
+	return s.userRepo.FindByID(ctx, userID)
+}
+
+// findLinkedAddress retrieves an address linked to a user
+func (s *userService) findLinkedAddress(ctx context.Context, userID uint, address string) (*models.UserAddress, error) {
+	userAddress, err := s.userAddressRepo.FindByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if userAddress == nil || userAddress.UserID != userID {
+		return nil, service.ErrAddressNotLinked
+	}
+
+	return userAddress, nil
+}
+
+// ListUsers retrieves the users matching a filter, oldest first, with pagination
+func (s *userService) ListUsers(ctx context.Context, filter models.UserFilter, offset, limit int) ([]*models.User, error) {
+	return s.userRepo.List(ctx, filter, offset, limit)
+}
+
+// ListUsersByCursor retrieves up to limit users matching a filter, oldest first, from a cursor
+func (s *userService) ListUsersByCursor(ctx context.Context, filter models.UserFilter, cursor *models.Cursor, limit int) ([]*models.User, error) {
+	return s.userRepo.ListByCursor(ctx, filter, cursor, limit)
 }
 
 // Count returns the total number of users
@@ -129,23 +251,33 @@ func (s *userService) Count(ctx context.Context) (int64, error) {
 }
 
 // CountWithFilter counts users that match the given filter criteria
-func (s *userService) CountWithFilter(ctx context.Context, filter map[string]any) (int64, error) {
+func (s *userService) CountWithFilter(ctx context.Context, filter models.UserFilter) (int64, error) {
 	return s.userRepo.CountWithFilter(ctx, filter)
 }
 
-// Delete marks a user as deleted (soft delete) and invalidates all tokens
+// Delete marks a user as deleted (soft delete) and revokes all its sessions
 func (s *userService) Delete(ctx context.Context, id uint) error {
-	// First invalidate all user tokens
-	if err := s.authService.InvalidateAllUserTokens(ctx, id); err != nil {
+	before, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// First revoke all user sessions
+	if err := s.authService.RevokeAllSessions(ctx, id); err != nil {
 		// Log the error but continue with deletion
 		fmt.Printf("Failed to invalidate tokens for user %d: %v\n", id, err)
 	}
 
 	// Then perform the soft delete
-	return s.userRepo.Delete(ctx, id)
+	if err := s.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditService, models.AuditUserDelete, userTarget(id), before, nil)
+	return nil
 }
 
-// GenerateAuthToken generates an authentication token for a user
-func (s *userService) GenerateAuthToken(ctx context.Context, user *models.User) (string, time.Time, error) {
-	return s.authService.GenerateToken(ctx, user)
+// userTarget identifies a user account in the audit log
+func userTarget(id uint) service.AuditTarget {
+	return service.AuditTarget{Type: "user", ID: strconv.FormatUint(uint64(id), 10)}
 }
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/repository"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// userTable finds users that are not deleted, as the users table does
+type userTable struct {
+	repository.UserRepository
+	users []*models.User
+}
+
+func (r *userTable) FindByAddress(ctx context.Context, address string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Address == address {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+// userAddressTable holds linked addresses, whether or not their user is deleted
+type userAddressTable struct {
+	repository.UserAddressRepository
+	addresses []*models.UserAddress
+}
+
+func (r *userAddressTable) FindByAddress(ctx context.Context, address string) (*models.UserAddress, error) {
+	for _, userAddress := range r.addresses {
+		if userAddress.Address == address {
+			return userAddress, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userAddressTable) Create(ctx context.Context, userAddress *models.UserAddress) error {
+	r.addresses = append(r.addresses, userAddress)
+	return nil
+}
+
+func TestLinkAddress(t *testing.T) {
+	const address = "0x71C7656EC7ab88b098defB751B7401B5f6d8976F"
+
+	tests := []struct {
+		name      string
+		users     []*models.User
+		addresses []*models.UserAddress
+		wantErr   error
+	}{
+		{name: "free address"},
+		{
+			name:      "primary address of another user",
+			users:     []*models.User{{ID: 2, Address: address}},
+			addresses: []*models.UserAddress{{UserID: 2, Address: address, IsPrimary: true}},
+			wantErr:   service.ErrAddressInUse,
+		},
+		{
+			// The user is deleted but not purged, so its address is still linked
+			name:      "address of a deleted user",
+			addresses: []*models.UserAddress{{UserID: 2, Address: address}},
+			wantErr:   service.ErrAddressInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addresses := &userAddressTable{addresses: tt.addresses}
+			s := &userService{
+				userRepo:        &userTable{users: tt.users},
+				userAddressRepo: addresses,
+				auditService:    NewAuditService(&auditTable{}),
+			}
+
+			linked, err := s.LinkAddress(context.Background(), 1, address)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && (linked.UserID != 1 || len(addresses.addresses) != 1) {
+				t.Errorf("got %+v, want the address linked to user 1", linked)
+			}
+			if tt.wantErr != nil && len(addresses.addresses) != len(tt.addresses) {
+				t.Errorf("got %d linked addresses, want none added", len(addresses.addresses))
+			}
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/Mattouff/Lending-Borrowing/docs"
 	"github.com/gofiber/fiber/v2"
@@ -18,6 +23,9 @@ import (
 	"github.com/Mattouff/Lending-Borrowing/pkg/database"
 )
 
+// shutdownTimeout is how long requests and bundle runs are given to stop on shutdown
+const shutdownTimeout = 30 * time.Second
+
 // @title Lending & Borrowing Platform API
 // @version 1.0
 // @description API for decentralized lending and borrowing platform
@@ -26,6 +34,9 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	// Load environment variables and configuration
 	if err := config.LoadEnv(""); err != nil {
@@ -38,6 +49,10 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Cancelled on SIGINT or SIGTERM, stopping the background jobs
+	shutdownCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize database connection
 	db, err := database.Connect(cfg.Database.GetDSN())
 	if err != nil {
@@ -73,30 +88,93 @@ func main() {
 	userRepo := repoFactory.GetUserRepository()
 	transactionRepo := repoFactory.GetTransactionRepository()
 	positionRepo := repoFactory.GetPositionRepository()
+	priceSampleRepo := repoFactory.GetPriceSampleRepository()
+	marketRepo := repoFactory.GetMarketRepository()
+	solvencyRepo := repoFactory.GetSolvencySnapshotRepository()
+	apiKeyRepo := repoFactory.GetAPIKeyRepository()
+	auditLogRepo := repoFactory.GetAuditLogRepository()
+	userAddressRepo := repoFactory.GetUserAddressRepository()
+	dataExportRepo := repoFactory.GetDataExportRepository()
+	deletionRepo := repoFactory.GetDeletionRequestRepository()
+	bundleRepo := repoFactory.GetBundleRepository()
 
 	// Initialize services
-	authService := service.NewAuthService(
+	auditService := service.NewAuditService(auditLogRepo)
+
+	authService, err := service.NewAuthService(
 		cfg,
 		userRepo,
+		apiKeyRepo,
+		auditService,
 		valkeyClient,
 	)
+	if err != nil {
+		log.Fatalf("Failed to create auth service: %v", err)
+	}
+
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, auditService)
+
+	userService := service.NewUserService(userRepo, userAddressRepo, cfg, authService, auditService)
+
+	privacyService := service.NewPrivacyService(
+		cfg,
+		userRepo,
+		userAddressRepo,
+		apiKeyRepo,
+		transactionRepo,
+		positionRepo,
+		dataExportRepo,
+		deletionRepo,
+		authService,
+		auditService,
+	)
+
+	// Periodically assemble the requested personal data exports
+	service.StartDataExportWorker(
+		shutdownCtx,
+		privacyService,
+		time.Duration(cfg.Privacy.ExportInterval)*time.Second,
+	)
+
+	// Register the configured markets before the services that operate on them
+	marketRegistry, err := service.NewMarketRegistry(context.Background(), cfg, marketRepo)
+	if err != nil {
+		log.Fatalf("Failed to create market registry: %v", err)
+	}
 
-	userService := service.NewUserService(userRepo, cfg, authService)
+	// Positions and transactions recorded before markets existed belong to the default market
+	defaultMarket, err := marketRegistry.GetMarket(context.Background(), "")
+	if err != nil {
+		log.Fatalf("Failed to resolve the default market: %v", err)
+	}
+	if err := database.BackfillMarketIDs(db, defaultMarket.ID); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
 
 	// Initialize collateral service first since borrowing service depends on it
 	collateralService, err := service.NewCollateralService(
 		transactionRepo,
 		userRepo,
 		positionRepo,
+		marketRegistry,
 	)
 	if err != nil {
 		log.Fatalf("Failed to create collateral service: %v", err)
 	}
 
+	// Periodically cross-check the collateral held on-chain against indexed positions
+	service.StartCollateralMonitor(
+		shutdownCtx,
+		collateralService,
+		marketRegistry,
+		time.Duration(cfg.Blockchain.CollateralCheckInterval)*time.Minute,
+	)
+
 	// Initialize other services
 	lendingService, err := service.NewLendingService(
 		transactionRepo,
 		userRepo,
+		marketRegistry,
 	)
 	if err != nil {
 		log.Fatalf("Failed to create lending service: %v", err)
@@ -106,6 +184,7 @@ func main() {
 		transactionRepo,
 		userRepo,
 		positionRepo,
+		marketRegistry,
 		collateralService,
 	)
 	if err != nil {
@@ -116,18 +195,103 @@ func main() {
 		transactionRepo,
 		userRepo,
 		positionRepo,
+		marketRegistry,
 		collateralService,
 	)
 	if err != nil {
 		log.Fatalf("Failed to create liquidation service: %v", err)
 	}
 
+	positionService := service.NewPositionService(positionRepo, transactionRepo)
+	transactionService := service.NewTransactionService(transactionRepo)
+
+	marketService, err := service.NewMarketService(marketRegistry)
+	if err != nil {
+		log.Fatalf("Failed to create market service: %v", err)
+	}
+
+	priceService, err := service.NewPriceService(cfg, priceSampleRepo, marketRegistry)
+	if err != nil {
+		log.Fatalf("Failed to create price service: %v", err)
+	}
+
+	// Periodically record prices so the TWAP feed has samples to average
+	service.StartPriceSampler(
+		shutdownCtx,
+		priceService,
+		time.Duration(cfg.Oracle.SampleInterval)*time.Minute,
+	)
+
+	solvencyService, err := service.NewSolvencyService(
+		cfg,
+		solvencyRepo,
+		positionRepo,
+		userRepo,
+		marketRegistry,
+	)
+	if err != nil {
+		log.Fatalf("Failed to create solvency service: %v", err)
+	}
+
+	// Periodically snapshot the solvency of every market and alert on thresholds
+	service.StartSolvencyMonitor(
+		shutdownCtx,
+		solvencyService,
+		marketRegistry,
+		time.Duration(cfg.Solvency.SnapshotInterval)*time.Minute,
+	)
+
+	liveService := service.NewLiveService(
+		cfg,
+		valkeyClient,
+		transactionRepo,
+		positionRepo,
+		userRepo,
+		marketRegistry,
+		marketService,
+	)
+
+	// Deliver live events published by every replica, and watch the chain and health factors for new ones
+	service.StartLiveFeed(
+		shutdownCtx,
+		liveService,
+		time.Duration(cfg.Live.PollInterval)*time.Second,
+		time.Duration(cfg.Live.HealthInterval)*time.Second,
+	)
+
+	bundleService, err := service.NewBundleService(
+		bundleRepo,
+		transactionRepo,
+		marketRegistry,
+		collateralService,
+		borrowingService,
+	)
+	if err != nil {
+		log.Fatalf("Failed to create bundle service: %v", err)
+	}
+
+	// Resume the signer bundles left running by stopped servers, now and periodically
+	if err := bundleService.ResumeBundles(context.Background()); err != nil {
+		log.Printf("Failed to resume bundles: %v", err)
+	}
+	service.StartBundleResumer(
+		shutdownCtx,
+		bundleService,
+		time.Duration(cfg.Bundle.ResumeInterval)*time.Second,
+	)
+
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middleware.ErrorHandler(),
+		// Client IPs, which rate limits and bans are keyed by, come from the proxy header only behind a trusted proxy
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableIPValidation:      true,
 	})
 
 	// Middleware
+	app.Use(middleware.RequestID())
 	app.Use(middleware.Logger())
 	app.Use(middleware.CORS())
 
@@ -138,7 +302,18 @@ func main() {
 		BorrowingService:   borrowingService,
 		CollateralService:  collateralService,
 		LiquidationService: liquidationService,
+		MarketService:      marketService,
+		MarketRegistry:     marketRegistry,
+		PriceService:       priceService,
+		SolvencyService:    solvencyService,
 		AuthService:        authService,
+		APIKeyService:      apiKeyService,
+		AuditService:       auditService,
+		PrivacyService:     privacyService,
+		PositionService:    positionService,
+		TransactionService: transactionService,
+		LiveService:        liveService,
+		BundleService:      bundleService,
 		ValkeyClient:       valkeyClient,
 	}
 
@@ -157,6 +332,24 @@ func main() {
 
 	// Start server
 	serverAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	log.Printf("Server starting on %s", serverAddr)
-	log.Fatal(app.Listen(serverAddr))
+	go func() {
+		log.Printf("Server starting on %s", serverAddr)
+		if err := app.Listen(serverAddr); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// On SIGINT or SIGTERM, stop serving and release the running bundles before closing the clients
+	<-shutdownCtx.Done()
+	log.Println("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		log.Printf("Failed to shut down server: %v", err)
+	}
+	if err := bundleService.Shutdown(ctx); err != nil {
+		log.Printf("Failed to stop bundles: %v", err)
+	}
 }
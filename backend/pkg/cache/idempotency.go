@@ -0,0 +1,48 @@
+package valkey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// ClaimIdempotencyKey records the first attempt of a request of a user under its idempotency key, until it
+// expires, and reports whether this call recorded it. Later attempts find the record instead
+func (c *Client) ClaimIdempotencyKey(ctx context.Context, userID uint, key, record string, expiration time.Duration) (bool, error) {
+	err := c.client.Do(ctx, c.client.B().Set().Key(formatIdempotencyKey(userID, key)).Value(record).Nx().Px(expiration).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadIdempotencyRecord returns the record of a request of a user under its idempotency key, or an empty
+// string if there is none
+func (c *Client) LoadIdempotencyRecord(ctx context.Context, userID uint, key string) (string, error) {
+	record, err := c.client.Do(ctx, c.client.B().Get().Key(formatIdempotencyKey(userID, key)).Build()).ToString()
+	if valkey.IsValkeyNil(err) {
+		return "", nil
+	}
+	return record, err
+}
+
+// StoreIdempotencyRecord replaces the record of a request of a user under its idempotency key, e.g. with
+// its response once served
+func (c *Client) StoreIdempotencyRecord(ctx context.Context, userID uint, key, record string, expiration time.Duration) error {
+	return c.client.Do(ctx, c.client.B().Set().Key(formatIdempotencyKey(userID, key)).Value(record).Px(expiration).Build()).Error()
+}
+
+// ReleaseIdempotencyKey removes the record of a request of a user, so that its next attempt is served anew
+func (c *Client) ReleaseIdempotencyKey(ctx context.Context, userID uint, key string) error {
+	return c.client.Do(ctx, c.client.B().Del().Key(formatIdempotencyKey(userID, key)).Build()).Error()
+}
+
+// Helper function to format Valkey key for idempotency records
+func formatIdempotencyKey(userID uint, key string) string {
+	return fmt.Sprintf("idempotency:%d:%s", userID, key)
+}
@@ -0,0 +1,59 @@
+package valkey
+
+import (
+	"context"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// Publish sends a message to the subscribers of a channel, on every replica
+func (c *Client) Publish(ctx context.Context, channel, message string) error {
+	return c.client.Do(ctx, c.client.B().Publish().Channel(channel).Message(message).Build()).Error()
+}
+
+// Subscribe calls fn with every message published to a channel, until the context is cancelled
+// or the connection is lost. Messages published while not subscribed are not received
+func (c *Client) Subscribe(ctx context.Context, channel string, fn func(message string)) error {
+	return c.client.Receive(ctx, c.client.B().Subscribe().Channel(channel).Build(), func(msg valkey.PubSubMessage) {
+		fn(msg.Message)
+	})
+}
+
+// ClaimOnce records a key until it expires and reports whether this call recorded it, so that
+// a job shared by every replica is only run by the first one to claim it
+func (c *Client) ClaimOnce(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	err := c.client.Do(ctx, c.client.B().Set().Key(formatClaimKey(key)).Value("1").Nx().Px(expiration).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadJobState returns the state a job shared by every replica last stored, or an empty string if there is none
+func (c *Client) LoadJobState(ctx context.Context, key string) (string, error) {
+	state, err := c.client.Do(ctx, c.client.B().Get().Key(formatJobStateKey(key)).Build()).ToString()
+	if valkey.IsValkeyNil(err) {
+		return "", nil
+	}
+	return state, err
+}
+
+// StoreJobState records the state of a job shared by every replica until it expires, so that whichever
+// replica runs the job next carries on from it
+func (c *Client) StoreJobState(ctx context.Context, key, state string, expiration time.Duration) error {
+	return c.client.Do(ctx, c.client.B().Set().Key(formatJobStateKey(key)).Value(state).Px(expiration).Build()).Error()
+}
+
+// Helper function to format Valkey key for job claims
+func formatClaimKey(key string) string {
+	return "claim:" + key
+}
+
+// Helper function to format Valkey key for job states
+func formatJobStateKey(key string) string {
+	return "job_state:" + key
+}
@@ -0,0 +1,121 @@
+package valkey
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/valkey-io/valkey-go"
+)
+
+// RateLimitResult is the state of a client's sliding window after a request
+type RateLimitResult struct {
+	Allowed   bool
+	Count     int           // Requests counted in the window, including this one if allowed
+	ResetTime time.Duration // Until the oldest request in the window expires
+}
+
+// slidingWindowScript logs requests in a sorted set scored by time, drops those older than
+// the window and only logs the new request if the limit is not reached.
+// KEYS: window set. ARGV: now in ms, window in ms, limit, request ID
+var slidingWindowScript = valkey.NewLuaScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < tonumber(ARGV[3]) then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local oldestTime = now
+if oldest[2] then
+	oldestTime = tonumber(oldest[2])
+end
+return {allowed, count, oldestTime + window - now}
+`)
+
+// CheckRateLimit counts a request of a client against a sliding window of the given size
+func (c *Client) CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	args := []string{
+		strconv.FormatInt(time.Now().UnixMilli(), 10),
+		strconv.FormatInt(window.Milliseconds(), 10),
+		strconv.Itoa(limit),
+		uuid.New().String(),
+	}
+
+	values, err := slidingWindowScript.Exec(ctx, c.client, []string{formatRateLimitKey(key)}, args).AsIntSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit reply: %v", values)
+	}
+
+	return &RateLimitResult{
+		Allowed:   values[0] == 1,
+		Count:     int(values[1]),
+		ResetTime: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+// RecordFailure counts a failed attempt of a client and returns the failures counted in the window
+func (c *Client) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	failureKey := formatFailureKey(key)
+
+	count, err := c.client.Do(ctx, c.client.B().Incr().Key(failureKey).Build()).AsInt64()
+	if err != nil {
+		return 0, err
+	}
+
+	// The window starts with the first failure
+	if count == 1 {
+		err = c.client.Do(ctx, c.client.B().Pexpire().Key(failureKey).Milliseconds(window.Milliseconds()).Build()).Error()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// Ban blocks a client for a duration
+func (c *Client) Ban(ctx context.Context, key string, duration time.Duration) error {
+	return c.client.Do(ctx, c.client.B().Set().Key(formatBanKey(key)).Value("1").Px(duration).Build()).Error()
+}
+
+// BanRemaining returns how long a client stays banned, or 0 if it is not banned
+func (c *Client) BanRemaining(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.Do(ctx, c.client.B().Pttl().Key(formatBanKey(key)).Build()).AsInt64()
+	if err != nil {
+		return 0, err
+	}
+
+	// -2 means the key does not exist, -1 that it has no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
+// Helper function to format Valkey key for rate limit windows
+func formatRateLimitKey(key string) string {
+	return fmt.Sprintf("rate_limit:%s", key)
+}
+
+// Helper function to format Valkey key for failed attempts
+func formatFailureKey(key string) string {
+	return fmt.Sprintf("failures:%s", key)
+}
+
+// Helper function to format Valkey key for bans
+func formatBanKey(key string) string {
+	return fmt.Sprintf("ban:%s", key)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Mattouff/Lending-Borrowing/internal/config"
@@ -67,29 +69,151 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// StoreValidToken adds a token to a user's valid tokens set
-func (c *Client) StoreValidToken(ctx context.Context, userID uint, tokenID string, expiration time.Duration) error {
-	key := formatValidTokenKey(userID)
+// Session holds the metadata of a login session, stored next to the user's valid token set
+type Session struct {
+	ID          string
+	Device      string
+	IP          string
+	RefreshHash string // Hash of the current refresh token secret
+	CreatedAt   time.Time
+	LastSeen    time.Time
+	ExpiresAt   time.Time
+}
+
+// RotationResult is the outcome of a refresh token rotation
+type RotationResult int
+
+const (
+	// RotationSucceeded means the refresh token matched and was replaced
+	RotationSucceeded RotationResult = iota
+	// RotationReused means an outdated refresh token was presented and the session was revoked
+	RotationReused
+	// RotationSessionNotFound means the session has expired or been revoked
+	RotationSessionNotFound
+)
 
-	// Add token to set
-	err := c.client.Do(ctx, c.client.B().Sadd().Key(key).Member(tokenID).Build()).Error()
+// rotateScript swaps the refresh token hash of a session if the presented one is current.
+// Presenting any other token for a live session means it was stolen or replayed, so the
+// session is revoked. KEYS: session hash, valid token set. ARGV: presented hash, new hash,
+// ip, last seen, session ID, expires at, TTL in seconds
+var rotateScript = valkey.NewLuaScript(`
+local current = redis.call('HGET', KEYS[1], 'refresh_hash')
+if not current then
+	return 2
+end
+if current ~= ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	redis.call('SREM', KEYS[2], ARGV[5])
+	return 1
+end
+redis.call('HSET', KEYS[1], 'refresh_hash', ARGV[2], 'ip', ARGV[3], 'last_seen', ARGV[4], 'expires_at', ARGV[6])
+redis.call('EXPIRE', KEYS[1], ARGV[7])
+redis.call('EXPIRE', KEYS[2], ARGV[7])
+return 0
+`)
+
+// StoreSession adds a session to a user's valid token set and stores its metadata
+func (c *Client) StoreSession(ctx context.Context, userID uint, session *Session, expiration time.Duration) error {
+	setKey := formatValidTokenKey(userID)
+	sessionKey := formatSessionKey(userID, session.ID)
+
+	err := c.client.Do(ctx, c.client.B().Hset().Key(sessionKey).FieldValue().
+		FieldValue("device", session.Device).
+		FieldValue("ip", session.IP).
+		FieldValue("refresh_hash", session.RefreshHash).
+		FieldValue("created_at", formatTimestamp(session.CreatedAt)).
+		FieldValue("last_seen", formatTimestamp(session.LastSeen)).
+		FieldValue("expires_at", formatTimestamp(session.ExpiresAt)).
+		Build()).Error()
+	if err != nil {
+		return err
+	}
+
+	err = c.client.Do(ctx, c.client.B().Expire().Key(sessionKey).Seconds(int64(expiration.Seconds())).Build()).Error()
+	if err != nil {
+		return err
+	}
+
+	// Add session to set
+	err = c.client.Do(ctx, c.client.B().Sadd().Key(setKey).Member(session.ID).Build()).Error()
 	if err != nil {
 		return err
 	}
 
 	// Set expiration on the key
-	err = c.client.Do(ctx, c.client.B().Expire().Key(key).Seconds(int64(expiration.Seconds())).Build()).Error()
+	err = c.client.Do(ctx, c.client.B().Expire().Key(setKey).Seconds(int64(expiration.Seconds())).Build()).Error()
 	if err != nil {
 		return err
 	}
 
 	// Update cache
-	c.cache.Set(userID, tokenID, true)
+	c.cache.Set(userID, session.ID, true)
 
 	return nil
 }
 
-// IsValidToken checks if a token is valid for a user
+// RotateRefreshToken replaces the refresh token hash of a session if the presented one is current
+func (c *Client) RotateRefreshToken(ctx context.Context, userID uint, sessionID, presentedHash, newHash, ip string, expiration time.Duration) (RotationResult, error) {
+	now := time.Now()
+	keys := []string{formatSessionKey(userID, sessionID), formatValidTokenKey(userID)}
+	args := []string{
+		presentedHash,
+		newHash,
+		ip,
+		formatTimestamp(now),
+		sessionID,
+		formatTimestamp(now.Add(expiration)),
+		strconv.FormatInt(int64(expiration.Seconds()), 10),
+	}
+
+	result, err := rotateScript.Exec(ctx, c.client, keys, args).AsInt64()
+	if err != nil {
+		return RotationSessionNotFound, err
+	}
+
+	if RotationResult(result) == RotationReused {
+		c.cache.Invalidate(userID, sessionID)
+	}
+
+	return RotationResult(result), nil
+}
+
+// ListSessions returns the live sessions of a user
+func (c *Client) ListSessions(ctx context.Context, userID uint) ([]*Session, error) {
+	setKey := formatValidTokenKey(userID)
+	sessionIDs, err := c.client.Do(ctx, c.client.B().Smembers().Key(setKey).Build()).AsStrSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		fields, err := c.client.Do(ctx, c.client.B().Hgetall().Key(formatSessionKey(userID, sessionID)).Build()).AsStrMap()
+		if err != nil {
+			return nil, err
+		}
+
+		// The metadata expired before the set did: the session is over
+		if len(fields) == 0 {
+			c.client.Do(ctx, c.client.B().Srem().Key(setKey).Member(sessionID).Build())
+			continue
+		}
+
+		sessions = append(sessions, &Session{
+			ID:          sessionID,
+			Device:      fields["device"],
+			IP:          fields["ip"],
+			RefreshHash: fields["refresh_hash"],
+			CreatedAt:   parseTimestamp(fields["created_at"]),
+			LastSeen:    parseTimestamp(fields["last_seen"]),
+			ExpiresAt:   parseTimestamp(fields["expires_at"]),
+		})
+	}
+
+	return sessions, nil
+}
+
+// IsValidToken checks if a session is still valid for a user
 func (c *Client) IsValidToken(ctx context.Context, userID uint, tokenID string) (bool, error) {
 	// Check cache first
 	if valid, found := c.cache.Get(userID, tokenID); found {
@@ -110,12 +234,19 @@ func (c *Client) IsValidToken(ctx context.Context, userID uint, tokenID string)
 	return false, err
 }
 
-// InvalidateAllUserTokens removes all valid tokens for a user
+// InvalidateAllUserTokens removes every session of a user
 func (c *Client) InvalidateAllUserTokens(ctx context.Context, userID uint) error {
 	key := formatValidTokenKey(userID)
 
+	sessionIDs, err := c.client.Do(ctx, c.client.B().Smembers().Key(key).Build()).AsStrSlice()
+	if err == nil {
+		for _, sessionID := range sessionIDs {
+			c.client.Do(ctx, c.client.B().Del().Key(formatSessionKey(userID, sessionID)).Build())
+		}
+	}
+
 	// Clear from Valkey
-	err := c.client.Do(ctx, c.client.B().Del().Key(key).Build()).Error()
+	err = c.client.Do(ctx, c.client.B().Del().Key(key).Build()).Error()
 
 	// Clear from cache (even if Valkey operation failed)
 	c.cache.InvalidateUser(userID)
@@ -123,17 +254,68 @@ func (c *Client) InvalidateAllUserTokens(ctx context.Context, userID uint) error
 	return err
 }
 
-// InvalidateToken removes a specific token from valid tokens
-func (c *Client) InvalidateToken(ctx context.Context, userID uint, tokenID string) error {
+// InvalidateToken removes a session and reports whether it existed
+func (c *Client) InvalidateToken(ctx context.Context, userID uint, tokenID string) (bool, error) {
 	key := formatValidTokenKey(userID)
 
 	// Remove from Valkey
-	err := c.client.Do(ctx, c.client.B().Srem().Key(key).Member(tokenID).Build()).Error()
+	removed, err := c.client.Do(ctx, c.client.B().Srem().Key(key).Member(tokenID).Build()).AsInt64()
+	if err == nil {
+		err = c.client.Do(ctx, c.client.B().Del().Key(formatSessionKey(userID, tokenID)).Build()).Error()
+	}
 
 	// Remove from cache
 	c.cache.Invalidate(userID, tokenID)
 
-	return err
+	return removed == 1, err
+}
+
+// StoreNonce records a sign-in nonce issued to an address until it expires
+func (c *Client) StoreNonce(ctx context.Context, nonce, address string, expiration time.Duration) error {
+	key := formatNonceKey(nonce)
+	return c.client.Do(ctx, c.client.B().Set().Key(key).Value(strings.ToLower(address)).Nx().Ex(expiration).Build()).Error()
+}
+
+// ConsumeNonce atomically removes a sign-in nonce and reports whether it had been issued to the address.
+// A nonce can only be consumed once, whatever the outcome
+func (c *Client) ConsumeNonce(ctx context.Context, nonce, address string) (bool, error) {
+	key := formatNonceKey(nonce)
+	issuedTo, err := c.client.Do(ctx, c.client.B().Getdel().Key(key).Build()).ToString()
+	if valkey.IsValkeyNil(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return issuedTo == strings.ToLower(address), nil
+}
+
+// StoreLinkChallenge records the message a user must have signed by two addresses to link the second one
+func (c *Client) StoreLinkChallenge(ctx context.Context, userID uint, address, nonce, message string, expiration time.Duration) error {
+	key := formatLinkChallengeKey(userID, address, nonce)
+	return c.client.Do(ctx, c.client.B().Set().Key(key).Value(message).Nx().Ex(expiration).Build()).Error()
+}
+
+// ConsumeLinkChallenge atomically removes a link challenge and returns its message, or an empty string
+// if it was never issued, has expired or was already used
+func (c *Client) ConsumeLinkChallenge(ctx context.Context, userID uint, address, nonce string) (string, error) {
+	key := formatLinkChallengeKey(userID, address, nonce)
+	message, err := c.client.Do(ctx, c.client.B().Getdel().Key(key).Build()).ToString()
+	if valkey.IsValkeyNil(err) {
+		return "", nil
+	}
+	return message, err
+}
+
+// Helper function to format Valkey key for sign-in nonces
+func formatNonceKey(nonce string) string {
+	return fmt.Sprintf("siwe_nonce:%s", nonce)
+}
+
+// Helper function to format Valkey key for address link challenges
+func formatLinkChallengeKey(userID uint, address, nonce string) string {
+	return fmt.Sprintf("link_nonce:%d:%s:%s", userID, strings.ToLower(address), nonce)
 }
 
 // Helper function to format Valkey key for valid tokens
@@ -141,6 +323,23 @@ func formatValidTokenKey(userID uint) string {
 	return fmt.Sprintf("valid_token:%d", userID)
 }
 
+// Helper function to format Valkey key for session metadata
+func formatSessionKey(userID uint, sessionID string) string {
+	return fmt.Sprintf("session:%d:%s", userID, sessionID)
+}
+
+func formatTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func parseTimestamp(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
 // Ping checks if the Valkey server is reachable
 func (c *Client) Ping(ctx context.Context) error {
 	return c.client.Do(ctx, c.client.B().Ping().Build()).Error()
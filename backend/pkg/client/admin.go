@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+)
+
+// AdminService calls the user administration routes under /users/admin. Each call needs the permission
+// its route names
+type AdminService struct {
+	client *Client
+}
+
+// ListUsers returns a page of users. Type takes comma-separated roles and status verified or unverified
+func (s *AdminService) ListUsers(ctx context.Context, options ListOptions) (*dto.UserListResponse, error) {
+	return listUsers(ctx, s.client, s.usersRequest(options))
+}
+
+// AllUsers iterates over the users, from the page of options
+func (s *AdminService) AllUsers(ctx context.Context, options ListOptions) iter.Seq2[dto.UserResponse, error] {
+	return paginate(ctx, s.usersRequest(options), func(ctx context.Context, req *request) (page[dto.UserResponse], error) {
+		resp, err := listUsers(ctx, s.client, req)
+		if err != nil {
+			return page[dto.UserResponse]{}, err
+		}
+		return page[dto.UserResponse]{items: resp.Users, page: resp.Page, totalPage: resp.TotalPage, next: resp.Next}, nil
+	})
+}
+
+// GetUser returns a user
+func (s *AdminService) GetUser(ctx context.Context, id uint) (*dto.UserResponse, error) {
+	return s.user(ctx, &request{method: http.MethodGet, path: "/users/admin/" + formatID(id)})
+}
+
+// GetUserByAddress returns the user an address belongs to
+func (s *AdminService) GetUserByAddress(ctx context.Context, address common.Address) (*dto.UserResponse, error) {
+	return s.user(ctx, &request{method: http.MethodGet, path: "/users/admin/address/" + address.Hex()})
+}
+
+// VerifyUser marks a user as verified
+func (s *AdminService) VerifyUser(ctx context.Context, id uint) error {
+	return s.client.call(ctx, &request{method: http.MethodPut, path: "/users/admin/" + formatID(id) + "/verify"}, nil)
+}
+
+// AssignRole gives a user a role
+func (s *AdminService) AssignRole(ctx context.Context, id uint, role dto.UserRole) (*dto.UserResponse, error) {
+	return s.user(ctx, &request{
+		method: http.MethodPut,
+		path:   "/users/admin/" + formatID(id) + "/role",
+		body:   dto.AssignRoleRequest{Role: role},
+	})
+}
+
+// DeleteUser deletes a user
+func (s *AdminService) DeleteUser(ctx context.Context, id uint) error {
+	return s.client.call(ctx, &request{method: http.MethodDelete, path: "/users/admin/" + formatID(id)}, nil)
+}
+
+// ListRoles returns the built-in roles and the permissions they grant
+func (s *AdminService) ListRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	return callData[[]dto.RoleResponse](ctx, s.client, &request{method: http.MethodGet, path: "/users/admin/roles"})
+}
+
+// ListDeletionRequests returns a page of account deletion requests. Status takes comma-separated statuses
+func (s *AdminService) ListDeletionRequests(ctx context.Context, options ListOptions) (*dto.DeletionRequestListResponse, error) {
+	return listDeletionRequests(ctx, s.client, s.deletionRequestsRequest(options))
+}
+
+// AllDeletionRequests iterates over the account deletion requests, from the page of options
+func (s *AdminService) AllDeletionRequests(ctx context.Context, options ListOptions) iter.Seq2[dto.DeletionRequestResponse, error] {
+	return paginate(ctx, s.deletionRequestsRequest(options), func(ctx context.Context, req *request) (page[dto.DeletionRequestResponse], error) {
+		resp, err := listDeletionRequests(ctx, s.client, req)
+		if err != nil {
+			return page[dto.DeletionRequestResponse]{}, err
+		}
+		return page[dto.DeletionRequestResponse]{items: resp.Requests, page: resp.Page, totalPage: resp.TotalPage}, nil
+	})
+}
+
+// ApproveDeletionRequest approves a pending deletion request, which purges the account
+func (s *AdminService) ApproveDeletionRequest(ctx context.Context, id uint, note string) (*dto.DeletionRequestResponse, error) {
+	return s.reviewDeletionRequest(ctx, id, "approve", note)
+}
+
+// RejectDeletionRequest rejects a pending deletion request
+func (s *AdminService) RejectDeletionRequest(ctx context.Context, id uint, note string) (*dto.DeletionRequestResponse, error) {
+	return s.reviewDeletionRequest(ctx, id, "reject", note)
+}
+
+func (s *AdminService) reviewDeletionRequest(ctx context.Context, id uint, decision, note string) (*dto.DeletionRequestResponse, error) {
+	req := &request{
+		method: http.MethodPost,
+		path:   "/users/admin/deletion-requests/" + formatID(id) + "/" + decision,
+		body:   dto.ReviewDeletionRequest{Note: note},
+	}
+
+	deletion, err := callData[dto.DeletionRequestResponse](ctx, s.client, req)
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+func (s *AdminService) user(ctx context.Context, req *request) (*dto.UserResponse, error) {
+	user, err := callData[dto.UserResponse](ctx, s.client, req)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *AdminService) usersRequest(options ListOptions) *request {
+	return &request{method: http.MethodGet, path: "/users/admin", query: encodeQuery(options)}
+}
+
+func (s *AdminService) deletionRequestsRequest(options ListOptions) *request {
+	return &request{method: http.MethodGet, path: "/users/admin/deletion-requests", query: encodeQuery(options)}
+}
+
+func listUsers(ctx context.Context, c *Client, req *request) (*dto.UserListResponse, error) {
+	var resp dto.UserListResponse
+	if err := c.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func listDeletionRequests(ctx context.Context, c *Client, req *request) (*dto.DeletionRequestListResponse, error) {
+	var resp dto.DeletionRequestListResponse
+	if err := c.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AuditLogFilter selects audit log entries; zero fields match every entry
+type AuditLogFilter struct {
+	ActorID    uint   `query:"actorId"`
+	Action     string `query:"action"` // e.g. user.verify
+	TargetType string `query:"targetType"`
+	TargetID   string `query:"targetId"`
+	StartDate  string `query:"startDate"` // First day, YYYY-MM-DD
+	EndDate    string `query:"endDate"`   // Last day, YYYY-MM-DD
+}
+
+// AuditService calls the audit log routes under /audit. Each call needs the audit.read permission
+type AuditService struct {
+	client *Client
+}
+
+// List returns a page of the audit log entries matching a filter, newest first
+func (s *AuditService) List(ctx context.Context, filter AuditLogFilter, options dto.PaginationRequest) (*dto.AuditLogListResponse, error) {
+	return listAuditLogs(ctx, s.client, s.listRequest(filter, options))
+}
+
+// All iterates over the audit log entries matching a filter, newest first, from the page of options
+func (s *AuditService) All(ctx context.Context, filter AuditLogFilter, options dto.PaginationRequest) iter.Seq2[dto.AuditLogResponse, error] {
+	return paginate(ctx, s.listRequest(filter, options), func(ctx context.Context, req *request) (page[dto.AuditLogResponse], error) {
+		resp, err := listAuditLogs(ctx, s.client, req)
+		if err != nil {
+			return page[dto.AuditLogResponse]{}, err
+		}
+		return page[dto.AuditLogResponse]{items: resp.Entries, page: resp.Page, totalPage: resp.TotalPage}, nil
+	})
+}
+
+// Export returns every audit log entry matching a filter, oldest first, as csv or json lines. The caller
+// closes the returned body
+func (s *AuditService) Export(ctx context.Context, format string, filter AuditLogFilter) (io.ReadCloser, error) {
+	query := encodeQuery(filter)
+	if format != "" {
+		query.Set("format", format)
+	}
+	return s.client.download(ctx, &request{method: http.MethodGet, path: "/audit/export", query: query})
+}
+
+// Verify checks the hash chain of the audit log
+func (s *AuditService) Verify(ctx context.Context) (*dto.AuditVerificationResponse, error) {
+	var resp dto.AuditVerificationResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/audit/verify"}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (s *AuditService) listRequest(filter AuditLogFilter, options dto.PaginationRequest) *request {
+	query := encodeQuery(filter)
+	for name, values := range encodeQuery(options) {
+		query[name] = values
+	}
+	return &request{method: http.MethodGet, path: "/audit", query: query}
+}
+
+func listAuditLogs(ctx context.Context, c *Client, req *request) (*dto.AuditLogListResponse, error) {
+	var resp dto.AuditLogListResponse
+	if err := c.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
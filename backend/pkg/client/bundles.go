@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"strconv"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+)
+
+// BundlesService calls the action bundle routes under /bundles
+type BundlesService struct {
+	client *Client
+}
+
+// Create validates a bundle of actions and runs it. Signer bundles are sent by the server in the background;
+// unsigned bundles come back with the transactions for the wallet to sign, in order
+func (s *BundlesService) Create(ctx context.Context, req dto.CreateBundleRequest) (*dto.BundleResponse, error) {
+	bundle, err := callData[dto.BundleResponse](ctx, s.client, &request{method: http.MethodPost, path: "/bundles", body: req, scoped: true})
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// List returns a page of the bundles of the user, newest first
+func (s *BundlesService) List(ctx context.Context, options dto.PaginationRequest) (*dto.BundleListResponse, error) {
+	return listBundles(ctx, s.client, s.listRequest(options))
+}
+
+// All iterates over the bundles of the user, newest first, from the page of options
+func (s *BundlesService) All(ctx context.Context, options dto.PaginationRequest) iter.Seq2[dto.BundleResponse, error] {
+	return paginate(ctx, s.listRequest(options), func(ctx context.Context, req *request) (page[dto.BundleResponse], error) {
+		resp, err := listBundles(ctx, s.client, req)
+		if err != nil {
+			return page[dto.BundleResponse]{}, err
+		}
+		return page[dto.BundleResponse]{items: resp.Bundles, page: resp.Page, totalPage: resp.TotalPage}, nil
+	})
+}
+
+// Get returns a bundle of the user with the status of each step
+func (s *BundlesService) Get(ctx context.Context, id uint) (*dto.BundleResponse, error) {
+	bundle, err := callData[dto.BundleResponse](ctx, s.client, &request{method: http.MethodGet, path: "/bundles/" + formatID(id)})
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// SubmitStep hands over the hash of the transaction the wallet sent for a step of an unsigned bundle
+func (s *BundlesService) SubmitStep(ctx context.Context, id uint, position int, hash string) (*dto.BundleResponse, error) {
+	req := &request{
+		method: http.MethodPost,
+		path:   "/bundles/" + formatID(id) + "/steps/" + strconv.Itoa(position) + "/submit",
+		body:   dto.SubmitBundleStepRequest{Hash: hash},
+	}
+
+	bundle, err := callData[dto.BundleResponse](ctx, s.client, req)
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (s *BundlesService) listRequest(options dto.PaginationRequest) *request {
+	return &request{method: http.MethodGet, path: "/bundles", query: encodeQuery(options)}
+}
+
+func listBundles(ctx context.Context, c *Client, req *request) (*dto.BundleListResponse, error) {
+	var resp dto.BundleListResponse
+	if err := c.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,479 @@
+// Package client is a Go SDK for the lending and borrowing REST API. It signs in with Sign-In with Ethereum or
+// an API key, renews access tokens before they expire, retries requests that failed before the server acted on
+// them, and returns failures as *Error carrying the stable error code of the API
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apiPrefix is the path every versioned route is under
+	apiPrefix = "/api/v1"
+	// apiKeyHeader is the header API keys are sent in
+	apiKeyHeader = "X-API-Key"
+	// IdempotencyKeyHeader is the header identifying the attempts of a request that changes state.
+	// Every retry of a request carries the same key, and the API answers them with the response to the first
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// refreshMargin is how long before it expires an access token is renewed
+	refreshMargin = 30 * time.Second
+	// defaultTimeout bounds a single attempt of a request
+	defaultTimeout = 30 * time.Second
+)
+
+// Client calls the REST API. It is safe for concurrent use
+type Client struct {
+	baseURL    string
+	market     string // Prefixes market-scoped routes with /markets/{market} when set
+	httpClient *http.Client
+	retry      RetryPolicy
+	apiKey     string
+	userAgent  string
+
+	// Session tokens are shared with the clients returned by Market
+	session *session
+
+	Users        *UsersService
+	Addresses    *AddressesService
+	APIKeys      *APIKeysService
+	Privacy      *PrivacyService
+	Admin        *AdminService
+	Audit        *AuditService
+	Lending      *LendingService
+	Borrowing    *BorrowingService
+	Collateral   *CollateralService
+	Liquidation  *LiquidationService
+	Solvency     *SolvencyService
+	Positions    *PositionsService
+	Transactions *TransactionsService
+	Bundles      *BundlesService
+	Markets      *MarketsService
+	GraphQL      *GraphQLService
+	Live         *LiveService
+}
+
+// RetryPolicy decides how often and how long apart failed requests are tried again
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts of a request, including the first; 1 disables retries
+	MinBackoff  time.Duration // Wait before the first retry, doubled for each following one
+	MaxBackoff  time.Duration // Longest wait between attempts, Retry-After included
+}
+
+// DefaultRetryPolicy tries a request up to three times, waiting from half a second up to ten seconds in between
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// Option configures a client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates every request with an API key instead of a session
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithTokens resumes a session from tokens obtained earlier, e.g. by another process
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) {
+		c.session.tokens = tokens
+	}
+}
+
+// WithTokenHandler sets a function called with the tokens of the session whenever they change, to persist them
+func WithTokenHandler(handler func(Tokens)) Option {
+	return func(c *Client) {
+		c.session.onChange = handler
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithUserAgent sets the User-Agent of requests, which the API shows as the device of sessions
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client for the API served at baseURL, e.g. https://api.example.com
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retry:      DefaultRetryPolicy,
+		userAgent:  "lending-borrowing-go-client",
+		session:    &session{},
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.bindServices()
+	return c
+}
+
+// Market returns a client whose lending, borrowing, collateral, liquidation, solvency and bundle calls operate on
+// the market with the given identifier rather than the default market. It shares the session of c
+func (c *Client) Market(identifier string) *Client {
+	scoped := *c
+	scoped.market = identifier
+	scoped.bindServices()
+	return &scoped
+}
+
+func (c *Client) bindServices() {
+	c.Users = &UsersService{c}
+	c.Addresses = &AddressesService{c}
+	c.APIKeys = &APIKeysService{c}
+	c.Privacy = &PrivacyService{c}
+	c.Admin = &AdminService{c}
+	c.Audit = &AuditService{c}
+	c.Lending = &LendingService{c}
+	c.Borrowing = &BorrowingService{c}
+	c.Collateral = &CollateralService{c}
+	c.Liquidation = &LiquidationService{c}
+	c.Solvency = &SolvencyService{c}
+	c.Positions = &PositionsService{c}
+	c.Transactions = &TransactionsService{c}
+	c.Bundles = &BundlesService{c}
+	c.Markets = &MarketsService{c}
+	c.GraphQL = &GraphQLService{c}
+	c.Live = &LiveService{c}
+}
+
+// Tokens are the tokens of a session
+type Tokens struct {
+	SessionID        string
+	AccessToken      string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// Tokens returns the tokens of the current session, empty when signed out
+func (c *Client) Tokens() Tokens {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	return c.session.tokens
+}
+
+// session holds the tokens of the signed-in user. Renewals are serialized, so that a refresh token
+// is only used once even when concurrent requests find the access token expired
+type session struct {
+	mu       sync.Mutex // Guards tokens
+	renewing sync.Mutex // Held while the tokens are renewed
+	tokens   Tokens
+	onChange func(Tokens)
+}
+
+func (s *session) set(tokens Tokens) {
+	s.mu.Lock()
+	s.tokens = tokens
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	if onChange != nil {
+		onChange(tokens)
+	}
+}
+
+// envelope is the standard response body, with data of type T
+type envelope[T any] struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Data    T      `json:"data,omitempty"`
+}
+
+// request describes a call to the API
+type request struct {
+	method string
+	path   string     // Under apiPrefix unless absolute is set
+	query  url.Values // Optional
+	body   any        // Encoded as JSON when not nil
+
+	scoped   bool // Under /markets/{market} when the client has a market
+	absolute bool // Not under apiPrefix, e.g. /.well-known/jwks.json
+	public   bool // Sent without credentials, and never renews the session
+}
+
+// url returns the URL of a request
+func (c *Client) url(req *request) string {
+	path := req.path
+	if req.scoped && c.market != "" {
+		path = "/markets/" + url.PathEscape(c.market) + path
+	}
+	if !req.absolute {
+		path = apiPrefix + path
+	}
+
+	if len(req.query) > 0 {
+		path += "?" + req.query.Encode()
+	}
+	return c.baseURL + path
+}
+
+// call sends a request and decodes its JSON response into out, unless out is nil
+func (c *Client) call(ctx context.Context, req *request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// callData sends a request answered with the standard envelope and returns its data
+func callData[T any](ctx context.Context, c *Client, req *request) (T, error) {
+	var resp envelope[T]
+	err := c.call(ctx, req, &resp)
+	return resp.Data, err
+}
+
+// send sends a request, renewing the session and retrying as needed, and returns the successful response.
+// The caller closes its body
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("failed to encode %s %s request: %w", req.method, req.path, err)
+		}
+	}
+
+	// Every attempt of a request that changes state carries the same key
+	var idempotencyKey string
+	if req.method != http.MethodGet {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		if !req.public {
+			if err := c.ensureFresh(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.attempt(ctx, req, body, idempotencyKey)
+		if err == nil && resp.StatusCode < 400 {
+			return resp, nil
+		}
+
+		var apiErr *Error
+		if err == nil {
+			apiErr = decodeError(resp)
+			err = apiErr
+		}
+
+		// An access token rejected before it expired was revoked or rotated; renew it once
+		if apiErr != nil && apiErr.StatusCode == http.StatusUnauthorized && !req.public && !refreshed && c.canRefresh() {
+			refreshed = true
+			if refreshErr := c.refresh(ctx); refreshErr != nil {
+				return nil, err
+			}
+			attempt--
+			continue
+		}
+
+		if attempt >= c.retry.MaxAttempts || !c.retryable(req, err) {
+			return nil, err
+		}
+
+		wait := c.backoff(attempt)
+		if apiErr != nil && apiErr.RetryAfter > 0 {
+			wait = min(apiErr.RetryAfter, c.retry.MaxBackoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt sends a request once
+func (c *Client) attempt(ctx context.Context, req *request, body []byte, idempotencyKey string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.url(req), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		httpReq.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+
+	if !req.public {
+		if c.apiKey != "" {
+			httpReq.Header.Set(apiKeyHeader, c.apiKey)
+		} else if token := c.Tokens().AccessToken; token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// retryable reports whether a failed request may be sent again. Reads, and authenticated requests that change
+// state, which the API deduplicates by their Idempotency-Key, are retried after any network error or server
+// failure. Public requests that change state are only retried when the server cannot have acted on them: the
+// connection was never made, or the request was throttled or turned away as unavailable
+func (c *Client) retryable(req *request, err error) bool {
+	deduplicated := req.method == http.MethodGet || !req.public
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return true
+		case http.StatusBadGateway, http.StatusGatewayTimeout:
+			return deduplicated
+		case http.StatusConflict:
+			// The first attempt is still being served; the next one gets its response
+			return apiErr.Code == CodeIdempotencyKeyInUse
+		}
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if deduplicated {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// backoff returns how long to wait after a failed attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := time.Duration(float64(c.retry.MinBackoff) * math.Pow(2, float64(attempt-1)))
+	if wait <= 0 || wait > c.retry.MaxBackoff {
+		return c.retry.MaxBackoff
+	}
+	return wait
+}
+
+// canRefresh reports whether the session has a refresh token that may still be used
+func (c *Client) canRefresh() bool {
+	if c.apiKey != "" {
+		return false
+	}
+
+	tokens := c.Tokens()
+	return tokens.RefreshToken != "" && (tokens.RefreshExpiresAt.IsZero() || time.Now().Before(tokens.RefreshExpiresAt))
+}
+
+// ensureFresh renews the access token when it is about to expire
+func (c *Client) ensureFresh(ctx context.Context) error {
+	tokens := c.Tokens()
+	if tokens.AccessToken == "" || tokens.ExpiresAt.IsZero() || time.Until(tokens.ExpiresAt) > refreshMargin || !c.canRefresh() {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+// refresh renews the tokens of the session. Concurrent callers wait for a single renewal
+func (c *Client) refresh(ctx context.Context) error {
+	before := c.Tokens()
+
+	// Another request may have renewed the tokens while this one waited
+	c.session.renewing.Lock()
+	defer c.session.renewing.Unlock()
+	if c.Tokens().RefreshToken != before.RefreshToken {
+		return nil
+	}
+
+	resp, err := c.Users.Refresh(ctx, before.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	c.session.set(Tokens{
+		SessionID:        resp.SessionID,
+		AccessToken:      resp.Token,
+		ExpiresAt:        resp.ExpiresAt,
+		RefreshToken:     resp.RefreshToken,
+		RefreshExpiresAt: resp.RefreshExpiresAt,
+	})
+	return nil
+}
+
+// newIdempotencyKey returns a random key identifying the attempts of a request
+func newIdempotencyKey() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// TransactionHash is the result of a call that sent a transaction
+type TransactionHash struct {
+	TransactionHash string `json:"transactionHash"`
+}
+
+// download sends a request answered with a file and returns its body, which the caller closes
+func (c *Client) download(ctx context.Context, req *request) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// formatID formats the ID of a resource for its path
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
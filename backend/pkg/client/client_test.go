@@ -0,0 +1,500 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/middleware"
+	"github.com/Mattouff/Lending-Borrowing/internal/api/routes"
+	"github.com/Mattouff/Lending-Borrowing/internal/config"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/models"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// testUser is the only account of the stubbed services
+var testUser = &models.User{ID: 1, Address: "0x71C7656EC7ab88b098defB751B7401B5f6d8976F", Role: models.RoleUser}
+
+// authStub issues sign-in challenges and sessions for the test user, rotating its tokens on every refresh
+type authStub struct {
+	service.AuthService
+
+	mu        sync.Mutex
+	message   string          // Sign-in message last issued
+	valid     map[string]bool // Access tokens currently accepted
+	refresh   string          // Refresh token currently accepted
+	issued    int
+	refreshes int
+}
+
+func (s *authStub) CreateSignInChallenge(ctx context.Context, address string) (*service.SignInChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = "localhost wants you to sign in with your Ethereum account:\n" + address + "\n\nNonce: 1"
+	return &service.SignInChallenge{Nonce: "1", Message: s.message, ExpiresAt: time.Now().Add(time.Minute)}, nil
+}
+
+func (s *authStub) VerifySignIn(ctx context.Context, message, signature string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if message != s.message {
+		return "", service.ErrInvalidSignIn
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return "", service.ErrInvalidSignature
+	}
+	sig[crypto.RecoveryIDOffset] -= 27
+	key, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return "", service.ErrInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*key).Hex(), nil
+}
+
+func (s *authStub) CreateSession(ctx context.Context, user *models.User, device, ip string) (*service.TokenPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issue(), nil
+}
+
+func (s *authStub) RefreshSession(ctx context.Context, refreshToken, ip string) (*service.TokenPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if refreshToken == "" || refreshToken != s.refresh {
+		return nil, service.ErrInvalidRefreshToken
+	}
+	s.refreshes++
+	return s.issue(), nil
+}
+
+func (s *authStub) ValidateToken(ctx context.Context, tokenString string) (*models.User, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.valid[tokenString] {
+		return nil, "", errors.New("invalid token")
+	}
+	return testUser, "session", nil
+}
+
+// issue rotates the tokens of the session, revoking the previous access token
+func (s *authStub) issue() *service.TokenPair {
+	s.issued++
+	access := fmt.Sprintf("access-%d", s.issued)
+	s.valid = map[string]bool{access: true}
+	s.refresh = fmt.Sprintf("refresh-%d", s.issued)
+	return &service.TokenPair{
+		SessionID:        "session",
+		AccessToken:      access,
+		AccessExpiresAt:  time.Now().Add(time.Hour),
+		RefreshToken:     s.refresh,
+		RefreshExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+}
+
+// userStub finds the test user
+type userStub struct {
+	service.UserService
+	address string // Address the test user signs in with
+}
+
+func (s *userStub) GetByAddress(ctx context.Context, address string) (*models.User, error) {
+	if address != s.address {
+		return nil, nil
+	}
+	user := *testUser
+	user.Address = address
+	return &user, nil
+}
+
+func (s *userStub) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	return testUser, nil
+}
+
+// transactionStub lists transactions newest first, by page or by cursor
+type transactionStub struct {
+	service.TransactionService
+	transactions []*models.Transaction
+	cursorPages  int
+}
+
+func (s *transactionStub) ListTransactions(ctx context.Context, filter models.TransactionFilter, offset, limit int) ([]*models.Transaction, error) {
+	return s.transactions[min(offset, len(s.transactions)):min(offset+limit, len(s.transactions))], nil
+}
+
+func (s *transactionStub) CountTransactions(ctx context.Context, filter models.TransactionFilter) (int64, error) {
+	return int64(len(s.transactions)), nil
+}
+
+func (s *transactionStub) ListTransactionsByCursor(ctx context.Context, filter models.TransactionFilter, cursor *models.Cursor, limit int) ([]*models.Transaction, error) {
+	s.cursorPages++
+	start := slices.IndexFunc(s.transactions, func(tx *models.Transaction) bool {
+		return tx.CreatedAt.Before(cursor.CreatedAt) || (tx.CreatedAt.Equal(cursor.CreatedAt) && tx.ID < cursor.ID)
+	})
+	if start < 0 {
+		return nil, nil
+	}
+	return s.transactions[start:min(start+limit, len(s.transactions))], nil
+}
+
+// bundleStub lists bundles by page, and has none to get
+type bundleStub struct {
+	service.BundleService
+	bundles []*models.Bundle
+	offsets []int
+}
+
+func (s *bundleStub) ListBundles(ctx context.Context, userID uint, offset, limit int) ([]*models.Bundle, error) {
+	s.offsets = append(s.offsets, offset)
+	return s.bundles[min(offset, len(s.bundles)):min(offset+limit, len(s.bundles))], nil
+}
+
+func (s *bundleStub) CountBundles(ctx context.Context, userID uint) (int64, error) {
+	return int64(len(s.bundles)), nil
+}
+
+func (s *bundleStub) GetBundle(ctx context.Context, userID, bundleID uint) (*models.Bundle, error) {
+	return nil, service.ErrBundleNotFound
+}
+
+// borrowingStub refuses every borrow as over the limit of the collateral
+type borrowingStub struct {
+	service.BorrowingService
+}
+
+func (s *borrowingStub) Borrow(ctx context.Context, market string, userAddress common.Address, amount *big.Int) (string, error) {
+	return "", service.ErrBorrowLimitExceeded.WithDetails(map[string]any{"maxBorrowable": "750"})
+}
+
+// testServices are the stubbed services behind a test server
+type testServices struct {
+	auth         *authStub
+	users        *userStub
+	transactions *transactionStub
+	bundles      *bundleStub
+}
+
+func newTestServices() *testServices {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var transactions []*models.Transaction
+	var bundles []*models.Bundle
+	for id := uint(5); id >= 1; id-- {
+		transactions = append(transactions, &models.Transaction{ID: id, UserID: testUser.ID, CreatedAt: start.Add(time.Duration(id) * time.Hour)})
+		bundles = append(bundles, &models.Bundle{ID: id, UserID: testUser.ID})
+	}
+
+	return &testServices{
+		auth:         &authStub{},
+		users:        &userStub{},
+		transactions: &transactionStub{transactions: transactions},
+		bundles:      &bundleStub{bundles: bundles},
+	}
+}
+
+// newTestServer serves the routes of the API over the stubbed services
+func newTestServer(t *testing.T, stubs *testServices) *httptest.Server {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Pagination.CursorSecret = "test"
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler()})
+	routes.SetupRoutes(app, &routes.Services{
+		AuthService:        stubs.auth,
+		UserService:        stubs.users,
+		TransactionService: stubs.transactions,
+		BundleService:      stubs.bundles,
+		BorrowingService:   &borrowingStub{},
+	}, &routes.Repositories{}, cfg)
+
+	server := httptest.NewServer(adaptor.FiberApp(app))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// signedIn returns a client holding a session of the test user
+func signedIn(t *testing.T, server *httptest.Server, stubs *testServices) *Client {
+	t.Helper()
+
+	stubs.auth.mu.Lock()
+	tokens := stubs.auth.issue()
+	stubs.auth.mu.Unlock()
+
+	return New(server.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithTokens(Tokens{
+		SessionID:        tokens.SessionID,
+		AccessToken:      tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}))
+}
+
+func TestLogin(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewKeySigner(key)
+
+	stubs := newTestServices()
+	stubs.users.address = signer.Address().Hex()
+	c := New(newTestServer(t, stubs).URL)
+
+	resp, err := c.Users.Login(context.Background(), signer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.User.Address != signer.Address().Hex() || resp.Token != "access-1" {
+		t.Errorf("got user %s with token %q, want %s with access-1", resp.User.Address, resp.Token, signer.Address().Hex())
+	}
+	if tokens := c.Tokens(); tokens.AccessToken != "access-1" || tokens.RefreshToken != "refresh-1" {
+		t.Errorf("got session %+v, want the tokens of the sign-in kept", tokens)
+	}
+
+	// The session authenticates the following requests
+	if _, err := c.Users.Profile(context.Background()); err != nil {
+		t.Errorf("unexpected error after sign-in: %v", err)
+	}
+}
+
+func TestLoginWithAnotherWallet(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No account is registered for the address of the wallet
+	stubs := newTestServices()
+	stubs.users.address = testUser.Address
+	c := New(newTestServer(t, stubs).URL)
+
+	_, err = c.Users.Login(context.Background(), NewKeySigner(key))
+	if !IsCode(err, CodeNotFound) {
+		t.Fatalf("got %v, want a %s error", err, CodeNotFound)
+	}
+	if tokens := c.Tokens(); tokens.AccessToken != "" {
+		t.Errorf("got session %+v, want none", tokens)
+	}
+}
+
+func TestRefreshOnUnauthorized(t *testing.T) {
+	tests := []struct {
+		name          string
+		refreshToken  string
+		wantErr       string // Error code, empty on success
+		wantRefreshes int
+	}{
+		{name: "renewed once", refreshToken: "refresh-1", wantRefreshes: 1},
+		{name: "refresh token revoked", refreshToken: "revoked", wantErr: CodeUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubs := newTestServices()
+			stubs.auth.mu.Lock()
+			stubs.auth.issue()
+			stubs.auth.mu.Unlock()
+
+			// The access token was revoked before it expired
+			var saved []Tokens
+			c := New(newTestServer(t, stubs).URL,
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+				WithTokenHandler(func(tokens Tokens) { saved = append(saved, tokens) }),
+				WithTokens(Tokens{
+					AccessToken:      "revoked",
+					ExpiresAt:        time.Now().Add(time.Hour),
+					RefreshToken:     tt.refreshToken,
+					RefreshExpiresAt: time.Now().Add(time.Hour),
+				}))
+
+			_, err := c.Users.Profile(context.Background())
+			if tt.wantErr != "" {
+				if !IsCode(err, tt.wantErr) {
+					t.Fatalf("got %v, want a %s error", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if stubs.auth.refreshes != tt.wantRefreshes {
+				t.Errorf("got %d refreshes, want %d", stubs.auth.refreshes, tt.wantRefreshes)
+			}
+			if tt.wantRefreshes > 0 && (len(saved) != 1 || saved[0].AccessToken != "access-2" || c.Tokens().RefreshToken != "refresh-2") {
+				t.Errorf("got saved tokens %+v, want the renewed pair", saved)
+			}
+		})
+	}
+}
+
+func TestPaginationIterators(t *testing.T) {
+	tests := []struct {
+		name  string
+		all   func(c *Client) iter.Seq2[uint, error]
+		pages func(stubs *testServices) string
+		want  string
+	}{
+		{
+			name: "cursor links",
+			all: func(c *Client) iter.Seq2[uint, error] {
+				return ids(c.Transactions.All(context.Background(), dto.TransactionListRequest{PaginationRequest: dto.PaginationRequest{PageSize: 2}}),
+					func(tx dto.TransactionResponse) uint { return tx.ID })
+			},
+			pages: func(stubs *testServices) string { return fmt.Sprint(stubs.transactions.cursorPages) },
+			want:  "2",
+		},
+		{
+			name: "page numbers",
+			all: func(c *Client) iter.Seq2[uint, error] {
+				return ids(c.Bundles.All(context.Background(), dto.PaginationRequest{PageSize: 2}),
+					func(bundle dto.BundleResponse) uint { return bundle.ID })
+			},
+			pages: func(stubs *testServices) string { return fmt.Sprint(stubs.bundles.offsets) },
+			want:  "[0 2 4]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubs := newTestServices()
+			server := newTestServer(t, stubs)
+			c := signedIn(t, server, stubs)
+
+			var got []uint
+			for id, err := range tt.all(c) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				got = append(got, id)
+			}
+
+			if want := []uint{5, 4, 3, 2, 1}; !slices.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			if pages := tt.pages(stubs); pages != tt.want {
+				t.Errorf("got pages %s, want %s", pages, tt.want)
+			}
+		})
+	}
+}
+
+// ids maps the items of a list iterator to their IDs
+func ids[T any](items iter.Seq2[T, error], id func(T) uint) iter.Seq2[uint, error] {
+	return func(yield func(uint, error) bool) {
+		for item, err := range items {
+			if !yield(id(item), err) {
+				return
+			}
+		}
+	}
+}
+
+func TestPaginationIteratorStopsOnError(t *testing.T) {
+	stubs := newTestServices()
+	c := New(newTestServer(t, stubs).URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+	var errs []error
+	for _, err := range c.Bundles.All(context.Background(), dto.PaginationRequest{}) {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 1 || !IsCode(errs[0], CodeUnauthorized) {
+		t.Errorf("got %v, want a single %s error", errs, CodeUnauthorized)
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		signedIn    bool
+		call        func(c *Client) error
+		wantStatus  int
+		wantCode    string
+		wantIs      error
+		wantDetails map[string]any
+		wantInvalid string // Field reported by ValidationErrors
+	}{
+		{
+			name:     "service error with details",
+			signedIn: true,
+			call: func(c *Client) error {
+				_, err := c.Borrowing.Borrow(context.Background(), big.NewInt(1000))
+				return err
+			},
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    string(service.CodeBorrowLimitExceeded),
+			wantIs:      service.ErrBorrowLimitExceeded,
+			wantDetails: map[string]any{"maxBorrowable": "750"},
+		},
+		{
+			name:        "invalid field",
+			signedIn:    true,
+			call:        func(c *Client) error { _, err := c.Borrowing.Borrow(context.Background(), big.NewInt(0)); return err },
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    CodeValidationFailed,
+			wantInvalid: "amount",
+		},
+		{
+			name:       "not found",
+			signedIn:   true,
+			call:       func(c *Client) error { _, err := c.Bundles.Get(context.Background(), 7); return err },
+			wantStatus: http.StatusNotFound,
+			wantCode:   string(service.CodeBundleNotFound),
+			wantIs:     service.ErrBundleNotFound,
+		},
+		{
+			name:       "signed out",
+			call:       func(c *Client) error { _, err := c.Users.Profile(context.Background()); return err },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   CodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubs := newTestServices()
+			server := newTestServer(t, stubs)
+			c := New(server.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+			if tt.signedIn {
+				c = signedIn(t, server, stubs)
+			}
+
+			var apiErr *Error
+			if err := tt.call(c); !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an *Error", err)
+			}
+
+			if apiErr.StatusCode != tt.wantStatus || apiErr.Code != tt.wantCode || apiErr.Message == "" {
+				t.Errorf("got %d %s %q, want %d %s with a message", apiErr.StatusCode, apiErr.Code, apiErr.Message, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantIs != nil && !errors.Is(apiErr, tt.wantIs) {
+				t.Errorf("got %v, want it to match %v", apiErr, tt.wantIs)
+			}
+			for field, want := range tt.wantDetails {
+				if apiErr.Details[field] != want {
+					t.Errorf("got details %v, want %s = %v", apiErr.Details, field, want)
+				}
+			}
+			if tt.wantInvalid != "" && apiErr.ValidationErrors()[tt.wantInvalid] == "" {
+				t.Errorf("got invalid fields %v, want %s", apiErr.ValidationErrors(), tt.wantInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// maxErrorBody bounds how much of an error response is read
+const maxErrorBody = 1 << 20
+
+// Error codes of failures that do not come from a service; every other code is a service.ErrorCode
+const (
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeInternalError    = "INTERNAL_ERROR"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeForbidden        = "FORBIDDEN"
+	CodeTooManyRequests  = "TOO_MANY_REQUESTS"
+
+	// A request repeating an Idempotency-Key while its first attempt is served, or with another method, path or body
+	CodeIdempotencyKeyInUse  = "IDEMPOTENCY_KEY_IN_USE"
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// Error is a failure answered by the API
+type Error struct {
+	StatusCode int
+	Code       string         // Stable code to act on, e.g. BORROW_LIMIT_EXCEEDED
+	Message    string         // Safe to show to users
+	Details    map[string]any // e.g. the maximum amount, or the message of each invalid field
+	RetryAfter time.Duration  // When throttled, how long the API asked to wait
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// Is matches service errors with the same code, so that errors.Is(err, service.ErrBorrowLimitExceeded)
+// holds for the error the API answered with
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *service.Error:
+		return string(t.Code) == e.Code
+	case *Error:
+		return t.Code == e.Code
+	}
+	return false
+}
+
+// IsCode reports whether err is an error answered by the API with the given code
+func IsCode(err error, code string) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// ValidationErrors returns the message of each invalid field of a request rejected with VALIDATION_FAILED,
+// keyed by the field path, e.g. actions[1].amount
+func (e *Error) ValidationErrors() map[string]string {
+	if e.Code != CodeValidationFailed {
+		return nil
+	}
+
+	fields := make(map[string]string, len(e.Details))
+	for field, message := range e.Details {
+		if text, ok := message.(string); ok {
+			fields[field] = text
+		}
+	}
+	return fields
+}
+
+// decodeError reads the error of a failed response and closes its body
+func decodeError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var body dto.ErrorResponse
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err := json.Unmarshal(data, &body); err == nil {
+		apiErr.Code = body.Code
+		apiErr.Details = body.Details
+		if body.Message != "" {
+			apiErr.Message = body.Message
+		} else if body.Error != "" {
+			apiErr.Message = body.Error
+		}
+	}
+
+	return apiErr
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+)
+
+// ListOptions are the filters and page of transaction histories. Type and status take comma-separated values,
+// and the dates, YYYY-MM-DD, bound the window
+type ListOptions struct {
+	dto.PaginationRequest
+	dto.FilterRequest
+}
+
+// LendingService calls the lending pool routes under /lending
+type LendingService struct {
+	client *Client
+}
+
+// Deposit deposits tokens in the lending pool
+func (s *LendingService) Deposit(ctx context.Context, amount *big.Int) (string, error) {
+	return sendTransaction(ctx, s.client, "/lending/deposit", dto.TransactionRequest{Amount: amount.String()})
+}
+
+// Withdraw withdraws tokens from the lending pool
+func (s *LendingService) Withdraw(ctx context.Context, amount *big.Int) (string, error) {
+	return sendTransaction(ctx, s.client, "/lending/withdraw", dto.TransactionRequest{Amount: amount.String()})
+}
+
+// Balance returns the tokens the user deposited, summed over every linked address when aggregate is set
+func (s *LendingService) Balance(ctx context.Context, aggregate bool) (*big.Int, error) {
+	resp, err := callData[struct {
+		Balance string `json:"balance"`
+	}](ctx, s.client, &request{method: http.MethodGet, path: "/lending/balance", query: aggregateQuery(aggregate), scoped: true})
+	if err != nil {
+		return nil, err
+	}
+	return parseAmount(resp.Balance)
+}
+
+// Info returns the deposits and interest of the user, summed over every linked address when aggregate is set
+func (s *LendingService) Info(ctx context.Context, aggregate bool) (*dto.LendingInfoResponse, error) {
+	var resp dto.LendingInfoResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/lending/info", query: aggregateQuery(aggregate), scoped: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PoolInfo returns the tokens deposited in the pool and its interest rate
+func (s *LendingService) PoolInfo(ctx context.Context) (*PoolInfo, error) {
+	resp, err := callData[PoolInfo](ctx, s.client, &request{method: http.MethodGet, path: "/lending/pool-info", scoped: true, public: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Transactions returns a page of the lending transactions of the user
+func (s *LendingService) Transactions(ctx context.Context, options ListOptions) (*dto.TransactionListResponse, error) {
+	return listTransactions(ctx, s.client, s.transactionsRequest(options))
+}
+
+// AllTransactions iterates over the lending transactions of the user, from the page of options
+func (s *LendingService) AllTransactions(ctx context.Context, options ListOptions) iter.Seq2[dto.TransactionResponse, error] {
+	return paginate(ctx, s.transactionsRequest(options), transactionPage(s.client))
+}
+
+func (s *LendingService) transactionsRequest(options ListOptions) *request {
+	return &request{method: http.MethodGet, path: "/lending/transactions", query: encodeQuery(options), scoped: true}
+}
+
+// PoolInfo represents the state of the lending pool
+type PoolInfo struct {
+	TotalDeposited string `json:"totalDeposited"`
+	InterestRate   string `json:"interestRate"`
+}
+
+// BorrowingService calls the borrowing routes under /borrowing
+type BorrowingService struct {
+	client *Client
+}
+
+// Borrow borrows tokens against the collateral of the user
+func (s *BorrowingService) Borrow(ctx context.Context, amount *big.Int) (string, error) {
+	return sendTransaction(ctx, s.client, "/borrowing/borrow", dto.TransactionRequest{Amount: amount.String()})
+}
+
+// Repay repays borrowed tokens
+func (s *BorrowingService) Repay(ctx context.Context, amount *big.Int) (string, error) {
+	return sendTransaction(ctx, s.client, "/borrowing/repay", dto.TransactionRequest{Amount: amount.String()})
+}
+
+// Balance returns the tokens the user borrowed, summed over every linked address when aggregate is set
+func (s *BorrowingService) Balance(ctx context.Context, aggregate bool) (*big.Int, error) {
+	resp, err := callData[struct {
+		BorrowedAmount string `json:"borrowedAmount"`
+	}](ctx, s.client, &request{method: http.MethodGet, path: "/borrowing/balance", query: aggregateQuery(aggregate), scoped: true})
+	if err != nil {
+		return nil, err
+	}
+	return parseAmount(resp.BorrowedAmount)
+}
+
+// Info returns the debt and interest of the user, summed over every linked address when aggregate is set
+func (s *BorrowingService) Info(ctx context.Context, aggregate bool) (*dto.BorrowingInfoResponse, error) {
+	var resp dto.BorrowingInfoResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/borrowing/info", query: aggregateQuery(aggregate), scoped: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Stats returns the tokens borrowed from the market and its interest rate
+func (s *BorrowingService) Stats(ctx context.Context) (*BorrowingStats, error) {
+	resp, err := callData[BorrowingStats](ctx, s.client, &request{method: http.MethodGet, path: "/borrowing/stats", scoped: true, public: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Transactions returns a page of the borrowing transactions of the user
+func (s *BorrowingService) Transactions(ctx context.Context, options ListOptions) (*dto.TransactionListResponse, error) {
+	return listTransactions(ctx, s.client, s.transactionsRequest(options))
+}
+
+// AllTransactions iterates over the borrowing transactions of the user, from the page of options
+func (s *BorrowingService) AllTransactions(ctx context.Context, options ListOptions) iter.Seq2[dto.TransactionResponse, error] {
+	return paginate(ctx, s.transactionsRequest(options), transactionPage(s.client))
+}
+
+func (s *BorrowingService) transactionsRequest(options ListOptions) *request {
+	return &request{method: http.MethodGet, path: "/borrowing/transactions", query: encodeQuery(options), scoped: true}
+}
+
+// BorrowingStats represents the borrowing activity of a market
+type BorrowingStats struct {
+	TotalBorrowed string `json:"totalBorrowed"`
+	InterestRate  string `json:"interestRate"`
+}
+
+// CollateralService calls the collateral routes under /collateral
+type CollateralService struct {
+	client *Client
+}
+
+// Deposit deposits collateral
+func (s *CollateralService) Deposit(ctx context.Context, amount *big.Int) (string, error) {
+	return sendTransaction(ctx, s.client, "/collateral/deposit", dto.TransactionRequest{Amount: amount.String()})
+}
+
+// Withdraw withdraws collateral
+func (s *CollateralService) Withdraw(ctx context.Context, amount *big.Int) (string, error) {
+	return sendTransaction(ctx, s.client, "/collateral/withdraw", dto.TransactionRequest{Amount: amount.String()})
+}
+
+// Balance returns the collateral of the user, summed over every linked address when aggregate is set
+func (s *CollateralService) Balance(ctx context.Context, aggregate bool) (*big.Int, error) {
+	resp, err := callData[struct {
+		CollateralBalance string `json:"collateralBalance"`
+	}](ctx, s.client, &request{method: http.MethodGet, path: "/collateral/balance", query: aggregateQuery(aggregate), scoped: true})
+	if err != nil {
+		return nil, err
+	}
+	return parseAmount(resp.CollateralBalance)
+}
+
+// Info returns the collateral, collateral ratio and borrowing capacity of the user
+func (s *CollateralService) Info(ctx context.Context) (*dto.CollateralInfoResponse, error) {
+	var resp dto.CollateralInfoResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/collateral/info", scoped: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Reconciliation compares the collateral held by the contract with the indexed balances; admins only
+func (s *CollateralService) Reconciliation(ctx context.Context) (*dto.CollateralReconciliationResponse, error) {
+	var resp dto.CollateralReconciliationResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/collateral/admin/reconciliation", scoped: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Transactions returns a page of the collateral transactions of the user
+func (s *CollateralService) Transactions(ctx context.Context, options ListOptions) (*dto.TransactionListResponse, error) {
+	return listTransactions(ctx, s.client, s.transactionsRequest(options))
+}
+
+// AllTransactions iterates over the collateral transactions of the user, from the page of options
+func (s *CollateralService) AllTransactions(ctx context.Context, options ListOptions) iter.Seq2[dto.TransactionResponse, error] {
+	return paginate(ctx, s.transactionsRequest(options), transactionPage(s.client))
+}
+
+func (s *CollateralService) transactionsRequest(options ListOptions) *request {
+	return &request{method: http.MethodGet, path: "/collateral/transactions", query: encodeQuery(options), scoped: true}
+}
+
+// LiquidationService calls the liquidation routes under /liquidation
+type LiquidationService struct {
+	client *Client
+}
+
+// Liquidate repays part of the debt of an under-collateralized borrower in exchange for their collateral
+func (s *LiquidationService) Liquidate(ctx context.Context, borrower common.Address, amount *big.Int) (string, error) {
+	return sendTransaction(ctx, s.client, "/liquidation/liquidate", dto.TransactionLiquidationRequest{
+		BorrowerAddress: borrower.Hex(),
+		Amount:          amount.String(),
+	})
+}
+
+// Positions returns the positions that can be liquidated
+func (s *LiquidationService) Positions(ctx context.Context) ([]dto.LiquidatablePositionResponse, error) {
+	var resp dto.LiquidatablePositionsResponse
+	err := s.client.call(ctx, &request{method: http.MethodGet, path: "/liquidation/positions", scoped: true, public: true}, &resp)
+	return resp.Positions, err
+}
+
+// History returns a page of the liquidations of the market
+func (s *LiquidationService) History(ctx context.Context, options ListOptions) (*dto.TransactionListResponse, error) {
+	return listTransactions(ctx, s.client, s.historyRequest(options))
+}
+
+// AllHistory iterates over the liquidations of the market, from the page of options
+func (s *LiquidationService) AllHistory(ctx context.Context, options ListOptions) iter.Seq2[dto.TransactionResponse, error] {
+	return paginate(ctx, s.historyRequest(options), transactionPage(s.client))
+}
+
+func (s *LiquidationService) historyRequest(options ListOptions) *request {
+	return &request{method: http.MethodGet, path: "/liquidation/history", query: encodeQuery(options), scoped: true, public: true}
+}
+
+// Bonus returns the share of the repaid debt liquidators receive on top in collateral
+func (s *LiquidationService) Bonus(ctx context.Context) (*big.Int, error) {
+	resp, err := callData[struct {
+		LiquidationBonus string `json:"liquidationBonus"`
+	}](ctx, s.client, &request{method: http.MethodGet, path: "/liquidation/bonus", scoped: true, public: true})
+	if err != nil {
+		return nil, err
+	}
+	return parseAmount(resp.LiquidationBonus)
+}
+
+// SolvencyService calls the protocol solvency routes under /solvency
+type SolvencyService struct {
+	client *Client
+}
+
+// Report returns the current solvency of the market
+func (s *SolvencyService) Report(ctx context.Context) (*dto.SolvencyReportResponse, error) {
+	var resp dto.SolvencyReportResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/solvency/report", scoped: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// History returns the solvency snapshots of the market taken from startDate through endDate, both YYYY-MM-DD.
+// Empty dates default to the last 24 hours
+func (s *SolvencyService) History(ctx context.Context, startDate, endDate string) (*dto.SolvencyHistoryResponse, error) {
+	query := encodeQuery(dto.FilterRequest{StartDate: startDate, EndDate: endDate})
+
+	var resp dto.SolvencyHistoryResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/solvency/history", query: query, scoped: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// sendTransaction posts a request that sends a transaction and returns its hash
+func sendTransaction(ctx context.Context, c *Client, path string, body any) (string, error) {
+	resp, err := callData[TransactionHash](ctx, c, &request{method: http.MethodPost, path: path, body: body, scoped: true})
+	return resp.TransactionHash, err
+}
+
+// aggregateQuery asks balance and info routes to sum over every address linked to the account
+func aggregateQuery(aggregate bool) url.Values {
+	if !aggregate {
+		return nil
+	}
+	return url.Values{"aggregate": {strconv.FormatBool(aggregate)}}
+}
+
+// parseAmount parses a decimal amount of token units
+func parseAmount(value string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q in response", value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+	"github.com/Mattouff/Lending-Borrowing/internal/domain/service"
+)
+
+// MarketsService calls the public market data routes, along with the health check and the signing keys of
+// access tokens
+type MarketsService struct {
+	client *Client
+}
+
+// List returns the active markets
+func (s *MarketsService) List(ctx context.Context) ([]dto.MarketResponse, error) {
+	var resp dto.MarketsResponse
+	err := s.client.call(ctx, &request{method: http.MethodGet, path: "/markets", public: true}, &resp)
+	return resp.Markets, err
+}
+
+// Overview returns the TVL and rates of the market of the client, or of the default market
+func (s *MarketsService) Overview(ctx context.Context) (*dto.MarketOverviewResponse, error) {
+	var query url.Values
+	if s.client.market != "" {
+		query = url.Values{"market": {s.client.market}}
+	}
+
+	var resp dto.MarketOverviewResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/market/overview", query: query, public: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Tokens returns the market data of the protocol tokens
+func (s *MarketsService) Tokens(ctx context.Context) ([]dto.TokenMarketData, error) {
+	var resp dto.TokensMarketResponse
+	err := s.client.call(ctx, &request{method: http.MethodGet, path: "/market/tokens", public: true}, &resp)
+	return resp.Tokens, err
+}
+
+// JWKS returns the public keys access tokens may be signed with
+func (s *MarketsService) JWKS(ctx context.Context) (*dto.JSONWebKeySet, error) {
+	var resp dto.JSONWebKeySet
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/.well-known/jwks.json", absolute: true, public: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Health checks that the API is up
+func (s *MarketsService) Health(ctx context.Context) error {
+	return s.client.call(ctx, &request{method: http.MethodGet, path: "/api/health", absolute: true, public: true}, nil)
+}
+
+// GraphQLService calls the GraphQL endpoint
+type GraphQLService struct {
+	client *Client
+}
+
+// Query runs a GraphQL query. Fields the query could not resolve are null in data, with an error naming their
+// path; they do not fail the call
+func (s *GraphQLService) Query(ctx context.Context, req dto.GraphQLRequest) (*dto.GraphQLResponse, error) {
+	var resp dto.GraphQLResponse
+	if err := s.client.call(ctx, &request{method: http.MethodPost, path: "/graphql", body: req}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ErrLiveFeedClosed is returned when the server closes the live feed, e.g. because the client fell behind
+var ErrLiveFeedClosed = errors.New("live feed closed by the server")
+
+// LiveService subscribes to the live feed over Server-Sent Events. The WebSocket route sends the same events
+type LiveService struct {
+	client *Client
+}
+
+// Events streams the live events of some topics, or of every topic when none are given. Iteration ends when
+// the context is done, the loop breaks or the feed fails, with the error yielded
+func (s *LiveService) Events(ctx context.Context, topics ...service.LiveTopic) iter.Seq2[service.LiveEvent, error] {
+	return func(yield func(service.LiveEvent, error) bool) {
+		names := make([]string, len(topics))
+		for i, topic := range topics {
+			names[i] = string(topic)
+		}
+		var query url.Values
+		if len(names) > 0 {
+			query = url.Values{"topics": {strings.Join(names, ",")}}
+		}
+
+		// The stream stays open for as long as the caller reads it, past the timeout of other requests
+		stream := *s.client
+		httpClient := *s.client.httpClient
+		httpClient.Timeout = 0
+		stream.httpClient = &httpClient
+
+		body, err := stream.download(ctx, &request{method: http.MethodGet, path: "/live/events", query: query})
+		if err != nil {
+			yield(service.LiveEvent{}, err)
+			return
+		}
+		defer body.Close()
+
+		var name, data string
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+				continue
+			case strings.HasPrefix(line, "data:"):
+				data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+				continue
+			case line != "":
+				// Comments, such as heartbeats
+				continue
+			}
+
+			// A blank line ends an event
+			eventName, eventData := name, data
+			name, data = "", ""
+			if eventData == "" {
+				continue
+			}
+
+			if eventName == "close" {
+				yield(service.LiveEvent{}, fmt.Errorf("%w: %s", ErrLiveFeedClosed, eventData))
+				return
+			}
+
+			var event service.LiveEvent
+			if err := json.Unmarshal([]byte(eventData), &event); err != nil {
+				yield(service.LiveEvent{}, fmt.Errorf("failed to decode live event: %w", err))
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+
+		err = scanner.Err()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err == nil {
+			err = ErrLiveFeedClosed
+		}
+		yield(service.LiveEvent{}, err)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// page is one page of a list response
+type page[T any] struct {
+	items     []T
+	page      int
+	totalPage int
+	next      string // Link to the following page of cursor lists
+}
+
+// paginate iterates over every item of a list, from the page first asks for. Lists with cursor links are followed
+// link by link, others page number by page number. Iteration stops at the first error, which is yielded
+func paginate[T any](ctx context.Context, first *request, fetch func(context.Context, *request) (page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		req := first
+		for {
+			p, err := fetch(ctx, req)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range p.items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			switch {
+			case p.next != "":
+				req = req.follow(p.next)
+			case len(p.items) > 0 && p.page < p.totalPage:
+				req = req.withPage(p.page + 1)
+			default:
+				return
+			}
+		}
+	}
+}
+
+// follow returns the request of a next or prev link, which holds the full path and query
+func (r *request) follow(link string) *request {
+	path, rawQuery, _ := strings.Cut(link, "?")
+	query, _ := url.ParseQuery(rawQuery)
+
+	return &request{
+		method:   r.method,
+		path:     path,
+		query:    query,
+		absolute: true,
+		public:   r.public,
+	}
+}
+
+// withPage returns the request of another page of a list
+func (r *request) withPage(number int) *request {
+	next := *r
+	next.query = maps.Clone(r.query)
+	if next.query == nil {
+		next.query = url.Values{}
+	}
+	next.query.Set("page", strconv.Itoa(number))
+	return &next
+}
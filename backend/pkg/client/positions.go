@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+)
+
+// PositionsService calls the position routes under /positions
+type PositionsService struct {
+	client *Client
+}
+
+// List returns a page of the positions of the user
+func (s *PositionsService) List(ctx context.Context, options dto.PositionListRequest) (*dto.PositionListResponse, error) {
+	return listPositions(ctx, s.client, s.listRequest("/positions", options))
+}
+
+// All iterates over the positions of the user, from the page of options
+func (s *PositionsService) All(ctx context.Context, options dto.PositionListRequest) iter.Seq2[dto.PositionResponse, error] {
+	return paginate(ctx, s.listRequest("/positions", options), positionPage(s.client))
+}
+
+// Get returns a position of the user along with the transactions that changed it
+func (s *PositionsService) Get(ctx context.Context, id uint) (*dto.PositionDetailResponse, error) {
+	return s.get(ctx, "/positions/"+formatID(id))
+}
+
+// ListAll returns a page of the positions of every user; admins only
+func (s *PositionsService) ListAll(ctx context.Context, options dto.PositionListRequest) (*dto.PositionListResponse, error) {
+	return listPositions(ctx, s.client, s.listRequest("/positions/admin", options))
+}
+
+// AllOfEveryone iterates over the positions of every user, from the page of options; admins only
+func (s *PositionsService) AllOfEveryone(ctx context.Context, options dto.PositionListRequest) iter.Seq2[dto.PositionResponse, error] {
+	return paginate(ctx, s.listRequest("/positions/admin", options), positionPage(s.client))
+}
+
+// GetAny returns any position along with the transactions that changed it; admins only
+func (s *PositionsService) GetAny(ctx context.Context, id uint) (*dto.PositionDetailResponse, error) {
+	return s.get(ctx, "/positions/admin/"+formatID(id))
+}
+
+func (s *PositionsService) get(ctx context.Context, path string) (*dto.PositionDetailResponse, error) {
+	position, err := callData[dto.PositionDetailResponse](ctx, s.client, &request{method: http.MethodGet, path: path})
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+func (s *PositionsService) listRequest(path string, options dto.PositionListRequest) *request {
+	return &request{method: http.MethodGet, path: path, query: encodeQuery(options)}
+}
+
+func listPositions(ctx context.Context, c *Client, req *request) (*dto.PositionListResponse, error) {
+	var resp dto.PositionListResponse
+	if err := c.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func positionPage(c *Client) func(context.Context, *request) (page[dto.PositionResponse], error) {
+	return func(ctx context.Context, req *request) (page[dto.PositionResponse], error) {
+		resp, err := listPositions(ctx, c, req)
+		if err != nil {
+			return page[dto.PositionResponse]{}, err
+		}
+		return page[dto.PositionResponse]{items: resp.Positions, page: resp.Page, totalPage: resp.TotalPage, next: resp.Next}, nil
+	}
+}
+
+// TransactionsService calls the transaction routes under /transactions
+type TransactionsService struct {
+	client *Client
+}
+
+// List returns a page of the transactions of the user
+func (s *TransactionsService) List(ctx context.Context, options dto.TransactionListRequest) (*dto.TransactionListResponse, error) {
+	return listTransactions(ctx, s.client, s.listRequest(options))
+}
+
+// All iterates over the transactions of the user, from the page of options
+func (s *TransactionsService) All(ctx context.Context, options dto.TransactionListRequest) iter.Seq2[dto.TransactionResponse, error] {
+	return paginate(ctx, s.listRequest(options), transactionPage(s.client))
+}
+
+// Get returns the transactions of the user with a hash, one for each role the user played in it
+func (s *TransactionsService) Get(ctx context.Context, hash string) ([]dto.TransactionResponse, error) {
+	return callData[[]dto.TransactionResponse](ctx, s.client, &request{method: http.MethodGet, path: "/transactions/" + url.PathEscape(hash)})
+}
+
+func (s *TransactionsService) listRequest(options dto.TransactionListRequest) *request {
+	return &request{method: http.MethodGet, path: "/transactions", query: encodeQuery(options)}
+}
+
+func listTransactions(ctx context.Context, c *Client, req *request) (*dto.TransactionListResponse, error) {
+	var resp dto.TransactionListResponse
+	if err := c.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func transactionPage(c *Client) func(context.Context, *request) (page[dto.TransactionResponse], error) {
+	return func(ctx context.Context, req *request) (page[dto.TransactionResponse], error) {
+		resp, err := listTransactions(ctx, c, req)
+		if err != nil {
+			return page[dto.TransactionResponse]{}, err
+		}
+		return page[dto.TransactionResponse]{items: resp.Transactions, page: resp.Page, totalPage: resp.TotalPage, next: resp.Next}, nil
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+)
+
+// PrivacyService calls the personal data export and account purge routes under /users. They take a session;
+// API keys are turned away
+type PrivacyService struct {
+	client *Client
+}
+
+// CreateExport requests an export of the personal data of the user, built in the background
+func (s *PrivacyService) CreateExport(ctx context.Context, req dto.CreateDataExportRequest) (*dto.DataExportResponse, error) {
+	export, err := callData[dto.DataExportResponse](ctx, s.client, &request{method: http.MethodPost, path: "/users/exports", body: req})
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ListExports returns the personal data exports of the user
+func (s *PrivacyService) ListExports(ctx context.Context) ([]dto.DataExportResponse, error) {
+	resp, err := callData[dto.DataExportListResponse](ctx, s.client, &request{method: http.MethodGet, path: "/users/exports"})
+	return resp.Exports, err
+}
+
+// GetExport returns a personal data export of the user
+func (s *PrivacyService) GetExport(ctx context.Context, id uint) (*dto.DataExportResponse, error) {
+	export, err := callData[dto.DataExportResponse](ctx, s.client, &request{method: http.MethodGet, path: "/users/exports/" + formatID(id)})
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// DownloadExport returns the archive of a ready personal data export, which the caller closes
+func (s *PrivacyService) DownloadExport(ctx context.Context, id uint) (io.ReadCloser, error) {
+	return s.client.download(ctx, &request{method: http.MethodGet, path: "/users/exports/" + formatID(id) + "/download"})
+}
+
+// RequestDeletion asks for the account of the user to be purged once an admin approves
+func (s *PrivacyService) RequestDeletion(ctx context.Context, req dto.CreateDeletionRequest) (*dto.DeletionRequestResponse, error) {
+	deletion, err := callData[dto.DeletionRequestResponse](ctx, s.client, &request{method: http.MethodPost, path: "/users/deletion-request", body: req})
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// GetDeletionRequest returns the latest deletion request of the user
+func (s *PrivacyService) GetDeletionRequest(ctx context.Context) (*dto.DeletionRequestResponse, error) {
+	deletion, err := callData[dto.DeletionRequestResponse](ctx, s.client, &request{method: http.MethodGet, path: "/users/deletion-request"})
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
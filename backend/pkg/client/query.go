@@ -0,0 +1,66 @@
+package client
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// encodeQuery encodes the fields of a struct with query tags, such as the list requests of the API DTOs, as query
+// parameters. Zero values are left out and embedded structs are flattened
+func encodeQuery(v any) url.Values {
+	query := url.Values{}
+	if v != nil {
+		addQuery(query, reflect.ValueOf(v))
+	}
+	return query
+}
+
+func addQuery(query url.Values, value reflect.Value) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+
+		if field.Anonymous {
+			addQuery(query, fieldValue)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name == "" || name == "-" || !field.IsExported() || fieldValue.IsZero() {
+			continue
+		}
+
+		if encoded, ok := queryValue(fieldValue); ok {
+			query.Set(name, encoded)
+		}
+	}
+}
+
+// queryValue formats a query parameter
+func queryValue(value reflect.Value) (string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true
+	case reflect.Pointer:
+		return queryValue(value.Elem())
+	}
+	return "", false
+}
@@ -0,0 +1,274 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"net/http"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Mattouff/Lending-Borrowing/internal/api/dto"
+)
+
+// Signer signs Sign-In with Ethereum messages and address link challenges for a wallet
+type Signer interface {
+	// Address returns the address of the wallet
+	Address() common.Address
+	// SignMessage returns the EIP-191 personal signature of a message, as personal_sign does
+	SignMessage(message []byte) ([]byte, error)
+}
+
+// keySigner signs with a private key held in memory
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewKeySigner returns a signer for the wallet of a private key
+func NewKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &keySigner{key: key}
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) SignMessage(message []byte) ([]byte, error) {
+	signature, err := crypto.Sign(accounts.TextHash(message), s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wallets return recovery IDs of 27 or 28
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// UsersService calls the account and session routes under /users
+type UsersService struct {
+	client *Client
+}
+
+// Register creates an account for an address
+func (s *UsersService) Register(ctx context.Context, req dto.UserRegistrationRequest) (*dto.UserResponse, error) {
+	user, err := callData[dto.UserResponse](ctx, s.client, &request{method: http.MethodPost, path: "/users/register", body: req, public: true})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Nonce returns a single-use sign-in nonce for an address, along with the message to sign with it
+func (s *UsersService) Nonce(ctx context.Context, address common.Address) (*dto.NonceResponse, error) {
+	var resp dto.NonceResponse
+	if err := s.client.call(ctx, &request{method: http.MethodGet, path: "/users/nonce/" + address.Hex(), public: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Authenticate signs in with a signed Sign-In with Ethereum message and keeps the session on the client
+func (s *UsersService) Authenticate(ctx context.Context, message, signature string) (*dto.AuthResponse, error) {
+	var resp dto.AuthResponse
+	req := &request{
+		method: http.MethodPost,
+		path:   "/users/auth",
+		body:   dto.UserAuthRequest{Message: message, Signature: signature},
+		public: true,
+	}
+	if err := s.client.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+
+	s.client.session.set(Tokens{
+		SessionID:        resp.SessionID,
+		AccessToken:      resp.Token,
+		ExpiresAt:        resp.ExpiresAt,
+		RefreshToken:     resp.RefreshToken,
+		RefreshExpiresAt: resp.RefreshExpiresAt,
+	})
+	return &resp, nil
+}
+
+// Login signs in with a wallet: it asks for a nonce, signs the message it comes with and authenticates with it
+func (s *UsersService) Login(ctx context.Context, signer Signer) (*dto.AuthResponse, error) {
+	nonce, err := s.Nonce(ctx, signer.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.SignMessage([]byte(nonce.Message))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Authenticate(ctx, nonce.Message, hexutil.Encode(signature))
+}
+
+// Refresh renews a token pair. Clients renew their own session on their own; this is for tokens held elsewhere
+func (s *UsersService) Refresh(ctx context.Context, refreshToken string) (*dto.TokenResponse, error) {
+	var resp dto.TokenResponse
+	req := &request{
+		method: http.MethodPost,
+		path:   "/users/refresh",
+		body:   dto.RefreshRequest{RefreshToken: refreshToken},
+		public: true,
+	}
+	if err := s.client.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Logout revokes the current session and forgets its tokens
+func (s *UsersService) Logout(ctx context.Context) error {
+	if err := s.client.call(ctx, &request{method: http.MethodPost, path: "/users/logout"}, nil); err != nil {
+		return err
+	}
+	s.client.session.set(Tokens{})
+	return nil
+}
+
+// LogoutAll revokes every session of the user and forgets the tokens of the current one
+func (s *UsersService) LogoutAll(ctx context.Context) error {
+	if err := s.client.call(ctx, &request{method: http.MethodPost, path: "/users/logout-all"}, nil); err != nil {
+		return err
+	}
+	s.client.session.set(Tokens{})
+	return nil
+}
+
+// ListSessions returns the sessions of the user
+func (s *UsersService) ListSessions(ctx context.Context) ([]dto.SessionResponse, error) {
+	resp, err := callData[dto.SessionListResponse](ctx, s.client, &request{method: http.MethodGet, path: "/users/sessions"})
+	return resp.Sessions, err
+}
+
+// RevokeSession revokes a session of the user
+func (s *UsersService) RevokeSession(ctx context.Context, sessionID string) error {
+	return s.client.call(ctx, &request{method: http.MethodDelete, path: "/users/sessions/" + url.PathEscape(sessionID)}, nil)
+}
+
+// Profile returns the profile of the user
+func (s *UsersService) Profile(ctx context.Context) (*dto.UserResponse, error) {
+	user, err := callData[dto.UserResponse](ctx, s.client, &request{method: http.MethodGet, path: "/users/profile"})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile updates the profile of the user
+func (s *UsersService) UpdateProfile(ctx context.Context, req dto.UserUpdateRequest) (*dto.UserResponse, error) {
+	user, err := callData[dto.UserResponse](ctx, s.client, &request{method: http.MethodPut, path: "/users/profile", body: req})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteAccount deletes the account of the user and forgets the tokens of the session
+func (s *UsersService) DeleteAccount(ctx context.Context) error {
+	if err := s.client.call(ctx, &request{method: http.MethodDelete, path: "/users/account"}, nil); err != nil {
+		return err
+	}
+	s.client.session.set(Tokens{})
+	return nil
+}
+
+// AddressesService calls the linked address routes under /users/addresses
+type AddressesService struct {
+	client *Client
+}
+
+// List returns the addresses linked to the account
+func (s *AddressesService) List(ctx context.Context) ([]dto.UserAddressResponse, error) {
+	var resp dto.UserAddressListResponse
+	err := s.client.call(ctx, &request{method: http.MethodGet, path: "/users/addresses"}, &resp)
+	return resp.Addresses, err
+}
+
+// CreateChallenge returns the message both the primary address and the address to link must sign
+func (s *AddressesService) CreateChallenge(ctx context.Context, address common.Address) (*dto.LinkChallengeResponse, error) {
+	var resp dto.LinkChallengeResponse
+	req := &request{
+		method: http.MethodPost,
+		path:   "/users/addresses/challenge",
+		body:   dto.LinkChallengeRequest{Address: address.Hex()},
+	}
+	if err := s.client.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Link links an address with a challenge signed by both addresses
+func (s *AddressesService) Link(ctx context.Context, req dto.LinkAddressRequest) (*dto.UserAddressResponse, error) {
+	address, err := callData[dto.UserAddressResponse](ctx, s.client, &request{method: http.MethodPost, path: "/users/addresses", body: req})
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// LinkWith links the address of a wallet, signing the challenge with the primary wallet and the wallet to link
+func (s *AddressesService) LinkWith(ctx context.Context, primary, linked Signer) (*dto.UserAddressResponse, error) {
+	challenge, err := s.CreateChallenge(ctx, linked.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	primarySignature, err := primary.SignMessage([]byte(challenge.Message))
+	if err != nil {
+		return nil, err
+	}
+	addressSignature, err := linked.SignMessage([]byte(challenge.Message))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Link(ctx, dto.LinkAddressRequest{
+		Address:          linked.Address().Hex(),
+		Nonce:            challenge.Nonce,
+		PrimarySignature: hexutil.Encode(primarySignature),
+		AddressSignature: hexutil.Encode(addressSignature),
+	})
+}
+
+// SetPrimary makes a linked address the primary address of the account
+func (s *AddressesService) SetPrimary(ctx context.Context, address common.Address) error {
+	return s.client.call(ctx, &request{method: http.MethodPut, path: "/users/addresses/" + address.Hex() + "/primary"}, nil)
+}
+
+// Unlink unlinks an address from the account
+func (s *AddressesService) Unlink(ctx context.Context, address common.Address) error {
+	return s.client.call(ctx, &request{method: http.MethodDelete, path: "/users/addresses/" + address.Hex()}, nil)
+}
+
+// APIKeysService calls the API key routes under /users/api-keys
+type APIKeysService struct {
+	client *Client
+}
+
+// Create issues an API key. Its plaintext value is only returned here
+func (s *APIKeysService) Create(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	key, err := callData[dto.CreateAPIKeyResponse](ctx, s.client, &request{method: http.MethodPost, path: "/users/api-keys", body: req})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List returns the API keys of the user
+func (s *APIKeysService) List(ctx context.Context) ([]dto.APIKeyResponse, error) {
+	resp, err := callData[dto.APIKeyListResponse](ctx, s.client, &request{method: http.MethodGet, path: "/users/api-keys"})
+	return resp.APIKeys, err
+}
+
+// Revoke revokes an API key
+func (s *APIKeysService) Revoke(ctx context.Context, id uint) error {
+	return s.client.call(ctx, &request{method: http.MethodDelete, path: "/users/api-keys/" + formatID(id)}, nil)
+}
@@ -27,6 +27,8 @@ func Connect(dsn string) (*gorm.DB, error) {
 		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
 			PrepareStmt: false,
 			Logger:      logger.Default.LogMode(logMode),
+			// Unique violations surface as gorm.ErrDuplicatedKey, answered with a 409
+			TranslateError: true,
 		})
 
 		if err == nil {
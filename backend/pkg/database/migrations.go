@@ -7,16 +7,66 @@ import (
 	"gorm.io/gorm"
 )
 
+// auditLogImmutableSQL rejects any update or deletion of audit log entries
+var auditLogImmutableSQL = []string{
+	`CREATE OR REPLACE FUNCTION audit_logs_immutable() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_logs is append-only';
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS audit_logs_immutable ON audit_logs`,
+	`CREATE TRIGGER audit_logs_immutable BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_immutable()`,
+}
+
+// backfillPrimaryAddressesSQL links the address of every user without linked addresses as its primary address.
+// Deleted users are skipped, since purged ones no longer have an address
+const backfillPrimaryAddressesSQL = `INSERT INTO user_addresses (user_id, address, is_primary, created_at)
+	SELECT id, address, true, created_at FROM users
+	WHERE deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM user_addresses WHERE user_addresses.user_id = users.id)`
+
+// dropTransactionHashUniqueSQL lets a transaction hash have one ledger entry per role, under the constraint
+// names given by older and newer GORM versions
+var dropTransactionHashUniqueSQL = []string{
+	`ALTER TABLE IF EXISTS transactions DROP CONSTRAINT IF EXISTS transactions_hash_key`,
+	`ALTER TABLE IF EXISTS transactions DROP CONSTRAINT IF EXISTS uni_transactions_hash`,
+}
+
+// backfillLiquidatorRoleSQL marks liquidations recorded before ledger roles existed as liquidator entries,
+// the borrower entry having always failed on the unique hash
+const backfillLiquidatorRoleSQL = `UPDATE transactions SET role = 'liquidator' WHERE type = 'liquidate' AND role = 'owner'`
+
+// marketScopedTables hold rows that belonged to the only market before markets were introduced
+var marketScopedTables = []string{"positions", "transactions"}
+
 // MigrateDB runs database migrations to create or update tables
 func MigrateDB(db *gorm.DB) error {
 	log.Println("Running database migrations...")
 
+	// Transaction hashes are no longer unique on their own
+	for _, statement := range dropTransactionHashUniqueSQL {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Printf("Migration failed: %v", err)
+			return err
+		}
+	}
+
 	// List all models that should be migrated
 	// Order matters for foreign key dependencies
 	err := db.AutoMigrate(
 		&models.User{},
+		&models.UserAddress{},
+		&models.Market{},
 		&models.Transaction{},
 		&models.Position{},
+		&models.PriceSample{},
+		&models.SolvencySnapshot{},
+		&models.APIKey{},
+		&models.AuditLog{},
+		&models.DataExport{},
+		&models.DeletionRequest{},
+		&models.Bundle{},
+		&models.BundleStep{},
 	)
 
 	if err != nil {
@@ -24,10 +74,47 @@ func MigrateDB(db *gorm.DB) error {
 		return err
 	}
 
+	// Every user has a primary address, including those registered before addresses could be linked
+	if err := db.Exec(backfillPrimaryAddressesSQL).Error; err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
+	if err := db.Exec(backfillLiquidatorRoleSQL).Error; err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
+	// Audit log entries can only be appended, even by direct SQL
+	for _, statement := range auditLogImmutableSQL {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Printf("Migration failed: %v", err)
+			return err
+		}
+	}
+
 	log.Println("Database migration completed successfully")
 	return nil
 }
 
+// BackfillMarketIDs assigns the positions and transactions recorded before markets existed to the default
+// market, then requires every row to have a market. Runs once the configured markets are registered
+func BackfillMarketIDs(db *gorm.DB, defaultMarketID uint) error {
+	for _, table := range marketScopedTables {
+		if err := db.Exec("UPDATE "+table+" SET market_id = ? WHERE market_id IS NULL OR market_id = 0", defaultMarketID).Error; err != nil {
+			log.Printf("Market backfill failed: %v", err)
+			return err
+		}
+
+		if err := db.Exec("ALTER TABLE " + table + " ALTER COLUMN market_id SET NOT NULL").Error; err != nil {
+			log.Printf("Market backfill failed: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
 // SeedDB seeds the database with initial data if needed
 func SeedDB(db *gorm.DB) error {
 	// Check if admin user exists
@@ -59,8 +146,18 @@ func ResetDB(db *gorm.DB) error {
 	log.Println("WARNING: Resetting database (all data will be lost)...")
 
 	err := db.Migrator().DropTable(
+		&models.BundleStep{},
+		&models.Bundle{},
+		&models.DeletionRequest{},
+		&models.DataExport{},
+		&models.AuditLog{},
+		&models.APIKey{},
+		&models.SolvencySnapshot{},
+		&models.PriceSample{},
 		&models.Position{},
 		&models.Transaction{},
+		&models.Market{},
+		&models.UserAddress{},
 		&models.User{},
 	)
 
@@ -0,0 +1,274 @@
+package siwe
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Version is the only EIP-4361 message version
+const Version = "1"
+
+const headerSuffix = " wants you to sign in with your Ethereum account:"
+
+var (
+	// ErrMalformedMessage is returned when a message does not follow the EIP-4361 format
+	ErrMalformedMessage = errors.New("malformed SIWE message")
+	// ErrInvalidSignature is returned when a signature was not made by the message address
+	ErrInvalidSignature = errors.New("invalid SIWE signature")
+)
+
+// Message is an EIP-4361 Sign-In with Ethereum message
+type Message struct {
+	Domain         string
+	Address        common.Address
+	Statement      string // Optional
+	URI            string
+	Version        string
+	ChainID        int
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time // Optional
+	NotBefore      *time.Time // Optional
+	RequestID      string     // Optional
+	Resources      []string   // Optional
+}
+
+// GenerateNonce returns a random alphanumeric nonce
+func GenerateNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// String renders the message in the EIP-4361 format, as it must be signed
+func (m *Message) String() string {
+	var b strings.Builder
+
+	b.WriteString(m.Domain + headerSuffix + "\n")
+	b.WriteString(m.Address.Hex() + "\n")
+	b.WriteString("\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "URI: %s\n", m.URI)
+	fmt.Fprintf(&b, "Version: %s\n", m.Version)
+	fmt.Fprintf(&b, "Chain ID: %d\n", m.ChainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&b, "Issued At: %s", m.IssuedAt.UTC().Format(time.RFC3339))
+	if m.ExpirationTime != nil {
+		fmt.Fprintf(&b, "\nExpiration Time: %s", m.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	if m.NotBefore != nil {
+		fmt.Fprintf(&b, "\nNot Before: %s", m.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, resource := range m.Resources {
+			b.WriteString("\n- " + resource)
+		}
+	}
+
+	return b.String()
+}
+
+// ParseMessage parses an EIP-4361 message
+func ParseMessage(raw string) (*Message, error) {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	if len(lines) < 3 {
+		return nil, ErrMalformedMessage
+	}
+
+	domain, found := strings.CutSuffix(lines[0], headerSuffix)
+	if !found || domain == "" {
+		return nil, fmt.Errorf("%w: invalid header", ErrMalformedMessage)
+	}
+
+	if !common.IsHexAddress(lines[1]) || !strings.HasPrefix(lines[1], "0x") {
+		return nil, fmt.Errorf("%w: invalid address", ErrMalformedMessage)
+	}
+
+	if lines[2] != "" {
+		return nil, fmt.Errorf("%w: expected an empty line after the address", ErrMalformedMessage)
+	}
+
+	message := &Message{
+		Domain:  domain,
+		Address: common.HexToAddress(lines[1]),
+	}
+
+	// The statement is optional and surrounded by empty lines
+	rest := lines[3:]
+	if len(rest) > 0 && !strings.HasPrefix(rest[0], "URI: ") {
+		if len(rest) < 2 || rest[1] != "" {
+			return nil, fmt.Errorf("%w: expected an empty line after the statement", ErrMalformedMessage)
+		}
+		message.Statement = rest[0]
+		rest = rest[2:]
+	}
+
+	fields := &fieldReader{lines: rest}
+
+	var err error
+	if message.URI, err = fields.required("URI"); err != nil {
+		return nil, err
+	}
+	if message.Version, err = fields.required("Version"); err != nil {
+		return nil, err
+	}
+	if message.Version != Version {
+		return nil, fmt.Errorf("%w: unsupported version %s", ErrMalformedMessage, message.Version)
+	}
+
+	chainID, err := fields.required("Chain ID")
+	if err != nil {
+		return nil, err
+	}
+	if message.ChainID, err = strconv.Atoi(chainID); err != nil {
+		return nil, fmt.Errorf("%w: invalid chain ID", ErrMalformedMessage)
+	}
+
+	if message.Nonce, err = fields.required("Nonce"); err != nil {
+		return nil, err
+	}
+	if len(message.Nonce) < 8 || !isAlphanumeric(message.Nonce) {
+		return nil, fmt.Errorf("%w: nonce must be at least 8 alphanumeric characters", ErrMalformedMessage)
+	}
+
+	issuedAt, err := fields.required("Issued At")
+	if err != nil {
+		return nil, err
+	}
+	if message.IssuedAt, err = time.Parse(time.RFC3339, issuedAt); err != nil {
+		return nil, fmt.Errorf("%w: invalid issued at time", ErrMalformedMessage)
+	}
+
+	if value, ok := fields.optional("Expiration Time"); ok {
+		expirationTime, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid expiration time", ErrMalformedMessage)
+		}
+		message.ExpirationTime = &expirationTime
+	}
+
+	if value, ok := fields.optional("Not Before"); ok {
+		notBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid not before time", ErrMalformedMessage)
+		}
+		message.NotBefore = &notBefore
+	}
+
+	if value, ok := fields.optional("Request ID"); ok {
+		message.RequestID = value
+	}
+
+	if fields.header("Resources:") {
+		for fields.more() {
+			resource, found := strings.CutPrefix(fields.next(), "- ")
+			if !found {
+				return nil, fmt.Errorf("%w: invalid resource", ErrMalformedMessage)
+			}
+			message.Resources = append(message.Resources, resource)
+		}
+	}
+
+	if fields.more() {
+		return nil, fmt.Errorf("%w: unexpected content after the fields", ErrMalformedMessage)
+	}
+
+	return message, nil
+}
+
+// SignatureVerifier checks that an EIP-191 personal signature of a message was made by an address
+type SignatureVerifier interface {
+	VerifyPersonalSignature(ctx context.Context, signer common.Address, message, signature []byte) (bool, error)
+}
+
+// VerifySignature checks that a signature of the raw message was made by its address.
+// The verifier decides which kinds of accounts can sign, e.g. EOAs and EIP-1271 contract wallets
+func (m *Message) VerifySignature(ctx context.Context, raw, signature string, verifier SignatureVerifier) error {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) == 0 {
+		return fmt.Errorf("%w: signature must be a non-empty hex string", ErrInvalidSignature)
+	}
+
+	valid, err := verifier.VerifyPersonalSignature(ctx, m.Address, []byte(raw), sig)
+	if err != nil {
+		return err
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// fieldReader reads the "Name: value" lines of a message in order
+type fieldReader struct {
+	lines []string
+	pos   int
+}
+
+func (r *fieldReader) more() bool {
+	return r.pos < len(r.lines)
+}
+
+func (r *fieldReader) next() string {
+	line := r.lines[r.pos]
+	r.pos++
+	return line
+}
+
+func (r *fieldReader) optional(name string) (string, bool) {
+	if !r.more() {
+		return "", false
+	}
+	value, found := strings.CutPrefix(r.lines[r.pos], name+": ")
+	if !found {
+		return "", false
+	}
+	r.pos++
+	return value, true
+}
+
+func (r *fieldReader) required(name string) (string, error) {
+	value, found := r.optional(name)
+	if !found {
+		return "", fmt.Errorf("%w: missing %s", ErrMalformedMessage, name)
+	}
+	return value, nil
+}
+
+func (r *fieldReader) header(line string) bool {
+	if r.more() && r.lines[r.pos] == line {
+		r.pos++
+		return true
+	}
+	return false
+}
+
+func isAlphanumeric(value string) bool {
+	for _, char := range value {
+		if !(char >= 'a' && char <= 'z') && !(char >= 'A' && char <= 'Z') && !(char >= '0' && char <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package siwe
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testAddress = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+
+// keyVerifier recovers the signer of personal signatures, as for externally owned accounts
+type keyVerifier struct{}
+
+func (keyVerifier) VerifyPersonalSignature(ctx context.Context, signer common.Address, message, signature []byte) (bool, error) {
+	if len(signature) != crypto.SignatureLength {
+		return false, nil
+	}
+	sig := append([]byte{}, signature...)
+	sig[crypto.RecoveryIDOffset] -= 27
+
+	publicKey, err := crypto.SigToPub(accounts.TextHash(message), sig)
+	if err != nil {
+		return false, nil
+	}
+	return crypto.PubkeyToAddress(*publicKey) == signer, nil
+}
+
+// failingVerifier fails as an unreachable node would
+type failingVerifier struct{}
+
+func (failingVerifier) VerifyPersonalSignature(ctx context.Context, signer common.Address, message, signature []byte) (bool, error) {
+	return false, errors.New("node unreachable")
+}
+
+func validMessage() string {
+	return strings.Join([]string{
+		"example.com wants you to sign in with your Ethereum account:",
+		testAddress,
+		"",
+		"Sign in to the lending platform",
+		"",
+		"URI: https://example.com/login",
+		"Version: 1",
+		"Chain ID: 31337",
+		"Nonce: 32891756abcdef12",
+		"Issued At: 2025-01-02T03:04:05Z",
+		"Expiration Time: 2025-01-02T03:14:05Z",
+		"Not Before: 2025-01-02T03:04:00Z",
+		"Request ID: request-1",
+		"Resources:",
+		"- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq",
+		"- https://example.com/terms",
+	}, "\n")
+}
+
+func TestParseMessage(t *testing.T) {
+	message, err := ParseMessage(validMessage())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expiration := time.Date(2025, 1, 2, 3, 14, 5, 0, time.UTC)
+	notBefore := time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC)
+	checks := []struct {
+		field string
+		got   any
+		want  any
+	}{
+		{"domain", message.Domain, "example.com"},
+		{"address", message.Address, common.HexToAddress(testAddress)},
+		{"statement", message.Statement, "Sign in to the lending platform"},
+		{"uri", message.URI, "https://example.com/login"},
+		{"version", message.Version, "1"},
+		{"chain id", message.ChainID, 31337},
+		{"nonce", message.Nonce, "32891756abcdef12"},
+		{"issued at", message.IssuedAt, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"expiration time", *message.ExpirationTime, expiration},
+		{"not before", *message.NotBefore, notBefore},
+		{"request id", message.RequestID, "request-1"},
+		{"resources", len(message.Resources), 2},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s: got %v, want %v", check.field, check.got, check.want)
+		}
+	}
+
+	// The message renders back to what was signed
+	if rendered := message.String(); rendered != validMessage() {
+		t.Errorf("got rendered message\n%s\nwant\n%s", rendered, validMessage())
+	}
+}
+
+func TestParseMessageOptionalFields(t *testing.T) {
+	raw := strings.Join([]string{
+		"example.com wants you to sign in with your Ethereum account:",
+		testAddress,
+		"",
+		"URI: https://example.com",
+		"Version: 1",
+		"Chain ID: 1",
+		"Nonce: abcdefgh",
+		"Issued At: 2025-01-02T03:04:05Z",
+	}, "\r\n")
+
+	message, err := ParseMessage(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if message.Statement != "" || message.ExpirationTime != nil || message.NotBefore != nil || message.RequestID != "" || message.Resources != nil {
+		t.Errorf("got optional fields %+v, want none", message)
+	}
+}
+
+func TestParseMessageMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace [2]string // Turns the valid message into the malformed one
+	}{
+		{name: "missing header", replace: [2]string{"example.com wants you to sign in with your Ethereum account:", "example.com"}},
+		{name: "empty domain", replace: [2]string{"example.com wants", " wants"}},
+		{name: "invalid address", replace: [2]string{testAddress, "0x1234"}},
+		{name: "address without prefix", replace: [2]string{testAddress, strings.TrimPrefix(testAddress, "0x")}},
+		{name: "no empty line after the address", replace: [2]string{testAddress + "\n\n", testAddress + "\n"}},
+		{name: "no empty line after the statement", replace: [2]string{"platform\n\n", "platform\n"}},
+		{name: "missing uri", replace: [2]string{"URI: https://example.com/login\n", ""}},
+		{name: "unsupported version", replace: [2]string{"Version: 1", "Version: 2"}},
+		{name: "invalid chain id", replace: [2]string{"Chain ID: 31337", "Chain ID: mainnet"}},
+		{name: "short nonce", replace: [2]string{"Nonce: 32891756abcdef12", "Nonce: abc"}},
+		{name: "non alphanumeric nonce", replace: [2]string{"Nonce: 32891756abcdef12", "Nonce: 32891756-abcdef"}},
+		{name: "invalid issued at", replace: [2]string{"Issued At: 2025-01-02T03:04:05Z", "Issued At: yesterday"}},
+		{name: "invalid expiration time", replace: [2]string{"Expiration Time: 2025-01-02T03:14:05Z", "Expiration Time: soon"}},
+		{name: "invalid not before", replace: [2]string{"Not Before: 2025-01-02T03:04:00Z", "Not Before: later"}},
+		{name: "fields out of order", replace: [2]string{"Version: 1\nChain ID: 31337", "Chain ID: 31337\nVersion: 1"}},
+		{name: "invalid resource", replace: [2]string{"- https://example.com/terms", "https://example.com/terms"}},
+		{name: "trailing content", replace: [2]string{"- https://example.com/terms", "- https://example.com/terms\nP.S. hi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := strings.Replace(validMessage(), tt.replace[0], tt.replace[1], 1)
+			if raw == validMessage() {
+				t.Fatal("replacement left the message unchanged")
+			}
+			if _, err := ParseMessage(raw); !errors.Is(err, ErrMalformedMessage) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedMessage)
+			}
+		})
+	}
+
+	if _, err := ParseMessage("too short"); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("got error %v for a truncated message, want %v", err, ErrMalformedMessage)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := &Message{
+		Domain:   "example.com",
+		Address:  crypto.PubkeyToAddress(key.PublicKey),
+		URI:      "https://example.com",
+		Version:  Version,
+		ChainID:  31337,
+		Nonce:    "abcdefgh12",
+		IssuedAt: time.Now(),
+	}
+	raw := message.String()
+
+	sign := func(key []byte, text string) string {
+		privateKey, err := crypto.ToECDSA(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := crypto.Sign(accounts.TextHash([]byte(text)), privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature[crypto.RecoveryIDOffset] += 27
+		return hexutil.Encode(signature)
+	}
+
+	tests := []struct {
+		name      string
+		signature string
+		verifier  SignatureVerifier
+		wantErr   error
+		anyErr    bool
+	}{
+		{name: "signed by the address", signature: sign(crypto.FromECDSA(key), raw), verifier: keyVerifier{}},
+		{name: "signed by another key", signature: sign(crypto.FromECDSA(other), raw), verifier: keyVerifier{}, wantErr: ErrInvalidSignature},
+		{name: "signature of another message", signature: sign(crypto.FromECDSA(key), raw+" "), verifier: keyVerifier{}, wantErr: ErrInvalidSignature},
+		{name: "not hex", signature: "signature", verifier: keyVerifier{}, wantErr: ErrInvalidSignature},
+		{name: "empty", signature: "0x", verifier: keyVerifier{}, wantErr: ErrInvalidSignature},
+		{name: "verifier failure is not an invalid signature", signature: sign(crypto.FromECDSA(key), raw), verifier: failingVerifier{}, anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := message.VerifySignature(context.Background(), raw, tt.signature, tt.verifier)
+			switch {
+			case tt.anyErr:
+				if err == nil || errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("got error %v, want the failure of the verifier", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got error %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateNonce(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 100 {
+		nonce, err := GenerateNonce()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(nonce) < 8 || !isAlphanumeric(nonce) {
+			t.Fatalf("got nonce %q, want at least 8 alphanumeric characters", nonce)
+		}
+		if seen[nonce] {
+			t.Fatalf("nonce %q issued twice", nonce)
+		}
+		seen[nonce] = true
+	}
+}
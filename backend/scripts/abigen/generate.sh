@@ -63,5 +63,11 @@ generate_binding "$ABI_DIR/Collateral.abi" \
                 "generated" \
                 "Collateral"
 
+# Chainlink-compatible price feed (AggregatorV3Interface)
+generate_binding "$ABI_DIR/AggregatorV3.abi" \
+                "$OUTPUT_DIR/aggregator_v3.go" \
+                "generated" \
+                "AggregatorV3"
+
 echo "✨ All contract bindings generated successfully!"
 echo "📁 Output directory: $OUTPUT_DIR"
\ No newline at end of file
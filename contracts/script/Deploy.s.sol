@@ -6,6 +6,7 @@ import "../src/Token.sol";
 import "../src/LendingPool.sol";
 import "../src/Borrowing.sol";
 import "../src/Collateral.sol";
+import "../src/PriceAggregator.sol";
 
 contract Deploy is Script {
     // Configuration
@@ -14,12 +15,15 @@ contract Deploy is Script {
     uint256 public constant BORROW_MIN_RATE = 3 * 1e16; // 3% minimum borrow rate
     uint256 public constant BORROW_MAX_RATE = 20 * 1e16; // 20% maximum borrow rate
     uint256 public constant BETA_FACTOR = 1 * 1e18; // Elasticity factor = 1
+    uint8 public constant PRICE_DECIMALS = 8; // Chainlink USD feeds use 8 decimals
+    int256 public constant INITIAL_TOKEN_PRICE = 1 * 1e8; // 1 USD
 
     // Deployed contract addresses
     Token public token;
     LendingPool public lendingPool;
     Borrowing public borrowing;
     Collateral public collateral;
+    PriceAggregator public priceAggregator;
 
     function run() external {
         // Get the private key from the environment variable
@@ -51,6 +55,10 @@ contract Deploy is Script {
         borrowing = new Borrowing(address(token), address(collateral), BORROW_MIN_RATE, BORROW_MAX_RATE, BETA_FACTOR);
         console.log("Borrowing redeployed at:", address(borrowing));
 
+        // Deploy a Chainlink-compatible price feed for the token
+        priceAggregator = new PriceAggregator(PRICE_DECIMALS, "LBT / USD", INITIAL_TOKEN_PRICE);
+        console.log("PriceAggregator deployed at:", address(priceAggregator));
+
         // Create a small buffer of tokens in the LendingPool and Borrowing contracts
         token.transfer(address(lendingPool), 100_000 * 1e18);
         token.transfer(address(borrowing), 100_000 * 1e18);
@@ -84,5 +92,9 @@ contract Deploy is Script {
         );
         console.log("\n--- Contract Addresses for .env ---");
         console.log("CONTRACT_ADDRESSES=", contractAddresses);
+
+        string memory oracleAggregators =
+            string(abi.encodePacked(vm.toString(address(token)), "=", vm.toString(address(priceAggregator))));
+        console.log("ORACLE_AGGREGATORS=", oracleAggregators);
     }
 }
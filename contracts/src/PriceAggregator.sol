@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT
+pragma solidity 0.8.29;
+
+import "./interfaces/AggregatorV3Interface.sol";
+
+/// @title PriceAggregator - A minimal Chainlink-compatible price feed for local and test networks.
+/// @notice The owner pushes new answers; every update opens a new round that can be read through AggregatorV3Interface.
+contract PriceAggregator is AggregatorV3Interface {
+    struct Round {
+        int256 answer;
+        uint256 startedAt;
+        uint256 updatedAt;
+    }
+
+    /// @notice The account allowed to push new answers.
+    address public owner;
+
+    /// @notice Number of decimals of every answer (e.g. 8 means 1e8 = 1 USD).
+    uint8 public immutable override decimals;
+
+    /// @notice Human readable description of the feed (e.g. "LBT / USD").
+    string public override description;
+
+    /// @notice Identifier of the latest round.
+    uint80 public latestRound;
+
+    mapping(uint80 => Round) private rounds;
+
+    /// @notice Emitted when a new answer is pushed.
+    event AnswerUpdated(int256 indexed current, uint256 indexed roundId, uint256 updatedAt);
+
+    /// @notice Constructor for the PriceAggregator contract.
+    /// @param _decimals The number of decimals of the answers.
+    /// @param _description The description of the feed.
+    /// @param _initialAnswer The first answer of the feed.
+    constructor(uint8 _decimals, string memory _description, int256 _initialAnswer) {
+        owner = msg.sender;
+        decimals = _decimals;
+        description = _description;
+        _updateAnswer(_initialAnswer);
+    }
+
+    /// @notice Pushes a new answer and opens a new round.
+    /// @param answer The new answer, expressed with `decimals` decimals.
+    function updateAnswer(int256 answer) external {
+        require(msg.sender == owner, "Not authorized");
+        _updateAnswer(answer);
+    }
+
+    /// @notice Returns the version of the aggregator interface implemented.
+    function version() external pure override returns (uint256) {
+        return 4;
+    }
+
+    /// @notice Returns the data of a specific round.
+    /// @param _roundId The round to read.
+    function getRoundData(uint80 _roundId)
+        external
+        view
+        override
+        returns (uint80 roundId, int256 answer, uint256 startedAt, uint256 updatedAt, uint80 answeredInRound)
+    {
+        Round memory round = rounds[_roundId];
+        require(round.updatedAt > 0, "No data present");
+        return (_roundId, round.answer, round.startedAt, round.updatedAt, _roundId);
+    }
+
+    /// @notice Returns the data of the latest round.
+    function latestRoundData()
+        external
+        view
+        override
+        returns (uint80 roundId, int256 answer, uint256 startedAt, uint256 updatedAt, uint80 answeredInRound)
+    {
+        Round memory round = rounds[latestRound];
+        return (latestRound, round.answer, round.startedAt, round.updatedAt, latestRound);
+    }
+
+    function _updateAnswer(int256 answer) internal {
+        latestRound++;
+        rounds[latestRound] = Round({answer: answer, startedAt: block.timestamp, updatedAt: block.timestamp});
+        emit AnswerUpdated(answer, latestRound, block.timestamp);
+    }
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT
+pragma solidity 0.8.29;
+
+import "forge-std/Test.sol";
+import "../src/PriceAggregator.sol";
+
+contract PriceAggregatorTest is Test {
+    PriceAggregator public aggregator;
+    int256 public initialAnswer = 1 * 10 ** 8;
+
+    function setUp() public {
+        aggregator = new PriceAggregator(8, "LBT / USD", initialAnswer);
+    }
+
+    function testInitialRound() public view {
+        (uint80 roundId, int256 answer,, uint256 updatedAt, uint80 answeredInRound) = aggregator.latestRoundData();
+        assertEq(roundId, 1, "Incorrect round");
+        assertEq(answer, initialAnswer, "Incorrect answer");
+        assertEq(updatedAt, block.timestamp, "Incorrect update time");
+        assertEq(answeredInRound, roundId, "Incorrect answered round");
+        assertEq(aggregator.decimals(), 8, "Incorrect decimals");
+    }
+
+    function testUpdateAnswer() public {
+        vm.warp(block.timestamp + 1 hours);
+        aggregator.updateAnswer(2 * 10 ** 8);
+
+        (uint80 roundId, int256 answer,, uint256 updatedAt,) = aggregator.latestRoundData();
+        assertEq(roundId, 2, "Incorrect round");
+        assertEq(answer, 2 * 10 ** 8, "Incorrect answer");
+        assertEq(updatedAt, block.timestamp, "Incorrect update time");
+
+        (, int256 previousAnswer,,,) = aggregator.getRoundData(1);
+        assertEq(previousAnswer, initialAnswer, "Previous round overwritten");
+    }
+
+    function testUpdateAnswerNotOwner() public {
+        vm.prank(address(0xBEEF));
+        vm.expectRevert("Not authorized");
+        aggregator.updateAnswer(2 * 10 ** 8);
+    }
+
+    function testGetRoundDataMissing() public {
+        vm.expectRevert("No data present");
+        aggregator.getRoundData(42);
+    }
+}